@@ -1,5 +0,0 @@
--- Users
-INSERT INTO users (user_id, user_name, password, email, created_at, updated_at) VALUES
-('hoge','user1', 'hogehoge', 'hogehoge@gmail.com', '2023-05-01 04:00:00', '2023-05-01 04:00:00'),
-('huge','user2', 'password2', 'user2@example.com', '2023-05-01 04:00:00', '2023-05-01 04:00:00'),
-('hage','user3', 'password3', 'user3@example.com', '2023-05-01 04:00:00', '2023-05-01 04:00:00');
\ No newline at end of file
@@ -0,0 +1,32 @@
+package auth
+
+import "context"
+
+type contextKey string
+
+const (
+	userIdKey         contextKey = "authUserId"
+	organizationIdKey contextKey = "authOrganizationId"
+)
+
+// 認証済みユーザーIDをコンテキストに格納する
+func WithUserId(ctx context.Context, userId string) context.Context {
+	return context.WithValue(ctx, userIdKey, userId)
+}
+
+// コンテキストから認証済みユーザーIDを取得する
+func UserIdFromContext(ctx context.Context) (string, bool) {
+	userId, ok := ctx.Value(userIdKey).(string)
+	return userId, ok && userId != ""
+}
+
+// アクセストークンで指定された組織IDをコンテキストに格納する
+func WithOrganizationId(ctx context.Context, organizationId string) context.Context {
+	return context.WithValue(ctx, organizationIdKey, organizationId)
+}
+
+// コンテキストからアクセストークンで指定された組織IDを取得する
+func OrganizationIdFromContext(ctx context.Context) (string, bool) {
+	organizationId, ok := ctx.Value(organizationIdKey).(string)
+	return organizationId, ok && organizationId != ""
+}
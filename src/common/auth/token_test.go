@@ -0,0 +1,74 @@
+package auth_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Go_CleanArch/common/auth"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenIssuer(t *testing.T) {
+	t.Parallel()
+
+	secret := "test-secret-0123456789"
+
+	t.Run("正常系: 発行したトークンを検証できる", func(t *testing.T) {
+		t.Parallel()
+
+		issuer, err := auth.NewTokenIssuer(secret, time.Hour)
+		assert.NoError(t, err)
+		token, err := issuer.Issue("user123")
+		assert.NoError(t, err)
+
+		claims, err := issuer.Parse(token)
+		assert.NoError(t, err)
+		assert.Equal(t, "user123", claims.Subject)
+	})
+
+	t.Run("正常系: 組織を指定したトークンを検証できる", func(t *testing.T) {
+		t.Parallel()
+
+		issuer, _ := auth.NewTokenIssuer(secret, time.Hour)
+		token, err := issuer.IssueForOrganization("user123", "org123")
+		assert.NoError(t, err)
+
+		claims, err := issuer.Parse(token)
+		assert.NoError(t, err)
+		assert.Equal(t, "user123", claims.Subject)
+		assert.Equal(t, "org123", claims.OrganizationId)
+	})
+
+	t.Run("異常系: 署名鍵が短い", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := auth.NewTokenIssuer("short", time.Hour)
+		assert.Error(t, err)
+	})
+
+	t.Run("異常系: 改ざんされたトークン", func(t *testing.T) {
+		t.Parallel()
+
+		issuer, _ := auth.NewTokenIssuer(secret, time.Hour)
+		other, _ := auth.NewTokenIssuer("other-secret-0123456789", time.Hour)
+		token, _ := other.Issue("user123")
+
+		_, err := issuer.Parse(token)
+		assert.ErrorIs(t, err, auth.ErrInvalidToken)
+		_, err = issuer.Parse(strings.TrimSuffix(token, token[len(token)-2:]))
+		assert.ErrorIs(t, err, auth.ErrInvalidToken)
+	})
+
+	t.Run("異常系: 有効期限切れのトークン", func(t *testing.T) {
+		t.Parallel()
+
+		issuer, _ := auth.NewTokenIssuer(secret, time.Hour)
+		issuer.Now = func() time.Time { return time.Now().Add(-2 * time.Hour) }
+		token, _ := issuer.Issue("user123")
+		issuer.Now = time.Now
+
+		_, err := issuer.Parse(token)
+		assert.ErrorIs(t, err, auth.ErrExpiredToken)
+	})
+}
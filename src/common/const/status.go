@@ -41,6 +41,14 @@ var ErrorStatusMap = map[string]Status{
 		StatusCode: 404,
 		StatusName: "Not Found",
 	},
+	"CONFLICT": {
+		StatusCode: 409,
+		StatusName: "Conflict",
+	},
+	"PAYLOAD_TOO_LARGE": {
+		StatusCode: 413,
+		StatusName: "Payload Too Large",
+	},
 	"INTERNAL_SERVER_ERROR": {
 		StatusCode: 500,
 		StatusName: "Internal Server Error",
@@ -0,0 +1,42 @@
+// Package correlation はリクエストを識別する値をコンテキストで受け渡し、
+// 同じリクエストで出力したログ(リクエストログ・SQLのログなど)を関連付けます。
+package correlation
+
+import (
+	"context"
+
+	"github.com/Go_CleanArch/common/auth"
+	"github.com/Go_CleanArch/common/tenant"
+	log "github.com/sirupsen/logrus"
+)
+
+type contextKey string
+
+const requestIdKey contextKey = "requestId"
+
+// リクエストIDをコンテキストに格納する
+func WithRequestId(ctx context.Context, requestId string) context.Context {
+	return context.WithValue(ctx, requestIdKey, requestId)
+}
+
+// コンテキストからリクエストIDを取得する
+func RequestIdFromContext(ctx context.Context) (string, bool) {
+	requestId, ok := ctx.Value(requestIdKey).(string)
+	return requestId, ok && requestId != ""
+}
+
+// LogFields はコンテキストのリクエストID・認証済みユーザーID・テナントをログの項目として返す
+// コンテキストに無い項目は含めない。
+func LogFields(ctx context.Context) log.Fields {
+	fields := log.Fields{}
+	if requestId, ok := RequestIdFromContext(ctx); ok {
+		fields["requestId"] = requestId
+	}
+	if userId, ok := auth.UserIdFromContext(ctx); ok {
+		fields["userId"] = userId
+	}
+	if t, ok := tenant.FromContext(ctx); ok {
+		fields["organizationId"] = t.OrganizationId
+	}
+	return fields
+}
@@ -0,0 +1,33 @@
+package correlation_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Go_CleanArch/common/auth"
+	"github.com/Go_CleanArch/common/correlation"
+	"github.com/Go_CleanArch/common/tenant"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogFields(t *testing.T) {
+	t.Parallel()
+
+	t.Run("正常系_コンテキストの値をログの項目とする", func(t *testing.T) {
+		t.Parallel()
+		ctx := correlation.WithRequestId(context.Background(), "req-1")
+		ctx = auth.WithUserId(ctx, "user-1")
+		ctx = tenant.WithTenant(ctx, tenant.Tenant{OrganizationId: "org-1", Role: "owner"})
+
+		assert.Equal(t, log.Fields{"requestId": "req-1", "userId": "user-1", "organizationId": "org-1"}, correlation.LogFields(ctx))
+	})
+
+	t.Run("正常系_コンテキストに無い項目は含めない", func(t *testing.T) {
+		t.Parallel()
+		_, ok := correlation.RequestIdFromContext(context.Background())
+
+		assert.False(t, ok)
+		assert.Empty(t, correlation.LogFields(context.Background()))
+	})
+}
@@ -1,7 +1,9 @@
 package crypto
 
 import (
+	"crypto/rand"
 	"crypto/sha256"
+	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
 
@@ -76,15 +78,39 @@ func GenerateUUIDWithDate() string {
 	return shortHash
 }
 
-// ユーザーIDの暗号化
-func GenerateUserId(email string) string {
-	// SHA-256ハッシュ関数を使用してハッシュ値を生成
-	hasher := sha256.New()
-	hasher.Write([]byte(email))
-	hash := hex.EncodeToString(hasher.Sum(nil))
+// 時系列順に並ぶ不透明なID(UUIDv7)を生成
+func GenerateId() (string, error) {
+	newUUID, err := uuid.NewV7()
+	if err != nil {
+		log.WithError(err).Error("Failed to generate ID")
+		return "", err
+	}
+	return newUUID.String(), nil
+}
 
-	// ハッシュ値から先頭6桁を取得
-	shortHash := hash[:12]
-	log.WithField("userId", shortHash).Info("User ID generated successfully")
-	return shortHash
+// ユーザーIDの生成
+// メールアドレス等の属性には依存しない、時系列順に並ぶ不透明なID(UUIDv7)を返す。
+func GenerateUserId() (string, error) {
+	userId, err := GenerateId()
+	if err != nil {
+		return "", err
+	}
+	log.WithField("userId", userId).Info("User ID generated successfully")
+	return userId, nil
+}
+
+// メール確認用などのランダムなトークンを生成
+func GenerateRandomToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		log.WithError(err).Error("Failed to generate random token")
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// トークンのハッシュ化(DBにはトークンそのものではなくハッシュ値を保存する)
+func HashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
 }
@@ -1,9 +1,11 @@
 package errors
 
 import (
+	goErrors "errors"
 	"fmt"
 	"strings"
 
+	status "github.com/Go_CleanArch/common/const"
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 )
 
@@ -11,6 +13,8 @@ type ApiErr struct {
 	Messages []ApiErrMessage `json:"messages"`
 	Status   int             `json:"status"`
 	Detail   string          `json:"detail"`
+	// エラーが発生したリクエストのID(ログと照合するため、エラーのレスポンスで返す)
+	RequestId string `json:"requestId,omitempty"`
 }
 
 type ApiErrMessage struct {
@@ -34,6 +38,34 @@ func OutputApiError(messages []ApiErrMessage, state int, detail string) *ApiErr
 	return &apiErr
 }
 
+// InvalidRequestError はリクエスト(ボディ・クエリパラメーター)を入力に変換できない場合のエラー
+func InvalidRequestError() *ApiErr {
+	return OutputApiError(
+		[]ApiErrMessage{
+			{
+				Key:   "request",
+				Value: "リクエストの形式が正しくありません",
+			},
+		},
+		status.ErrorStatusMap["BAD_REQUEST"].StatusCode,
+		status.ErrorStatusMap["BAD_REQUEST"].StatusName,
+	)
+}
+
+// InternalServerError は想定外のエラーが発生した場合のエラー(内部の情報は含めない)
+func InternalServerError() *ApiErr {
+	return OutputApiError(
+		[]ApiErrMessage{
+			{
+				Key:   "server",
+				Value: "サーバーでエラーが発生しました。時間をおいて再度お試しください",
+			},
+		},
+		status.ErrorStatusMap["INTERNAL_SERVER_ERROR"].StatusCode,
+		status.ErrorStatusMap["INTERNAL_SERVER_ERROR"].StatusName,
+	)
+}
+
 // addValidationErrors はバリデーションエラーを受け取り、ApiErrMessage スライスに追加します。
 func AddValidationErrors(apiErrMessages *[]ApiErrMessage, err error, errorLocation *ErrorLocation) {
 	if validationErrors, ok := err.(validation.Errors); ok {
@@ -47,6 +79,28 @@ func AddValidationErrors(apiErrMessages *[]ApiErrMessage, err error, errorLocati
 	}
 }
 
+// apiError は ApiErr を保持するエラー(ApiErr.Error で生成する)
+type apiError struct {
+	apiErr  ApiErr
+	message string
+}
+
+func (e *apiError) Error() string {
+	return e.message
+}
+
+// ApiErrFrom は err(ラップされたエラーを含む)が ApiErr.Error で生成したエラーの場合に、元の ApiErr を返します
+// ユースケースが返したエラーを、呼び出し元(コントローラーなど)がレスポンスに変換するために使用します
+func ApiErrFrom(err error) (*ApiErr, bool) {
+	var target *apiError
+	if !goErrors.As(err, &target) {
+		return nil, false
+	}
+	apiErr := target.apiErr
+	return &apiErr, true
+}
+
+// Error は ApiErr を保持するエラーを返します(ApiErrFrom で取り出せます)
 func (apiErr ApiErr) Error() error {
 	var errMessages []string
 	for _, msg := range apiErr.Messages {
@@ -64,11 +118,14 @@ func (apiErr ApiErr) Error() error {
 		errMessages = append(errMessages, errMsg)
 	}
 	errMsg := strings.Join(errMessages, ", ")
-	return fmt.Errorf(`	{
+	return &apiError{
+		apiErr: apiErr,
+		message: fmt.Sprintf(`	{
 		"messages": [
 		%s
 		],
 		"status": %d,
 		"detail": "%s"
-	}`, errMsg, apiErr.Status, apiErr.Detail)
+	}`, errMsg, apiErr.Status, apiErr.Detail),
+	}
 }
@@ -0,0 +1,39 @@
+package errors_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/Go_CleanArch/common/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApiErrFrom(t *testing.T) {
+	t.Parallel()
+
+	t.Run("正常系_ラップされたエラーから ApiErr を取り出す", func(t *testing.T) {
+		t.Parallel()
+		apiErr := errors.OutputApiError(
+			[]errors.ApiErrMessage{{Key: "email", Value: "Emailアドレスが存在しません"}},
+			404,
+			"Not Found",
+		)
+		err := fmt.Errorf("ログインできません: %w", apiErr.Error())
+
+		found, ok := errors.ApiErrFrom(err)
+
+		assert.True(t, ok)
+		assert.Equal(t, apiErr, found)
+		assert.Contains(t, err.Error(), "Emailアドレスが存在しません")
+	})
+
+	t.Run("異常系_ApiErr を保持しないエラー", func(t *testing.T) {
+		t.Parallel()
+		found, ok := errors.ApiErrFrom(fmt.Errorf("connection refused"))
+
+		assert.False(t, ok)
+		assert.Nil(t, found)
+		_, ok = errors.ApiErrFrom(nil)
+		assert.False(t, ok)
+	})
+}
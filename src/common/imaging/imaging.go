@@ -0,0 +1,85 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	stdDraw "image/draw"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/image/draw"
+)
+
+// デコードを許可する最大ピクセル数(解凍爆弾対策)
+const maxPixels = 40_000_000
+
+var ErrTooManyPixels = errors.New("画像の解像度が大きすぎます")
+
+// 画像のデコード
+// 事前にヘッダーのみを読み込み、解像度が上限を超える場合はデコードしない。
+func Decode(data []byte) (image.Image, string, error) {
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		log.WithError(err).Error("Failed to decode image config")
+		return nil, "", err
+	}
+	if config.Width*config.Height > maxPixels {
+		return nil, "", ErrTooManyPixels
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		log.WithError(err).Error("Failed to decode image")
+		return nil, "", err
+	}
+	return img, format, nil
+}
+
+// 縦横比を保ったまま、長辺が指定した長さに収まるよう縮小する
+// 元画像が指定サイズより小さい場合は拡大しない。
+func Fit(src image.Image, maxSide int) image.Image {
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if longSide := max(width, height); longSide > maxSide {
+		width = max(1, width*maxSide/longSide)
+		height = max(1, height*maxSide/longSide)
+	}
+	return scale(src, bounds, width, height)
+}
+
+// 画像を正方形に切り抜き、指定した一辺の長さに縮小する
+// 元画像が指定サイズより小さい場合は拡大しない。
+func SquareThumbnail(src image.Image, size int) image.Image {
+	bounds := src.Bounds()
+	side := min(bounds.Dx(), bounds.Dy())
+	crop := image.Rect(0, 0, side, side).Add(image.Pt(
+		bounds.Min.X+(bounds.Dx()-side)/2,
+		bounds.Min.Y+(bounds.Dy()-side)/2,
+	))
+	size = min(size, side)
+	return scale(src, crop, size, size)
+}
+
+func scale(src image.Image, srcRect image.Rectangle, width int, height int) image.Image {
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	// 透過部分は白で塗りつぶす(JPEGは透過を扱えないため)
+	stdDraw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, stdDraw.Src)
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, srcRect, draw.Over, nil)
+	return dst
+}
+
+// JPEGへのエンコード
+// 再エンコードした画像にはEXIF等のメタデータは含まれない。
+func EncodeJPEG(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 85}); err != nil {
+		log.WithError(err).Error("Failed to encode JPEG")
+		return nil, fmt.Errorf("画像のエンコードに失敗しました: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,39 @@
+package tenant
+
+import "context"
+
+type contextKey string
+
+const (
+	tenantKey   contextKey = "tenant"
+	unscopedKey contextKey = "tenantUnscoped"
+)
+
+// Tenant はリクエストの対象となる組織と、組織内でのユーザーのロールを表す。
+type Tenant struct {
+	OrganizationId string
+	Role           string
+}
+
+// テナントをコンテキストに格納する
+func WithTenant(ctx context.Context, tenant Tenant) context.Context {
+	return context.WithValue(ctx, tenantKey, tenant)
+}
+
+// コンテキストからテナントを取得する
+func FromContext(ctx context.Context) (Tenant, bool) {
+	tenant, ok := ctx.Value(tenantKey).(Tenant)
+	return tenant, ok && tenant.OrganizationId != ""
+}
+
+// テナントによる絞り込みを行わないことを明示する
+// 組織横断の処理(所属組織の一覧、サブドメインからの組織の解決、退会処理など)に限って使用する。
+func WithoutScope(ctx context.Context) context.Context {
+	return context.WithValue(ctx, unscopedKey, true)
+}
+
+// テナントによる絞り込みが明示的に解除されているか
+func IsUnscoped(ctx context.Context) bool {
+	unscoped, _ := ctx.Value(unscopedKey).(bool)
+	return unscoped
+}
@@ -0,0 +1,35 @@
+// domain/entity/avatar.go
+package entity
+
+import "fmt"
+
+// アバター画像の種類(元画像と正方形サムネイル)
+const (
+	AvatarVariantOriginal = "original"
+	AvatarVariantLarge    = "256"
+	AvatarVariantSmall    = "64"
+)
+
+// AvatarVariants は保存するアバター画像の種類を返す。
+func AvatarVariants() []string {
+	return []string{AvatarVariantOriginal, AvatarVariantLarge, AvatarVariantSmall}
+}
+
+// AvatarObjectKey はアバター画像の種類ごとのオブジェクトキーを返す。
+func AvatarObjectKey(avatarKey string, variant string) string {
+	return fmt.Sprintf("%s/%s.jpg", avatarKey, variant)
+}
+
+// AvatarObject はストレージに保存する画像1件を表す。
+type AvatarObject struct {
+	Key         string
+	ContentType string
+	Body        []byte
+}
+
+// Avatar はユーザーのアバター画像一式を表す。
+// AvatarKey はユーザーに紐づけて保存し、各画像のキーは AvatarObjectKey で導出する。
+type Avatar struct {
+	AvatarKey string
+	Objects   []AvatarObject
+}
@@ -0,0 +1,108 @@
+// domain/entity/email_change_request.go
+package entity
+
+import (
+	"time"
+
+	status "github.com/Go_CleanArch/common/const"
+	"github.com/Go_CleanArch/common/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// EmailChangeRequest はメールアドレス変更の申請を表す。
+// ConfirmToken / CancelToken はメール送信にのみ使用し、永続化するのはハッシュ値のみとする。
+type EmailChangeRequest struct {
+	EmailChangeRequestID string
+	UserID               string
+	CurrentEmail         string
+	NewEmail             string
+	ConfirmToken         string
+	ConfirmTokenHash     string
+	CancelToken          string
+	CancelTokenHash      string
+	ExpiresAt            time.Time
+}
+
+type EmailChangeRequestOption func(*EmailChangeRequest) ([]errors.ApiErrMessage, error)
+
+func NewEmailChangeRequest(opts ...EmailChangeRequestOption) (*EmailChangeRequest, *errors.ApiErr) {
+	apiErrMessages := make([]errors.ApiErrMessage, 0)
+	request := &EmailChangeRequest{}
+	for _, opt := range opts {
+		setErrMessages, err := opt(request)
+		if err != nil {
+			// エラーが発生した場合は、Internal Server Error を返す
+			log.WithError(err).Error("email change request domain entity INTERNAL_SERVER_ERROR")
+			apiErr := errors.InternalServerError()
+			return nil, apiErr
+		}
+		apiErrMessages = append(apiErrMessages, setErrMessages...)
+	}
+
+	// エラーメッセージがある場合は、EnableCheckError を返す
+	if len(apiErrMessages) > 0 {
+		apiErr := errors.OutputApiError(
+			apiErrMessages,
+			status.ErrorStatusMap["ENABLE_CHECK_ERROR"].StatusCode,
+			status.ErrorStatusMap["ENABLE_CHECK_ERROR"].StatusName,
+		)
+		return nil, apiErr
+	}
+
+	return request, nil
+}
+
+func WithEmailChangeRequestID(emailChangeRequestID string) EmailChangeRequestOption {
+	return func(r *EmailChangeRequest) ([]errors.ApiErrMessage, error) {
+		r.EmailChangeRequestID = emailChangeRequestID
+		return nil, nil
+	}
+}
+
+func WithEmailChangeUserID(userID string) EmailChangeRequestOption {
+	return func(r *EmailChangeRequest) ([]errors.ApiErrMessage, error) {
+		r.UserID = userID
+		return nil, nil
+	}
+}
+
+// 変更前後のメールアドレス。同一アドレスへの変更は受け付けない
+func WithEmails(currentEmail string, newEmail string) EmailChangeRequestOption {
+	return func(r *EmailChangeRequest) ([]errors.ApiErrMessage, error) {
+		newEmail = NormalizeEmail(newEmail)
+		if NormalizeEmail(currentEmail) == newEmail {
+			return []errors.ApiErrMessage{
+				{
+					Key:   "email",
+					Value: "現在と同じメールアドレスです",
+				},
+			}, nil
+		}
+		r.CurrentEmail = currentEmail
+		r.NewEmail = newEmail
+		return nil, nil
+	}
+}
+
+func WithConfirmToken(token string, tokenHash string) EmailChangeRequestOption {
+	return func(r *EmailChangeRequest) ([]errors.ApiErrMessage, error) {
+		r.ConfirmToken = token
+		r.ConfirmTokenHash = tokenHash
+		return nil, nil
+	}
+}
+
+func WithCancelToken(token string, tokenHash string) EmailChangeRequestOption {
+	return func(r *EmailChangeRequest) ([]errors.ApiErrMessage, error) {
+		r.CancelToken = token
+		r.CancelTokenHash = tokenHash
+		return nil, nil
+	}
+}
+
+func WithExpiresAt(expiresAt time.Time) EmailChangeRequestOption {
+	return func(r *EmailChangeRequest) ([]errors.ApiErrMessage, error) {
+		r.ExpiresAt = expiresAt
+		return nil, nil
+	}
+}
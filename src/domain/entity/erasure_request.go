@@ -0,0 +1,14 @@
+// domain/entity/erasure_request.go
+package entity
+
+import "time"
+
+// ErasureRequest は個人データ削除(忘れられる権利)の申請を表す。
+// ConfirmToken はメール送信にのみ使用し、永続化するのはハッシュ値のみとする。
+type ErasureRequest struct {
+	ErasureRequestID string
+	UserID           string
+	ConfirmToken     string
+	ConfirmTokenHash string
+	ExpiresAt        time.Time
+}
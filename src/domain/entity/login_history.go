@@ -0,0 +1,19 @@
+// domain/entity/login_history.go
+package entity
+
+import (
+	"time"
+
+	"github.com/Go_CleanArch/domain/event"
+)
+
+// LoginHistory はログインの履歴を表す。
+type LoginHistory struct {
+	LoginHistoryID string
+	UserID         string
+	IpAddress      string
+	UserAgent      string
+	LoggedInAt     time.Time
+	// ログインにより発生したドメインイベント
+	event.Recorder
+}
@@ -0,0 +1,38 @@
+// domain/entity/organization.go
+package entity
+
+// 組織内のロール
+const (
+	OrganizationRoleOwner  = "owner"
+	OrganizationRoleAdmin  = "admin"
+	OrganizationRoleMember = "member"
+)
+
+// Organization はサービスを利用する組織(テナント)を表す。
+// Slug はサブドメインによる組織の識別に使用する。
+type Organization struct {
+	OrganizationID string
+	Name           string
+	Slug           string
+}
+
+// OrganizationMembership はユーザーの組織への所属と、組織内でのロールを表す。
+type OrganizationMembership struct {
+	OrganizationID string
+	UserID         string
+	Role           string
+}
+
+// 定義済みのロールか
+func IsOrganizationRole(role string) bool {
+	switch role {
+	case OrganizationRoleOwner, OrganizationRoleAdmin, OrganizationRoleMember:
+		return true
+	}
+	return false
+}
+
+// メンバーの追加など、組織の管理操作が可能なロールか
+func CanManageOrganization(role string) bool {
+	return role == OrganizationRoleOwner || role == OrganizationRoleAdmin
+}
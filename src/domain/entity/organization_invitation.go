@@ -0,0 +1,17 @@
+// domain/entity/organization_invitation.go
+package entity
+
+import "time"
+
+// OrganizationInvitation は組織への招待を表す。
+// Token は招待メールの送信にのみ使用し、永続化するのはハッシュ値のみとする。
+type OrganizationInvitation struct {
+	InvitationID   string
+	OrganizationID string
+	Email          string
+	Role           string
+	InvitedBy      string
+	Token          string `json:"-"`
+	TokenHash      string
+	ExpiresAt      time.Time
+}
@@ -0,0 +1,20 @@
+// domain/entity/privacy_audit_log.go
+package entity
+
+import "time"
+
+// 個人データに関する請求の種別
+const (
+	PrivacyActionDataExport       = "data_export"
+	PrivacyActionErasureRequested = "erasure_requested"
+	PrivacyActionErasureCompleted = "erasure_completed"
+)
+
+// PrivacyAuditLog は個人データの開示・削除請求の監査記録を表す。
+type PrivacyAuditLog struct {
+	PrivacyAuditLogID string
+	UserID            string
+	Action            string
+	IpAddress         string
+	CreatedAt         time.Time
+}
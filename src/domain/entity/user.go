@@ -2,8 +2,11 @@
 package entity
 
 import (
+	"strings"
+
 	status "github.com/Go_CleanArch/common/const"
 	"github.com/Go_CleanArch/common/errors"
+	"github.com/Go_CleanArch/domain/event"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -12,6 +15,8 @@ type User struct {
 	UserName string
 	Password string
 	Email    string
+	// ユーザーに発生したドメインイベント
+	event.Recorder
 }
 
 type UserOption func(*User) ([]errors.ApiErrMessage, error)
@@ -24,15 +29,7 @@ func NewUser(opts ...UserOption) (*User, *errors.ApiErr) {
 		if err != nil {
 			// エラーが発生した場合は、Internal Server Error を返す
 			log.WithError(err).Error("login user domain entity INTERNAL_SERVER_ERROR")
-			apiErr := errors.OutputApiError([]errors.ApiErrMessage{
-				{
-					Key:   "undefined",
-					Value: err.Error(),
-				},
-			},
-				status.ErrorStatusMap["INTERNAL_SERVER_ERROR"].StatusCode,
-				status.ErrorStatusMap["INTERNAL_SERVER_ERROR"].StatusName,
-			)
+			apiErr := errors.InternalServerError()
 			return nil, apiErr
 		}
 		apiErrMessages = append(apiErrMessages, setErrMessages...)
@@ -74,7 +71,13 @@ func WithPassword(password string) UserOption {
 
 func WithEmail(email string) UserOption {
 	return func(u *User)  ([]errors.ApiErrMessage, error) {
-		u.Email = email
+		u.Email = NormalizeEmail(email)
 		return nil, nil
 	}
 }
+
+// メールアドレスの正規化
+// 大文字・小文字の違いは同一のアドレスとして扱うため、小文字に揃えて保存・比較する。
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
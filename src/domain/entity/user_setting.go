@@ -0,0 +1,270 @@
+// domain/entity/user_setting.go
+package entity
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+	// 実行環境にタイムゾーンデータベースが無い場合でもタイムゾーンを検証できるようにする
+	_ "time/tzdata"
+
+	status "github.com/Go_CleanArch/common/const"
+	"github.com/Go_CleanArch/common/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// 設定項目のキー
+const (
+	SettingLocale                    = "locale"
+	SettingTimezone                  = "timezone"
+	SettingNotificationLoginAlert    = "notification.loginAlert"
+	SettingNotificationProductUpdate = "notification.productUpdate"
+	SettingUiTheme                   = "ui.theme"
+)
+
+// 設定値の型
+type SettingKind string
+
+const (
+	SettingKindString SettingKind = "string"
+	SettingKindBool   SettingKind = "bool"
+)
+
+// SettingDefinition は設定項目のスキーマ(型・既定値・入力チェック)を表す。
+type SettingDefinition struct {
+	Key     string
+	Kind    SettingKind
+	Default interface{}
+	// 文字列型の入力チェック(nil の場合は型のみ検証する)
+	validate func(value string) string
+}
+
+// 設定項目のスキーマ一覧
+var settingDefinitions = map[string]SettingDefinition{
+	SettingLocale: {
+		Key:      SettingLocale,
+		Kind:     SettingKindString,
+		Default:  "ja",
+		validate: oneOf("言語は ja / en のいずれかを指定してください", "ja", "en"),
+	},
+	SettingTimezone: {
+		Key:      SettingTimezone,
+		Kind:     SettingKindString,
+		Default:  "Asia/Tokyo",
+		validate: validTimezone,
+	},
+	SettingNotificationLoginAlert: {
+		Key:     SettingNotificationLoginAlert,
+		Kind:    SettingKindBool,
+		Default: true,
+	},
+	SettingNotificationProductUpdate: {
+		Key:     SettingNotificationProductUpdate,
+		Kind:    SettingKindBool,
+		Default: false,
+	},
+	SettingUiTheme: {
+		Key:      SettingUiTheme,
+		Kind:     SettingKindString,
+		Default:  "system",
+		validate: oneOf("テーマは system / light / dark のいずれかを指定してください", "system", "light", "dark"),
+	},
+}
+
+func oneOf(message string, allowed ...string) func(value string) string {
+	return func(value string) string {
+		for _, a := range allowed {
+			if value == a {
+				return ""
+			}
+		}
+		return message
+	}
+}
+
+func validTimezone(value string) string {
+	// 空文字はUTCとして解釈されるため、明示的に拒否する
+	if value == "" {
+		return "タイムゾーンを指定してください"
+	}
+	if _, err := time.LoadLocation(value); err != nil {
+		return "タイムゾーンは Asia/Tokyo 形式で指定してください"
+	}
+	return ""
+}
+
+// 定義済みの設定項目のキー一覧(キー順)
+func SettingKeys() []string {
+	keys := make([]string, 0, len(settingDefinitions))
+	for key := range settingDefinitions {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// UserSettings はユーザーの設定値を表す。
+// 未設定の項目はスキーマの既定値で補完される。
+type UserSettings struct {
+	UserID string
+	values map[string]interface{}
+	// 今回の変更で値が変わった項目(永続化用にJSONエンコード済み)
+	changed map[string]string
+}
+
+type UserSettingsOption func(*UserSettings) ([]errors.ApiErrMessage, error)
+
+func NewUserSettings(opts ...UserSettingsOption) (*UserSettings, *errors.ApiErr) {
+	apiErrMessages := make([]errors.ApiErrMessage, 0)
+	settings := &UserSettings{
+		values:  make(map[string]interface{}, len(settingDefinitions)),
+		changed: make(map[string]string),
+	}
+	for key, definition := range settingDefinitions {
+		settings.values[key] = definition.Default
+	}
+	for _, opt := range opts {
+		setErrMessages, err := opt(settings)
+		if err != nil {
+			// エラーが発生した場合は、Internal Server Error を返す
+			log.WithError(err).Error("user settings domain entity INTERNAL_SERVER_ERROR")
+			apiErr := errors.InternalServerError()
+			return nil, apiErr
+		}
+		apiErrMessages = append(apiErrMessages, setErrMessages...)
+	}
+
+	// エラーメッセージがある場合は、EnableCheckError を返す
+	if len(apiErrMessages) > 0 {
+		apiErr := errors.OutputApiError(
+			apiErrMessages,
+			status.ErrorStatusMap["ENABLE_CHECK_ERROR"].StatusCode,
+			status.ErrorStatusMap["ENABLE_CHECK_ERROR"].StatusName,
+		)
+		return nil, apiErr
+	}
+
+	return settings, nil
+}
+
+func WithUserSettingsUserID(userID string) UserSettingsOption {
+	return func(s *UserSettings) ([]errors.ApiErrMessage, error) {
+		s.UserID = userID
+		return nil, nil
+	}
+}
+
+// 保存済みの設定値(キー → JSONエンコードされた値)
+// スキーマから削除された項目や不正な値は読み飛ばし、既定値を使用する。
+func WithStoredSettings(stored map[string]string) UserSettingsOption {
+	return func(s *UserSettings) ([]errors.ApiErrMessage, error) {
+		for key, raw := range stored {
+			definition, ok := settingDefinitions[key]
+			if !ok {
+				log.WithField("key", key).Warn("Unknown user setting is ignored")
+				continue
+			}
+			value, message := decodeSettingValue(definition, json.RawMessage(raw))
+			if message != "" {
+				log.WithField("key", key).Warn("Invalid user setting is ignored")
+				continue
+			}
+			s.values[key] = value
+		}
+		return nil, nil
+	}
+}
+
+// 設定値の変更(キー → JSON値)。未定義のキーや不正な値はエラーとする
+func WithSettingChanges(changes map[string]json.RawMessage) UserSettingsOption {
+	return func(s *UserSettings) ([]errors.ApiErrMessage, error) {
+		var apiErrMessages []errors.ApiErrMessage
+		for key, raw := range changes {
+			definition, ok := settingDefinitions[key]
+			if !ok {
+				apiErrMessages = append(apiErrMessages, errors.ApiErrMessage{
+					Key:   key,
+					Value: "未定義の設定項目です",
+				})
+				continue
+			}
+			value, message := decodeSettingValue(definition, raw)
+			if message != "" {
+				apiErrMessages = append(apiErrMessages, errors.ApiErrMessage{
+					Key:   key,
+					Value: message,
+				})
+				continue
+			}
+			encoded, err := json.Marshal(value)
+			if err != nil {
+				return nil, err
+			}
+			s.values[key] = value
+			s.changed[key] = string(encoded)
+		}
+		return apiErrMessages, nil
+	}
+}
+
+func decodeSettingValue(definition SettingDefinition, raw json.RawMessage) (interface{}, string) {
+	switch definition.Kind {
+	case SettingKindBool:
+		var value bool
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return nil, "true または false を指定してください"
+		}
+		return value, ""
+	case SettingKindString:
+		var value string
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return nil, "文字列を指定してください"
+		}
+		if definition.validate != nil {
+			if message := definition.validate(value); message != "" {
+				return nil, message
+			}
+		}
+		return value, ""
+	default:
+		return nil, fmt.Sprintf("未対応の設定値の型です: %s", definition.Kind)
+	}
+}
+
+// 全項目の設定値(既定値で補完済み)
+func (s *UserSettings) Values() map[string]interface{} {
+	values := make(map[string]interface{}, len(s.values))
+	for key, value := range s.values {
+		values[key] = value
+	}
+	return values
+}
+
+// 変更された項目(キー → JSONエンコードされた値)
+func (s *UserSettings) Changed() map[string]string {
+	return s.changed
+}
+
+func (s *UserSettings) Locale() string {
+	return s.values[SettingLocale].(string)
+}
+
+// 日時表示に使用するタイムゾーン
+func (s *UserSettings) Location() *time.Location {
+	location, err := time.LoadLocation(s.values[SettingTimezone].(string))
+	if err != nil {
+		return time.UTC
+	}
+	return location
+}
+
+func (s *UserSettings) UiTheme() string {
+	return s.values[SettingUiTheme].(string)
+}
+
+// 通知の受信設定
+func (s *UserSettings) NotificationEnabled(key string) bool {
+	enabled, ok := s.values[key].(bool)
+	return ok && enabled
+}
@@ -0,0 +1,35 @@
+// Package event はドメインで発生した出来事(ドメインイベント)を表す。
+// イベントは集約・ファクトリが状態の変更とあわせて記録し、ユースケースが状態の変更と同じトランザクションで
+// アウトボックスへ保存する。保存したイベントは非同期に購読者へ配信される。
+package event
+
+import "time"
+
+// Event はドメインイベントを表す。
+// 購読者へは JSON にエンコードした内容を配信するため、配信する項目には json タグを指定する。
+type Event interface {
+	// イベントの種類(購読の単位)
+	EventName() string
+	// イベントが発生した集約の種類・ID(同じ集約のイベントは発生順に配信する)
+	AggregateType() string
+	AggregateId() string
+	OccurredAt() time.Time
+}
+
+// Recorder は集約で発生したイベントを保持する。
+// 集約に埋め込んで使用し、ユースケースは PullEvents で取り出したイベントを保存する。
+type Recorder struct {
+	events []Event
+}
+
+// イベントの記録
+func (r *Recorder) Record(events ...Event) {
+	r.events = append(r.events, events...)
+}
+
+// 記録したイベントを発生順に取り出す(取り出したイベントは保持しない)
+func (r *Recorder) PullEvents() []Event {
+	events := r.events
+	r.events = nil
+	return events
+}
@@ -0,0 +1,51 @@
+package event
+
+import "time"
+
+// ユーザーに関するイベントの種類
+const (
+	UserRegisteredName   = "user.registered"
+	UserLoggedInName     = "user.loggedIn"
+	UserEmailChangedName = "user.emailChanged"
+)
+
+// 集約の種類
+const AggregateUser = "user"
+
+// UserRegistered はユーザーが登録されたことを表す。
+type UserRegistered struct {
+	UserId       string    `json:"userId"`
+	Email        string    `json:"email"`
+	RegisteredAt time.Time `json:"registeredAt"`
+}
+
+func (e UserRegistered) EventName() string     { return UserRegisteredName }
+func (e UserRegistered) AggregateType() string { return AggregateUser }
+func (e UserRegistered) AggregateId() string   { return e.UserId }
+func (e UserRegistered) OccurredAt() time.Time { return e.RegisteredAt }
+
+// UserLoggedIn はユーザーがログインしたことを表す。
+type UserLoggedIn struct {
+	UserId     string    `json:"userId"`
+	IpAddress  string    `json:"ipAddress"`
+	UserAgent  string    `json:"userAgent"`
+	LoggedInAt time.Time `json:"loggedInAt"`
+}
+
+func (e UserLoggedIn) EventName() string     { return UserLoggedInName }
+func (e UserLoggedIn) AggregateType() string { return AggregateUser }
+func (e UserLoggedIn) AggregateId() string   { return e.UserId }
+func (e UserLoggedIn) OccurredAt() time.Time { return e.LoggedInAt }
+
+// UserEmailChanged はユーザーのメールアドレスの変更が確定したことを表す。
+type UserEmailChanged struct {
+	UserId        string    `json:"userId"`
+	PreviousEmail string    `json:"previousEmail"`
+	NewEmail      string    `json:"newEmail"`
+	ChangedAt     time.Time `json:"changedAt"`
+}
+
+func (e UserEmailChanged) EventName() string     { return UserEmailChangedName }
+func (e UserEmailChanged) AggregateType() string { return AggregateUser }
+func (e UserEmailChanged) AggregateId() string   { return e.UserId }
+func (e UserEmailChanged) OccurredAt() time.Time { return e.ChangedAt }
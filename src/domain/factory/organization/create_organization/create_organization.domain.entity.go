@@ -0,0 +1,113 @@
+package createorganization
+
+import (
+	status "github.com/Go_CleanArch/common/const"
+	"github.com/Go_CleanArch/common/crypto"
+	"github.com/Go_CleanArch/common/errors"
+	entity "github.com/Go_CleanArch/domain/entity"
+	log "github.com/sirupsen/logrus"
+)
+
+// サブドメインとして使用できないスラッグ
+var reservedSlugs = map[string]bool{
+	"www":   true,
+	"api":   true,
+	"app":   true,
+	"admin": true,
+	"mail":  true,
+}
+
+type CreateOrganizationFactory struct {
+	GenerateId func() (string, error)
+}
+
+func NewCreateOrganizationFactory() *CreateOrganizationFactory {
+	return &CreateOrganizationFactory{
+		GenerateId: crypto.GenerateId,
+	}
+}
+
+// 組織の生成
+// 作成したユーザーを組織のオーナーとして所属させる。
+func (cf *CreateOrganizationFactory) CreateOrganization(ownerUserId string, name string, slug string) (*entity.Organization, *entity.OrganizationMembership, *errors.ApiErr) {
+	if reservedSlugs[slug] {
+		return nil, nil, enableCheckError("slug", "このスラッグは使用できません")
+	}
+
+	organizationId, err := cf.GenerateId()
+	if err != nil {
+		log.WithError(err).Error("Failed to generate organization id")
+		return nil, nil, errors.InternalServerError()
+	}
+
+	organization := &entity.Organization{
+		OrganizationID: organizationId,
+		Name:           name,
+		Slug:           slug,
+	}
+	owner := &entity.OrganizationMembership{
+		OrganizationID: organizationId,
+		UserID:         ownerUserId,
+		Role:           entity.OrganizationRoleOwner,
+	}
+	return organization, owner, nil
+}
+
+// メンバーの追加
+// オーナーは組織の作成者のみとし、追加時には付与できない。
+func (cf *CreateOrganizationFactory) AddMember(operatorRole string, organizationId string, userId string, role string) (*entity.OrganizationMembership, *errors.ApiErr) {
+	if !entity.CanManageOrganization(operatorRole) {
+		return nil, forbiddenError("メンバーを追加する権限がありません")
+	}
+	if !entity.IsOrganizationRole(role) || role == entity.OrganizationRoleOwner {
+		return nil, enableCheckError("role", "ロールは admin / member のいずれかを指定してください")
+	}
+
+	return &entity.OrganizationMembership{
+		OrganizationID: organizationId,
+		UserID:         userId,
+		Role:           role,
+	}, nil
+}
+
+// 既に所属しているユーザーを追加しようとした場合のメッセージ
+func MemberAlreadyExistsMessage() errors.ApiErrMessage {
+	return errors.ApiErrMessage{
+		Key:   "email",
+		Value: "すでに組織に所属しているユーザーです",
+	}
+}
+
+// 既に使用されているスラッグを指定した場合のメッセージ
+func SlugAlreadyExistsMessage() errors.ApiErrMessage {
+	return errors.ApiErrMessage{
+		Key:   "slug",
+		Value: "すでに使用されているスラッグです",
+	}
+}
+
+func enableCheckError(key string, value string) *errors.ApiErr {
+	return errors.OutputApiError(
+		[]errors.ApiErrMessage{
+			{
+				Key:   key,
+				Value: value,
+			},
+		},
+		status.ErrorStatusMap["ENABLE_CHECK_ERROR"].StatusCode,
+		status.ErrorStatusMap["ENABLE_CHECK_ERROR"].StatusName,
+	)
+}
+
+func forbiddenError(value string) *errors.ApiErr {
+	return errors.OutputApiError(
+		[]errors.ApiErrMessage{
+			{
+				Key:   "role",
+				Value: value,
+			},
+		},
+		status.ErrorStatusMap["FORBIDDEN"].StatusCode,
+		status.ErrorStatusMap["FORBIDDEN"].StatusName,
+	)
+}
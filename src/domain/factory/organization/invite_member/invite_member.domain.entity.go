@@ -0,0 +1,126 @@
+package invitemember
+
+import (
+	"time"
+
+	status "github.com/Go_CleanArch/common/const"
+	"github.com/Go_CleanArch/common/crypto"
+	"github.com/Go_CleanArch/common/errors"
+	entity "github.com/Go_CleanArch/domain/entity"
+	log "github.com/sirupsen/logrus"
+)
+
+// 招待の有効期間
+const InvitationTTL = 7 * 24 * time.Hour
+
+// InvitationState は永続化済みの招待の状態を表す。
+type InvitationState struct {
+	OrganizationID string
+	Email          string
+	Role           string
+	ExpiresAt      time.Time
+	AcceptedAt     *time.Time
+}
+
+type InviteMemberFactory struct {
+	GenerateId    func() (string, error)
+	GenerateToken func() (string, error)
+	Now           func() time.Time
+}
+
+func NewInviteMemberFactory() *InviteMemberFactory {
+	return &InviteMemberFactory{
+		GenerateId:    crypto.GenerateId,
+		GenerateToken: crypto.GenerateRandomToken,
+		Now:           time.Now,
+	}
+}
+
+// 招待の生成(オーナー・管理者のみ)
+// オーナーは組織の作成者のみとし、招待では付与できない。
+func (mf *InviteMemberFactory) Invite(operatorRole string, organizationId string, invitedBy string, email string, role string) (*entity.OrganizationInvitation, *errors.ApiErr) {
+	if !entity.CanManageOrganization(operatorRole) {
+		return nil, errors.OutputApiError(
+			[]errors.ApiErrMessage{
+				{
+					Key:   "role",
+					Value: "メンバーを招待する権限がありません",
+				},
+			},
+			status.ErrorStatusMap["FORBIDDEN"].StatusCode,
+			status.ErrorStatusMap["FORBIDDEN"].StatusName,
+		)
+	}
+	if !entity.IsOrganizationRole(role) || role == entity.OrganizationRoleOwner {
+		return nil, enableCheckError("role", "ロールは admin / member のいずれかを指定してください")
+	}
+
+	invitationId, err := mf.GenerateId()
+	if err != nil {
+		log.WithError(err).Error("Failed to generate invitation id")
+		return nil, errors.InternalServerError()
+	}
+	token, err := mf.GenerateToken()
+	if err != nil {
+		log.WithError(err).Error("Failed to generate invitation token")
+		return nil, errors.InternalServerError()
+	}
+
+	return &entity.OrganizationInvitation{
+		InvitationID:   invitationId,
+		OrganizationID: organizationId,
+		Email:          entity.NormalizeEmail(email),
+		Role:           role,
+		InvitedBy:      invitedBy,
+		Token:          token,
+		TokenHash:      crypto.HashToken(token),
+		ExpiresAt:      mf.Now().Add(InvitationTTL),
+	}, nil
+}
+
+// 招待の承諾可否チェック
+// 招待は宛先のメールアドレスのユーザーのみが承諾できる。
+func (mf *InviteMemberFactory) CheckAcceptable(state InvitationState, email string) *errors.ApiErr {
+	if state.AcceptedAt != nil {
+		return AlreadyAcceptedError()
+	}
+	if !mf.Now().Before(state.ExpiresAt) {
+		return enableCheckError("invitationToken", "招待の有効期限が切れています")
+	}
+	if entity.NormalizeEmail(state.Email) != entity.NormalizeEmail(email) {
+		return enableCheckError("email", "招待されたメールアドレスと一致しません")
+	}
+	return nil
+}
+
+// 招待の承諾による所属の生成
+func (mf *InviteMemberFactory) AcceptedMembership(state InvitationState, userId string) *entity.OrganizationMembership {
+	return &entity.OrganizationMembership{
+		OrganizationID: state.OrganizationID,
+		UserID:         userId,
+		Role:           state.Role,
+	}
+}
+
+// 存在しない招待トークンを指定した場合のエラー
+func InvalidInvitationError() *errors.ApiErr {
+	return enableCheckError("invitationToken", "招待が見つかりません")
+}
+
+// 承諾済みの招待を指定した場合のエラー
+func AlreadyAcceptedError() *errors.ApiErr {
+	return enableCheckError("invitationToken", "この招待はすでに承諾されています")
+}
+
+func enableCheckError(key string, value string) *errors.ApiErr {
+	return errors.OutputApiError(
+		[]errors.ApiErrMessage{
+			{
+				Key:   key,
+				Value: value,
+			},
+		},
+		status.ErrorStatusMap["ENABLE_CHECK_ERROR"].StatusCode,
+		status.ErrorStatusMap["ENABLE_CHECK_ERROR"].StatusName,
+	)
+}
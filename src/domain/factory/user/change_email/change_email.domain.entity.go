@@ -0,0 +1,161 @@
+package changeemail
+
+import (
+	"time"
+
+	status "github.com/Go_CleanArch/common/const"
+	"github.com/Go_CleanArch/common/crypto"
+	"github.com/Go_CleanArch/common/errors"
+	entity "github.com/Go_CleanArch/domain/entity"
+	"github.com/Go_CleanArch/domain/event"
+	createUserFactory "github.com/Go_CleanArch/domain/factory/user/create_user"
+	log "github.com/sirupsen/logrus"
+)
+
+// 確認トークンの有効期間
+const EmailChangeRequestTTL = 24 * time.Hour
+
+type ChangeEmailInitProps struct {
+	UserId       string
+	CurrentEmail string
+	NewEmail     string
+	// 変更先のメールアドレスで既に登録されているユーザーのID
+	ExistUserId string
+}
+
+// EmailChangeRequestState は永続化済みの変更申請の状態を表す。
+type EmailChangeRequestState struct {
+	ExpiresAt   time.Time
+	ConfirmedAt *time.Time
+	CancelledAt *time.Time
+}
+
+type ChangeEmailFactory struct {
+	GenerateId    func() (string, error)
+	GenerateToken func() (string, error)
+	Now           func() time.Time
+}
+
+func NewChangeEmailFactory() *ChangeEmailFactory {
+	return &ChangeEmailFactory{
+		GenerateId:    crypto.GenerateId,
+		GenerateToken: crypto.GenerateRandomToken,
+		Now:           time.Now,
+	}
+}
+
+// メールアドレス変更申請の生成
+func (cf *ChangeEmailFactory) RequestEmailChange(props *ChangeEmailInitProps) (*entity.EmailChangeRequest, *errors.ApiErr) {
+	apiErrMessages := make([]errors.ApiErrMessage, 0)
+	if props.ExistUserId != "" {
+		log.WithField("userId", props.ExistUserId).Info("New email already exists")
+		apiErrMessages = append(apiErrMessages, createUserFactory.EmailAlreadyExistsMessage())
+	}
+
+	requestId, err := cf.GenerateId()
+	if err != nil {
+		log.WithError(err).Error("Failed to generate email change request id")
+		return nil, errors.InternalServerError()
+	}
+	confirmToken, err := cf.GenerateToken()
+	if err != nil {
+		log.WithError(err).Error("Failed to generate confirm token")
+		return nil, errors.InternalServerError()
+	}
+	cancelToken, err := cf.GenerateToken()
+	if err != nil {
+		log.WithError(err).Error("Failed to generate cancel token")
+		return nil, errors.InternalServerError()
+	}
+
+	request, newRequestErrorMessage := entity.NewEmailChangeRequest(
+		entity.WithEmailChangeRequestID(requestId),
+		entity.WithEmailChangeUserID(props.UserId),
+		entity.WithEmails(props.CurrentEmail, props.NewEmail),
+		entity.WithConfirmToken(confirmToken, crypto.HashToken(confirmToken)),
+		entity.WithCancelToken(cancelToken, crypto.HashToken(cancelToken)),
+		entity.WithExpiresAt(cf.Now().Add(EmailChangeRequestTTL)),
+	)
+	if newRequestErrorMessage != nil {
+		apiErrMessages = append(apiErrMessages, newRequestErrorMessage.Messages...)
+	}
+
+	// エラーメッセージがある場合は、EnableCheckError を返す
+	if len(apiErrMessages) > 0 {
+		apiErr := errors.OutputApiError(
+			apiErrMessages,
+			status.ErrorStatusMap["ENABLE_CHECK_ERROR"].StatusCode,
+			status.ErrorStatusMap["ENABLE_CHECK_ERROR"].StatusName,
+		)
+		return nil, apiErr
+	}
+	return request, nil
+}
+
+// 変更申請の確定可否チェック
+func (cf *ChangeEmailFactory) CheckConfirmable(state EmailChangeRequestState) *errors.ApiErr {
+	if apiErr := checkPending(state); apiErr != nil {
+		return apiErr
+	}
+	if !cf.Now().Before(state.ExpiresAt) {
+		return enableCheckError("token", "確認トークンの有効期限が切れています")
+	}
+	return nil
+}
+
+// 申請時のメールアドレスが現在のメールアドレスと一致するかチェック
+// 申請後に別の申請でメールアドレスを変更した場合、以前の申請は確定できない。
+func (cf *ChangeEmailFactory) CheckCurrentEmail(requestedEmail string, currentEmail string) *errors.ApiErr {
+	if entity.NormalizeEmail(requestedEmail) == entity.NormalizeEmail(currentEmail) {
+		return nil
+	}
+	return errors.OutputApiError(
+		[]errors.ApiErrMessage{
+			{
+				Key:   "token",
+				Value: "申請後にメールアドレスが変更されたため、この変更は確定できません",
+			},
+		},
+		status.ErrorStatusMap["CONFLICT"].StatusCode,
+		status.ErrorStatusMap["CONFLICT"].StatusName,
+	)
+}
+
+// メールアドレス変更の確定のイベント
+// 確定日時にはイベントの発生日時を使用する。
+func (cf *ChangeEmailFactory) EmailChanged(userId string, previousEmail string, newEmail string) event.UserEmailChanged {
+	return event.UserEmailChanged{
+		UserId:        userId,
+		PreviousEmail: previousEmail,
+		NewEmail:      newEmail,
+		ChangedAt:     cf.Now(),
+	}
+}
+
+// 変更申請の取消可否チェック
+func (cf *ChangeEmailFactory) CheckCancellable(state EmailChangeRequestState) *errors.ApiErr {
+	return checkPending(state)
+}
+
+func checkPending(state EmailChangeRequestState) *errors.ApiErr {
+	if state.ConfirmedAt != nil {
+		return enableCheckError("token", "このメールアドレス変更はすでに確定しています")
+	}
+	if state.CancelledAt != nil {
+		return enableCheckError("token", "このメールアドレス変更は取り消されています")
+	}
+	return nil
+}
+
+func enableCheckError(key string, value string) *errors.ApiErr {
+	return errors.OutputApiError(
+		[]errors.ApiErrMessage{
+			{
+				Key:   key,
+				Value: value,
+			},
+		},
+		status.ErrorStatusMap["ENABLE_CHECK_ERROR"].StatusCode,
+		status.ErrorStatusMap["ENABLE_CHECK_ERROR"].StatusName,
+	)
+}
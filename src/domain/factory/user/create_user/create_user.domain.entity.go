@@ -1,10 +1,13 @@
 package createuser
 
 import (
+	"time"
+
 	status "github.com/Go_CleanArch/common/const"
 	"github.com/Go_CleanArch/common/crypto"
 	"github.com/Go_CleanArch/common/errors"
 	entity "github.com/Go_CleanArch/domain/entity"
+	"github.com/Go_CleanArch/domain/event"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -17,11 +20,13 @@ type CreateUserInitProps struct {
 
 type CreateUserFactory struct {
 	GeneratePassword func(string) (string, error)
+	GenerateUserId   func() (string, error)
 }
 
 func NewCreateUserFactory() *CreateUserFactory {
 	return &CreateUserFactory{
 		GeneratePassword: generatePassword,
+		GenerateUserId:   generateUserId,
 	}
 }
 
@@ -31,16 +36,7 @@ func (uf *CreateUserFactory) CreateUser(props *CreateUserInitProps) (*entity.Use
 	checkUserExistErrorMessage, err := checkUserExist(props.UserId)
 	if err != nil {
 		log.WithError(err).Error("Failed to check user existence")
-		return nil, errors.OutputApiError(
-			[]errors.ApiErrMessage{
-				{
-					Key:   "undefined",
-					Value: err.Error(),
-				},
-			},
-			status.ErrorStatusMap["INTERNAL_SERVER_ERROR"].StatusCode,
-			status.ErrorStatusMap["INTERNAL_SERVER_ERROR"].StatusName,
-		)
+		return nil, errors.InternalServerError()
 	}
 	// checkUserExistErrorMessageの値がnil以外だった場合APIエラーメッセージリストに内容を追加する
 	if checkUserExistErrorMessage != nil {
@@ -51,21 +47,19 @@ func (uf *CreateUserFactory) CreateUser(props *CreateUserInitProps) (*entity.Use
 	hashedPassword, err := uf.GeneratePassword(props.Password)
 	if err != nil {
 		log.WithError(err).Error("Failed to generate password")
-		return nil, errors.OutputApiError(
-			[]errors.ApiErrMessage{
-				{
-					Key:   "undefined",
-					Value: err.Error(),
-				},
-			},
-			status.ErrorStatusMap["INTERNAL_SERVER_ERROR"].StatusCode,
-			status.ErrorStatusMap["INTERNAL_SERVER_ERROR"].StatusName,
-		)
+		return nil, errors.InternalServerError()
+	}
+
+	// ユーザーIDの採番
+	userId, err := uf.GenerateUserId()
+	if err != nil {
+		log.WithError(err).Error("Failed to generate user id")
+		return nil, errors.InternalServerError()
 	}
 
 	// entity.Userを生成して返す
 	user, newUserErrorMessage := entity.NewUser(
-		entity.WithUserID(crypto.GenerateUserId(props.Email)),
+		entity.WithUserID(userId),
 		entity.WithUserName(props.UserName),
 		entity.WithPassword(hashedPassword),
 		entity.WithEmail(props.Email),
@@ -83,6 +77,13 @@ func (uf *CreateUserFactory) CreateUser(props *CreateUserInitProps) (*entity.Use
 		)
 		return nil, apiErr
 	}
+
+	// ユーザー登録のイベントを記録する
+	user.Record(event.UserRegistered{
+		UserId:       user.UserID,
+		Email:        user.Email,
+		RegisteredAt: time.Now(),
+	})
 	return user, nil
 }
 
@@ -90,16 +91,29 @@ func checkUserExist(userId string) ([]errors.ApiErrMessage, error) {
 	if userId != "" {
 		// ユーザーIDが空でない場合は、エラーメッセージを返す
 		log.WithField("userId", userId).Info("User ID already exists")
-		return []errors.ApiErrMessage{
-			{
-				Key:   "email",
-				Value: "すでに登録されているアドレスです",
-			},
-		}, nil
+		return []errors.ApiErrMessage{EmailAlreadyExistsMessage()}, nil
 	}
 	return nil, nil
 }
 
+// 登録済みアドレスのエラーメッセージ
+func EmailAlreadyExistsMessage() errors.ApiErrMessage {
+	return errors.ApiErrMessage{
+		Key:   "email",
+		Value: "すでに登録されているアドレスです",
+	}
+}
+
+// 登録済みアドレスのエラー
+// 同時に登録された場合など、データベースの一意制約で重複を検知した場合にも使用する。
+func EmailAlreadyExistsError() *errors.ApiErr {
+	return errors.OutputApiError(
+		[]errors.ApiErrMessage{EmailAlreadyExistsMessage()},
+		status.ErrorStatusMap["ENABLE_CHECK_ERROR"].StatusCode,
+		status.ErrorStatusMap["ENABLE_CHECK_ERROR"].StatusName,
+	)
+}
+
 func generatePassword(password string) (string, error) {
 	hashedPw, err := crypto.PasswordEncrypt(password)
 	if err != nil {
@@ -108,3 +122,12 @@ func generatePassword(password string) (string, error) {
 	}
 	return hashedPw, nil
 }
+
+func generateUserId() (string, error) {
+	userId, err := crypto.GenerateUserId()
+	if err != nil {
+		log.WithError(err).Error("Failed to generate user id")
+		return "", err
+	}
+	return userId, nil
+}
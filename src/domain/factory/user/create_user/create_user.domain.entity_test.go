@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	status "github.com/Go_CleanArch/common/const"
+	"github.com/Go_CleanArch/domain/event"
 	createUserDomain "github.com/Go_CleanArch/domain/factory/user/create_user"
 	"github.com/stretchr/testify/assert"
 )
@@ -14,6 +15,16 @@ func mockGeneratePassword(password string) (string, error) {
 	return "", fmt.Errorf("encryption failed %s", password)
 }
 
+// generateUserIdのモック関数
+func mockGenerateUserId() (string, error) {
+	return "0192a7e4-5c1b-7d2e-9f3a-4b5c6d7e8f90", nil
+}
+
+// generateUserIdの失敗モック関数
+func mockGenerateUserIdFailed() (string, error) {
+	return "", fmt.Errorf("id generation failed")
+}
+
 func TestCreateUser(t *testing.T) {
 	t.Parallel()
 
@@ -39,6 +50,78 @@ func TestCreateUser(t *testing.T) {
 		assert.NotEqual(t, password, user.Password)
 	})
 
+	t.Run("正常系: メールアドレスは小文字に正規化する", func(t *testing.T) {
+		t.Parallel()
+
+		factory := createUserDomain.NewCreateUserFactory()
+		user, err := factory.CreateUser(&createUserDomain.CreateUserInitProps{
+			UserName: userName,
+			Password: password,
+			Email:    " Test@Example.COM ",
+		})
+
+		assert.Nil(t, err)
+		assert.Equal(t, email, user.Email)
+	})
+
+	t.Run("正常系: ユーザー登録のイベントを記録", func(t *testing.T) {
+		t.Parallel()
+
+		factory := createUserDomain.NewCreateUserFactory()
+		user, err := factory.CreateUser(&createUserDomain.CreateUserInitProps{
+			UserName: userName,
+			Password: password,
+			Email:    email,
+		})
+		assert.Nil(t, err)
+
+		events := user.PullEvents()
+		assert.Len(t, events, 1)
+		registered, ok := events[0].(event.UserRegistered)
+		assert.True(t, ok)
+		assert.Equal(t, user.UserID, registered.AggregateId())
+		assert.Equal(t, email, registered.Email)
+		assert.False(t, registered.RegisteredAt.IsZero())
+		// 取り出したイベントは保持しない
+		assert.Empty(t, user.PullEvents())
+	})
+
+	t.Run("正常系: ユーザーIDはメールアドレスに依存しない", func(t *testing.T) {
+		t.Parallel()
+
+		factory := createUserDomain.NewCreateUserFactory()
+		first, err := factory.CreateUser(&createUserDomain.CreateUserInitProps{
+			UserName: userName,
+			Password: password,
+			Email:    email,
+		})
+		assert.Nil(t, err)
+		second, err := factory.CreateUser(&createUserDomain.CreateUserInitProps{
+			UserName: userName,
+			Password: password,
+			Email:    email,
+		})
+		assert.Nil(t, err)
+
+		assert.NotEqual(t, first.UserID, second.UserID)
+		assert.Len(t, first.UserID, 36)
+	})
+
+	t.Run("正常系: 注入したID生成関数でユーザーIDを採番", func(t *testing.T) {
+		t.Parallel()
+
+		factory := createUserDomain.NewCreateUserFactory()
+		factory.GenerateUserId = mockGenerateUserId
+		user, err := factory.CreateUser(&createUserDomain.CreateUserInitProps{
+			UserName: userName,
+			Password: password,
+			Email:    email,
+		})
+
+		assert.Nil(t, err)
+		assert.Equal(t, "0192a7e4-5c1b-7d2e-9f3a-4b5c6d7e8f90", user.UserID)
+	})
+
 	t.Run("異常系: ユーザーIDが既に存在する", func(t *testing.T) {
 		t.Parallel()
 
@@ -79,4 +162,20 @@ func TestCreateUser(t *testing.T) {
 		assert.Equal(t, status.ErrorStatusMap["INTERNAL_SERVER_ERROR"].StatusCode, err.Status)
 		assert.Contains(t, err.Detail, "Internal Server Error")
 	})
-}
\ No newline at end of file
+
+	t.Run("異常系: ユーザーIDの採番に失敗", func(t *testing.T) {
+		t.Parallel()
+
+		factory := createUserDomain.NewCreateUserFactory()
+		factory.GenerateUserId = mockGenerateUserIdFailed
+		user, err := factory.CreateUser(&createUserDomain.CreateUserInitProps{
+			UserName: userName,
+			Password: password,
+			Email:    email,
+		})
+
+		assert.Nil(t, user)
+		assert.NotNil(t, err)
+		assert.Equal(t, status.ErrorStatusMap["INTERNAL_SERVER_ERROR"].StatusCode, err.Status)
+	})
+}
@@ -0,0 +1,105 @@
+package createuser
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"strings"
+
+	status "github.com/Go_CleanArch/common/const"
+	"github.com/Go_CleanArch/common/errors"
+)
+
+// サインアップの受付モード
+const (
+	// 誰でもサインアップできる
+	SignupModeOpen = "open"
+	// 有効な招待がある場合、または許可したドメインのメールアドレスの場合のみサインアップできる
+	SignupModeRestricted = "restricted"
+)
+
+//go:embed disposable_email_domains.txt
+var disposableEmailDomainList string
+
+// SignupPolicy はサインアップを受け付けるメールアドレスの条件を表す。
+// 使い捨てメールアドレスは、招待されている場合を除きモードにかかわらず受け付けない。
+type SignupPolicy struct {
+	Mode              string
+	AllowedDomains    map[string]bool
+	DisposableDomains map[string]bool
+}
+
+func NewSignupPolicy(mode string, allowedDomains []string) (*SignupPolicy, error) {
+	if mode == "" {
+		mode = SignupModeOpen
+	}
+	if mode != SignupModeOpen && mode != SignupModeRestricted {
+		return nil, fmt.Errorf("サインアップの受付モードは %s / %s のいずれかを指定してください: %s", SignupModeOpen, SignupModeRestricted, mode)
+	}
+
+	policy := &SignupPolicy{
+		Mode:              mode,
+		AllowedDomains:    make(map[string]bool),
+		DisposableDomains: parseDomainList(disposableEmailDomainList),
+	}
+	for _, domain := range allowedDomains {
+		if domain = normalizeDomain(domain); domain != "" {
+			policy.AllowedDomains[domain] = true
+		}
+	}
+	return policy, nil
+}
+
+// サインアップ可否チェック
+// invited は有効な招待が確認済みであることを表す。
+func (sp *SignupPolicy) CheckSignup(email string, invited bool) *errors.ApiErr {
+	if invited {
+		return nil
+	}
+
+	domain := emailDomain(email)
+	if sp.DisposableDomains[domain] {
+		return signupError("使い捨てメールアドレスは登録できません")
+	}
+	if sp.Mode == SignupModeRestricted && !sp.AllowedDomains[domain] {
+		return signupError("招待されたメールアドレス、または許可されたドメインのメールアドレスのみ登録できます")
+	}
+	return nil
+}
+
+func emailDomain(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return ""
+	}
+	return normalizeDomain(email[at+1:])
+}
+
+func normalizeDomain(domain string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(domain)), ".")
+}
+
+func parseDomainList(list string) map[string]bool {
+	domains := make(map[string]bool)
+	scanner := bufio.NewScanner(strings.NewReader(list))
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		if domain := normalizeDomain(line); domain != "" {
+			domains[domain] = true
+		}
+	}
+	return domains
+}
+
+func signupError(value string) *errors.ApiErr {
+	return errors.OutputApiError(
+		[]errors.ApiErrMessage{
+			{
+				Key:   "email",
+				Value: value,
+			},
+		},
+		status.ErrorStatusMap["FORBIDDEN"].StatusCode,
+		status.ErrorStatusMap["FORBIDDEN"].StatusName,
+	)
+}
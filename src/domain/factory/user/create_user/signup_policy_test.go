@@ -0,0 +1,56 @@
+package createuser_test
+
+import (
+	"testing"
+
+	status "github.com/Go_CleanArch/common/const"
+	createUserDomain "github.com/Go_CleanArch/domain/factory/user/create_user"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignupPolicy(t *testing.T) {
+	t.Parallel()
+
+	t.Run("正常系: openモードは任意のドメインを受け付ける", func(t *testing.T) {
+		t.Parallel()
+
+		policy, err := createUserDomain.NewSignupPolicy("", nil)
+		assert.NoError(t, err)
+
+		assert.Nil(t, policy.CheckSignup("test@example.com", false))
+	})
+
+	t.Run("異常系: 使い捨てメールアドレスはモードにかかわらず拒否する", func(t *testing.T) {
+		t.Parallel()
+
+		policy, _ := createUserDomain.NewSignupPolicy(createUserDomain.SignupModeOpen, nil)
+
+		apiErr := policy.CheckSignup("test@Mailinator.com", false)
+		assert.NotNil(t, apiErr)
+		assert.Equal(t, status.ErrorStatusMap["FORBIDDEN"].StatusCode, apiErr.Status)
+	})
+
+	t.Run("正常系: restrictedモードは許可したドメインを受け付ける", func(t *testing.T) {
+		t.Parallel()
+
+		policy, _ := createUserDomain.NewSignupPolicy(createUserDomain.SignupModeRestricted, []string{" Example.co.jp ", ""})
+
+		assert.Nil(t, policy.CheckSignup("test@example.co.jp", false))
+		assert.NotNil(t, policy.CheckSignup("test@example.com", false))
+	})
+
+	t.Run("正常系: 招待されている場合はドメインにかかわらず受け付ける", func(t *testing.T) {
+		t.Parallel()
+
+		policy, _ := createUserDomain.NewSignupPolicy(createUserDomain.SignupModeRestricted, nil)
+
+		assert.Nil(t, policy.CheckSignup("test@yopmail.com", true))
+	})
+
+	t.Run("異常系: 未定義のモード", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := createUserDomain.NewSignupPolicy("closed", nil)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,119 @@
+package eraseuser
+
+import (
+	"time"
+
+	status "github.com/Go_CleanArch/common/const"
+	"github.com/Go_CleanArch/common/crypto"
+	"github.com/Go_CleanArch/common/errors"
+	entity "github.com/Go_CleanArch/domain/entity"
+	log "github.com/sirupsen/logrus"
+)
+
+// 確認トークンの有効期間
+const ErasureRequestTTL = 24 * time.Hour
+
+// 匿名化後のユーザー名
+const AnonymizedUserName = "退会済みユーザー"
+
+// 匿名化後のメールアドレスのドメイン(RFC 2606 の予約ドメイン)
+const anonymizedEmailDomain = "@erased.invalid"
+
+// ErasureRequestState は永続化済みの削除申請の状態を表す。
+type ErasureRequestState struct {
+	ExpiresAt   time.Time
+	ConfirmedAt *time.Time
+}
+
+type EraseUserFactory struct {
+	GenerateId    func() (string, error)
+	GenerateToken func() (string, error)
+	Now           func() time.Time
+}
+
+func NewEraseUserFactory() *EraseUserFactory {
+	return &EraseUserFactory{
+		GenerateId:    crypto.GenerateId,
+		GenerateToken: crypto.GenerateRandomToken,
+		Now:           time.Now,
+	}
+}
+
+// 個人データ削除申請の生成
+func (ef *EraseUserFactory) RequestErasure(userId string) (*entity.ErasureRequest, *errors.ApiErr) {
+	requestId, err := ef.GenerateId()
+	if err != nil {
+		log.WithError(err).Error("Failed to generate erasure request id")
+		return nil, errors.InternalServerError()
+	}
+	confirmToken, err := ef.GenerateToken()
+	if err != nil {
+		log.WithError(err).Error("Failed to generate confirm token")
+		return nil, errors.InternalServerError()
+	}
+
+	return &entity.ErasureRequest{
+		ErasureRequestID: requestId,
+		UserID:           userId,
+		ConfirmToken:     confirmToken,
+		ConfirmTokenHash: crypto.HashToken(confirmToken),
+		ExpiresAt:        ef.Now().Add(ErasureRequestTTL),
+	}, nil
+}
+
+// 削除申請の確定可否チェック
+func (ef *EraseUserFactory) CheckConfirmable(state ErasureRequestState) *errors.ApiErr {
+	if state.ConfirmedAt != nil {
+		return enableCheckError("token", "この削除申請はすでに実行されています")
+	}
+	if !ef.Now().Before(state.ExpiresAt) {
+		return enableCheckError("token", "確認トークンの有効期限が切れています")
+	}
+	return nil
+}
+
+// 匿名化したユーザー情報の生成
+// 主キー(ユーザーID)は参照整合性を保つため維持し、個人を特定できる項目のみを置き換える。
+func (ef *EraseUserFactory) AnonymizeUser(userId string) (*entity.User, *errors.ApiErr) {
+	return entity.NewUser(
+		entity.WithUserID(userId),
+		entity.WithUserName(AnonymizedUserName),
+		entity.WithPassword(""),
+		entity.WithEmail(AnonymizedEmail(userId)),
+	)
+}
+
+// 監査記録の生成
+func (ef *EraseUserFactory) NewPrivacyAuditLog(userId string, action string, ipAddress string) (*entity.PrivacyAuditLog, *errors.ApiErr) {
+	auditLogId, err := ef.GenerateId()
+	if err != nil {
+		log.WithError(err).Error("Failed to generate privacy audit log id")
+		return nil, errors.InternalServerError()
+	}
+	return &entity.PrivacyAuditLog{
+		PrivacyAuditLogID: auditLogId,
+		UserID:            userId,
+		Action:            action,
+		IpAddress:         ipAddress,
+		CreatedAt:         ef.Now(),
+	}, nil
+}
+
+// 匿名化後のメールアドレス
+// 元のアドレスを推測できず、かつユーザーごとに一意となる値を返す。
+func AnonymizedEmail(userId string) string {
+	return crypto.HashToken(userId)[:16] + anonymizedEmailDomain
+}
+
+func enableCheckError(key string, value string) *errors.ApiErr {
+	return errors.OutputApiError(
+		[]errors.ApiErrMessage{
+			{
+				Key:   key,
+				Value: value,
+			},
+		},
+		status.ErrorStatusMap["ENABLE_CHECK_ERROR"].StatusCode,
+		status.ErrorStatusMap["ENABLE_CHECK_ERROR"].StatusName,
+	)
+}
@@ -1,9 +1,13 @@
 package loginuser
 
 import (
+	"time"
+
 	status "github.com/Go_CleanArch/common/const"
 	"github.com/Go_CleanArch/common/crypto"
 	"github.com/Go_CleanArch/common/errors"
+	entity "github.com/Go_CleanArch/domain/entity"
+	"github.com/Go_CleanArch/domain/event"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -24,15 +28,7 @@ func NewLoginUserDomainServiceProps(opts ...LoginUserBuilderPropsOption) (*Login
 		if err != nil {
 			// エラーが発生した場合は、Internal Server Error を返す
 			log.WithError(err).Error("login user domain entity INTERNAL_SERVER_ERROR")
-			apiErr := errors.OutputApiError([]errors.ApiErrMessage{
-				{
-					Key:   "undefined",
-					Value: err.Error(),
-				},
-			},
-				status.ErrorStatusMap["INTERNAL_SERVER_ERROR"].StatusCode,
-				status.ErrorStatusMap["INTERNAL_SERVER_ERROR"].StatusName,
-			)
+			apiErr := errors.InternalServerError()
 			return nil, apiErr
 		}
 		apiErrMessages = append(apiErrMessages, setErrMessages...)
@@ -52,9 +48,15 @@ func NewLoginUserDomainServiceProps(opts ...LoginUserBuilderPropsOption) (*Login
 	return loginUserBuilderProps, nil
 }
 
-func WithLoginUserIdAndEmail(email string) LoginUserBuilderPropsOption {
+func WithLoginUserId(userId string) LoginUserBuilderPropsOption {
+	return func(props *LoginUserBuilderProps) ([]errors.ApiErrMessage, error) {
+		props.UserId = userId
+		return nil, nil
+	}
+}
+
+func WithLoginEmail(email string) LoginUserBuilderPropsOption {
 	return func(props *LoginUserBuilderProps) ([]errors.ApiErrMessage, error) {
-		props.UserId = crypto.GenerateUserId(email)
 		props.Email = email
 		return nil, nil
 	}
@@ -82,3 +84,27 @@ func WithLoginPassword(targetPassword string, sourcePassword string) LoginUserBu
 		return nil, nil
 	}
 }
+
+// ログイン履歴の生成
+// ログインのイベントを記録する。
+func NewLoginHistory(userId string, ipAddress string, userAgent string) (*entity.LoginHistory, error) {
+	loginHistoryId, err := crypto.GenerateId()
+	if err != nil {
+		log.WithError(err).Error("Failed to generate login history id")
+		return nil, err
+	}
+	loginHistory := &entity.LoginHistory{
+		LoginHistoryID: loginHistoryId,
+		UserID:         userId,
+		IpAddress:      ipAddress,
+		UserAgent:      userAgent,
+		LoggedInAt:     time.Now(),
+	}
+	loginHistory.Record(event.UserLoggedIn{
+		UserId:     userId,
+		IpAddress:  ipAddress,
+		UserAgent:  userAgent,
+		LoggedInAt: loginHistory.LoggedInAt,
+	})
+	return loginHistory, nil
+}
@@ -0,0 +1,111 @@
+package uploadavatar
+
+import (
+	stdErrors "errors"
+	"fmt"
+	"image"
+	"net/http"
+
+	status "github.com/Go_CleanArch/common/const"
+	"github.com/Go_CleanArch/common/crypto"
+	"github.com/Go_CleanArch/common/errors"
+	"github.com/Go_CleanArch/common/imaging"
+	entity "github.com/Go_CleanArch/domain/entity"
+	log "github.com/sirupsen/logrus"
+)
+
+// アップロードを許可する最大サイズ(5MB)
+const MaxAvatarBytes = 5 << 20
+
+// 元画像の長辺の最大ピクセル数
+const maxOriginalSide = 1024
+
+// アップロードを許可する画像形式
+var allowedContentTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+}
+
+// サムネイルの一辺の長さ
+var thumbnailSizes = map[string]int{
+	entity.AvatarVariantLarge: 256,
+	entity.AvatarVariantSmall: 64,
+}
+
+type UploadAvatarFactory struct {
+	GenerateId func() (string, error)
+}
+
+func NewUploadAvatarFactory() *UploadAvatarFactory {
+	return &UploadAvatarFactory{
+		GenerateId: crypto.GenerateId,
+	}
+}
+
+// アバター画像一式の生成
+// 形式とサイズを検証したうえで再エンコードすることで、EXIF等のメタデータを取り除く。
+func (af *UploadAvatarFactory) BuildAvatar(userId string, data []byte) (*entity.Avatar, *errors.ApiErr) {
+	if len(data) == 0 {
+		return nil, enableCheckError("画像ファイルを選択してください")
+	}
+	if len(data) > MaxAvatarBytes {
+		return nil, enableCheckError(fmt.Sprintf("画像ファイルは %dMB 以下にしてください", MaxAvatarBytes>>20))
+	}
+	// 拡張子やリクエストヘッダーではなく、ファイルの内容から形式を判定する
+	if contentType := http.DetectContentType(data); !allowedContentTypes[contentType] {
+		log.WithField("contentType", contentType).Info("Unsupported avatar content type")
+		return nil, enableCheckError("JPEG / PNG / GIF 形式の画像を選択してください")
+	}
+
+	src, _, err := imaging.Decode(data)
+	if stdErrors.Is(err, imaging.ErrTooManyPixels) {
+		return nil, enableCheckError(err.Error())
+	} else if err != nil {
+		return nil, enableCheckError("画像ファイルを読み込めませんでした")
+	}
+
+	avatarId, err := af.GenerateId()
+	if err != nil {
+		log.WithError(err).Error("Failed to generate avatar id")
+		return nil, errors.InternalServerError()
+	}
+	avatarKey := fmt.Sprintf("avatars/%s/%s", userId, avatarId)
+
+	variants := map[string]image.Image{
+		entity.AvatarVariantOriginal: imaging.Fit(src, maxOriginalSide),
+	}
+	for variant, size := range thumbnailSizes {
+		variants[variant] = imaging.SquareThumbnail(src, size)
+	}
+
+	avatar := &entity.Avatar{AvatarKey: avatarKey}
+	for _, variant := range entity.AvatarVariants() {
+		body, err := imaging.EncodeJPEG(variants[variant])
+		if err != nil {
+			log.WithError(err).Error("Failed to encode avatar image")
+			return nil, errors.InternalServerError()
+		}
+		avatar.Objects = append(avatar.Objects, entity.AvatarObject{
+			Key:         entity.AvatarObjectKey(avatarKey, variant),
+			ContentType: "image/jpeg",
+			Body:        body,
+		})
+	}
+
+	log.WithField("avatarKey", avatarKey).Info("Avatar built successfully")
+	return avatar, nil
+}
+
+func enableCheckError(value string) *errors.ApiErr {
+	return errors.OutputApiError(
+		[]errors.ApiErrMessage{
+			{
+				Key:   "avatar",
+				Value: value,
+			},
+		},
+		status.ErrorStatusMap["ENABLE_CHECK_ERROR"].StatusCode,
+		status.ErrorStatusMap["ENABLE_CHECK_ERROR"].StatusName,
+	)
+}
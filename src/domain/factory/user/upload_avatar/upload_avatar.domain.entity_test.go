@@ -0,0 +1,105 @@
+package uploadavatar_test
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	status "github.com/Go_CleanArch/common/const"
+	entity "github.com/Go_CleanArch/domain/entity"
+	uploadAvatarDomain "github.com/Go_CleanArch/domain/factory/user/upload_avatar"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestImage(width int, height int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+	return img
+}
+
+// EXIF(APP1)セグメントを含むJPEGを生成する
+func newJPEGWithExif(t *testing.T) []byte {
+	var buf bytes.Buffer
+	assert.NoError(t, jpeg.Encode(&buf, newTestImage(300, 200), nil))
+	exifPayload := []byte("Exif\x00\x00GPS-LOCATION-SECRET")
+	segmentLength := len(exifPayload) + 2
+	app1 := append([]byte{0xFF, 0xE1, byte(segmentLength >> 8), byte(segmentLength)}, exifPayload...)
+	data := buf.Bytes()
+	return append(append(append([]byte{}, data[:2]...), app1...), data[2:]...)
+}
+
+func TestBuildAvatar(t *testing.T) {
+	t.Parallel()
+
+	userId := "0192a7e4-5c1b-7d2e-9f3a-4b5c6d7e8f90"
+
+	t.Run("正常系: 元画像とサムネイルを生成しEXIFを除去する", func(t *testing.T) {
+		t.Parallel()
+
+		data := newJPEGWithExif(t)
+		assert.True(t, bytes.Contains(data, []byte("GPS-LOCATION-SECRET")))
+
+		factory := uploadAvatarDomain.NewUploadAvatarFactory()
+		avatar, err := factory.BuildAvatar(userId, data)
+
+		assert.Nil(t, err)
+		assert.Contains(t, avatar.AvatarKey, "avatars/"+userId+"/")
+		assert.Len(t, avatar.Objects, len(entity.AvatarVariants()))
+		for _, object := range avatar.Objects {
+			assert.Equal(t, "image/jpeg", object.ContentType)
+			assert.False(t, bytes.Contains(object.Body, []byte("GPS-LOCATION-SECRET")))
+		}
+
+		thumbnail, _, decodeErr := image.DecodeConfig(bytes.NewReader(avatar.Objects[2].Body))
+		assert.NoError(t, decodeErr)
+		assert.Equal(t, 64, thumbnail.Width)
+		assert.Equal(t, 64, thumbnail.Height)
+		assert.Equal(t, entity.AvatarObjectKey(avatar.AvatarKey, entity.AvatarVariantSmall), avatar.Objects[2].Key)
+	})
+
+	t.Run("正常系: PNG画像を受け付ける", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		assert.NoError(t, png.Encode(&buf, newTestImage(40, 80)))
+
+		factory := uploadAvatarDomain.NewUploadAvatarFactory()
+		avatar, err := factory.BuildAvatar(userId, buf.Bytes())
+
+		assert.Nil(t, err)
+		original, _, decodeErr := image.DecodeConfig(bytes.NewReader(avatar.Objects[0].Body))
+		assert.NoError(t, decodeErr)
+		assert.Equal(t, 40, original.Width)
+		assert.Equal(t, 80, original.Height)
+	})
+
+	t.Run("異常系: 画像以外のファイル", func(t *testing.T) {
+		t.Parallel()
+
+		factory := uploadAvatarDomain.NewUploadAvatarFactory()
+		avatar, err := factory.BuildAvatar(userId, []byte("<html>not an image</html>"))
+
+		assert.Nil(t, avatar)
+		assert.NotNil(t, err)
+		assert.Equal(t, status.ErrorStatusMap["ENABLE_CHECK_ERROR"].StatusCode, err.Status)
+		assert.Equal(t, "avatar", err.Messages[0].Key)
+	})
+
+	t.Run("異常系: サイズ超過", func(t *testing.T) {
+		t.Parallel()
+
+		factory := uploadAvatarDomain.NewUploadAvatarFactory()
+		avatar, err := factory.BuildAvatar(userId, make([]byte, uploadAvatarDomain.MaxAvatarBytes+1))
+
+		assert.Nil(t, avatar)
+		assert.NotNil(t, err)
+		assert.Contains(t, err.Messages[0].Value, "5MB")
+	})
+}
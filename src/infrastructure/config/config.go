@@ -0,0 +1,365 @@
+package config
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 実行環境(プロファイル)
+const (
+	ProfileLocal = "local"
+	ProfileTest  = "test"
+	ProfileProd  = "prod"
+)
+
+// Config はアプリケーション全体の設定を表す。
+// 各項目は yaml タグで設定ファイルのキーを、env タグで上書きに使用する環境変数(Docker secret のファイル名)を指定する。
+type Config struct {
+	Profile  string         `yaml:"-"`
+	Server   ServerConfig   `yaml:"server"`
+	Database DatabaseConfig `yaml:"database"`
+	Auth     AuthConfig     `yaml:"auth"`
+	App      AppConfig      `yaml:"app"`
+	Mail     MailConfig     `yaml:"mail"`
+	Storage  StorageConfig  `yaml:"storage"`
+	Signup   SignupConfig   `yaml:"signup"`
+	Outbox   OutboxConfig   `yaml:"outbox"`
+	Cache    CacheConfig    `yaml:"cache"`
+	// Idempotency-Key を指定したリクエストの設定
+	Idempotency IdempotencyConfig `yaml:"idempotency"`
+}
+
+type ServerConfig struct {
+	Port int `yaml:"port" env:"PORT"`
+	// CORS でアクセスを許可するアクセス元
+	CorsAllowOrigins []string `yaml:"corsAllowOrigins" env:"CORS_ALLOW_ORIGINS"`
+	// リクエストログの日時に使用するタイムゾーン
+	LogTimezone string `yaml:"logTimezone" env:"LOG_TIMEZONE"`
+	// サブドメインから組織を解決する場合のベースドメイン
+	TenantBaseDomain string `yaml:"tenantBaseDomain" env:"TENANT_BASE_DOMAIN"`
+	// リクエスト(ヘッダー・ボディ)の読み込みの上限時間
+	ReadTimeout time.Duration `yaml:"readTimeout" env:"SERVER_READ_TIMEOUT"`
+	// レスポンスの書き込みを終えるまでの上限時間(リクエストの処理時間を含む)
+	WriteTimeout time.Duration `yaml:"writeTimeout" env:"SERVER_WRITE_TIMEOUT"`
+	// Keep-Alive の接続で次のリクエストを待つ上限時間
+	IdleTimeout time.Duration `yaml:"idleTimeout" env:"SERVER_IDLE_TIMEOUT"`
+	// 終了時に処理中のリクエストの完了を待つ上限時間
+	ShutdownTimeout time.Duration `yaml:"shutdownTimeout" env:"SERVER_SHUTDOWN_TIMEOUT"`
+}
+
+type DatabaseConfig struct {
+	// 接続先のデータベース(postgres / sqlite)
+	Driver string `yaml:"driver" env:"DB_DRIVER"`
+	// SQLite のデータベースファイル(:memory: の場合はメモリ上に作成し、終了時に破棄する)
+	SqlitePath string `yaml:"sqlitePath" env:"DB_SQLITE_PATH"`
+
+	Host     string `yaml:"host" env:"DB_HOST"`
+	Port     int    `yaml:"port" env:"DB_PORT"`
+	User     string `yaml:"user" env:"DB_USER"`
+	Password string `yaml:"password" env:"DB_PASSWORD"`
+	Name     string `yaml:"name" env:"DB_NAME"`
+	SslMode  string `yaml:"sslMode" env:"DB_SSLMODE"`
+	// 読み取り専用のレプリカ(host または host:port、ユーザー・パスワード・データベース名はプライマリと共通)
+	ReplicaHosts []string `yaml:"replicaHosts" env:"DB_REPLICA_HOSTS"`
+	// 起動時に未適用のマイグレーションを適用する
+	AutoMigrate bool `yaml:"autoMigrate" env:"DB_AUTO_MIGRATE"`
+	// 呼び出し元のコンテキストに期限が無い場合の、SQL 1件あたりの実行時間の上限(0 は無期限)
+	QueryTimeout time.Duration     `yaml:"queryTimeout" env:"DB_QUERY_TIMEOUT"`
+	Pool         PoolConfig        `yaml:"pool"`
+	HealthCheck  HealthCheckConfig `yaml:"healthCheck"`
+	QueryLog     QueryLogConfig    `yaml:"queryLog"`
+	Encryption   EncryptionConfig  `yaml:"encryption"`
+}
+
+// コネクションプールの設定
+type PoolConfig struct {
+	MaxOpenConns int `yaml:"maxOpenConns" env:"DB_MAX_OPEN_CONNS"`
+	MaxIdleConns int `yaml:"maxIdleConns" env:"DB_MAX_IDLE_CONNS"`
+	// 接続を再利用する最大期間(0 は無期限)
+	ConnMaxLifetime time.Duration `yaml:"connMaxLifetime" env:"DB_CONN_MAX_LIFETIME"`
+	// 未使用の接続を維持する最大期間(0 は無期限)
+	ConnMaxIdleTime time.Duration `yaml:"connMaxIdleTime" env:"DB_CONN_MAX_IDLE_TIME"`
+}
+
+// 接続の死活監視の設定
+type HealthCheckConfig struct {
+	Interval time.Duration `yaml:"interval" env:"DB_HEALTH_CHECK_INTERVAL"`
+	Timeout  time.Duration `yaml:"timeout" env:"DB_HEALTH_CHECK_TIMEOUT"`
+	// 連続して失敗した場合に接続を開き直す回数
+	FailureThreshold int `yaml:"failureThreshold" env:"DB_HEALTH_CHECK_FAILURE_THRESHOLD"`
+}
+
+// 実行したSQLのログ出力の設定
+// SQLはパラメーターを伏せて出力する。
+type QueryLogConfig struct {
+	// 出力する範囲(silent: 出力しない / error: エラーのみ / warn: エラーとスロークエリ / info: すべてのSQL)
+	Level string `yaml:"level" env:"DB_QUERY_LOG_LEVEL"`
+	// スロークエリとして警告する実行時間(0 の場合は警告しない)
+	SlowThreshold time.Duration `yaml:"slowThreshold" env:"DB_SLOW_QUERY_THRESHOLD"`
+}
+
+// 個人情報(氏名・メールアドレス)の列の暗号化の設定
+// 鍵はいずれも base64 で表した32バイトの値を指定する。
+type EncryptionConfig struct {
+	// 暗号化の鍵(鍵ID:鍵 の形式、鍵をローテーションした後も復号のため以前の鍵を残す)
+	Keys []string `yaml:"keys" env:"DB_ENCRYPTION_KEYS"`
+	// 暗号化に使用する鍵ID
+	ActiveKeyId string `yaml:"activeKeyId" env:"DB_ENCRYPTION_ACTIVE_KEY_ID"`
+	// メールアドレスで検索するためのブラインドインデックス(HMAC)の鍵
+	// 変更すると既存のレコードを検索できなくなるため、ローテーションしない。
+	BlindIndexKey string `yaml:"blindIndexKey" env:"DB_ENCRYPTION_BLIND_INDEX_KEY"`
+	// 鍵ファイル(keys・activeKeyId・blindIndexKey を記載した yaml)
+	// keys は設定ファイルの値に追加し、activeKeyId・blindIndexKey は設定ファイルで指定されなかった場合に使用する。
+	KeyFile string `yaml:"keyFile" env:"DB_ENCRYPTION_KEY_FILE"`
+}
+
+type AuthConfig struct {
+	TokenSecret    string        `yaml:"tokenSecret" env:"AUTH_TOKEN_SECRET"`
+	AccessTokenTTL time.Duration `yaml:"accessTokenTtl" env:"AUTH_ACCESS_TOKEN_TTL"`
+	// 管理者のユーザーID(監査ログの検索など管理者向けの操作を許可する)
+	AdminUserIds []string `yaml:"adminUserIds" env:"AUTH_ADMIN_USER_IDS"`
+}
+
+type AppConfig struct {
+	// メール本文に記載するリンクのベースURL
+	BaseUrl string `yaml:"baseUrl" env:"APP_BASE_URL"`
+}
+
+type MailConfig struct {
+	// 送信方式(log / smtp)
+	Driver string     `yaml:"driver" env:"MAIL_DRIVER"`
+	From   string     `yaml:"from" env:"MAIL_FROM"`
+	Smtp   SmtpConfig `yaml:"smtp"`
+}
+
+type SmtpConfig struct {
+	Host     string `yaml:"host" env:"SMTP_HOST"`
+	Port     int    `yaml:"port" env:"SMTP_PORT"`
+	Username string `yaml:"username" env:"SMTP_USERNAME"`
+	Password string `yaml:"password" env:"SMTP_PASSWORD"`
+}
+
+type StorageConfig struct {
+	// 保存先(local / s3)
+	Driver        string   `yaml:"driver" env:"STORAGE_DRIVER"`
+	LocalDir      string   `yaml:"localDir" env:"STORAGE_LOCAL_DIR"`
+	PublicBaseUrl string   `yaml:"publicBaseUrl" env:"STORAGE_PUBLIC_BASE_URL"`
+	S3            S3Config `yaml:"s3"`
+}
+
+type S3Config struct {
+	Endpoint        string `yaml:"endpoint" env:"S3_ENDPOINT"`
+	Region          string `yaml:"region" env:"S3_REGION"`
+	Bucket          string `yaml:"bucket" env:"S3_BUCKET"`
+	AccessKeyId     string `yaml:"accessKeyId" env:"S3_ACCESS_KEY_ID"`
+	SecretAccessKey string `yaml:"secretAccessKey" env:"S3_SECRET_ACCESS_KEY"`
+}
+
+type SignupConfig struct {
+	// サインアップの受付モード(open / restricted)
+	Mode                string   `yaml:"mode" env:"SIGNUP_MODE"`
+	AllowedEmailDomains []string `yaml:"allowedEmailDomains" env:"SIGNUP_ALLOWED_EMAIL_DOMAINS"`
+}
+
+// ドメインイベントの配信の設定
+type OutboxConfig struct {
+	// 配信待ちのイベントを確認する間隔
+	PollInterval time.Duration `yaml:"pollInterval" env:"OUTBOX_POLL_INTERVAL"`
+	// 1回の確認で配信するイベントの最大件数
+	BatchSize int `yaml:"batchSize" env:"OUTBOX_BATCH_SIZE"`
+	// 配信を試行する最大回数(すべて失敗したイベントはデッドレターとして配信を停止する)
+	MaxAttempts int `yaml:"maxAttempts" env:"OUTBOX_MAX_ATTEMPTS"`
+	// 再試行までの待機時間(失敗するごとに2倍にし、retryMaxDelay を上限とする)
+	RetryBaseDelay time.Duration `yaml:"retryBaseDelay" env:"OUTBOX_RETRY_BASE_DELAY"`
+	RetryMaxDelay  time.Duration `yaml:"retryMaxDelay" env:"OUTBOX_RETRY_MAX_DELAY"`
+	// 配信中のイベントを他のディスパッチャーが取得しないようにする期間(購読者の処理時間の上限)
+	LockTimeout time.Duration `yaml:"lockTimeout" env:"OUTBOX_LOCK_TIMEOUT"`
+	// 配信済みのイベントを保存する期間(期間を過ぎたイベントは削除する。デッドレターは調査のため削除しない)
+	Retention time.Duration `yaml:"retention" env:"OUTBOX_RETENTION"`
+	// 保存期間を過ぎたイベントを削除する間隔
+	PurgeInterval time.Duration `yaml:"purgeInterval" env:"OUTBOX_PURGE_INTERVAL"`
+}
+
+// Idempotency-Key を指定した POST リクエストの設定
+type IdempotencyConfig struct {
+	// レスポンスを保存する期間(この期間内の同じキーの再送には保存したレスポンスを返す)
+	TTL time.Duration `yaml:"ttl" env:"IDEMPOTENCY_TTL"`
+	// 処理中のリクエストを他のリクエストが引き継がないようにする期間(リクエストの処理時間の上限)
+	LockTimeout time.Duration `yaml:"lockTimeout" env:"IDEMPOTENCY_LOCK_TIMEOUT"`
+	// 期限切れのレコードを削除する間隔
+	PurgeInterval time.Duration `yaml:"purgeInterval" env:"IDEMPOTENCY_PURGE_INTERVAL"`
+}
+
+// リポジトリの検索結果のキャッシュの設定
+type CacheConfig struct {
+	// 保存先(none / memory)
+	// memory はサーバーごとに保持するため、複数のサーバーで実行する場合は他のサーバーでの変更が ttl の間反映されない。
+	Driver string `yaml:"driver" env:"CACHE_DRIVER"`
+	// memory の場合に保持する最大件数
+	Capacity int `yaml:"capacity" env:"CACHE_CAPACITY"`
+	// 検索結果を保持する期間
+	TTL time.Duration `yaml:"ttl" env:"CACHE_TTL"`
+	// 存在しなかったことを保持する期間(0 の場合は保持しない)
+	NegativeTTL time.Duration `yaml:"negativeTtl" env:"CACHE_NEGATIVE_TTL"`
+}
+
+// 既定値
+// 設定ファイル・環境変数で指定されなかった項目に使用する。
+func defaults() Config {
+	return Config{
+		Server: ServerConfig{
+			Port:             8080,
+			CorsAllowOrigins: []string{"*"},
+			LogTimezone:      "Asia/Tokyo",
+			ReadTimeout:      15 * time.Second,
+			WriteTimeout:     30 * time.Second,
+			IdleTimeout:      60 * time.Second,
+			ShutdownTimeout:  20 * time.Second,
+		},
+		Database: DatabaseConfig{
+			Driver:     "postgres",
+			SqlitePath: ":memory:",
+			Port:       5432,
+			SslMode:    "require",
+			Pool: PoolConfig{
+				MaxOpenConns:    25,
+				MaxIdleConns:    5,
+				ConnMaxLifetime: 30 * time.Minute,
+				ConnMaxIdleTime: 5 * time.Minute,
+			},
+			HealthCheck: HealthCheckConfig{
+				Interval:         10 * time.Second,
+				Timeout:          2 * time.Second,
+				FailureThreshold: 3,
+			},
+			QueryTimeout: 30 * time.Second,
+			QueryLog: QueryLogConfig{
+				Level:         "warn",
+				SlowThreshold: 200 * time.Millisecond,
+			},
+		},
+		Auth: AuthConfig{
+			AccessTokenTTL: 24 * time.Hour,
+		},
+		Mail: MailConfig{
+			Driver: "log",
+			Smtp: SmtpConfig{
+				Port: 587,
+			},
+		},
+		Storage: StorageConfig{
+			Driver:   "local",
+			LocalDir: "./storage",
+		},
+		Signup: SignupConfig{
+			Mode: "open",
+		},
+		Outbox: OutboxConfig{
+			PollInterval:   time.Second,
+			BatchSize:      100,
+			MaxAttempts:    10,
+			RetryBaseDelay: time.Second,
+			RetryMaxDelay:  10 * time.Minute,
+			LockTimeout:    time.Minute,
+			Retention:      7 * 24 * time.Hour,
+			PurgeInterval:  time.Hour,
+		},
+		Cache: CacheConfig{
+			Driver:      "none",
+			Capacity:    10000,
+			TTL:         5 * time.Minute,
+			NegativeTTL: 30 * time.Second,
+		},
+		Idempotency: IdempotencyConfig{
+			TTL:           24 * time.Hour,
+			LockTimeout:   time.Minute,
+			PurgeInterval: time.Hour,
+		},
+	}
+}
+
+// ログ出力に使用するタイムゾーン
+// 読み込み時に検証済みのため、失敗した場合は UTC とする。
+func (sc ServerConfig) LogLocation() *time.Location {
+	location, err := time.LoadLocation(sc.LogTimezone)
+	if err != nil {
+		return time.UTC
+	}
+	return location
+}
+
+// レプリカごとの接続設定
+// 接続先以外はプライマリの設定を引き継ぐ。
+func (dc DatabaseConfig) ReplicaConfigs() ([]DatabaseConfig, error) {
+	configs := make([]DatabaseConfig, 0, len(dc.ReplicaHosts))
+	for _, replicaHost := range dc.ReplicaHosts {
+		replica := dc
+		replica.ReplicaHosts = nil
+		replica.Host = replicaHost
+		if host, port, err := net.SplitHostPort(replicaHost); err == nil {
+			number, err := strconv.Atoi(port)
+			if err != nil || host == "" {
+				return nil, fmt.Errorf("レプリカの接続先が不正です: %s", replicaHost)
+			}
+			replica.Host, replica.Port = host, number
+		}
+		configs = append(configs, replica)
+	}
+	return configs, nil
+}
+
+// 鍵IDごとの暗号化の鍵
+func (ec EncryptionConfig) ParsedKeys() (map[string][]byte, error) {
+	keys := make(map[string][]byte, len(ec.Keys))
+	for _, entry := range ec.Keys {
+		keyId, encoded, ok := strings.Cut(entry, ":")
+		if !ok || !validKeyId.MatchString(keyId) {
+			return nil, fmt.Errorf("鍵は 鍵ID:鍵 の形式で指定してください(鍵IDは英数字と _ - のみ)")
+		}
+		if _, ok := keys[keyId]; ok {
+			return nil, fmt.Errorf("鍵ID %s が重複しています", keyId)
+		}
+		key, err := decodeKey(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("鍵ID %s の鍵が不正です: %w", keyId, err)
+		}
+		keys[keyId] = key
+	}
+	return keys, nil
+}
+
+// ブラインドインデックスの鍵
+func (ec EncryptionConfig) ParsedBlindIndexKey() ([]byte, error) {
+	return decodeKey(ec.BlindIndexKey)
+}
+
+// 暗号化の鍵の長さ(AES-256・HMAC-SHA256)
+const encryptionKeySize = 32
+
+var validKeyId = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+func decodeKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("base64 で指定してください")
+	}
+	if len(key) != encryptionKeySize {
+		return nil, fmt.Errorf("%dバイトの値を指定してください", encryptionKeySize)
+	}
+	return key, nil
+}
+
+// PostgreSQL の接続文字列(key=value 形式)
+// 値に空白や引用符を含む場合に備え、すべての値を引用符で囲む。
+func (dc DatabaseConfig) Dsn() string {
+	quote := func(value string) string {
+		return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value) + "'"
+	}
+	return fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
+		quote(dc.Host), dc.Port, quote(dc.User), quote(dc.Password), quote(dc.Name), quote(dc.SslMode))
+}
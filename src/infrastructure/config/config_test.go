@@ -0,0 +1,337 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Go_CleanArch/infrastructure/config"
+	"github.com/stretchr/testify/assert"
+)
+
+const validYaml = `
+database:
+  host: db
+  user: app
+  name: app
+  sslMode: disable
+  encryption:
+    keys:
+      - k1:MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=
+    activeKeyId: k1
+    blindIndexKey: ZmVkY2JhOTg3NjU0MzIxMGZlZGNiYTk4NzY1NDMyMTA=
+auth:
+  tokenSecret: file-secret-0123456789
+app:
+  baseUrl: http://localhost:3000
+`
+
+// テスト用の設定ファイル・secret を一時ディレクトリに作成し、読み込み元を返す
+func newSource(t *testing.T, profile string, content string, env map[string]string, secrets map[string]string) config.Source {
+	t.Helper()
+	dir := t.TempDir()
+	if content != "" {
+		if err := os.WriteFile(filepath.Join(dir, profile+".yaml"), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	secretsDir := t.TempDir()
+	for name, value := range secrets {
+		if err := os.WriteFile(filepath.Join(secretsDir, name), []byte(value), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return config.Source{
+		Profile:    profile,
+		Dir:        dir,
+		SecretsDir: secretsDir,
+		LookupEnv: func(key string) (string, bool) {
+			value, ok := env[key]
+			return value, ok
+		},
+	}
+}
+
+func TestLoadFrom(t *testing.T) {
+	t.Parallel()
+
+	t.Run("正常系_既定値と設定ファイル", func(t *testing.T) {
+		t.Parallel()
+		cfg, err := config.LoadFrom(newSource(t, config.ProfileLocal, validYaml, nil, nil))
+
+		assert.NoError(t, err)
+		assert.Equal(t, config.ProfileLocal, cfg.Profile)
+		assert.Equal(t, 8080, cfg.Server.Port)
+		assert.Equal(t, []string{"*"}, cfg.Server.CorsAllowOrigins)
+		assert.Equal(t, "db", cfg.Database.Host)
+		assert.Equal(t, "disable", cfg.Database.SslMode)
+		assert.Equal(t, 24*time.Hour, cfg.Auth.AccessTokenTTL)
+		assert.Empty(t, cfg.Auth.AdminUserIds)
+		assert.Equal(t, "log", cfg.Mail.Driver)
+		assert.Equal(t, 10, cfg.Outbox.MaxAttempts)
+		assert.Equal(t, time.Minute, cfg.Outbox.LockTimeout)
+		assert.Equal(t, 7*24*time.Hour, cfg.Outbox.Retention)
+		assert.Equal(t, "none", cfg.Cache.Driver)
+	})
+
+	t.Run("正常系_環境変数とsecretで上書き", func(t *testing.T) {
+		t.Parallel()
+		env := map[string]string{
+			"DB_HOST":               "env-host",
+			"DB_PORT":               "15432",
+			"AUTH_TOKEN_SECRET":     "env-secret-0123456789",
+			"AUTH_ACCESS_TOKEN_TTL": "30m",
+			"CORS_ALLOW_ORIGINS":    "https://a.example.com, https://b.example.com",
+			"AUTH_ADMIN_USER_IDS":   "admin-1,admin-2",
+			// 空の環境変数は未指定として扱う
+			"DB_USER": "",
+		}
+		secrets := map[string]string{
+			"auth_token_secret": "secret-file-0123456789\n",
+		}
+		cfg, err := config.LoadFrom(newSource(t, config.ProfileLocal, validYaml, env, secrets))
+
+		assert.NoError(t, err)
+		assert.Equal(t, "env-host", cfg.Database.Host)
+		assert.Equal(t, 15432, cfg.Database.Port)
+		assert.Equal(t, "app", cfg.Database.User)
+		assert.Equal(t, "secret-file-0123456789", cfg.Auth.TokenSecret)
+		assert.Equal(t, 30*time.Minute, cfg.Auth.AccessTokenTTL)
+		assert.Equal(t, []string{"https://a.example.com", "https://b.example.com"}, cfg.Server.CorsAllowOrigins)
+		assert.Equal(t, []string{"admin-1", "admin-2"}, cfg.Auth.AdminUserIds)
+	})
+
+	t.Run("異常系_プロファイルが不正", func(t *testing.T) {
+		t.Parallel()
+		_, err := config.LoadFrom(newSource(t, "staging", validYaml, nil, nil))
+
+		assert.ErrorContains(t, err, "プロファイル")
+	})
+
+	t.Run("異常系_設定ファイルが存在しない", func(t *testing.T) {
+		t.Parallel()
+		_, err := config.LoadFrom(newSource(t, config.ProfileTest, "", nil, nil))
+
+		assert.ErrorContains(t, err, "test.yaml")
+	})
+
+	t.Run("異常系_設定ファイルに未定義のキー", func(t *testing.T) {
+		t.Parallel()
+		_, err := config.LoadFrom(newSource(t, config.ProfileLocal, validYaml+"unknown: value\n", nil, nil))
+
+		assert.ErrorContains(t, err, "unknown")
+	})
+
+	t.Run("異常系_環境変数の型が不正", func(t *testing.T) {
+		t.Parallel()
+		_, err := config.LoadFrom(newSource(t, config.ProfileLocal, validYaml, map[string]string{"DB_PORT": "abc"}, nil))
+
+		assert.ErrorContains(t, err, "database.port (DB_PORT)")
+	})
+
+	t.Run("異常系_不正な項目をまとめて返す", func(t *testing.T) {
+		t.Parallel()
+		_, err := config.LoadFrom(newSource(t, config.ProfileProd, "server:\n  port: 0\n", nil, nil))
+
+		assert.ErrorContains(t, err, "プロファイル: prod")
+		assert.ErrorContains(t, err, "server.port")
+		assert.ErrorContains(t, err, "server.corsAllowOrigins: 本番環境では * を指定できません")
+		assert.ErrorContains(t, err, "database.host")
+		assert.ErrorContains(t, err, "auth.tokenSecret")
+		assert.ErrorContains(t, err, "app.baseUrl")
+	})
+}
+
+func TestServerTimeouts(t *testing.T) {
+	t.Parallel()
+
+	t.Run("正常系_既定値と環境変数による上書き", func(t *testing.T) {
+		t.Parallel()
+		cfg, err := config.LoadFrom(newSource(t, config.ProfileLocal, validYaml, map[string]string{"SERVER_SHUTDOWN_TIMEOUT": "5s"}, nil))
+
+		assert.NoError(t, err)
+		assert.Equal(t, 15*time.Second, cfg.Server.ReadTimeout)
+		assert.Equal(t, 30*time.Second, cfg.Server.WriteTimeout)
+		assert.Equal(t, time.Minute, cfg.Server.IdleTimeout)
+		assert.Equal(t, 5*time.Second, cfg.Server.ShutdownTimeout)
+	})
+
+	t.Run("異常系_上限時間に0を指定", func(t *testing.T) {
+		t.Parallel()
+		env := map[string]string{"SERVER_WRITE_TIMEOUT": "0s", "SERVER_SHUTDOWN_TIMEOUT": "0s"}
+		_, err := config.LoadFrom(newSource(t, config.ProfileLocal, validYaml, env, nil))
+
+		assert.ErrorContains(t, err, "server.writeTimeout")
+		assert.ErrorContains(t, err, "server.shutdownTimeout")
+	})
+}
+
+func TestCacheConfig(t *testing.T) {
+	t.Parallel()
+
+	t.Run("正常系_環境変数でメモリ上のキャッシュに切り替え", func(t *testing.T) {
+		t.Parallel()
+		env := map[string]string{"CACHE_DRIVER": "memory", "CACHE_TTL": "1m", "CACHE_NEGATIVE_TTL": "0s"}
+		cfg, err := config.LoadFrom(newSource(t, config.ProfileLocal, validYaml, env, nil))
+
+		assert.NoError(t, err)
+		assert.Equal(t, "memory", cfg.Cache.Driver)
+		assert.Equal(t, 10000, cfg.Cache.Capacity)
+		assert.Equal(t, time.Minute, cfg.Cache.TTL)
+		assert.Equal(t, time.Duration(0), cfg.Cache.NegativeTTL)
+	})
+
+	t.Run("異常系_不正なキャッシュの設定", func(t *testing.T) {
+		t.Parallel()
+		env := map[string]string{"CACHE_DRIVER": "memory", "CACHE_CAPACITY": "0", "CACHE_TTL": "10s", "CACHE_NEGATIVE_TTL": "1m"}
+		_, err := config.LoadFrom(newSource(t, config.ProfileLocal, validYaml, env, nil))
+
+		assert.ErrorContains(t, err, "cache.capacity")
+		assert.ErrorContains(t, err, "cache.negativeTtl")
+
+		_, err = config.LoadFrom(newSource(t, config.ProfileLocal, validYaml, map[string]string{"CACHE_DRIVER": "redis"}, nil))
+		assert.ErrorContains(t, err, "cache.driver")
+	})
+}
+
+func TestIdempotencyConfig(t *testing.T) {
+	t.Parallel()
+
+	t.Run("正常系_既定値と環境変数による上書き", func(t *testing.T) {
+		t.Parallel()
+		cfg, err := config.LoadFrom(newSource(t, config.ProfileLocal, validYaml, map[string]string{"IDEMPOTENCY_TTL": "1h"}, nil))
+
+		assert.NoError(t, err)
+		assert.Equal(t, time.Hour, cfg.Idempotency.TTL)
+		assert.Equal(t, time.Minute, cfg.Idempotency.LockTimeout)
+		assert.Equal(t, time.Hour, cfg.Idempotency.PurgeInterval)
+	})
+
+	t.Run("異常系_処理中の期限が保存期間より長い", func(t *testing.T) {
+		t.Parallel()
+		env := map[string]string{"IDEMPOTENCY_TTL": "30s", "IDEMPOTENCY_LOCK_TIMEOUT": "1m"}
+		_, err := config.LoadFrom(newSource(t, config.ProfileLocal, validYaml, env, nil))
+
+		assert.ErrorContains(t, err, "idempotency.lockTimeout")
+	})
+}
+
+func TestEncryptionConfig(t *testing.T) {
+	t.Parallel()
+
+	t.Run("正常系_鍵ファイルの鍵を追加する", func(t *testing.T) {
+		t.Parallel()
+		keyFile := filepath.Join(t.TempDir(), "keys.yaml")
+		content := "keys:\n  - k2:ZmVkY2JhOTg3NjU0MzIxMGZlZGNiYTk4NzY1NDMyMTA=\nactiveKeyId: k2\n"
+		if err := os.WriteFile(keyFile, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		env := map[string]string{"DB_ENCRYPTION_KEY_FILE": keyFile}
+		cfg, err := config.LoadFrom(newSource(t, config.ProfileLocal, validYaml, env, nil))
+
+		assert.NoError(t, err)
+		keys, err := cfg.Database.Encryption.ParsedKeys()
+		assert.NoError(t, err)
+		assert.Len(t, keys, 2)
+		assert.Len(t, keys["k2"], 32)
+		// 設定ファイルで指定した値を優先する
+		assert.Equal(t, "k1", cfg.Database.Encryption.ActiveKeyId)
+	})
+
+	t.Run("異常系_鍵ファイルが存在しない", func(t *testing.T) {
+		t.Parallel()
+		env := map[string]string{"DB_ENCRYPTION_KEY_FILE": filepath.Join(t.TempDir(), "missing.yaml")}
+		_, err := config.LoadFrom(newSource(t, config.ProfileLocal, validYaml, env, nil))
+
+		assert.ErrorContains(t, err, "missing.yaml")
+	})
+
+	t.Run("異常系_不正な鍵", func(t *testing.T) {
+		t.Parallel()
+		env := map[string]string{
+			"DB_ENCRYPTION_KEYS":            "k1:c2hvcnQ=",
+			"DB_ENCRYPTION_ACTIVE_KEY_ID":   "k2",
+			"DB_ENCRYPTION_BLIND_INDEX_KEY": "not-base64",
+		}
+		_, err := config.LoadFrom(newSource(t, config.ProfileLocal, validYaml, env, nil))
+
+		assert.ErrorContains(t, err, "database.encryption.keys: 鍵ID k1 の鍵が不正です")
+		assert.ErrorContains(t, err, "database.encryption.blindIndexKey")
+
+		env = map[string]string{"DB_ENCRYPTION_ACTIVE_KEY_ID": "k2"}
+		_, err = config.LoadFrom(newSource(t, config.ProfileLocal, validYaml, env, nil))
+		assert.ErrorContains(t, err, "database.encryption.activeKeyId")
+	})
+}
+
+func TestDatabaseDriver(t *testing.T) {
+	t.Parallel()
+
+	t.Run("正常系_SQLiteは接続先の指定が不要", func(t *testing.T) {
+		t.Parallel()
+		content := "database:\n  driver: sqlite\n  encryption:\n    keys: [k1:MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=]\n    activeKeyId: k1\n    blindIndexKey: ZmVkY2JhOTg3NjU0MzIxMGZlZGNiYTk4NzY1NDMyMTA=\nauth:\n  tokenSecret: file-secret-0123456789\napp:\n  baseUrl: http://localhost:3000\n"
+		cfg, err := config.LoadFrom(newSource(t, config.ProfileLocal, content, nil, nil))
+
+		assert.NoError(t, err)
+		assert.Equal(t, "sqlite", cfg.Database.Driver)
+		assert.Equal(t, ":memory:", cfg.Database.SqlitePath)
+	})
+
+	t.Run("正常系_環境変数でSQLiteに切り替え", func(t *testing.T) {
+		t.Parallel()
+		env := map[string]string{"DB_DRIVER": "sqlite", "DB_SQLITE_PATH": "./tmp/app.db"}
+		cfg, err := config.LoadFrom(newSource(t, config.ProfileLocal, validYaml, env, nil))
+
+		assert.NoError(t, err)
+		assert.Equal(t, "sqlite", cfg.Database.Driver)
+		assert.Equal(t, "./tmp/app.db", cfg.Database.SqlitePath)
+	})
+
+	t.Run("異常系_不正な接続先", func(t *testing.T) {
+		t.Parallel()
+		_, err := config.LoadFrom(newSource(t, config.ProfileLocal, validYaml, map[string]string{"DB_DRIVER": "mysql"}, nil))
+
+		assert.ErrorContains(t, err, "database.driver")
+	})
+
+	t.Run("異常系_本番環境ではSQLiteを使用できない", func(t *testing.T) {
+		t.Parallel()
+		_, err := config.LoadFrom(newSource(t, config.ProfileProd, validYaml, map[string]string{"DB_DRIVER": "sqlite"}, nil))
+
+		assert.ErrorContains(t, err, "database.driver: 本番環境では sqlite を指定できません")
+	})
+}
+
+func TestReplicaConfigs(t *testing.T) {
+	t.Parallel()
+
+	t.Run("正常系_接続先以外はプライマリの設定を引き継ぐ", func(t *testing.T) {
+		t.Parallel()
+		primary := config.DatabaseConfig{
+			Host:         "primary",
+			Port:         5432,
+			User:         "app",
+			Name:         "app",
+			ReplicaHosts: []string{"replica-1", "replica-2:6432"},
+		}
+
+		replicas, err := primary.ReplicaConfigs()
+
+		assert.NoError(t, err)
+		assert.Len(t, replicas, 2)
+		assert.Equal(t, "replica-1", replicas[0].Host)
+		assert.Equal(t, 5432, replicas[0].Port)
+		assert.Equal(t, "replica-2", replicas[1].Host)
+		assert.Equal(t, 6432, replicas[1].Port)
+		assert.Equal(t, "app", replicas[1].User)
+		assert.Empty(t, replicas[1].ReplicaHosts)
+	})
+
+	t.Run("異常系_ポートが不正", func(t *testing.T) {
+		t.Parallel()
+		_, err := config.LoadFrom(newSource(t, config.ProfileLocal, validYaml, map[string]string{"DB_REPLICA_HOSTS": "replica-1:abc"}, nil))
+
+		assert.ErrorContains(t, err, "database.replicaHosts")
+	})
+}
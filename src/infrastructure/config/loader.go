@@ -0,0 +1,228 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Source は設定の読み込み元を表す。
+type Source struct {
+	// 実行環境(local / test / prod)
+	Profile string
+	// プロファイルごとの設定ファイル(<Profile>.yaml)を格納するディレクトリ
+	Dir string
+	// Docker secret のファイルを格納するディレクトリ(ファイル名は環境変数名の小文字)
+	SecretsDir string
+	// 環境変数の参照
+	LookupEnv func(key string) (string, bool)
+}
+
+// 実行環境の環境変数から読み込み元を決定する
+// APP_ENV でプロファイルを、CONFIG_DIR・SECRETS_DIR で各ディレクトリを指定できる。
+func SourceFromEnv() Source {
+	source := Source{
+		Profile:    os.Getenv("APP_ENV"),
+		Dir:        os.Getenv("CONFIG_DIR"),
+		SecretsDir: os.Getenv("SECRETS_DIR"),
+		LookupEnv:  os.LookupEnv,
+	}
+	if source.Profile == "" {
+		source.Profile = ProfileLocal
+	}
+	if source.Dir == "" {
+		source.Dir = "config"
+	}
+	if source.SecretsDir == "" {
+		source.SecretsDir = "/run/secrets"
+	}
+	return source
+}
+
+// 実行環境の環境変数を読み込み元として設定を読み込む
+func Load() (*Config, error) {
+	return LoadFrom(SourceFromEnv())
+}
+
+// 設定の読み込み
+// 既定値 → 設定ファイル → 環境変数 → Docker secret の順に読み込み、後から読み込んだ値で上書きする。
+// 読み込んだ設定は検証し、不正な場合はすべての項目のエラーをまとめて返す。
+func LoadFrom(source Source) (*Config, error) {
+	switch source.Profile {
+	case ProfileLocal, ProfileTest, ProfileProd:
+	default:
+		return nil, fmt.Errorf("設定の読み込みに失敗しました: プロファイルは %s / %s / %s のいずれかを指定してください: %q", ProfileLocal, ProfileTest, ProfileProd, source.Profile)
+	}
+
+	cfg := defaults()
+	cfg.Profile = source.Profile
+
+	path := filepath.Join(source.Dir, source.Profile+".yaml")
+	if err := loadFile(path, &cfg); err != nil {
+		return nil, fmt.Errorf("設定の読み込みに失敗しました: %w", err)
+	}
+	if source.LookupEnv != nil {
+		if err := applyOverrides(reflect.ValueOf(&cfg).Elem(), "", func(env string) (string, bool, error) {
+			// compose の ${VAR} 展開などで空文字が渡される場合があるため、空の環境変数は未指定として扱う
+			value, ok := source.LookupEnv(env)
+			return value, ok && value != "", nil
+		}); err != nil {
+			return nil, fmt.Errorf("設定の読み込みに失敗しました: %w", err)
+		}
+	}
+	if source.SecretsDir != "" {
+		if err := applyOverrides(reflect.ValueOf(&cfg).Elem(), "", secretReader(source.SecretsDir)); err != nil {
+			return nil, fmt.Errorf("設定の読み込みに失敗しました: %w", err)
+		}
+	}
+	if cfg.Database.Encryption.KeyFile != "" {
+		if err := loadKeyFile(cfg.Database.Encryption.KeyFile, &cfg.Database.Encryption); err != nil {
+			return nil, fmt.Errorf("設定の読み込みに失敗しました: %w", err)
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+func loadFile(path string, cfg *Config) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("設定ファイル %s を読み込めません: %w", path, err)
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	// 記述ミスに気付けるよう、未定義のキーはエラーとする
+	decoder.KnownFields(true)
+	if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("設定ファイル %s の形式が不正です: %w", path, err)
+	}
+	return nil
+}
+
+// 暗号化の鍵ファイルを読み込む
+// keys は設定済みの鍵に追加し、activeKeyId・blindIndexKey は未指定の場合のみ使用する。
+func loadKeyFile(path string, encryption *EncryptionConfig) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("鍵ファイル %s を読み込めません: %w", path, err)
+	}
+	var keyFile struct {
+		Keys          []string `yaml:"keys"`
+		ActiveKeyId   string   `yaml:"activeKeyId"`
+		BlindIndexKey string   `yaml:"blindIndexKey"`
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&keyFile); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("鍵ファイル %s の形式が不正です: %w", path, err)
+	}
+	encryption.Keys = append(encryption.Keys, keyFile.Keys...)
+	if encryption.ActiveKeyId == "" {
+		encryption.ActiveKeyId = keyFile.ActiveKeyId
+	}
+	if encryption.BlindIndexKey == "" {
+		encryption.BlindIndexKey = keyFile.BlindIndexKey
+	}
+	return nil
+}
+
+// Docker secret のファイルから値を読み込む(ファイルが無い項目は上書きしない)
+func secretReader(dir string) func(env string) (string, bool, error) {
+	return func(env string) (string, bool, error) {
+		path := filepath.Join(dir, strings.ToLower(env))
+		content, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			return "", false, nil
+		}
+		if err != nil {
+			return "", false, fmt.Errorf("secret %s を読み込めません: %w", path, err)
+		}
+		return strings.TrimRight(string(content), "\r\n"), true, nil
+	}
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// env タグを持つ項目を、lookup で取得した値で上書きする
+func applyOverrides(v reflect.Value, prefix string, lookup func(env string) (string, bool, error)) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		value := v.Field(i)
+		key := prefix + strings.Split(field.Tag.Get("yaml"), ",")[0]
+
+		if field.Type.Kind() == reflect.Struct {
+			if err := applyOverrides(value, key+".", lookup); err != nil {
+				return err
+			}
+			continue
+		}
+
+		env := field.Tag.Get("env")
+		if env == "" {
+			continue
+		}
+		raw, ok, err := lookup(env)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		if err := setValue(value, raw); err != nil {
+			return fmt.Errorf("%s (%s) の値が不正です: %w", key, env, err)
+		}
+	}
+	return nil
+}
+
+func setValue(value reflect.Value, raw string) error {
+	if value.Type() == durationType {
+		duration, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		value.SetInt(int64(duration))
+		return nil
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(raw)
+	case reflect.Int:
+		number, err := strconv.Atoi(raw)
+		if err != nil {
+			return err
+		}
+		value.SetInt(int64(number))
+	case reflect.Bool:
+		flag, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		value.SetBool(flag)
+	case reflect.Slice:
+		// カンマ区切りで複数の値を指定する
+		items := []string{}
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		value.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("未対応の型です: %s", value.Type())
+	}
+	return nil
+}
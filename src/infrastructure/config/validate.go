@@ -0,0 +1,152 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// 設定の検証
+// 起動後に設定の誤りで失敗しないよう、不正な項目をすべて列挙したエラーを返す。
+func (cfg Config) Validate() error {
+	v := &validator{}
+
+	v.check(cfg.Server.Port > 0 && cfg.Server.Port <= 65535, "server.port", "1～65535 の範囲で指定してください")
+	v.check(len(cfg.Server.CorsAllowOrigins) > 0, "server.corsAllowOrigins", "アクセス元を1件以上指定してください")
+	if cfg.Profile == ProfileProd {
+		// 本番環境ではすべてのアクセス元を許可しない
+		v.check(!contains(cfg.Server.CorsAllowOrigins, "*"), "server.corsAllowOrigins", "本番環境では * を指定できません")
+	}
+	_, err := time.LoadLocation(cfg.Server.LogTimezone)
+	v.check(cfg.Server.LogTimezone != "" && err == nil, "server.logTimezone", "Asia/Tokyo 形式で指定してください")
+	v.check(cfg.Server.ReadTimeout > 0, "server.readTimeout", "1s 以上で指定してください")
+	v.check(cfg.Server.WriteTimeout > 0, "server.writeTimeout", "1s 以上で指定してください")
+	v.check(cfg.Server.IdleTimeout > 0, "server.idleTimeout", "1s 以上で指定してください")
+	v.check(cfg.Server.ShutdownTimeout > 0, "server.shutdownTimeout", "1s 以上で指定してください")
+
+	v.oneOf(cfg.Database.Driver, "database.driver", "postgres", "sqlite")
+	switch cfg.Database.Driver {
+	case "postgres":
+		v.required(cfg.Database.Host, "database.host")
+		v.check(cfg.Database.Port > 0 && cfg.Database.Port <= 65535, "database.port", "1～65535 の範囲で指定してください")
+		v.required(cfg.Database.User, "database.user")
+		v.required(cfg.Database.Name, "database.name")
+		v.oneOf(cfg.Database.SslMode, "database.sslMode", "disable", "allow", "prefer", "require", "verify-ca", "verify-full")
+		_, err = cfg.Database.ReplicaConfigs()
+		v.check(err == nil, "database.replicaHosts", "host または host:port の形式で指定してください")
+	case "sqlite":
+		// SQLite はデータベースサーバーを用意せずに動作させる開発・テスト用途に限る
+		v.check(cfg.Profile != ProfileProd, "database.driver", "本番環境では sqlite を指定できません")
+		v.required(cfg.Database.SqlitePath, "database.sqlitePath")
+		v.check(len(cfg.Database.ReplicaHosts) == 0, "database.replicaHosts", "sqlite では指定できません")
+	}
+	pool := cfg.Database.Pool
+	v.check(pool.MaxOpenConns > 0, "database.pool.maxOpenConns", "1 以上で指定してください")
+	v.check(pool.MaxIdleConns >= 0 && pool.MaxIdleConns <= pool.MaxOpenConns, "database.pool.maxIdleConns", "0 以上 maxOpenConns 以下で指定してください")
+	v.check(pool.ConnMaxLifetime >= 0, "database.pool.connMaxLifetime", "0 以上で指定してください")
+	v.check(pool.ConnMaxIdleTime >= 0, "database.pool.connMaxIdleTime", "0 以上で指定してください")
+	healthCheck := cfg.Database.HealthCheck
+	v.check(healthCheck.Interval > 0, "database.healthCheck.interval", "1s 以上で指定してください")
+	v.check(healthCheck.Timeout > 0 && healthCheck.Timeout <= healthCheck.Interval, "database.healthCheck.timeout", "interval 以下で指定してください")
+	v.check(healthCheck.FailureThreshold > 0, "database.healthCheck.failureThreshold", "1 以上で指定してください")
+	v.check(cfg.Database.QueryTimeout >= 0, "database.queryTimeout", "0 以上で指定してください")
+	v.oneOf(cfg.Database.QueryLog.Level, "database.queryLog.level", "silent", "error", "warn", "info")
+	v.check(cfg.Database.QueryLog.SlowThreshold >= 0, "database.queryLog.slowThreshold", "0 以上で指定してください")
+	encryption := cfg.Database.Encryption
+	keys, err := encryption.ParsedKeys()
+	if err != nil {
+		v.check(false, "database.encryption.keys", err.Error())
+	} else {
+		v.check(len(keys) > 0, "database.encryption.keys", "鍵を1件以上指定してください")
+		_, ok := keys[encryption.ActiveKeyId]
+		v.check(len(keys) == 0 || ok, "database.encryption.activeKeyId", "keys に含まれる鍵IDを指定してください")
+	}
+	_, err = encryption.ParsedBlindIndexKey()
+	v.check(err == nil, "database.encryption.blindIndexKey", "base64 で表した32バイトの値を指定してください")
+
+	v.check(len(cfg.Auth.TokenSecret) >= 16, "auth.tokenSecret", "16文字以上で指定してください")
+	v.check(cfg.Auth.AccessTokenTTL > 0, "auth.accessTokenTtl", "1s 以上で指定してください")
+
+	v.url(cfg.App.BaseUrl, "app.baseUrl")
+
+	v.oneOf(cfg.Mail.Driver, "mail.driver", "log", "smtp")
+	if cfg.Mail.Driver == "smtp" {
+		v.required(cfg.Mail.From, "mail.from")
+		v.required(cfg.Mail.Smtp.Host, "mail.smtp.host")
+		v.check(cfg.Mail.Smtp.Port > 0 && cfg.Mail.Smtp.Port <= 65535, "mail.smtp.port", "1～65535 の範囲で指定してください")
+	}
+
+	v.oneOf(cfg.Storage.Driver, "storage.driver", "local", "s3")
+	switch cfg.Storage.Driver {
+	case "local":
+		v.required(cfg.Storage.LocalDir, "storage.localDir")
+	case "s3":
+		v.required(cfg.Storage.S3.Region, "storage.s3.region")
+		v.required(cfg.Storage.S3.Bucket, "storage.s3.bucket")
+	}
+	if cfg.Storage.PublicBaseUrl != "" {
+		v.url(cfg.Storage.PublicBaseUrl, "storage.publicBaseUrl")
+	}
+
+	v.oneOf(cfg.Signup.Mode, "signup.mode", "open", "restricted")
+
+	outbox := cfg.Outbox
+	v.check(outbox.PollInterval > 0, "outbox.pollInterval", "1s 以上で指定してください")
+	v.check(outbox.BatchSize > 0, "outbox.batchSize", "1 以上で指定してください")
+	v.check(outbox.MaxAttempts > 0, "outbox.maxAttempts", "1 以上で指定してください")
+	v.check(outbox.RetryBaseDelay > 0, "outbox.retryBaseDelay", "1s 以上で指定してください")
+	v.check(outbox.RetryMaxDelay >= outbox.RetryBaseDelay, "outbox.retryMaxDelay", "retryBaseDelay 以上で指定してください")
+	v.check(outbox.LockTimeout > 0, "outbox.lockTimeout", "1s 以上で指定してください")
+	v.check(outbox.Retention > 0, "outbox.retention", "1s 以上で指定してください")
+	v.check(outbox.PurgeInterval > 0, "outbox.purgeInterval", "1s 以上で指定してください")
+
+	v.oneOf(cfg.Cache.Driver, "cache.driver", "none", "memory")
+	if cfg.Cache.Driver == "memory" {
+		v.check(cfg.Cache.Capacity > 0, "cache.capacity", "1 以上で指定してください")
+		v.check(cfg.Cache.TTL > 0, "cache.ttl", "1s 以上で指定してください")
+		v.check(cfg.Cache.NegativeTTL >= 0 && cfg.Cache.NegativeTTL <= cfg.Cache.TTL, "cache.negativeTtl", "0 以上 ttl 以下で指定してください")
+	}
+
+	idempotency := cfg.Idempotency
+	v.check(idempotency.TTL > 0, "idempotency.ttl", "1s 以上で指定してください")
+	v.check(idempotency.LockTimeout > 0 && idempotency.LockTimeout <= idempotency.TTL, "idempotency.lockTimeout", "1s 以上 ttl 以下で指定してください")
+	v.check(idempotency.PurgeInterval > 0, "idempotency.purgeInterval", "1s 以上で指定してください")
+
+	if len(v.problems) > 0 {
+		return fmt.Errorf("設定が不正です(プロファイル: %s):\n  - %s", cfg.Profile, strings.Join(v.problems, "\n  - "))
+	}
+	return nil
+}
+
+type validator struct {
+	problems []string
+}
+
+func (v *validator) check(ok bool, key string, message string) {
+	if !ok {
+		v.problems = append(v.problems, fmt.Sprintf("%s: %s", key, message))
+	}
+}
+
+func (v *validator) required(value string, key string) {
+	v.check(value != "", key, "値を指定してください")
+}
+
+func (v *validator) oneOf(value string, key string, allowed ...string) {
+	v.check(contains(allowed, value), key, fmt.Sprintf("%s のいずれかを指定してください(指定値: %q)", strings.Join(allowed, " / "), value))
+}
+
+func (v *validator) url(value string, key string) {
+	parsed, err := url.Parse(value)
+	v.check(err == nil && (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != "", key, "http(s):// から始まるURLを指定してください")
+}
+
+func contains(values []string, target string) bool {
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+	return false
+}
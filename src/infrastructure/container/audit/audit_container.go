@@ -0,0 +1,28 @@
+package audit
+
+import (
+	"context"
+
+	auditController "github.com/Go_CleanArch/interface_adapter/controller"
+	query "github.com/Go_CleanArch/usecase/query/service"
+	auditService "github.com/Go_CleanArch/usecase/service/audit"
+)
+
+type AuditContainer struct {
+	AuditLogController *auditController.AuditLogController
+}
+
+func NewContainer(ctx context.Context) (*AuditContainer, error) {
+	// DI注入
+	auditLogQuery, err := query.NewAuditLogQuery(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	auditLogSvc := auditService.NewAuditLogService(auditLogQuery)
+	auditLogCtrl := auditController.NewAuditLogController(*auditLogSvc)
+
+	return &AuditContainer{
+		AuditLogController: auditLogCtrl,
+	}, nil
+}
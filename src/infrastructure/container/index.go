@@ -2,21 +2,92 @@ package container
 
 import (
 	"context"
+	"strconv"
 
+	"github.com/Go_CleanArch/common/auth"
+	"github.com/Go_CleanArch/domain/event"
+	"github.com/Go_CleanArch/infrastructure/config"
+	audit "github.com/Go_CleanArch/infrastructure/container/audit"
+	organization "github.com/Go_CleanArch/infrastructure/container/organization"
 	user "github.com/Go_CleanArch/infrastructure/container/user"
+	"github.com/Go_CleanArch/infrastructure/idempotency"
+	"github.com/Go_CleanArch/infrastructure/outbox"
+	gatewayCache "github.com/Go_CleanArch/interface_adapter/gateway/cache"
+	gatewayMail "github.com/Go_CleanArch/interface_adapter/gateway/mail"
 )
 
 type Container struct {
-	UserContainer *user.UserContainer
+	UserContainer         *user.UserContainer
+	OrganizationContainer *organization.OrganizationContainer
+	AuditContainer        *audit.AuditContainer
+	TokenIssuer           *auth.TokenIssuer
+	// アウトボックスに保存したドメインイベントの配信
+	EventDispatcher *outbox.Dispatcher
+	// Idempotency-Key を指定したリクエストのレスポンスの保存
+	IdempotencyStore *idempotency.Store
 }
 
-func NewContainer(ctx context.Context) (*Container, error) {
-	userContainer, err := user.NewContainer(ctx)
+func NewContainer(ctx context.Context, cfg *config.Config) (*Container, error) {
+	tokenIssuer, err := auth.NewTokenIssuer(cfg.Auth.TokenSecret, cfg.Auth.AccessTokenTTL)
+	if err != nil {
+		return nil, err
+	}
+	mailSender, err := gatewayMail.NewMailSender(gatewayMail.MailConfig{
+		Driver: cfg.Mail.Driver,
+		Smtp: gatewayMail.SmtpConfig{
+			Host:     cfg.Mail.Smtp.Host,
+			Port:     strconv.Itoa(cfg.Mail.Smtp.Port),
+			Username: cfg.Mail.Smtp.Username,
+			Password: cfg.Mail.Smtp.Password,
+			From:     cfg.Mail.From,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// リポジトリの検索結果のキャッシュ(コンテナ間で共有し、変更したコンテナによらずキャッシュを削除する)
+	cacheStore, err := gatewayCache.NewStore(gatewayCache.StoreConfig{
+		Driver:   cfg.Cache.Driver,
+		Capacity: cfg.Cache.Capacity,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	organizationContainer, err := organization.NewContainer(ctx, cfg, tokenIssuer, mailSender, cacheStore)
+	if err != nil {
+		return nil, err
+	}
+
+	userContainer, err := user.NewContainer(ctx, cfg, tokenIssuer, mailSender, cacheStore, organizationContainer.InvitationAcceptor)
+	if err != nil {
+		return nil, err
+	}
+
+	auditContainer, err := audit.NewContainer(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// ドメインイベントの購読者の登録
+	eventDispatcher, err := outbox.NewDispatcher(ctx, cfg.Outbox)
+	if err != nil {
+		return nil, err
+	}
+	eventDispatcher.Subscribe(event.UserLoggedInName, outbox.Handle(userContainer.LoginAlertHandler.HandleUserLoggedIn))
+
+	idempotencyStore, err := idempotency.NewStore(ctx, cfg.Idempotency)
 	if err != nil {
 		return nil, err
 	}
 
 	return &Container{
-		UserContainer: userContainer,
+		UserContainer:         userContainer,
+		OrganizationContainer: organizationContainer,
+		AuditContainer:        auditContainer,
+		TokenIssuer:           tokenIssuer,
+		EventDispatcher:       eventDispatcher,
+		IdempotencyStore:      idempotencyStore,
 	}, nil
 }
@@ -0,0 +1,73 @@
+package organization
+
+import (
+	"context"
+
+	"github.com/Go_CleanArch/common/auth"
+	"github.com/Go_CleanArch/infrastructure/config"
+	organizationController "github.com/Go_CleanArch/interface_adapter/controller"
+	gatewayCache "github.com/Go_CleanArch/interface_adapter/gateway/cache"
+	gatewayRepository "github.com/Go_CleanArch/interface_adapter/gateway/repository"
+	query "github.com/Go_CleanArch/usecase/query/service"
+	repository "github.com/Go_CleanArch/usecase/repository_interface"
+	organizationService "github.com/Go_CleanArch/usecase/service/organization"
+)
+
+type OrganizationContainer struct {
+	OrganizationController *organizationController.OrganizationController
+	// リクエストの対象となるテナントの解決に使用する
+	TenantResolver *organizationService.OrganizationService
+	// 招待を伴うサインアップで使用する
+	InvitationAcceptor *organizationService.OrganizationService
+}
+
+func NewContainer(
+	ctx context.Context,
+	cfg *config.Config,
+	tokenIssuer *auth.TokenIssuer,
+	mailSender repository.MailSenderInterface,
+	cacheStore gatewayCache.Store,
+) (*OrganizationContainer, error) {
+	// DI注入
+	organizationRepository, err := gatewayRepository.NewOrganizationRepository(ctx)
+	if err != nil {
+		return nil, err
+	}
+	organizationMembershipRepository, err := gatewayRepository.NewOrganizationMembershipRepository(ctx)
+	if err != nil {
+		return nil, err
+	}
+	organizationInvitationRepository, err := gatewayRepository.NewOrganizationInvitationRepository(ctx)
+	if err != nil {
+		return nil, err
+	}
+	userRepository, err := gatewayRepository.NewUserRepository(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if cacheStore != nil {
+		userRepository = gatewayCache.NewUserRepository(userRepository, cacheStore, cfg.Cache.TTL, cfg.Cache.NegativeTTL)
+	}
+	organizationQuery, err := query.NewOrganizationQuery(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	organizationSvc := organizationService.NewOrganizationService(
+		organizationRepository,
+		organizationMembershipRepository,
+		organizationInvitationRepository,
+		userRepository,
+		organizationQuery,
+		mailSender,
+		tokenIssuer,
+		cfg.App.BaseUrl,
+	)
+	organizationCtrl := organizationController.NewOrganizationController(*organizationSvc)
+
+	return &OrganizationContainer{
+		OrganizationController: organizationCtrl,
+		TenantResolver:         organizationSvc,
+		InvitationAcceptor:     organizationSvc,
+	}, nil
+}
@@ -3,25 +3,147 @@ package user
 import (
 	"context"
 
+	"github.com/Go_CleanArch/common/auth"
+	createUserFactory "github.com/Go_CleanArch/domain/factory/user/create_user"
+	"github.com/Go_CleanArch/infrastructure/config"
+	dbConnect "github.com/Go_CleanArch/infrastructure/db"
 	userController "github.com/Go_CleanArch/interface_adapter/controller"
-	userRepository "github.com/Go_CleanArch/interface_adapter/gateway/repository"
+	gatewayCache "github.com/Go_CleanArch/interface_adapter/gateway/cache"
+	gatewayRepository "github.com/Go_CleanArch/interface_adapter/gateway/repository"
+	gatewayStorage "github.com/Go_CleanArch/interface_adapter/gateway/storage"
+	query "github.com/Go_CleanArch/usecase/query/service"
+	repository "github.com/Go_CleanArch/usecase/repository_interface"
 	userService "github.com/Go_CleanArch/usecase/service/user"
 )
 
 type UserContainer struct {
-	UserController *userController.UserController
+	UserController        *userController.UserController
+	EmailChangeController *userController.EmailChangeController
+	PrivacyController     *userController.PrivacyController
+	ProfileController     *userController.ProfileController
+	SettingController     *userController.SettingController
+	// ドメインイベントの購読者
+	LoginAlertHandler *userService.LoginAlertHandler
 }
 
-func NewContainer(ctx context.Context) (*UserContainer, error) {
+func NewContainer(
+	ctx context.Context,
+	cfg *config.Config,
+	tokenIssuer *auth.TokenIssuer,
+	mailSender repository.MailSenderInterface,
+	cacheStore gatewayCache.Store,
+	invitationAcceptor userService.InvitationAcceptorInterface,
+) (*UserContainer, error) {
 	// DI注入
-	userRepository, err := userRepository.NewUserRepository(ctx)
+	userRepository, err := gatewayRepository.NewUserRepository(ctx)
 	if err != nil {
 		return nil, err
 	}
-	userSvc := userService.NewUserService(userRepository)
+	emailChangeRequestRepository, err := gatewayRepository.NewEmailChangeRequestRepository(ctx)
+	if err != nil {
+		return nil, err
+	}
+	loginHistoryRepository, err := gatewayRepository.NewLoginHistoryRepository(ctx)
+	if err != nil {
+		return nil, err
+	}
+	erasureRequestRepository, err := gatewayRepository.NewErasureRequestRepository(ctx)
+	if err != nil {
+		return nil, err
+	}
+	privacyAuditLogRepository, err := gatewayRepository.NewPrivacyAuditLogRepository(ctx)
+	if err != nil {
+		return nil, err
+	}
+	userSettingRepository, err := gatewayRepository.NewUserSettingRepository(ctx)
+	if err != nil {
+		return nil, err
+	}
+	outboxRepository, err := gatewayRepository.NewOutboxRepository(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// ユーザーの検索結果のキャッシュ(個人データの削除による匿名化もキャッシュを削除する)
+	if cacheStore != nil {
+		userRepository = gatewayCache.NewUserRepository(userRepository, cacheStore, cfg.Cache.TTL, cfg.Cache.NegativeTTL)
+		erasureRequestRepository = gatewayCache.NewErasureRequestRepository(erasureRequestRepository, cacheStore)
+	}
+	transactionManager, err := dbConnect.NewTransactionManager(ctx)
+	if err != nil {
+		return nil, err
+	}
+	userDataExportQuery, err := query.NewUserDataExportQuery(ctx)
+	if err != nil {
+		return nil, err
+	}
+	userProfileQuery, err := query.NewUserProfileQuery(ctx)
+	if err != nil {
+		return nil, err
+	}
+	objectStorage, err := gatewayStorage.NewObjectStorage(gatewayStorage.StorageConfig{
+		Driver:        cfg.Storage.Driver,
+		LocalDir:      cfg.Storage.LocalDir,
+		PublicBaseUrl: cfg.Storage.PublicBaseUrl,
+		S3: gatewayStorage.S3Config{
+			Endpoint:        cfg.Storage.S3.Endpoint,
+			Region:          cfg.Storage.S3.Region,
+			Bucket:          cfg.Storage.S3.Bucket,
+			AccessKeyId:     cfg.Storage.S3.AccessKeyId,
+			SecretAccessKey: cfg.Storage.S3.SecretAccessKey,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	signupPolicy, err := createUserFactory.NewSignupPolicy(cfg.Signup.Mode, cfg.Signup.AllowedEmailDomains)
+	if err != nil {
+		return nil, err
+	}
+
+	userSvc := userService.NewUserService(
+		userRepository,
+		loginHistoryRepository,
+		invitationAcceptor,
+		signupPolicy,
+		tokenIssuer,
+		outboxRepository,
+		transactionManager,
+	)
 	userCtrl := userController.NewUserController(*userSvc)
+	emailChangeSvc := userService.NewEmailChangeService(
+		userRepository,
+		emailChangeRequestRepository,
+		mailSender,
+		userSettingRepository,
+		transactionManager,
+		outboxRepository,
+		cfg.App.BaseUrl,
+	)
+	emailChangeCtrl := userController.NewEmailChangeController(*emailChangeSvc)
+	privacySvc := userService.NewPrivacyService(
+		userRepository,
+		erasureRequestRepository,
+		privacyAuditLogRepository,
+		userDataExportQuery,
+		mailSender,
+		objectStorage,
+		userSettingRepository,
+		cfg.App.BaseUrl,
+	)
+	privacyCtrl := userController.NewPrivacyController(*privacySvc)
+	profileSvc := userService.NewProfileService(userRepository, userProfileQuery, objectStorage)
+	profileCtrl := userController.NewProfileController(*profileSvc)
+	settingSvc := userService.NewSettingService(userSettingRepository)
+	settingCtrl := userController.NewSettingController(*settingSvc)
+	loginAlertHandler := userService.NewLoginAlertHandler(userRepository, userSettingRepository, mailSender)
 
 	return &UserContainer{
-		UserController: userCtrl,
+		UserController:        userCtrl,
+		EmailChangeController: emailChangeCtrl,
+		PrivacyController:     privacyCtrl,
+		ProfileController:     profileCtrl,
+		SettingController:     settingCtrl,
+		LoginAlertHandler:     loginAlertHandler,
 	}, nil
 }
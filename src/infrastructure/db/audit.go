@@ -0,0 +1,392 @@
+package db
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/Go_CleanArch/common/auth"
+	"github.com/Go_CleanArch/common/correlation"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// 監査ログのテーブル
+const AuditLogTable = "audit_log"
+
+// 監査ログの操作の種類
+const (
+	AuditActionCreate = "create"
+	// ON CONFLICT を指定した作成(変更前の値は記録しない)
+	AuditActionUpsert = "upsert"
+	AuditActionUpdate = "update"
+	AuditActionDelete = "delete"
+)
+
+// 監査ログを記録しないテーブル(監査ログ自身・配信の状態を頻繁に更新するアウトボックス・
+// リクエストごとに作成する冪等キー・マイグレーションの履歴)
+var auditExcludedTables = map[string]bool{
+	AuditLogTable:       true,
+	"outbox":            true,
+	"idempotency_keys":  true,
+	"schema_migrations": true,
+}
+
+// 値を伏せる列(パスワード・個人情報)
+// 個人データの削除後も監査ログに個人情報が残らないよう、値は記録せず変更したことのみを記録する。
+var auditMaskedColumns = map[string]bool{
+	"password":      true,
+	"user_name":     true,
+	"email":         true,
+	"email_index":   true,
+	"current_email": true,
+	"new_email":     true,
+	"ip_address":    true,
+	"user_agent":    true,
+}
+
+// 伏せた値
+const auditMaskedValue = "***"
+
+// 更新時の差分に含めない列(監査ログの日時と重複するため)
+var auditIgnoredColumns = map[string]bool{
+	"created_at": true,
+	"updated_at": true,
+}
+
+const auditBeforeKey = "audit:before"
+
+// auditLog は監査ログのレコード
+type auditLog struct {
+	Id         int64 `gorm:"primaryKey;autoIncrement"`
+	OccurredAt time.Time
+	// 操作したユーザー(認証済みのリクエスト以外の操作は nil)
+	ActorId   *string
+	RequestId *string
+	Action    string
+	Table     string `gorm:"column:table_name"`
+	// 主キーの値(複合主キーの場合はカンマ区切り)
+	RecordId string
+	// 列ごとの変更前後の値(JSON)
+	Changes string
+}
+
+func (auditLog) TableName() string {
+	return AuditLogTable
+}
+
+// AuditChange は列の変更前後の値を表します(作成の場合は Before、削除の場合は After が nil)
+type AuditChange struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// AuditPlugin はモデルを指定した作成・更新・削除を、変更と同じトランザクションで監査ログに記録する。
+// 操作したユーザー・リクエストIDはコンテキストから取得する。
+// 監査ログを記録できない場合は変更もロールバックする。
+// Raw / Exec で記述したSQL、モデルを指定しない(Table のみを指定した)変更は記録しない。
+type AuditPlugin struct{}
+
+func (AuditPlugin) Name() string {
+	return "audit"
+}
+
+func (AuditPlugin) Initialize(db *gorm.DB) error {
+	callback := db.Callback()
+	// 変更前の値はテナントによる絞り込みを付与した後の条件で取得する
+	registrations := []error{
+		callback.Create().After("gorm:create").Before("gorm:commit_or_rollback_transaction").Register("audit:create", auditCreate),
+		callback.Update().After("tenant_scope:update").Before("gorm:update").Register("audit:update_before", auditCaptureBefore),
+		callback.Update().After("gorm:update").Before("gorm:commit_or_rollback_transaction").Register("audit:update", auditUpdate),
+		callback.Delete().After("tenant_scope:delete").Before("gorm:delete").Register("audit:delete_before", auditCaptureBefore),
+		callback.Delete().After("gorm:delete").Before("gorm:commit_or_rollback_transaction").Register("audit:delete", auditDelete),
+	}
+	for _, err := range registrations {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 監査ログを記録する変更か
+func auditTarget(db *gorm.DB) bool {
+	stmt := db.Statement
+	return db.Error == nil && stmt.Schema != nil && !auditExcludedTables[stmt.Table]
+}
+
+func auditCreate(db *gorm.DB) {
+	if !auditTarget(db) || db.RowsAffected == 0 {
+		return
+	}
+	stmt := db.Statement
+	action := AuditActionCreate
+	if _, ok := stmt.Clauses["ON CONFLICT"]; ok {
+		action = AuditActionUpsert
+	}
+
+	rows := []map[string]interface{}{}
+	switch stmt.ReflectValue.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < stmt.ReflectValue.Len(); i++ {
+			rows = append(rows, fieldValues(stmt, reflect.Indirect(stmt.ReflectValue.Index(i))))
+		}
+	case reflect.Struct:
+		rows = append(rows, fieldValues(stmt, stmt.ReflectValue))
+	}
+
+	entries := make([]auditLog, 0, len(rows))
+	for _, row := range rows {
+		entries = append(entries, newAuditLog(db, action, recordId(stmt.Schema, row), diff(nil, row)))
+	}
+	writeAuditLogs(db, entries)
+}
+
+// auditCaptureBefore は更新・削除の対象となる行の変更前の値を取得する
+func auditCaptureBefore(db *gorm.DB) {
+	if !auditTarget(db) {
+		return
+	}
+	conditions := auditConditions(db)
+	// 条件の無い更新・削除は gorm がエラーとする
+	if len(conditions) == 0 && !db.AllowGlobalUpdate {
+		return
+	}
+	rows, err := auditSelect(db, conditions)
+	if err != nil {
+		db.AddError(fmt.Errorf("監査ログの変更前の値を取得できませんでした: %w", err))
+		return
+	}
+	db.InstanceSet(auditBeforeKey, rows)
+}
+
+func auditUpdate(db *gorm.DB) {
+	before := capturedRows(db)
+	if !auditTarget(db) || len(before) == 0 {
+		return
+	}
+	stmt := db.Statement
+	after, err := auditSelect(db, []clause.Expression{primaryKeysIn(stmt.Schema, before)})
+	if err != nil {
+		db.AddError(fmt.Errorf("監査ログの変更後の値を取得できませんでした: %w", err))
+		return
+	}
+	afterById := make(map[string]map[string]interface{}, len(after))
+	for _, row := range after {
+		afterById[recordId(stmt.Schema, row)] = row
+	}
+
+	entries := []auditLog{}
+	for _, row := range before {
+		id := recordId(stmt.Schema, row)
+		changes := diff(row, afterById[id])
+		// 値を変更していない更新は記録しない
+		if len(changes) == 0 {
+			continue
+		}
+		entries = append(entries, newAuditLog(db, AuditActionUpdate, id, changes))
+	}
+	writeAuditLogs(db, entries)
+}
+
+func auditDelete(db *gorm.DB) {
+	before := capturedRows(db)
+	if !auditTarget(db) || len(before) == 0 {
+		return
+	}
+	entries := make([]auditLog, 0, len(before))
+	for _, row := range before {
+		entries = append(entries, newAuditLog(db, AuditActionDelete, recordId(db.Statement.Schema, row), diff(row, nil)))
+	}
+	writeAuditLogs(db, entries)
+}
+
+func capturedRows(db *gorm.DB) []map[string]interface{} {
+	value, ok := db.InstanceGet(auditBeforeKey)
+	if !ok {
+		return nil
+	}
+	return value.([]map[string]interface{})
+}
+
+// auditConditions は更新・削除の対象を絞り込む条件を返す
+// Save・Delete(&value) のように主キーを指定したモデルは、gorm が後から主キーを条件に加えるため、ここで加える。
+func auditConditions(db *gorm.DB) []clause.Expression {
+	stmt := db.Statement
+	conditions := []clause.Expression{}
+	if where, ok := stmt.Clauses["WHERE"].Expression.(clause.Where); ok {
+		conditions = append(conditions, where.Exprs...)
+	}
+	if stmt.ReflectValue.Kind() == reflect.Struct {
+		for _, field := range stmt.Schema.PrimaryFields {
+			if value, zero := field.ValueOf(stmt.Context, stmt.ReflectValue); !zero {
+				conditions = append(conditions, clause.Eq{Column: clause.Column{Table: stmt.Table, Name: field.DBName}, Value: value})
+			}
+		}
+	}
+	return conditions
+}
+
+// auditSelect は変更と同じトランザクションで、条件に一致する行を列名と値の組として取得する
+func auditSelect(db *gorm.DB, conditions []clause.Expression) ([]map[string]interface{}, error) {
+	rows := []map[string]interface{}{}
+	tx := db.Session(&gorm.Session{NewDB: true, Context: db.Statement.Context}).Table(db.Statement.Table)
+	if len(conditions) > 0 {
+		tx = tx.Where(clause.And(conditions...))
+	}
+	if err := tx.Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// primaryKeysIn は行の主キーのいずれかに一致する条件を返す
+func primaryKeysIn(s *schema.Schema, rows []map[string]interface{}) clause.Expression {
+	keys := make([]clause.Expression, 0, len(rows))
+	for _, row := range rows {
+		columns := make([]clause.Expression, 0, len(s.PrimaryFields))
+		for _, field := range s.PrimaryFields {
+			columns = append(columns, clause.Eq{Column: clause.Column{Name: field.DBName}, Value: row[field.DBName]})
+		}
+		keys = append(keys, clause.And(columns...))
+	}
+	return clause.Or(keys...)
+}
+
+// fieldValues は作成したモデルの値を列名と値の組として返す
+func fieldValues(stmt *gorm.Statement, value reflect.Value) map[string]interface{} {
+	row := map[string]interface{}{}
+	for _, field := range stmt.Schema.Fields {
+		if field.DBName == "" {
+			continue
+		}
+		fieldValue, zero := field.ValueOf(stmt.Context, value)
+		if zero && field.HasDefaultValue {
+			continue
+		}
+		// serializer を指定した列は、データベースに保存した値を記録する
+		if valuer, ok := fieldValue.(driver.Valuer); ok && field.Serializer != nil {
+			serialized, err := valuer.Value()
+			if err != nil {
+				continue
+			}
+			fieldValue = serialized
+		}
+		row[field.DBName] = fieldValue
+	}
+	return row
+}
+
+// recordId は主キーの値を返す(複合主キーの場合はカンマ区切り)
+func recordId(s *schema.Schema, row map[string]interface{}) string {
+	values := make([]string, 0, len(s.PrimaryFields))
+	for _, field := range s.PrimaryFields {
+		values = append(values, fmt.Sprint(normalize(row[field.DBName])))
+	}
+	return strings.Join(values, ",")
+}
+
+// diff は列ごとの変更前後の値を返す(before が nil の場合は作成、after が nil の場合は削除)
+func diff(before map[string]interface{}, after map[string]interface{}) map[string]AuditChange {
+	columns := map[string]bool{}
+	for column := range before {
+		columns[column] = true
+	}
+	for column := range after {
+		columns[column] = true
+	}
+
+	changes := map[string]AuditChange{}
+	for column := range columns {
+		b, a := normalize(before[column]), normalize(after[column])
+		if before != nil && after != nil && (auditIgnoredColumns[column] || equal(b, a)) {
+			continue
+		}
+		if b == nil && a == nil {
+			continue
+		}
+		if auditMaskedColumns[column] {
+			b, a = mask(b), mask(a)
+		}
+		changes[column] = AuditChange{Before: b, After: a}
+	}
+	return changes
+}
+
+// normalize はデータベースから取得した値と、モデルの値を比較・記録できる形に揃える
+// 暗号化した値は暗号化のたびに変わるため、復号した値で比較する
+func normalize(value interface{}) interface{} {
+	switch v := value.(type) {
+	case []byte:
+		return normalize(string(v))
+	case string:
+		if plaintext, err := DecryptValue(v); err == nil {
+			return plaintext
+		}
+		return v
+	case nil:
+		return nil
+	}
+	rv := reflect.ValueOf(value)
+	if rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil
+		}
+		return normalize(rv.Elem().Interface())
+	}
+	return value
+}
+
+func equal(a interface{}, b interface{}) bool {
+	if ta, ok := a.(time.Time); ok {
+		tb, ok := b.(time.Time)
+		return ok && ta.Equal(tb)
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func mask(value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+	return auditMaskedValue
+}
+
+func newAuditLog(db *gorm.DB, action string, id string, changes map[string]AuditChange) auditLog {
+	ctx := db.Statement.Context
+	entry := auditLog{
+		// 日時の範囲で検索するため UTC で記録する
+		OccurredAt: time.Now().UTC(),
+		Action:     action,
+		Table:      db.Statement.Table,
+		RecordId:   id,
+	}
+	if userId, ok := auth.UserIdFromContext(ctx); ok {
+		entry.ActorId = &userId
+	}
+	if requestId, ok := correlation.RequestIdFromContext(ctx); ok {
+		entry.RequestId = &requestId
+	}
+	// 列名の順に並べてエンコードされる
+	encoded, err := json.Marshal(changes)
+	if err != nil {
+		db.AddError(fmt.Errorf("監査ログの変更内容をエンコードできませんでした: %w", err))
+	}
+	entry.Changes = string(encoded)
+	return entry
+}
+
+// writeAuditLogs は変更と同じトランザクションで監査ログを記録する
+func writeAuditLogs(db *gorm.DB, entries []auditLog) {
+	if len(entries) == 0 || db.Error != nil {
+		return
+	}
+	tx := db.Session(&gorm.Session{NewDB: true, SkipDefaultTransaction: true, Context: db.Statement.Context})
+	if err := tx.Create(&entries).Error; err != nil {
+		db.AddError(fmt.Errorf("監査ログを記録できませんでした: %w", err))
+	}
+}
@@ -0,0 +1,169 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Go_CleanArch/common/auth"
+	"github.com/Go_CleanArch/common/correlation"
+	"github.com/Go_CleanArch/infrastructure/config"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type auditedUser struct {
+	UserId    string `gorm:"primaryKey"`
+	UserName  string
+	Password  string
+	Email     string
+	AvatarKey *string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (auditedUser) TableName() string {
+	return "users"
+}
+
+// newAuditTestDB はスキーマとプラグインを設定したインメモリデータベースを返す
+func newAuditTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	gormDB, err := openSqlite(config.DatabaseConfig{
+		Driver:     DriverSqlite,
+		SqlitePath: sqliteMemoryPath,
+		QueryLog:   config.QueryLogConfig{Level: "silent"},
+	})
+	assert.NoError(t, err)
+	return gormDB
+}
+
+func auditLogsOf(t *testing.T, gormDB *gorm.DB, recordId string) []auditLog {
+	t.Helper()
+	var logs []auditLog
+	assert.NoError(t, gormDB.Where("record_id = ?", recordId).Order("id").Find(&logs).Error)
+	return logs
+}
+
+func changesOf(t *testing.T, entry auditLog) map[string]AuditChange {
+	t.Helper()
+	changes := map[string]AuditChange{}
+	assert.NoError(t, json.Unmarshal([]byte(entry.Changes), &changes))
+	return changes
+}
+
+func TestAuditPlugin(t *testing.T) {
+	t.Parallel()
+
+	requestCtx := auth.WithUserId(correlation.WithRequestId(context.Background(), "request-1"), "admin-1")
+
+	t.Run("正常系_作成・更新・削除を操作したユーザーとリクエストIDとともに記録する", func(t *testing.T) {
+		t.Parallel()
+		gormDB := newAuditTestDB(t).WithContext(requestCtx)
+
+		user := auditedUser{UserId: "user-1", UserName: "山田", Password: "hashed", Email: "yamada@example.com"}
+		assert.NoError(t, gormDB.Create(&user).Error)
+		avatarKey := "avatars/user-1.png"
+		user.AvatarKey = &avatarKey
+		assert.NoError(t, gormDB.Save(&user).Error)
+		assert.NoError(t, gormDB.Delete(&auditedUser{}, "user_id = ?", "user-1").Error)
+
+		logs := auditLogsOf(t, gormDB, "user-1")
+		assert.Len(t, logs, 3)
+		for i, action := range []string{AuditActionCreate, AuditActionUpdate, AuditActionDelete} {
+			assert.Equal(t, action, logs[i].Action)
+			assert.Equal(t, "users", logs[i].Table)
+			assert.Equal(t, "admin-1", *logs[i].ActorId)
+			assert.Equal(t, "request-1", *logs[i].RequestId)
+		}
+
+		created := changesOf(t, logs[0])
+		assert.Equal(t, AuditChange{Before: nil, After: "user-1"}, created["user_id"])
+		// 更新は変更した列のみを記録する
+		assert.Equal(t, map[string]AuditChange{"avatar_key": {Before: nil, After: avatarKey}}, changesOf(t, logs[1]))
+		deleted := changesOf(t, logs[2])
+		assert.Equal(t, AuditChange{Before: avatarKey, After: nil}, deleted["avatar_key"])
+	})
+
+	t.Run("正常系_パスワード・個人情報の値は伏せて変更したことのみを記録する", func(t *testing.T) {
+		t.Parallel()
+		gormDB := newAuditTestDB(t).WithContext(requestCtx)
+
+		assert.NoError(t, gormDB.Create(&auditedUser{UserId: "user-1", UserName: "山田", Password: "hashed", Email: "yamada@example.com"}).Error)
+		assert.NoError(t, gormDB.Model(&auditedUser{}).Where("user_id = ?", "user-1").
+			Updates(map[string]interface{}{"password": "rehashed", "email": "new@example.com"}).Error)
+
+		logs := auditLogsOf(t, gormDB, "user-1")
+		assert.Len(t, logs, 2)
+		created := changesOf(t, logs[0])
+		assert.Equal(t, AuditChange{Before: nil, After: auditMaskedValue}, created["password"])
+		assert.Equal(t, AuditChange{Before: nil, After: auditMaskedValue}, created["user_name"])
+		assert.Equal(t, map[string]AuditChange{
+			"password": {Before: auditMaskedValue, After: auditMaskedValue},
+			"email":    {Before: auditMaskedValue, After: auditMaskedValue},
+		}, changesOf(t, logs[1]))
+		assert.NotContains(t, logs[0].Changes+logs[1].Changes, "example.com")
+		assert.NotContains(t, logs[0].Changes+logs[1].Changes, "hashed")
+	})
+
+	t.Run("正常系_値を変更しない更新・認証されていない操作", func(t *testing.T) {
+		t.Parallel()
+		gormDB := newAuditTestDB(t)
+
+		user := auditedUser{UserId: "user-1", UserName: "山田", Password: "hashed", Email: "yamada@example.com"}
+		assert.NoError(t, gormDB.Create(&user).Error)
+		assert.NoError(t, gormDB.Save(&user).Error)
+
+		logs := auditLogsOf(t, gormDB, "user-1")
+		assert.Len(t, logs, 1)
+		assert.Nil(t, logs[0].ActorId)
+		assert.Nil(t, logs[0].RequestId)
+	})
+
+	t.Run("正常系_条件に一致する複数の行の更新は行ごとに記録する", func(t *testing.T) {
+		t.Parallel()
+		gormDB := newAuditTestDB(t).WithContext(requestCtx)
+
+		assert.NoError(t, gormDB.Create(&[]auditedUser{
+			{UserId: "user-1", UserName: "山田", Password: "hashed", Email: "yamada@example.com"},
+			{UserId: "user-2", UserName: "田中", Password: "hashed", Email: "tanaka@example.com"},
+		}).Error)
+		avatarKey := "avatars/default.png"
+		assert.NoError(t, gormDB.Model(&auditedUser{}).Where("user_id IN ?", []string{"user-1", "user-2"}).
+			Update("avatar_key", avatarKey).Error)
+
+		for _, userId := range []string{"user-1", "user-2"} {
+			logs := auditLogsOf(t, gormDB, userId)
+			assert.Len(t, logs, 2)
+			assert.Equal(t, AuditActionUpdate, logs[1].Action)
+			assert.Equal(t, map[string]AuditChange{"avatar_key": {Before: nil, After: avatarKey}}, changesOf(t, logs[1]))
+		}
+	})
+
+	t.Run("正常系_ロールバックした変更は記録しない", func(t *testing.T) {
+		t.Parallel()
+		gormDB := newAuditTestDB(t).WithContext(requestCtx)
+
+		errRollback := errors.New("rollback")
+		err := gormDB.Transaction(func(tx *gorm.DB) error {
+			assert.NoError(t, tx.Create(&auditedUser{UserId: "user-1", UserName: "山田", Password: "hashed", Email: "yamada@example.com"}).Error)
+			return errRollback
+		})
+		assert.ErrorIs(t, err, errRollback)
+		assert.Empty(t, auditLogsOf(t, gormDB, "user-1"))
+	})
+
+	t.Run("異常系_監査ログを記録できない場合は変更もロールバックする", func(t *testing.T) {
+		t.Parallel()
+		gormDB := newAuditTestDB(t).WithContext(requestCtx)
+		assert.NoError(t, gormDB.Exec("DROP TABLE audit_log").Error)
+
+		err := gormDB.Create(&auditedUser{UserId: "user-1", UserName: "山田", Password: "hashed", Email: "yamada@example.com"}).Error
+		assert.ErrorContains(t, err, "監査ログを記録できませんでした")
+		var count int64
+		assert.NoError(t, gormDB.Model(&auditedUser{}).Count(&count).Error)
+		assert.Zero(t, count)
+	})
+}
@@ -4,75 +4,195 @@ import (
 	"context"
 	"fmt"
 	"log"
-	"os"
 	"sync/atomic"
 	"time"
 
+	"github.com/Go_CleanArch/infrastructure/config"
+
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
 )
 
+// DBConnection はリポジトリ・クエリサービスが使用するデータベース操作を提供します
+// db が指定されていない場合は共有の接続を使用し、ヘルスチェックで接続が差し替えられた後も最新の接続を参照します
 type DBConnection struct {
 	db  *gorm.DB
 	ctx context.Context
+	// 読み取り(Find / FindAll / FindWithRawJoinQuery)をレプリカで行う
+	readFromReplica bool
+	// 実行したクエリをログに出力しない(エラー・スロークエリは出力します)
+	withoutQueryLog bool
 }
 
-func NewDBConnection(ctx context.Context) (*DBConnection, error) {
-	// データベース接続を取得
-	dbInstance, err := getDB(ctx)
-	if err != nil {
+// ConnectionOption は DBConnection の振る舞いを指定します
+type ConnectionOption func(*DBConnection)
+
+// ReadFromReplica は読み取りをレプリカへ振り分けます
+// トランザクション中・WithPrimary を指定したコンテキスト・正常なレプリカが無い場合はプライマリで読み取ります
+func ReadFromReplica() ConnectionOption {
+	return func(dbConnect *DBConnection) {
+		dbConnect.readFromReplica = true
+	}
+}
+
+// WithoutQueryLog は実行したクエリのログ出力を抑止します
+// 定期的に実行するポーリングなど、クエリのログが大量に出力される処理に使用します
+func WithoutQueryLog() ConnectionOption {
+	return func(dbConnect *DBConnection) {
+		dbConnect.withoutQueryLog = true
+	}
+}
+
+func NewDBConnection(ctx context.Context, options ...ConnectionOption) (*DBConnection, error) {
+	// データベース接続が確立されていることを確認
+	if _, err := getDB(ctx); err != nil {
 		return nil, err
-	} else {
-		dbConnect := &DBConnection{
-			db:  dbInstance,
-			ctx: ctx,
-		}
-		return dbConnect, nil
 	}
+	dbConnect := &DBConnection{ctx: ctx}
+	for _, option := range options {
+		option(dbConnect)
+	}
+	return dbConnect, nil
 }
 
 type contextKey string
-type TransactionFunc func(tx *gorm.DB) error
+type transactionFunc func(tx *gorm.DB) error
 
 const CancelKey = "cancel"
 const dbKey contextKey = "transactionDB"
 
 var (
-	connection atomic.Value // *DBConnection
+	connection atomic.Value // *sharedConnection
 )
 
-func Init() {
+// sharedConnection はアプリケーション全体で共有する接続
+// 接続が差し替えられた場合は ctx がキャンセルされます
+type sharedConnection struct {
+	db     *gorm.DB
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func Init(dbConfig config.DatabaseConfig) {
+	// 個人情報の列の暗号化に使用する鍵を設定します
+	if err := configureEncryption(dbConfig.Encryption); err != nil {
+		log.Fatalf("暗号化の鍵の設定エラー: %v", err)
+	}
+
 	// プログラム起動時に最初のDB接続を確立します
-	err := establishConnection()
+	err := establishConnection(dbConfig)
+	if err != nil {
+		log.Fatalf("データベース接続の初期化エラー: %v", err)
+	}
+
+	// レプリカへの接続を開きます(接続できない場合は死活監視で再試行します)
+	replicaConfigs, err := dbConfig.ReplicaConfigs()
 	if err != nil {
 		log.Fatalf("データベース接続の初期化エラー: %v", err)
 	}
+	establishReplicaConnections(replicaConfigs, Open)
+
+	// 接続の死活監視を開始します
+	startHealthProber(context.Background(), dbConfig)
 }
 
-// establishConnection はデータベースの接続を設定します
-func establishConnection() error {
-	dsn := fmt.Sprintf("host=db port=5432 user=%s dbname=%s password=%s sslmode=disable",
-		os.Getenv("DB_USER"),
-		os.Getenv("DB_NAME"),
-		os.Getenv("DB_PASSWORD"))
+// Close は死活監視を停止し、共有の接続・レプリカへの接続を閉じます
+// 終了時に、接続を使用する処理(リクエストの処理・バックグラウンド処理)をすべて停止してから呼び出します
+func Close() {
+	if p := prober.Swap(nil); p != nil {
+		p.stop()
+	}
+	if set := replicaConnections.Swap(nil); set != nil {
+		for _, r := range set.replicas {
+			if replicaDb := r.db.Swap(nil); replicaDb != nil {
+				closeConnection(replicaDb)
+			}
+		}
+	}
+	if conn, ok := connection.Load().(*sharedConnection); ok {
+		conn.cancel()
+		closeConnection(conn.db)
+	}
+}
 
-	// 新しいデータベース接続を開きます
-	newDb, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Info),
+// Open は設定をもとに新しいデータベース接続を開きます
+// アプリケーション全体で共有する接続は Init で確立するため、マイグレーションなど単独で接続する場合に使用します
+func Open(dbConfig config.DatabaseConfig) (*gorm.DB, error) {
+	if dbConfig.Driver == DriverSqlite {
+		return openSqlite(dbConfig)
+	}
+
+	newDb, err := gorm.Open(postgres.Open(dbConfig.Dsn()), &gorm.Config{
+		Logger: newQueryLogger(dbConfig.QueryLog),
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	// コネクションプールの上限・接続の再利用期間を設定します
+	sqlDB, err := newDb.DB()
+	if err != nil {
+		return nil, err
+	}
+	sqlDB.SetMaxOpenConns(dbConfig.Pool.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(dbConfig.Pool.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(dbConfig.Pool.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(dbConfig.Pool.ConnMaxIdleTime)
+
+	if err := usePlugins(newDb, dbConfig); err != nil {
+		return nil, err
+	}
+	return newDb, nil
+}
+
+// usePlugins は接続に共通のプラグインを登録します
+func usePlugins(newDb *gorm.DB, dbConfig config.DatabaseConfig) error {
+	// テナント単位のテーブルへのアクセスに組織による絞り込みを付与します
+	if err := newDb.Use(TenantScopePlugin{}); err != nil {
+		return err
+	}
+	// モデルを指定した作成・更新・削除を監査ログに記録します
+	if err := newDb.Use(AuditPlugin{}); err != nil {
+		return err
+	}
+	// 期限の無いコンテキストで実行したSQLに実行時間の上限を設定します
+	return newDb.Use(QueryTimeoutPlugin{Timeout: dbConfig.QueryTimeout})
+}
 
+// establishConnection はデータベースの接続を設定します
+func establishConnection(dbConfig config.DatabaseConfig) error {
+	// 新しいデータベース接続を開きます
+	newDb, err := Open(dbConfig)
 	if err != nil {
 		return err
 	}
 
 	// データベース接続を保存します
-	conn := &DBConnection{
-		db:  newDb,
-		ctx: context.Background(),
+	storeConnection(newDb)
+
+	return nil
+}
+
+// storeConnection は共有する接続を差し替え、差し替え前の接続を返します
+// 差し替え前の接続のコンテキストはキャンセルされます
+func storeConnection(newDb *gorm.DB) *sharedConnection {
+	ctx, cancel := context.WithCancel(context.Background())
+	previous, _ := connection.Swap(&sharedConnection{
+		db:     newDb,
+		ctx:    ctx,
+		cancel: cancel,
+	}).(*sharedConnection)
+	if previous != nil {
+		previous.cancel()
 	}
-	connection.Store(conn)
+	return previous
+}
 
+// currentDB は共有の接続を返します(接続が確立されていない場合は nil)
+func currentDB() *gorm.DB {
+	if conn, ok := connection.Load().(*sharedConnection); ok {
+		return conn.db
+	}
 	return nil
 }
 
@@ -96,7 +216,7 @@ func getDB(ctx context.Context) (*gorm.DB, error) {
 	for i := 0; i < maxRetries; i++ {
 		connValue := connection.Load()
 		if connValue != nil {
-			conn := connValue.(*DBConnection)
+			conn := connValue.(*sharedConnection)
 
 			select {
 			case <-conn.ctx.Done():
@@ -129,17 +249,41 @@ func getDB(ctx context.Context) (*gorm.DB, error) {
 	return nil, fmt.Errorf("getDB: データベース接続の取得に失敗しました（リトライ回数: %d）", maxRetries)
 }
 
+// conn はコンテキストにトランザクションがある場合はそれを、無い場合は接続を返します
+func (dbConnect DBConnection) conn(ctx context.Context) *gorm.DB {
+	return dbConnect.session(fromContext(ctx, orShared(dbConnect.db)))
+}
+
+// session は接続にログ出力の設定を適用します
+func (dbConnect DBConnection) session(db *gorm.DB) *gorm.DB {
+	if !dbConnect.withoutQueryLog || db == nil {
+		return db
+	}
+	return db.Session(&gorm.Session{Logger: withoutStatements(db.Logger)})
+}
+
+// readConn は読み取りに使用する接続を返します
+func (dbConnect DBConnection) readConn(ctx context.Context) *gorm.DB {
+	if dbConnect.readFromReplica && dbConnect.db == nil {
+		if replicaDb := readReplica(ctx); replicaDb != nil {
+			return dbConnect.session(replicaDb)
+		}
+	}
+	return dbConnect.conn(ctx)
+}
+
 func (dbConnect DBConnection) Find(ctx context.Context, query string, args []interface{}, out interface{}) error {
 
-	if err := dbConnect.db.WithContext(ctx).Where(query, args...).First(out).Error; err != nil {
-		return err
+	if err := dbConnect.readConn(ctx).WithContext(ctx).Where(query, args...).First(out).Error; err != nil {
+		// レコードが見つからない場合は repository.ErrNotFound として判定できるようにする
+		return notFound(err)
 	}
 
 	return nil
 }
 
 func (dbConnect DBConnection) FindAll(ctx context.Context, query string, args []interface{}, out interface{}) error {
-	if err := dbConnect.db.WithContext(ctx).Where(query, args...).Find(out).Error; err != nil {
+	if err := dbConnect.readConn(ctx).WithContext(ctx).Where(query, args...).Find(out).Error; err != nil {
 		return err
 	}
 
@@ -147,7 +291,7 @@ func (dbConnect DBConnection) FindAll(ctx context.Context, query string, args []
 }
 
 func (dbConnect DBConnection) FindWithRawJoinQuery(ctx context.Context, sqlQuery string, out interface{}, params ...interface{}) error {
-	if err := dbConnect.db.Raw(sqlQuery, params...).Find(out).Error; err != nil {
+	if err := dbConnect.readConn(ctx).WithContext(ctx).Raw(sqlQuery, params...).Find(out).Error; err != nil {
 		return err
 	}
 
@@ -156,7 +300,7 @@ func (dbConnect DBConnection) FindWithRawJoinQuery(ctx context.Context, sqlQuery
 
 func (dbConnect DBConnection) Create(ctx context.Context, value interface{}) error {
 	// データベースにレコードを作成
-	if err := dbConnect.db.WithContext(ctx).Create(value).Error; err != nil {
+	if err := dbConnect.conn(ctx).WithContext(ctx).Create(value).Error; err != nil {
 		return fmt.Errorf("データベースにレコードを作成できませんでした: %w", err)
 	}
 
@@ -165,37 +309,29 @@ func (dbConnect DBConnection) Create(ctx context.Context, value interface{}) err
 
 func (dbConnect DBConnection) Delete(ctx context.Context, query string, args []interface{}, out interface{}) error {
 	// データベース接続を取得
-	if err := dbConnect.db.WithContext(ctx).Where(query, args...).Delete(out).Error; err != nil {
+	if err := dbConnect.conn(ctx).WithContext(ctx).Where(query, args...).Delete(out).Error; err != nil {
 		return fmt.Errorf("データベースのレコードを削除できませんでした: %w", err)
 	}
 
 	return nil
 }
 
-func (dbConnect DBConnection) WithTransaction(ctx context.Context, fn TransactionFunc) error {
-
-	tx := dbConnect.db.WithContext(ctx).Begin()
-	if tx.Error != nil {
-		return fmt.Errorf("トランザクションの開始に失敗: %w", tx.Error)
-	}
-
-	if err := fn(tx); err != nil { // この行を元に戻します
-		tx.Rollback()
-		return fmt.Errorf("トランザクション中のエラー: %w", err)
-	}
-
-	if err := tx.Commit().Error; err != nil {
-		return fmt.Errorf("トランザクションのコミットに失敗: %w", err)
+func (dbConnect DBConnection) Update(ctx context.Context, value interface{}) error {
+	// レコード更新
+	if err := dbConnect.conn(ctx).WithContext(ctx).Save(value).Error; err != nil {
+		return fmt.Errorf("データベースのレコードを更新できませんでした: %w", err)
 	}
 
 	return nil
 }
 
-func (dbConnect DBConnection) Update(ctx context.Context, value interface{}) error {
-	// レコード更新
-	if err := dbConnect.db.WithContext(ctx).Save(value).Error; err != nil {
-		return fmt.Errorf("データベースのレコードを更新できませんでした: %w", err)
+// Exec は SQL を実行し、更新した件数を返します
+// モデルを指定しないため、テナントによる絞り込み・監査ログの記録は行いません
+func (dbConnect DBConnection) Exec(ctx context.Context, sql string, args ...interface{}) (int64, error) {
+	result := dbConnect.conn(ctx).WithContext(ctx).Exec(sql, args...)
+	if result.Error != nil {
+		return 0, fmt.Errorf("SQLを実行できませんでした: %w", result.Error)
 	}
 
-	return nil
+	return result.RowsAffected, nil
 }
@@ -0,0 +1,331 @@
+package db
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync/atomic"
+
+	"github.com/Go_CleanArch/domain/entity"
+	"github.com/Go_CleanArch/infrastructure/config"
+	"gorm.io/gorm/schema"
+)
+
+// 暗号化した値の接頭辞(暗号化する前に保存した値と区別する)
+// 値は enc:v1:<鍵ID>:<鍵で暗号化したデータ鍵>:<データ鍵で暗号化した値> の形式で保存する(いずれも base64)。
+const encryptedValuePrefix = "enc:v1:"
+
+// データ鍵の長さ(AES-256)
+const dataKeySize = 32
+
+var ErrEncryptionNotConfigured = errors.New("個人情報の暗号化の鍵が設定されていません")
+
+// FieldCipher は列の値をエンベロープ暗号化(値ごとに生成したデータ鍵で暗号化し、データ鍵を設定の鍵で暗号化する)します
+// 暗号化した値には鍵IDを含めるため、鍵をローテーションした後も以前の鍵で暗号化した値を復号できます
+type FieldCipher struct {
+	keys          map[string]cipher.AEAD
+	activeKeyId   string
+	blindIndexKey []byte
+}
+
+func NewFieldCipher(encryptionConfig config.EncryptionConfig) (*FieldCipher, error) {
+	keys, err := encryptionConfig.ParsedKeys()
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := keys[encryptionConfig.ActiveKeyId]; !ok {
+		return nil, fmt.Errorf("暗号化に使用する鍵ID %q の鍵が設定されていません", encryptionConfig.ActiveKeyId)
+	}
+	blindIndexKey, err := encryptionConfig.ParsedBlindIndexKey()
+	if err != nil {
+		return nil, fmt.Errorf("ブラインドインデックスの鍵が不正です: %w", err)
+	}
+
+	newCipher := &FieldCipher{
+		keys:          make(map[string]cipher.AEAD, len(keys)),
+		activeKeyId:   encryptionConfig.ActiveKeyId,
+		blindIndexKey: blindIndexKey,
+	}
+	for keyId, key := range keys {
+		aead, err := newAead(key)
+		if err != nil {
+			return nil, err
+		}
+		newCipher.keys[keyId] = aead
+	}
+	return newCipher, nil
+}
+
+// ActiveKeyId は暗号化に使用する鍵ID
+func (fc *FieldCipher) ActiveKeyId() string {
+	return fc.activeKeyId
+}
+
+// Encrypt は値を暗号化します
+func (fc *FieldCipher) Encrypt(plaintext string) (string, error) {
+	dataKey := make([]byte, dataKeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", err
+	}
+	dataAead, err := newAead(dataKey)
+	if err != nil {
+		return "", err
+	}
+	// データ鍵は鍵IDと結び付けて暗号化する(鍵IDを書き換えた値は復号できない)
+	wrappedKey, err := seal(fc.keys[fc.activeKeyId], dataKey, []byte(fc.activeKeyId))
+	if err != nil {
+		return "", err
+	}
+	ciphertext, err := seal(dataAead, []byte(plaintext), nil)
+	if err != nil {
+		return "", err
+	}
+	return encryptedValuePrefix + strings.Join([]string{
+		fc.activeKeyId,
+		base64.RawStdEncoding.EncodeToString(wrappedKey),
+		base64.RawStdEncoding.EncodeToString(ciphertext),
+	}, ":"), nil
+}
+
+// Decrypt は暗号化した値を復号します
+// 暗号化する前に保存した値(reencrypt で暗号化していない値)はそのまま返します
+func (fc *FieldCipher) Decrypt(value string) (string, error) {
+	keyId, wrappedKey, ciphertext, ok, err := parseEncryptedValue(value)
+	if err != nil || !ok {
+		return value, err
+	}
+	aead, ok := fc.keys[keyId]
+	if !ok {
+		return "", fmt.Errorf("鍵ID %q の鍵が設定されていないため復号できません", keyId)
+	}
+	dataKey, err := open(aead, wrappedKey, []byte(keyId))
+	if err != nil {
+		return "", fmt.Errorf("データ鍵を復号できません(鍵ID: %s): %w", keyId, err)
+	}
+	dataAead, err := newAead(dataKey)
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := open(dataAead, ciphertext, nil)
+	if err != nil {
+		return "", fmt.Errorf("値を復号できません(鍵ID: %s): %w", keyId, err)
+	}
+	return string(plaintext), nil
+}
+
+// NeedsReencryption は値が暗号化されていない、または暗号化に使用する鍵以外で暗号化されているか
+func (fc *FieldCipher) NeedsReencryption(value string) bool {
+	keyId, ok := EncryptionKeyId(value)
+	return !ok || keyId != fc.activeKeyId
+}
+
+// BlindIndex は値の完全一致で検索するためのブラインドインデックス(HMAC-SHA256)を返します
+// 呼び出し元が正規化していない値でも一致させるため、entity.NormalizeEmail で正規化(前後の空白の除去・小文字化)してから計算します
+func (fc *FieldCipher) BlindIndex(value string) string {
+	mac := hmac.New(sha256.New, fc.blindIndexKey)
+	mac.Write([]byte(entity.NormalizeEmail(value)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// EncryptionKeyId は暗号化した値の鍵IDを返します(暗号化されていない値の場合は false)
+func EncryptionKeyId(value string) (string, bool) {
+	if !strings.HasPrefix(value, encryptedValuePrefix) {
+		return "", false
+	}
+	keyId, _, _ := strings.Cut(strings.TrimPrefix(value, encryptedValuePrefix), ":")
+	return keyId, true
+}
+
+func parseEncryptedValue(value string) (keyId string, wrappedKey []byte, ciphertext []byte, ok bool, err error) {
+	if !strings.HasPrefix(value, encryptedValuePrefix) {
+		return "", nil, nil, false, nil
+	}
+	parts := strings.Split(strings.TrimPrefix(value, encryptedValuePrefix), ":")
+	if len(parts) != 3 {
+		return "", nil, nil, false, fmt.Errorf("暗号化した値の形式が不正です")
+	}
+	if wrappedKey, err = base64.RawStdEncoding.DecodeString(parts[1]); err != nil {
+		return "", nil, nil, false, fmt.Errorf("暗号化した値の形式が不正です: %w", err)
+	}
+	if ciphertext, err = base64.RawStdEncoding.DecodeString(parts[2]); err != nil {
+		return "", nil, nil, false, fmt.Errorf("暗号化した値の形式が不正です: %w", err)
+	}
+	return parts[0], wrappedKey, ciphertext, true, nil
+}
+
+func newAead(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal は乱数のノンスで暗号化し、ノンスを先頭に付与して返します
+func seal(aead cipher.AEAD, plaintext []byte, additionalData []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+func open(aead cipher.AEAD, sealed []byte, additionalData []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, fmt.Errorf("暗号化した値が短すぎます")
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, additionalData)
+}
+
+// アプリケーション全体で使用する暗号化の鍵(Init で設定する)
+var fieldCipher atomic.Pointer[FieldCipher]
+
+// configureEncryption は暗号化の鍵を設定します(鍵が指定されていない場合は暗号化した列を保存できません)
+func configureEncryption(encryptionConfig config.EncryptionConfig) error {
+	if len(encryptionConfig.Keys) == 0 {
+		return nil
+	}
+	newCipher, err := NewFieldCipher(encryptionConfig)
+	if err != nil {
+		return err
+	}
+	fieldCipher.Store(newCipher)
+	return nil
+}
+
+// CurrentFieldCipher は設定された暗号化の鍵を返します
+func CurrentFieldCipher() (*FieldCipher, error) {
+	current := fieldCipher.Load()
+	if current == nil {
+		return nil, ErrEncryptionNotConfigured
+	}
+	return current, nil
+}
+
+// EncryptValue は値を暗号化します
+// モデルを指定せずに列を更新する場合(Updates に map を指定する場合など)に使用します
+func EncryptValue(plaintext string) (string, error) {
+	current, err := CurrentFieldCipher()
+	if err != nil {
+		return "", err
+	}
+	return current.Encrypt(plaintext)
+}
+
+// BlindIndex は値のブラインドインデックスを返します
+func BlindIndex(value string) (string, error) {
+	current, err := CurrentFieldCipher()
+	if err != nil {
+		return "", err
+	}
+	return current.BlindIndex(value), nil
+}
+
+// DecryptValue は暗号化した値を復号します(暗号化されていない値はそのまま返します)
+// モデルを指定せずに取得した値・データベース以外に保存した値の復号に使用します
+func DecryptValue(value string) (string, error) {
+	if !strings.HasPrefix(value, encryptedValuePrefix) {
+		return value, nil
+	}
+	current, err := CurrentFieldCipher()
+	if err != nil {
+		return "", err
+	}
+	return current.Decrypt(value)
+}
+
+func init() {
+	schema.RegisterSerializer("encrypted", EncryptedSerializer{})
+	schema.RegisterSerializer("blindindex", BlindIndexSerializer{})
+}
+
+// EncryptedSerializer は gorm:"serializer:encrypted" を指定した文字列の列を暗号化して保存し、取得時に復号します
+// *string のフィールドの場合、nil は NULL として保存します
+type EncryptedSerializer struct{}
+
+func (EncryptedSerializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue interface{}) error {
+	value, err := scannedString(dbValue)
+	if err != nil {
+		return err
+	}
+	plaintext, err := DecryptValue(value)
+	if err != nil {
+		return fmt.Errorf("%s の値を復号できません: %w", field.DBName, err)
+	}
+	target := field.ReflectValueOf(ctx, dst)
+	if target.Kind() == reflect.Pointer {
+		if dbValue == nil {
+			target.Set(reflect.Zero(target.Type()))
+		} else {
+			target.Set(reflect.ValueOf(&plaintext))
+		}
+		return nil
+	}
+	target.SetString(plaintext)
+	return nil
+}
+
+func (EncryptedSerializer) Value(ctx context.Context, field *schema.Field, dst reflect.Value, fieldValue interface{}) (interface{}, error) {
+	switch plaintext := fieldValue.(type) {
+	case string:
+		return EncryptValue(plaintext)
+	case *string:
+		if plaintext == nil {
+			return nil, nil
+		}
+		return EncryptValue(*plaintext)
+	default:
+		return nil, fmt.Errorf("%s: 暗号化できるのは文字列の列のみです", field.DBName)
+	}
+}
+
+// BlindIndexSerializer は gorm:"serializer:blindindex;blindindex:<フィールド名>" を指定した列に、
+// 同じモデルの指定したフィールドの値のブラインドインデックスを保存します(値が空の場合は NULL)
+type BlindIndexSerializer struct{}
+
+func (BlindIndexSerializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue interface{}) error {
+	value, err := scannedString(dbValue)
+	if err != nil {
+		return err
+	}
+	field.ReflectValueOf(ctx, dst).SetString(value)
+	return nil
+}
+
+func (BlindIndexSerializer) Value(ctx context.Context, field *schema.Field, dst reflect.Value, fieldValue interface{}) (interface{}, error) {
+	source := field.Schema.LookUpField(field.TagSettings["BLINDINDEX"])
+	if source == nil {
+		return nil, fmt.Errorf("%s: ブラインドインデックスを計算するフィールドが見つかりません", field.DBName)
+	}
+	value, ok := source.ReflectValueOf(ctx, dst).Interface().(string)
+	if !ok {
+		return nil, fmt.Errorf("%s: ブラインドインデックスを計算できるのは文字列のフィールドのみです", field.DBName)
+	}
+	if value == "" {
+		return nil, nil
+	}
+	return BlindIndex(value)
+}
+
+func scannedString(dbValue interface{}) (string, error) {
+	switch v := dbValue.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	default:
+		return "", fmt.Errorf("文字列以外の値は復号できません: %T", dbValue)
+	}
+}
@@ -0,0 +1,213 @@
+package db
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Go_CleanArch/infrastructure/config"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testEncryptionKey1     = "k1:MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="
+	testEncryptionKey2     = "k2:ZmVkY2JhOTg3NjU0MzIxMGZlZGNiYTk4NzY1NDMyMTA="
+	testBlindIndexKey      = "ZmVkY2JhOTg3NjU0MzIxMGZlZGNiYTk4NzY1NDMyMTA="
+	otherTestBlindIndexKey = "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="
+)
+
+func newTestFieldCipher(t *testing.T, activeKeyId string, keys ...string) *FieldCipher {
+	t.Helper()
+	fieldCipher, err := NewFieldCipher(config.EncryptionConfig{
+		Keys:          keys,
+		ActiveKeyId:   activeKeyId,
+		BlindIndexKey: testBlindIndexKey,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fieldCipher
+}
+
+func TestFieldCipher(t *testing.T) {
+	t.Parallel()
+
+	t.Run("正常系_暗号化した値を復号できる", func(t *testing.T) {
+		t.Parallel()
+		fieldCipher := newTestFieldCipher(t, "k1", testEncryptionKey1)
+
+		first, err := fieldCipher.Encrypt("yamada@example.com")
+		assert.NoError(t, err)
+		second, err := fieldCipher.Encrypt("yamada@example.com")
+		assert.NoError(t, err)
+
+		assert.True(t, strings.HasPrefix(first, "enc:v1:k1:"))
+		assert.NotContains(t, first, "yamada")
+		// 値ごとにデータ鍵・ノンスを生成するため、同じ値でも暗号化した結果は異なる
+		assert.NotEqual(t, first, second)
+		plaintext, err := fieldCipher.Decrypt(first)
+		assert.NoError(t, err)
+		assert.Equal(t, "yamada@example.com", plaintext)
+	})
+
+	t.Run("正常系_暗号化されていない値はそのまま返す", func(t *testing.T) {
+		t.Parallel()
+		fieldCipher := newTestFieldCipher(t, "k1", testEncryptionKey1)
+
+		plaintext, err := fieldCipher.Decrypt("yamada@example.com")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "yamada@example.com", plaintext)
+		assert.True(t, fieldCipher.NeedsReencryption("yamada@example.com"))
+	})
+
+	t.Run("正常系_ローテーション後も以前の鍵で暗号化した値を復号できる", func(t *testing.T) {
+		t.Parallel()
+		encrypted, err := newTestFieldCipher(t, "k1", testEncryptionKey1).Encrypt("山田 太郎")
+		assert.NoError(t, err)
+		rotated := newTestFieldCipher(t, "k2", testEncryptionKey1, testEncryptionKey2)
+
+		plaintext, err := rotated.Decrypt(encrypted)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "山田 太郎", plaintext)
+		assert.True(t, rotated.NeedsReencryption(encrypted))
+		reencrypted, err := rotated.Encrypt(plaintext)
+		assert.NoError(t, err)
+		assert.False(t, rotated.NeedsReencryption(reencrypted))
+		keyId, ok := EncryptionKeyId(reencrypted)
+		assert.True(t, ok)
+		assert.Equal(t, "k2", keyId)
+	})
+
+	t.Run("異常系_鍵が設定されていない・改ざんされた値は復号できない", func(t *testing.T) {
+		t.Parallel()
+		encrypted, err := newTestFieldCipher(t, "k2", testEncryptionKey2).Encrypt("yamada@example.com")
+		assert.NoError(t, err)
+
+		_, err = newTestFieldCipher(t, "k1", testEncryptionKey1).Decrypt(encrypted)
+		assert.ErrorContains(t, err, "k2")
+
+		// 鍵IDを書き換えた値は、同じ鍵が設定されていても復号できない
+		both := newTestFieldCipher(t, "k3", "k3:"+strings.TrimPrefix(testEncryptionKey2, "k2:"), testEncryptionKey2)
+		_, err = both.Decrypt(strings.Replace(encrypted, "enc:v1:k2:", "enc:v1:k3:", 1))
+		assert.Error(t, err)
+
+		_, err = both.Decrypt("enc:v1:k2:broken")
+		assert.ErrorContains(t, err, "形式が不正")
+	})
+
+	t.Run("正常系_ブラインドインデックスは大文字・小文字・前後の空白を区別しない", func(t *testing.T) {
+		t.Parallel()
+		fieldCipher := newTestFieldCipher(t, "k1", testEncryptionKey1)
+		other, err := NewFieldCipher(config.EncryptionConfig{
+			Keys:          []string{testEncryptionKey1},
+			ActiveKeyId:   "k1",
+			BlindIndexKey: otherTestBlindIndexKey,
+		})
+		assert.NoError(t, err)
+
+		index := fieldCipher.BlindIndex("Yamada@Example.com")
+
+		assert.Len(t, index, 64)
+		assert.Equal(t, index, fieldCipher.BlindIndex("yamada@example.com"))
+		assert.Equal(t, index, fieldCipher.BlindIndex(" yamada@example.com\n"))
+		assert.NotEqual(t, index, fieldCipher.BlindIndex("tanaka@example.com"))
+		// 鍵が異なる場合は一致しない(鍵を知らなければメールアドレスから計算できない)
+		assert.NotEqual(t, index, other.BlindIndex("yamada@example.com"))
+	})
+
+	t.Run("異常系_暗号化に使用する鍵が設定されていない", func(t *testing.T) {
+		t.Parallel()
+		_, err := NewFieldCipher(config.EncryptionConfig{
+			Keys:          []string{testEncryptionKey1},
+			ActiveKeyId:   "k2",
+			BlindIndexKey: testBlindIndexKey,
+		})
+
+		assert.ErrorContains(t, err, "k2")
+	})
+}
+
+type encryptedUser struct {
+	UserId     string `gorm:"primaryKey"`
+	UserName   string `gorm:"serializer:encrypted"`
+	Password   string
+	Email      string `gorm:"serializer:encrypted"`
+	EmailIndex string `gorm:"serializer:blindindex;blindindex:Email"`
+	AvatarKey  *string
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+func (encryptedUser) TableName() string {
+	return "users"
+}
+
+func TestEncryptedSerializer(t *testing.T) {
+	t.Parallel()
+	if err := configureEncryption(config.EncryptionConfig{
+		Keys:          []string{testEncryptionKey1},
+		ActiveKeyId:   "k1",
+		BlindIndexKey: testBlindIndexKey,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	fieldCipher, err := CurrentFieldCipher()
+	assert.NoError(t, err)
+
+	t.Run("正常系_暗号化して保存し、取得時に復号する", func(t *testing.T) {
+		t.Parallel()
+		gormDB := newAuditTestDB(t)
+
+		user := encryptedUser{UserId: "user-1", UserName: "山田 太郎", Password: "hashed", Email: "Yamada@example.com"}
+		assert.NoError(t, gormDB.Create(&user).Error)
+
+		var stored map[string]interface{}
+		assert.NoError(t, gormDB.Table("users").Select("user_name", "email", "email_index").Where("user_id = ?", "user-1").Take(&stored).Error)
+		assert.True(t, strings.HasPrefix(stored["email"].(string), "enc:v1:k1:"))
+		assert.True(t, strings.HasPrefix(stored["user_name"].(string), "enc:v1:k1:"))
+		assert.Equal(t, fieldCipher.BlindIndex("yamada@example.com"), stored["email_index"])
+
+		var found encryptedUser
+		assert.NoError(t, gormDB.Where("email_index = ?", fieldCipher.BlindIndex("YAMADA@example.com")).First(&found).Error)
+		assert.Equal(t, "山田 太郎", found.UserName)
+		assert.Equal(t, "Yamada@example.com", found.Email)
+	})
+
+	t.Run("正常系_暗号化する前に保存した値はそのまま取得する", func(t *testing.T) {
+		t.Parallel()
+		gormDB := newAuditTestDB(t)
+		assert.NoError(t, gormDB.Exec("INSERT INTO users (user_id, user_name, password, email, created_at) VALUES (?, ?, ?, ?, ?)",
+			"user-1", "山田 太郎", "hashed", "yamada@example.com", time.Now()).Error)
+
+		var found encryptedUser
+		assert.NoError(t, gormDB.First(&found, "user_id = ?", "user-1").Error)
+
+		assert.Equal(t, "山田 太郎", found.UserName)
+		assert.Equal(t, "yamada@example.com", found.Email)
+		assert.Empty(t, found.EmailIndex)
+	})
+
+	t.Run("正常系_値を変更していない列は監査ログに記録しない", func(t *testing.T) {
+		t.Parallel()
+		gormDB := newAuditTestDB(t)
+		user := encryptedUser{UserId: "user-1", UserName: "山田 太郎", Password: "hashed", Email: "yamada@example.com"}
+		assert.NoError(t, gormDB.Create(&user).Error)
+
+		// 保存するたびに暗号化した値は変わるが、復号した値で比較する
+		avatarKey := "avatars/user-1.png"
+		user.AvatarKey = &avatarKey
+		assert.NoError(t, gormDB.Save(&user).Error)
+		user.Email = "tanaka@example.com"
+		assert.NoError(t, gormDB.Save(&user).Error)
+
+		logs := auditLogsOf(t, gormDB, "user-1")
+		assert.Len(t, logs, 3)
+		assert.Equal(t, map[string]AuditChange{"avatar_key": {Before: nil, After: avatarKey}}, changesOf(t, logs[1]))
+		assert.Equal(t, map[string]AuditChange{
+			"email":       {Before: auditMaskedValue, After: auditMaskedValue},
+			"email_index": {Before: auditMaskedValue, After: auditMaskedValue},
+		}, changesOf(t, logs[2]))
+	})
+}
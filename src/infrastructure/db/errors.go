@@ -0,0 +1,45 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	gosqlite "github.com/glebarez/go-sqlite"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// PostgreSQL の一意制約違反のエラーコード
+const uniqueViolationCode = "23505"
+
+// SQLite の一意制約(UNIQUE 制約・一意インデックス)違反の拡張エラーコード
+const sqliteConstraintUnique = 2067
+
+// 指定した一意制約(インデックス)への違反か判定する
+// 事前の存在チェックでは同時実行時の重複を防げないため、最終的な重複の検知はデータベースの制約で行う。
+func IsUniqueViolation(err error, constraintName string) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == uniqueViolationCode && pgErr.ConstraintName == constraintName
+	}
+	// SQLite は制約名をエラーメッセージにのみ含める(例: UNIQUE constraint failed: index 'users_email_key')
+	var sqliteErr *gosqlite.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.Code() == sqliteConstraintUnique && strings.Contains(sqliteErr.Error(), fmt.Sprintf("index '%s'", constraintName))
+	}
+	return false
+}
+
+// 列に対する一意制約(インデックス)への違反か判定する
+// SQLite は列に対する一意インデックスの場合、制約名ではなく列名をエラーメッセージに含める(例: UNIQUE constraint failed: users.email)
+func IsUniqueViolationOnColumns(err error, constraintName string, table string, columns ...string) bool {
+	var sqliteErr *gosqlite.Error
+	if errors.As(err, &sqliteErr) {
+		qualified := make([]string, len(columns))
+		for i, column := range columns {
+			qualified[i] = table + "." + column
+		}
+		return sqliteErr.Code() == sqliteConstraintUnique && strings.HasSuffix(sqliteErr.Error(), "constraint failed: "+strings.Join(qualified, ", ")+fmt.Sprintf(" (%d)", sqliteConstraintUnique))
+	}
+	return IsUniqueViolation(err, constraintName)
+}
@@ -0,0 +1,84 @@
+package db_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	dbConnect "github.com/Go_CleanArch/infrastructure/db"
+	"github.com/glebarez/sqlite"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestIsUniqueViolation(t *testing.T) {
+	t.Parallel()
+
+	t.Run("一意制約違反_指定した制約", func(t *testing.T) {
+		t.Parallel()
+		err := fmt.Errorf("create: %w", &pgconn.PgError{Code: "23505", ConstraintName: "users_email_key"})
+
+		assert.True(t, dbConnect.IsUniqueViolation(err, "users_email_key"))
+	})
+
+	t.Run("一意制約違反_他の制約", func(t *testing.T) {
+		t.Parallel()
+		err := &pgconn.PgError{Code: "23505", ConstraintName: "users_pkey"}
+
+		assert.False(t, dbConnect.IsUniqueViolation(err, "users_email_key"))
+	})
+
+	t.Run("一意制約違反以外のエラー", func(t *testing.T) {
+		t.Parallel()
+		assert.False(t, dbConnect.IsUniqueViolation(&pgconn.PgError{Code: "23503", ConstraintName: "users_email_key"}, "users_email_key"))
+		assert.False(t, dbConnect.IsUniqueViolation(errors.New("connection refused"), "users_email_key"))
+	})
+
+	t.Run("一意制約違反_SQLite", func(t *testing.T) {
+		t.Parallel()
+		gormDB, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+		assert.NoError(t, err)
+		assert.NoError(t, gormDB.Exec("CREATE TABLE users (user_id TEXT PRIMARY KEY, email TEXT)").Error)
+		assert.NoError(t, gormDB.Exec("CREATE UNIQUE INDEX users_email_key ON users (LOWER(email))").Error)
+		assert.NoError(t, gormDB.Exec("INSERT INTO users VALUES ('u-1', 'a@example.com')").Error)
+
+		err = gormDB.Exec("INSERT INTO users VALUES ('u-2', 'A@example.com')").Error
+		assert.True(t, dbConnect.IsUniqueViolation(err, "users_email_key"))
+		assert.False(t, dbConnect.IsUniqueViolation(err, "users_pkey"))
+
+		// 主キーの重複は一意インデックスへの違反ではない
+		err = gormDB.Exec("INSERT INTO users VALUES ('u-1', 'b@example.com')").Error
+		assert.False(t, dbConnect.IsUniqueViolation(err, "users_email_key"))
+	})
+}
+
+func TestIsUniqueViolationOnColumns(t *testing.T) {
+	t.Parallel()
+
+	t.Run("一意制約違反_PostgreSQL", func(t *testing.T) {
+		t.Parallel()
+		err := &pgconn.PgError{Code: "23505", ConstraintName: "idempotency_keys_key"}
+
+		assert.True(t, dbConnect.IsUniqueViolationOnColumns(err, "idempotency_keys_key", "idempotency_keys", "scope", "idempotency_key"))
+		assert.False(t, dbConnect.IsUniqueViolationOnColumns(err, "users_email_key", "users", "email"))
+	})
+
+	t.Run("一意制約違反_SQLite", func(t *testing.T) {
+		t.Parallel()
+		gormDB, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+		assert.NoError(t, err)
+		assert.NoError(t, gormDB.Exec("CREATE TABLE idempotency_keys (id INTEGER PRIMARY KEY, scope TEXT, idempotency_key TEXT)").Error)
+		assert.NoError(t, gormDB.Exec("CREATE UNIQUE INDEX idempotency_keys_key ON idempotency_keys (scope, idempotency_key)").Error)
+		assert.NoError(t, gormDB.Exec("INSERT INTO idempotency_keys VALUES (1, 'POST /api/users', 'key')").Error)
+
+		err = gormDB.Exec("INSERT INTO idempotency_keys VALUES (2, 'POST /api/users', 'key')").Error
+		assert.True(t, dbConnect.IsUniqueViolationOnColumns(err, "idempotency_keys_key", "idempotency_keys", "scope", "idempotency_key"))
+		assert.False(t, dbConnect.IsUniqueViolationOnColumns(err, "idempotency_keys_key", "idempotency_keys", "scope"))
+
+		// 主キーの重複は一意インデックスへの違反ではない
+		err = gormDB.Exec("INSERT INTO idempotency_keys VALUES (1, 'POST /api/users', 'other')").Error
+		assert.False(t, dbConnect.IsUniqueViolationOnColumns(err, "idempotency_keys_key", "idempotency_keys", "scope", "idempotency_key"))
+	})
+}
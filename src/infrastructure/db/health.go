@@ -0,0 +1,315 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Go_CleanArch/infrastructure/config"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// HealthStatus はデータベース接続の死活監視の結果とコネクションプールの利用状況を表します
+type HealthStatus struct {
+	Healthy bool `json:"healthy"`
+	// 連続して疎通確認に失敗した回数
+	ConsecutiveFailures int `json:"consecutiveFailures"`
+	// 接続を開き直した回数
+	Reconnects    int        `json:"reconnects"`
+	LastCheckedAt *time.Time `json:"lastCheckedAt,omitempty"`
+	LastError     string     `json:"lastError,omitempty"`
+	Pool          PoolStats  `json:"pool"`
+	// レプリカの状態(異常なレプリカへの読み取りはプライマリで行うため、全体の状態には含めない)
+	Replicas []ReplicaStatus `json:"replicas,omitempty"`
+}
+
+// ReplicaStatus はレプリカの死活監視の結果とコネクションプールの利用状況を表します
+type ReplicaStatus struct {
+	Host    string `json:"host"`
+	Healthy bool   `json:"healthy"`
+	// 連続して疎通確認に失敗した回数
+	ConsecutiveFailures int `json:"consecutiveFailures"`
+	// 接続を開き直した回数
+	Reconnects int       `json:"reconnects"`
+	LastError  string    `json:"lastError,omitempty"`
+	Pool       PoolStats `json:"pool"`
+}
+
+// PoolStats はコネクションプールの利用状況を表します
+type PoolStats struct {
+	MaxOpenConnections int `json:"maxOpenConnections"`
+	OpenConnections    int `json:"openConnections"`
+	InUse              int `json:"inUse"`
+	Idle               int `json:"idle"`
+	// 空き接続を待った回数・合計時間
+	WaitCount         int64  `json:"waitCount"`
+	WaitDuration      string `json:"waitDuration"`
+	MaxIdleClosed     int64  `json:"maxIdleClosed"`
+	MaxIdleTimeClosed int64  `json:"maxIdleTimeClosed"`
+	MaxLifetimeClosed int64  `json:"maxLifetimeClosed"`
+}
+
+var prober atomic.Pointer[healthProber]
+
+// Health は共有の接続の状態を返します
+// 死活監視を開始する前(Init の前)は Healthy が false となります
+func Health() HealthStatus {
+	status := HealthStatus{}
+	if p := prober.Load(); p != nil {
+		status = p.snapshot()
+	}
+	status.Pool = poolStats(currentDB())
+	if set := replicaConnections.Load(); set != nil {
+		for _, r := range set.replicas {
+			status.Replicas = append(status.Replicas, ReplicaStatus{
+				Host:                r.dbConfig.Host,
+				Healthy:             r.healthy.Load(),
+				ConsecutiveFailures: int(r.consecutiveFailures.Load()),
+				Reconnects:          int(r.reconnects.Load()),
+				LastError:           r.lastError.Load().(string),
+				Pool:                poolStats(r.db.Load()),
+			})
+		}
+	}
+	return status
+}
+
+func poolStats(db *gorm.DB) PoolStats {
+	if db == nil {
+		return PoolStats{}
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return PoolStats{}
+	}
+	stats := sqlDB.Stats()
+	return PoolStats{
+		MaxOpenConnections: stats.MaxOpenConnections,
+		OpenConnections:    stats.OpenConnections,
+		InUse:              stats.InUse,
+		Idle:               stats.Idle,
+		WaitCount:          stats.WaitCount,
+		WaitDuration:       stats.WaitDuration.String(),
+		MaxIdleClosed:      stats.MaxIdleClosed,
+		MaxIdleTimeClosed:  stats.MaxIdleTimeClosed,
+		MaxLifetimeClosed:  stats.MaxLifetimeClosed,
+	}
+}
+
+// healthProber は共有の接続・レプリカの疎通を定期的に確認し、
+// 共有の接続が連続して失敗した場合は接続を開き直して差し替えます
+type healthProber struct {
+	dbConfig config.DatabaseConfig
+	open     func(dbConfig config.DatabaseConfig) (*gorm.DB, error)
+
+	mu     sync.RWMutex
+	status HealthStatus
+
+	// 死活監視の停止(startHealthProber で開始した場合のみ設定する)
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func newHealthProber(dbConfig config.DatabaseConfig, open func(dbConfig config.DatabaseConfig) (*gorm.DB, error)) *healthProber {
+	return &healthProber{
+		dbConfig: dbConfig,
+		open:     open,
+	}
+}
+
+// startHealthProber は ctx がキャンセルされるまで死活監視を行います
+func startHealthProber(ctx context.Context, dbConfig config.DatabaseConfig) {
+	ctx, cancel := context.WithCancel(ctx)
+	p := newHealthProber(dbConfig, Open)
+	p.cancel = cancel
+	p.done = make(chan struct{})
+	prober.Store(p)
+	go func() {
+		defer close(p.done)
+		p.run(ctx)
+	}()
+}
+
+// stop は死活監視を停止し、実行中の疎通確認・再接続の完了を待ちます
+func (p *healthProber) stop() {
+	if p.cancel == nil {
+		return
+	}
+	p.cancel()
+	<-p.done
+}
+
+func (p *healthProber) run(ctx context.Context) {
+	p.probe(ctx)
+	p.probeReplicas(ctx)
+	ticker := time.NewTicker(p.dbConfig.HealthCheck.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.probe(ctx)
+			p.probeReplicas(ctx)
+		}
+	}
+}
+
+func (p *healthProber) snapshot() HealthStatus {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.status
+}
+
+// probe は疎通確認を1回行い、失敗が閾値に達した場合は接続を開き直します
+func (p *healthProber) probe(ctx context.Context) {
+	err := p.ping(ctx, currentDB())
+
+	p.mu.Lock()
+	checkedAt := time.Now()
+	p.status.LastCheckedAt = &checkedAt
+	if err == nil {
+		if !p.status.Healthy && p.status.ConsecutiveFailures > 0 {
+			log.Info("Database connection recovered")
+		}
+		p.status.Healthy = true
+		p.status.ConsecutiveFailures = 0
+		p.status.LastError = ""
+		p.mu.Unlock()
+		return
+	}
+	p.status.Healthy = false
+	p.status.ConsecutiveFailures++
+	p.status.LastError = err.Error()
+	failures := p.status.ConsecutiveFailures
+	p.mu.Unlock()
+
+	log.WithError(err).WithField("consecutiveFailures", failures).Warn("Database health check failed")
+	if failures >= p.dbConfig.HealthCheck.FailureThreshold {
+		p.reconnect(ctx)
+	}
+}
+
+// reconnect は新しい接続を開き、疎通を確認できた場合に共有の接続と差し替えます
+func (p *healthProber) reconnect(ctx context.Context) {
+	// メモリ上の SQLite は開き直すとデータが失われるため、接続を維持する
+	if p.dbConfig.Driver == DriverSqlite && p.dbConfig.SqlitePath == sqliteMemoryPath {
+		return
+	}
+	newDb, err := p.open(p.dbConfig)
+	if err == nil {
+		if err = p.ping(ctx, newDb); err != nil {
+			closeConnection(newDb)
+		}
+	}
+	if err != nil {
+		log.WithError(err).Error("Failed to reconnect to the database")
+		p.mu.Lock()
+		p.status.LastError = err.Error()
+		p.mu.Unlock()
+		return
+	}
+
+	previous := storeConnection(newDb)
+	p.mu.Lock()
+	p.status.Healthy = true
+	p.status.ConsecutiveFailures = 0
+	p.status.LastError = ""
+	p.status.Reconnects++
+	p.mu.Unlock()
+	log.Warn("Database connection has been replaced")
+
+	// 差し替え前の接続は、実行中の処理が完了してから閉じる
+	if previous != nil {
+		go closeConnection(previous.db)
+	}
+}
+
+// probeReplicas はレプリカの疎通を確認し、読み取りの振り分け対象とするか更新します
+func (p *healthProber) probeReplicas(ctx context.Context) {
+	set := replicaConnections.Load()
+	if set == nil {
+		return
+	}
+	for _, r := range set.replicas {
+		err := p.probeReplica(ctx, r)
+
+		wasHealthy := r.healthy.Swap(err == nil)
+		if err != nil {
+			r.lastError.Store(err.Error())
+			log.WithError(err).WithField("host", r.dbConfig.Host).Warn("Database replica health check failed")
+			continue
+		}
+		r.lastError.Store("")
+		if !wasHealthy {
+			log.WithField("host", r.dbConfig.Host).Info("Database replica recovered")
+		}
+	}
+}
+
+// probeReplica はレプリカの疎通を1回確認します
+// 接続できていないレプリカは接続を再試行し、接続済みのレプリカの失敗が閾値に達した場合は接続を開き直して差し替えます
+func (p *healthProber) probeReplica(ctx context.Context, r *replica) error {
+	replicaDb := r.db.Load()
+	if replicaDb == nil {
+		newDb, err := p.open(r.dbConfig)
+		if err != nil {
+			r.consecutiveFailures.Add(1)
+			return err
+		}
+		r.db.Store(newDb)
+		replicaDb = newDb
+	}
+	err := p.ping(ctx, replicaDb)
+	if err == nil {
+		r.consecutiveFailures.Store(0)
+		return nil
+	}
+	if r.consecutiveFailures.Add(1) < int64(p.dbConfig.HealthCheck.FailureThreshold) {
+		return err
+	}
+
+	newDb, reconnectErr := p.open(r.dbConfig)
+	if reconnectErr == nil {
+		if reconnectErr = p.ping(ctx, newDb); reconnectErr != nil {
+			closeConnection(newDb)
+		}
+	}
+	if reconnectErr != nil {
+		log.WithError(reconnectErr).WithField("host", r.dbConfig.Host).Error("Failed to reconnect to the database replica")
+		return reconnectErr
+	}
+	r.db.Store(newDb)
+	r.consecutiveFailures.Store(0)
+	r.reconnects.Add(1)
+	log.WithField("host", r.dbConfig.Host).Warn("Database replica connection has been replaced")
+	// 差し替え前の接続は、実行中の処理が完了してから閉じる
+	go closeConnection(replicaDb)
+	return nil
+}
+
+func (p *healthProber) ping(ctx context.Context, db *gorm.DB) error {
+	if db == nil {
+		return fmt.Errorf("データベース接続が確立されていません")
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	pingCtx, cancel := context.WithTimeout(ctx, p.dbConfig.HealthCheck.Timeout)
+	defer cancel()
+	return sqlDB.PingContext(pingCtx)
+}
+
+func closeConnection(db *gorm.DB) {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return
+	}
+	if err := sqlDB.Close(); err != nil {
+		log.WithError(err).Warn("Failed to close database connection")
+	}
+}
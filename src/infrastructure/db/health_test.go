@@ -0,0 +1,151 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Go_CleanArch/infrastructure/config"
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func openHealthTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := fmt.Sprintf("file:health_test_%d?mode=memory&cache=shared", testDatabaseSeq.Add(1))
+	gormDB, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, gormDB.AutoMigrate(&globalNote{}))
+	return gormDB
+}
+
+func healthTestConfig() config.DatabaseConfig {
+	return config.DatabaseConfig{
+		HealthCheck: config.HealthCheckConfig{
+			Interval:         time.Second,
+			Timeout:          time.Second,
+			FailureThreshold: 2,
+		},
+	}
+}
+
+// 共有の接続を差し替えるため、このテストは並列に実行しない
+func TestHealthProber(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("正常系_疎通確認に成功", func(t *testing.T) {
+		storeConnection(openHealthTestDB(t))
+		p := newHealthProber(healthTestConfig(), nil)
+
+		p.probe(ctx)
+
+		status := p.snapshot()
+		assert.True(t, status.Healthy)
+		assert.Equal(t, 0, status.ConsecutiveFailures)
+		assert.NotNil(t, status.LastCheckedAt)
+	})
+
+	t.Run("異常系_失敗が閾値未満の場合は接続を維持", func(t *testing.T) {
+		broken := openHealthTestDB(t)
+		storeConnection(broken)
+		closeConnection(broken)
+		opened := 0
+		p := newHealthProber(healthTestConfig(), func(config.DatabaseConfig) (*gorm.DB, error) {
+			opened++
+			return openHealthTestDB(t), nil
+		})
+
+		p.probe(ctx)
+
+		status := p.snapshot()
+		assert.False(t, status.Healthy)
+		assert.Equal(t, 1, status.ConsecutiveFailures)
+		assert.NotEmpty(t, status.LastError)
+		assert.Equal(t, 0, opened)
+		assert.Same(t, broken, currentDB())
+	})
+
+	t.Run("正常系_失敗が閾値に達した場合は接続を差し替え", func(t *testing.T) {
+		broken := openHealthTestDB(t)
+		storeConnection(broken)
+		closeConnection(broken)
+		replacement := openHealthTestDB(t)
+		p := newHealthProber(healthTestConfig(), func(config.DatabaseConfig) (*gorm.DB, error) {
+			return replacement, nil
+		})
+		brokenConnection := connection.Load().(*sharedConnection)
+
+		p.probe(ctx)
+		p.probe(ctx)
+
+		status := p.snapshot()
+		assert.True(t, status.Healthy)
+		assert.Equal(t, 0, status.ConsecutiveFailures)
+		assert.Equal(t, 1, status.Reconnects)
+		assert.Same(t, replacement, currentDB())
+		// 差し替え前の接続のコンテキストはキャンセルされる
+		assert.Error(t, brokenConnection.ctx.Err())
+
+		// 共有の接続を使用するリポジトリは、差し替え後の接続で処理する
+		dbConnect, err := NewDBConnection(ctx)
+		assert.NoError(t, err)
+		assert.NoError(t, dbConnect.Create(ctx, &globalNote{NoteId: "g-1"}))
+		var count int64
+		assert.NoError(t, replacement.Model(&globalNote{}).Count(&count).Error)
+		assert.Equal(t, int64(1), count)
+	})
+
+	t.Run("異常系_再接続に失敗した場合は異常のまま", func(t *testing.T) {
+		broken := openHealthTestDB(t)
+		storeConnection(broken)
+		closeConnection(broken)
+		p := newHealthProber(healthTestConfig(), func(config.DatabaseConfig) (*gorm.DB, error) {
+			return nil, errors.New("connection refused")
+		})
+
+		p.probe(ctx)
+		p.probe(ctx)
+		p.probe(ctx)
+
+		status := p.snapshot()
+		assert.False(t, status.Healthy)
+		assert.Equal(t, 3, status.ConsecutiveFailures)
+		assert.Equal(t, 0, status.Reconnects)
+		assert.Equal(t, "connection refused", status.LastError)
+		assert.Same(t, broken, currentDB())
+	})
+
+	t.Run("正常系_コネクションプールの利用状況", func(t *testing.T) {
+		gormDB := openHealthTestDB(t)
+		storeConnection(gormDB)
+		sqlDB, _ := gormDB.DB()
+		sqlDB.SetMaxOpenConns(7)
+
+		assert.Equal(t, 7, Health().Pool.MaxOpenConnections)
+	})
+
+	t.Run("正常系_終了時に死活監視を停止し、接続を閉じる", func(t *testing.T) {
+		gormDB := openHealthTestDB(t)
+		storeConnection(gormDB)
+		startHealthProber(ctx, healthTestConfig())
+		p := prober.Load()
+
+		Close()
+
+		// 死活監視の終了を待ってから返る
+		select {
+		case <-p.done:
+		default:
+			t.Fatal("死活監視が停止していません")
+		}
+		assert.Nil(t, prober.Load())
+		sqlDB, _ := gormDB.DB()
+		assert.Error(t, sqlDB.Ping())
+	})
+}
@@ -0,0 +1,157 @@
+package migration
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/Go_CleanArch/infrastructure/config"
+	dbConnect "github.com/Go_CleanArch/infrastructure/db"
+	"github.com/Go_CleanArch/infrastructure/db/reencrypt"
+	"gorm.io/gorm"
+)
+
+const usage = `使い方: migrate <サブコマンド>
+  up                 未適用のマイグレーションをすべて適用する(暗号化する前に保存したレコードも暗号化する)
+  down [件数]        適用済みのマイグレーションを新しいものから取り消す(既定: 1件)
+  status             各マイグレーションの適用状況を表示する
+  create [-dir ディレクトリ] <名前>  空のマイグレーションファイルを作成する`
+
+// migrate サブコマンドの実行
+// create はデータベースに接続しないため、設定は接続が必要なサブコマンドでのみ読み込む。
+func RunCommand(ctx context.Context, args []string, out io.Writer, loadConfig func() (*config.Config, error)) error {
+	if len(args) == 0 {
+		return fmt.Errorf("サブコマンドを指定してください\n%s", usage)
+	}
+
+	switch args[0] {
+	case "create":
+		flags := flag.NewFlagSet("create", flag.ContinueOnError)
+		flags.SetOutput(out)
+		dir := flags.String("dir", DefaultDir, "マイグレーションファイルを格納するディレクトリ")
+		if err := flags.Parse(args[1:]); err != nil {
+			return err
+		}
+		if flags.NArg() != 1 {
+			return fmt.Errorf("マイグレーションの名前を1つ指定してください\n%s", usage)
+		}
+		paths, err := Create(*dir, flags.Arg(0))
+		if err != nil {
+			return err
+		}
+		for _, path := range paths {
+			fmt.Fprintf(out, "作成しました: %s\n", path)
+		}
+		return nil
+	case "up", "down", "status":
+	default:
+		return fmt.Errorf("不明なサブコマンドです: %s\n%s", args[0], usage)
+	}
+
+	steps := 1
+	if args[0] == "down" && len(args) > 1 {
+		parsed, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("取り消す件数は数値で指定してください: %s", args[1])
+		}
+		steps = parsed
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	if err := withMigrator(cfg.Database, func(migrator *Migrator) error {
+		switch args[0] {
+		case "up":
+			applied, err := migrator.Up(ctx)
+			for _, migration := range applied {
+				fmt.Fprintf(out, "適用しました: %s\n", migration.label())
+			}
+			if err == nil && len(applied) == 0 {
+				fmt.Fprintln(out, "未適用のマイグレーションはありません")
+			}
+			return err
+		case "down":
+			reverted, err := migrator.Down(ctx, steps)
+			for _, migration := range reverted {
+				fmt.Fprintf(out, "取り消しました: %s\n", migration.label())
+			}
+			return err
+		default:
+			statuses, err := migrator.Status(ctx)
+			if err != nil {
+				return err
+			}
+			return printStatus(out, statuses)
+		}
+	}); err != nil || args[0] != "up" {
+		return err
+	}
+
+	// 暗号化する前に保存したレコードを暗号化し、ブラインドインデックスを設定する(中断した場合も再実行すると続きから処理する)
+	dbConnect.Init(cfg.Database)
+	defer dbConnect.Close()
+	return reencrypt.Backfill(ctx, out)
+}
+
+// 起動時のマイグレーション
+// 複数のインスタンスが同時に起動してもアドバイザリロックにより1つずつ実行され、適用済みのバージョンは読み飛ばされる。
+// SQLite は接続時にスキーマを作成するため、マイグレーションは適用しない。
+func AutoMigrate(ctx context.Context, dbConfig config.DatabaseConfig) ([]Migration, error) {
+	if dbConfig.Driver == dbConnect.DriverSqlite {
+		return nil, nil
+	}
+	var applied []Migration
+	err := withMigrator(dbConfig, func(migrator *Migrator) error {
+		var err error
+		applied, err = migrator.Up(ctx)
+		return err
+	})
+	return applied, err
+}
+
+// マイグレーション専用の接続を開き、処理の完了後に閉じる
+func withMigrator(dbConfig config.DatabaseConfig, fn func(migrator *Migrator) error) error {
+	if dbConfig.Driver == dbConnect.DriverSqlite {
+		return fmt.Errorf("マイグレーションは PostgreSQL でのみ実行できます(SQLite のスキーマは接続時に作成されます)")
+	}
+	migrations, err := Embedded()
+	if err != nil {
+		return err
+	}
+	// テーブルの変更・データの移行は時間がかかる場合があるため、SQLの実行時間の上限を設定しない
+	dbConfig.QueryTimeout = 0
+	db, err := dbConnect.Open(dbConfig)
+	if err != nil {
+		return fmt.Errorf("データベースに接続できません: %w", err)
+	}
+	defer closeDB(db)
+
+	return fn(NewMigrator(db, migrations))
+}
+
+func closeDB(db *gorm.DB) {
+	if sqlDB, err := db.DB(); err == nil {
+		sqlDB.Close()
+	}
+}
+
+func printStatus(out io.Writer, statuses []Status) error {
+	writer := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+	for _, status := range statuses {
+		state, appliedAt := "未適用", "-"
+		if status.AppliedAt != nil {
+			state, appliedAt = "適用済み", status.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		if status.Missing {
+			state = "適用済み(ファイルなし)"
+		}
+		fmt.Fprintf(writer, "%04d\t%s\t%s\t%s\n", status.Version, status.Name, state, appliedAt)
+	}
+	return writer.Flush()
+}
@@ -0,0 +1,50 @@
+package migration
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var invalidNameCharacters = regexp.MustCompile(`[^a-z0-9]+`)
+
+// 次のバージョンで空のマイグレーションファイル(up / down)を作成し、作成したファイルのパスを返す
+// 名前は小文字の英数字とアンダースコアに変換する。
+func Create(dir string, name string) ([]string, error) {
+	name = strings.Trim(invalidNameCharacters.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if name == "" {
+		return nil, fmt.Errorf("マイグレーションの名前は英数字で指定してください")
+	}
+
+	migrations, err := Load(os.DirFS(dir))
+	if err != nil {
+		return nil, err
+	}
+	version := int64(1)
+	if len(migrations) > 0 {
+		version = migrations[len(migrations)-1].Version + 1
+	}
+
+	migration := Migration{Version: version, Name: name}
+	paths := []string{}
+	for _, direction := range []string{"up", "down"} {
+		path := filepath.Join(dir, fmt.Sprintf("%s.%s.sql", migration.label(), direction))
+		content := fmt.Sprintf("-- %s (%s)\n", migration.label(), direction)
+		// 既存のファイルを上書きしない
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if err != nil {
+			return paths, fmt.Errorf("マイグレーションファイルを作成できません: %w", err)
+		}
+		_, err = file.WriteString(content)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return paths, fmt.Errorf("マイグレーションファイルを作成できません: %w", err)
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
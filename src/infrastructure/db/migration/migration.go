@@ -0,0 +1,97 @@
+package migration
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+// アプリケーションに組み込むマイグレーションファイル
+//
+//go:embed sql/*.sql
+var embedded embed.FS
+
+// マイグレーションファイルを格納するディレクトリ(create で作成する場合の既定値)
+const DefaultDir = "infrastructure/db/migration/sql"
+
+// ファイル名の形式: <バージョン>_<名前>.up.sql / <バージョン>_<名前>.down.sql
+var fileNamePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Migration は1つのバージョンのスキーマ変更を表す。
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// アプリケーションに組み込んだマイグレーションを読み込む
+func Embedded() ([]Migration, error) {
+	sub, err := fs.Sub(embedded, "sql")
+	if err != nil {
+		return nil, err
+	}
+	return Load(sub)
+}
+
+// fsys の直下にあるマイグレーションファイルを読み込み、バージョンの昇順で返す
+// バージョンの重複や up / down のどちらかが欠けている場合はエラーとする。
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("マイグレーションファイルを読み込めません: %w", err)
+	}
+
+	migrations := map[int64]*Migration{}
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != ".sql" {
+			continue
+		}
+		matches := fileNamePattern.FindStringSubmatch(entry.Name())
+		if matches == nil {
+			return nil, fmt.Errorf("マイグレーションファイル名が不正です(<バージョン>_<名前>.up.sql の形式で指定してください): %s", entry.Name())
+		}
+		version, err := strconv.ParseInt(matches[1], 10, 64)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("マイグレーションのバージョンが不正です: %s", entry.Name())
+		}
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("マイグレーションファイルを読み込めません: %w", err)
+		}
+
+		migration, ok := migrations[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: matches[2]}
+			migrations[version] = migration
+		}
+		if migration.Name != matches[2] {
+			return nil, fmt.Errorf("マイグレーションのバージョンが重複しています: %d (%s / %s)", version, migration.Name, matches[2])
+		}
+		if matches[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	result := make([]Migration, 0, len(migrations))
+	for _, migration := range migrations {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("マイグレーション %s の up / down のファイルが揃っていません", migration.label())
+		}
+		result = append(result, *migration)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Version < result[j].Version
+	})
+	return result, nil
+}
+
+func (m Migration) label() string {
+	return fmt.Sprintf("%04d_%s", m.Version, m.Name)
+}
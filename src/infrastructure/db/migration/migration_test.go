@@ -0,0 +1,220 @@
+package migration_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"testing/fstest"
+
+	"github.com/Go_CleanArch/infrastructure/db/migration"
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var testDatabaseSeq atomic.Int64
+
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	// テストごとに独立したインメモリデータベースを使用する
+	dsn := fmt.Sprintf("file:migration_test_%d?mode=memory&cache=shared", testDatabaseSeq.Add(1))
+	gormDB, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	assert.NoError(t, err)
+	return gormDB
+}
+
+func testFiles() fstest.MapFS {
+	return fstest.MapFS{
+		"0001_create_notes.up.sql":     {Data: []byte("CREATE TABLE notes (note_id VARCHAR(36) NOT NULL PRIMARY KEY);")},
+		"0001_create_notes.down.sql":   {Data: []byte("DROP TABLE notes;")},
+		"0002_add_notes_body.up.sql":   {Data: []byte("ALTER TABLE notes ADD COLUMN body TEXT;\nCREATE INDEX notes_body_idx ON notes (body);")},
+		"0002_add_notes_body.down.sql": {Data: []byte("DROP INDEX notes_body_idx;\nALTER TABLE notes DROP COLUMN body;")},
+	}
+}
+
+func TestLoad(t *testing.T) {
+	t.Parallel()
+
+	t.Run("正常系_バージョンの昇順に読み込む", func(t *testing.T) {
+		t.Parallel()
+		migrations, err := migration.Load(testFiles())
+
+		assert.NoError(t, err)
+		assert.Len(t, migrations, 2)
+		assert.Equal(t, int64(1), migrations[0].Version)
+		assert.Equal(t, "create_notes", migrations[0].Name)
+		assert.Equal(t, int64(2), migrations[1].Version)
+		assert.Contains(t, migrations[1].Down, "DROP COLUMN body")
+	})
+
+	t.Run("正常系_組み込みのマイグレーション", func(t *testing.T) {
+		t.Parallel()
+		migrations, err := migration.Embedded()
+
+		assert.NoError(t, err)
+		assert.NotEmpty(t, migrations)
+		for i, m := range migrations {
+			assert.Equal(t, int64(i+1), m.Version, "バージョンは1からの連番とする")
+		}
+	})
+
+	t.Run("異常系_downのファイルが無い", func(t *testing.T) {
+		t.Parallel()
+		files := testFiles()
+		delete(files, "0002_add_notes_body.down.sql")
+		_, err := migration.Load(files)
+
+		assert.ErrorContains(t, err, "0002_add_notes_body")
+	})
+
+	t.Run("異常系_バージョンの重複", func(t *testing.T) {
+		t.Parallel()
+		files := testFiles()
+		files["0002_other.up.sql"] = &fstest.MapFile{Data: []byte("SELECT 1;")}
+		files["0002_other.down.sql"] = &fstest.MapFile{Data: []byte("SELECT 1;")}
+		_, err := migration.Load(files)
+
+		assert.ErrorContains(t, err, "重複")
+	})
+
+	t.Run("異常系_ファイル名が不正", func(t *testing.T) {
+		t.Parallel()
+		files := testFiles()
+		files["add_column.sql"] = &fstest.MapFile{Data: []byte("SELECT 1;")}
+		_, err := migration.Load(files)
+
+		assert.ErrorContains(t, err, "add_column.sql")
+	})
+}
+
+func TestMigrator(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	t.Run("正常系_未適用のマイグレーションのみ適用", func(t *testing.T) {
+		t.Parallel()
+		db := newTestDB(t)
+		migrations, _ := migration.Load(testFiles())
+
+		applied, err := migration.NewMigrator(db, migrations[:1]).Up(ctx)
+		assert.NoError(t, err)
+		assert.Len(t, applied, 1)
+
+		applied, err = migration.NewMigrator(db, migrations).Up(ctx)
+		assert.NoError(t, err)
+		assert.Len(t, applied, 1)
+		assert.Equal(t, int64(2), applied[0].Version)
+		assert.True(t, db.Migrator().HasColumn("notes", "body"))
+
+		// 再実行しても何も適用しない
+		applied, err = migration.NewMigrator(db, migrations).Up(ctx)
+		assert.NoError(t, err)
+		assert.Empty(t, applied)
+	})
+
+	t.Run("正常系_新しいものから取り消す", func(t *testing.T) {
+		t.Parallel()
+		db := newTestDB(t)
+		migrations, _ := migration.Load(testFiles())
+		migrator := migration.NewMigrator(db, migrations)
+		_, err := migrator.Up(ctx)
+		assert.NoError(t, err)
+
+		reverted, err := migrator.Down(ctx, 1)
+		assert.NoError(t, err)
+		assert.Len(t, reverted, 1)
+		assert.Equal(t, int64(2), reverted[0].Version)
+		assert.False(t, db.Migrator().HasColumn("notes", "body"))
+		assert.True(t, db.Migrator().HasTable("notes"))
+
+		reverted, err = migrator.Down(ctx, 5)
+		assert.NoError(t, err)
+		assert.Len(t, reverted, 1)
+		assert.False(t, db.Migrator().HasTable("notes"))
+	})
+
+	t.Run("正常系_適用状況", func(t *testing.T) {
+		t.Parallel()
+		db := newTestDB(t)
+		migrations, _ := migration.Load(testFiles())
+		_, err := migration.NewMigrator(db, migrations[:1]).Up(ctx)
+		assert.NoError(t, err)
+
+		statuses, err := migration.NewMigrator(db, migrations).Status(ctx)
+		assert.NoError(t, err)
+		assert.Len(t, statuses, 2)
+		assert.NotNil(t, statuses[0].AppliedAt)
+		assert.Nil(t, statuses[1].AppliedAt)
+
+		// ファイルが削除されたバージョンも表示する
+		statuses, err = migration.NewMigrator(db, nil).Status(ctx)
+		assert.NoError(t, err)
+		assert.Len(t, statuses, 1)
+		assert.True(t, statuses[0].Missing)
+	})
+
+	t.Run("異常系_失敗したマイグレーションは記録しない", func(t *testing.T) {
+		t.Parallel()
+		db := newTestDB(t)
+		files := testFiles()
+		files["0003_broken.up.sql"] = &fstest.MapFile{Data: []byte("CREATE TABLE tags (tag_id VARCHAR(36));\nINVALID SQL;")}
+		files["0003_broken.down.sql"] = &fstest.MapFile{Data: []byte("DROP TABLE tags;")}
+		migrations, _ := migration.Load(files)
+
+		applied, err := migration.NewMigrator(db, migrations).Up(ctx)
+		assert.ErrorContains(t, err, "0003_broken")
+		assert.Len(t, applied, 2)
+		assert.False(t, db.Migrator().HasTable("tags"))
+
+		statuses, err := migration.NewMigrator(db, migrations).Status(ctx)
+		assert.NoError(t, err)
+		assert.Nil(t, statuses[2].AppliedAt)
+	})
+
+	t.Run("異常系_取り消すファイルが無い", func(t *testing.T) {
+		t.Parallel()
+		db := newTestDB(t)
+		migrations, _ := migration.Load(testFiles())
+		_, err := migration.NewMigrator(db, migrations).Up(ctx)
+		assert.NoError(t, err)
+
+		_, err = migration.NewMigrator(db, migrations[:1]).Down(ctx, 1)
+		assert.ErrorIs(t, err, migration.ErrMissingFile)
+	})
+}
+
+func TestCreate(t *testing.T) {
+	t.Parallel()
+
+	t.Run("正常系_次のバージョンで作成", func(t *testing.T) {
+		t.Parallel()
+		dir := t.TempDir()
+		for name, file := range testFiles() {
+			assert.NoError(t, os.WriteFile(filepath.Join(dir, name), file.Data, 0o644))
+		}
+
+		paths, err := migration.Create(dir, "Add Notes Title")
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{
+			filepath.Join(dir, "0003_add_notes_title.up.sql"),
+			filepath.Join(dir, "0003_add_notes_title.down.sql"),
+		}, paths)
+		migrations, err := migration.Load(os.DirFS(dir))
+		assert.NoError(t, err)
+		assert.Len(t, migrations, 3)
+	})
+
+	t.Run("異常系_名前が不正", func(t *testing.T) {
+		t.Parallel()
+		_, err := migration.Create(t.TempDir(), "マイグレーション")
+
+		assert.Error(t, err)
+	})
+}
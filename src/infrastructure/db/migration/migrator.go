@@ -0,0 +1,191 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 複数のインスタンスが同時に起動した場合に、マイグレーションを1つずつ実行するためのアドバイザリロックのキー
+const advisoryLockKey int64 = 7_352_819_046
+
+const createVersionTableQuery = `CREATE TABLE IF NOT EXISTS schema_migrations (
+    version BIGINT NOT NULL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    applied_at TIMESTAMP NOT NULL
+)`
+
+var ErrMissingFile = errors.New("ロールバック対象のマイグレーションファイルが見つかりません")
+
+// Migrator は schema_migrations テーブルに適用済みのバージョンを記録し、スキーマ変更を適用・取り消す。
+// 各バージョンの変更は記録と同じトランザクションで実行するため、途中で失敗した場合は適用前の状態に戻る。
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+	now        func() time.Time
+}
+
+// Status は各バージョンの適用状況を表す。
+type Status struct {
+	Version   int64
+	Name      string
+	AppliedAt *time.Time
+	// データベースには適用済みだが、対応するファイルが存在しない
+	Missing bool
+}
+
+type appliedMigration struct {
+	Version   int64
+	Name      string
+	AppliedAt time.Time
+}
+
+func NewMigrator(db *gorm.DB, migrations []Migration) *Migrator {
+	return &Migrator{
+		db:         db,
+		migrations: migrations,
+		now:        time.Now,
+	}
+}
+
+// 未適用のマイグレーションをバージョンの昇順にすべて適用し、適用したものを返す
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	applied := []Migration{}
+	err := m.withLock(ctx, func(conn *gorm.DB) error {
+		versions, err := appliedVersions(conn)
+		if err != nil {
+			return err
+		}
+		for _, migration := range m.migrations {
+			if _, ok := versions[migration.Version]; ok {
+				continue
+			}
+			if err := conn.Transaction(func(tx *gorm.DB) error {
+				if err := tx.Exec(migration.Up).Error; err != nil {
+					return err
+				}
+				return tx.Exec("INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)",
+					migration.Version, migration.Name, m.now().UTC()).Error
+			}); err != nil {
+				return fmt.Errorf("マイグレーション %s の適用に失敗しました: %w", migration.label(), err)
+			}
+			applied = append(applied, migration)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// 適用済みのマイグレーションを新しいものから steps 件取り消し、取り消したものを返す
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	if steps <= 0 {
+		return nil, fmt.Errorf("取り消す件数は1以上で指定してください: %d", steps)
+	}
+
+	reverted := []Migration{}
+	err := m.withLock(ctx, func(conn *gorm.DB) error {
+		versions, err := appliedVersions(conn)
+		if err != nil {
+			return err
+		}
+		targets := make([]appliedMigration, 0, len(versions))
+		for _, version := range versions {
+			targets = append(targets, version)
+		}
+		sort.Slice(targets, func(i, j int) bool {
+			return targets[i].Version > targets[j].Version
+		})
+		if len(targets) > steps {
+			targets = targets[:steps]
+		}
+
+		for _, target := range targets {
+			migration, ok := m.find(target.Version)
+			if !ok {
+				return fmt.Errorf("%w: %04d_%s", ErrMissingFile, target.Version, target.Name)
+			}
+			if err := conn.Transaction(func(tx *gorm.DB) error {
+				if err := tx.Exec(migration.Down).Error; err != nil {
+					return err
+				}
+				return tx.Exec("DELETE FROM schema_migrations WHERE version = ?", migration.Version).Error
+			}); err != nil {
+				return fmt.Errorf("マイグレーション %s の取り消しに失敗しました: %w", migration.label(), err)
+			}
+			reverted = append(reverted, migration)
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// ファイルまたはデータベースに存在するバージョンを、昇順に適用状況とあわせて返す
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	statuses := []Status{}
+	err := m.withLock(ctx, func(conn *gorm.DB) error {
+		versions, err := appliedVersions(conn)
+		if err != nil {
+			return err
+		}
+		for _, migration := range m.migrations {
+			status := Status{Version: migration.Version, Name: migration.Name}
+			if applied, ok := versions[migration.Version]; ok {
+				appliedAt := applied.AppliedAt
+				status.AppliedAt = &appliedAt
+				delete(versions, migration.Version)
+			}
+			statuses = append(statuses, status)
+		}
+		for _, applied := range versions {
+			appliedAt := applied.AppliedAt
+			statuses = append(statuses, Status{Version: applied.Version, Name: applied.Name, AppliedAt: &appliedAt, Missing: true})
+		}
+		return nil
+	})
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Version < statuses[j].Version
+	})
+	return statuses, err
+}
+
+func (m *Migrator) find(version int64) (Migration, bool) {
+	for _, migration := range m.migrations {
+		if migration.Version == version {
+			return migration, true
+		}
+	}
+	return Migration{}, false
+}
+
+// 1つの接続上で適用状況を記録するテーブルを用意し、fn を実行する
+// PostgreSQL の場合はアドバイザリロックを取得し、他のインスタンスの処理が終わるまで待機する。
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *gorm.DB) error) error {
+	return m.db.WithContext(ctx).Connection(func(conn *gorm.DB) error {
+		if conn.Dialector.Name() == "postgres" {
+			if err := conn.Exec("SELECT pg_advisory_lock(?)", advisoryLockKey).Error; err != nil {
+				return fmt.Errorf("マイグレーションのロックを取得できません: %w", err)
+			}
+			defer conn.WithContext(context.Background()).Exec("SELECT pg_advisory_unlock(?)", advisoryLockKey)
+		}
+		if err := conn.Exec(createVersionTableQuery).Error; err != nil {
+			return fmt.Errorf("schema_migrations テーブルを作成できません: %w", err)
+		}
+		return fn(conn)
+	})
+}
+
+func appliedVersions(conn *gorm.DB) (map[int64]appliedMigration, error) {
+	var rows []appliedMigration
+	if err := conn.Raw("SELECT version, name, applied_at FROM schema_migrations").Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("適用済みのマイグレーションを取得できません: %w", err)
+	}
+	versions := make(map[int64]appliedMigration, len(rows))
+	for _, row := range rows {
+		versions[row.Version] = row
+	}
+	return versions, nil
+}
@@ -0,0 +1,16 @@
+-- ユーザー
+-- dump から作成した既存のデータベースにも適用できるよう、作成済みの場合は何もしない。
+CREATE TABLE IF NOT EXISTS users (
+    id SERIAL,
+    user_id VARCHAR(36) NOT NULL PRIMARY KEY,
+    user_name VARCHAR(60) NOT NULL,
+    password VARCHAR(255) NOT NULL,
+    email VARCHAR(40) NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP,
+    deleted_at TIMESTAMP
+);
+
+-- ユーザーIDをメールアドレス由来のハッシュ(CHAR(12))から、UUIDv7(36文字)を格納できる形式へ変更する。
+-- 既存レコードのIDはそのまま維持される(CHAR型の末尾空白は VARCHAR への変換時に除去される)。
+ALTER TABLE users ALTER COLUMN user_id TYPE VARCHAR(36);
@@ -0,0 +1,17 @@
+-- メールアドレス変更申請
+-- 確認・取消トークンはハッシュ値のみを保存する。
+CREATE TABLE IF NOT EXISTS email_change_requests (
+    email_change_request_id VARCHAR(36) NOT NULL PRIMARY KEY,
+    user_id VARCHAR(36) NOT NULL REFERENCES users (user_id),
+    current_email VARCHAR(40) NOT NULL,
+    new_email VARCHAR(40) NOT NULL,
+    confirm_token_hash CHAR(64) NOT NULL UNIQUE,
+    cancel_token_hash CHAR(64) NOT NULL UNIQUE,
+    expires_at TIMESTAMP NOT NULL,
+    confirmed_at TIMESTAMP,
+    cancelled_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS email_change_requests_user_id_idx ON email_change_requests (user_id);
@@ -0,0 +1,3 @@
+DROP TABLE privacy_audit_logs;
+DROP TABLE erasure_requests;
+DROP TABLE login_histories;
@@ -0,0 +1,33 @@
+-- ログイン履歴
+CREATE TABLE IF NOT EXISTS login_histories (
+    login_history_id VARCHAR(36) NOT NULL PRIMARY KEY,
+    user_id VARCHAR(36) NOT NULL REFERENCES users (user_id),
+    ip_address VARCHAR(45),
+    user_agent VARCHAR(512),
+    logged_in_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS login_histories_user_id_idx ON login_histories (user_id, logged_in_at DESC);
+
+-- 個人データ削除申請
+-- 確認トークンはハッシュ値のみを保存する。
+CREATE TABLE IF NOT EXISTS erasure_requests (
+    erasure_request_id VARCHAR(36) NOT NULL PRIMARY KEY,
+    user_id VARCHAR(36) NOT NULL REFERENCES users (user_id),
+    confirm_token_hash CHAR(64) NOT NULL UNIQUE,
+    expires_at TIMESTAMP NOT NULL,
+    confirmed_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP
+);
+
+-- 個人データの開示・削除請求の監査記録
+CREATE TABLE IF NOT EXISTS privacy_audit_logs (
+    privacy_audit_log_id VARCHAR(36) NOT NULL PRIMARY KEY,
+    user_id VARCHAR(36) NOT NULL REFERENCES users (user_id),
+    action VARCHAR(32) NOT NULL,
+    ip_address VARCHAR(45),
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS privacy_audit_logs_user_id_idx ON privacy_audit_logs (user_id, created_at DESC);
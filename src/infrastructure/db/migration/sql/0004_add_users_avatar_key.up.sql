@@ -0,0 +1,3 @@
+-- アバター画像の格納先キー(オブジェクトストレージ上のプレフィックス)
+-- 画像本体は storage.driver で指定したストレージに保存し、DBにはキーのみを保持する。
+ALTER TABLE users ADD COLUMN IF NOT EXISTS avatar_key VARCHAR(255);
@@ -0,0 +1,10 @@
+-- ユーザーの設定値(キー・バリュー形式)
+-- 値はJSONエンコードした文字列で保持し、型・既定値はアプリケーション側のスキーマで管理する。
+-- 未設定(既定値のまま)の項目はレコードを作成しない。
+CREATE TABLE IF NOT EXISTS user_settings (
+    user_id VARCHAR(36) NOT NULL REFERENCES users (user_id),
+    setting_key VARCHAR(64) NOT NULL,
+    setting_value TEXT NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, setting_key)
+);
@@ -0,0 +1,20 @@
+-- 組織(テナント)とユーザーの所属
+-- organization_id を持つテーブルは、アプリケーション側でテナントによる絞り込みが自動で付与される。
+CREATE TABLE IF NOT EXISTS organizations (
+    organization_id VARCHAR(36) NOT NULL PRIMARY KEY,
+    name VARCHAR(50) NOT NULL,
+    slug VARCHAR(32) NOT NULL UNIQUE,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS organization_memberships (
+    organization_id VARCHAR(36) NOT NULL REFERENCES organizations (organization_id),
+    user_id VARCHAR(36) NOT NULL REFERENCES users (user_id),
+    role VARCHAR(16) NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP,
+    PRIMARY KEY (organization_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS organization_memberships_user_id_idx ON organization_memberships (user_id);
@@ -0,0 +1,17 @@
+-- 組織への招待
+-- 招待トークンはハッシュ値のみを保存する。
+CREATE TABLE IF NOT EXISTS organization_invitations (
+    invitation_id VARCHAR(36) NOT NULL PRIMARY KEY,
+    organization_id VARCHAR(36) NOT NULL REFERENCES organizations (organization_id),
+    email VARCHAR(255) NOT NULL,
+    role VARCHAR(16) NOT NULL,
+    invited_by VARCHAR(36) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    expires_at TIMESTAMP NOT NULL,
+    accepted_at TIMESTAMP,
+    accepted_by VARCHAR(36),
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS organization_invitations_organization_id_idx ON organization_invitations (organization_id);
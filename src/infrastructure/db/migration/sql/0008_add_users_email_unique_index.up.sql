@@ -0,0 +1,5 @@
+-- メールアドレスの一意制約
+-- 大文字・小文字の違いは同一のアドレスとして扱うため、小文字に揃えたアドレスに一意インデックスを作成する。
+-- 削除(匿名化)済みのユーザーは対象外とする。
+-- 既存のデータベースに重複したアドレスがある場合は、事前に解消してから適用すること。
+CREATE UNIQUE INDEX IF NOT EXISTS users_email_key ON users (LOWER(email)) WHERE deleted_at IS NULL;
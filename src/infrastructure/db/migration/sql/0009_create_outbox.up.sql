@@ -0,0 +1,23 @@
+-- ドメインイベントのアウトボックス
+-- イベントは状態の変更と同じトランザクションで保存し、ディスパッチャーが非同期に購読者へ配信する。
+-- id は保存順を表し、同じ集約のイベントは id の順に配信する。
+CREATE TABLE IF NOT EXISTS outbox (
+    id BIGSERIAL PRIMARY KEY,
+    event_id VARCHAR(36) NOT NULL UNIQUE,
+    event_name VARCHAR(64) NOT NULL,
+    aggregate_type VARCHAR(32) NOT NULL,
+    aggregate_id VARCHAR(64) NOT NULL,
+    payload TEXT NOT NULL,
+    occurred_at TIMESTAMP NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL,
+    locked_until TIMESTAMP,
+    last_error TEXT,
+    published_at TIMESTAMP,
+    dead_lettered_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL
+);
+
+-- 配信待ちのイベントの検索・同じ集約の先行するイベントの確認に使用する
+CREATE INDEX IF NOT EXISTS outbox_pending_idx ON outbox (next_attempt_at) WHERE published_at IS NULL AND dead_lettered_at IS NULL;
+CREATE INDEX IF NOT EXISTS outbox_aggregate_idx ON outbox (aggregate_type, aggregate_id, id) WHERE published_at IS NULL AND dead_lettered_at IS NULL;
@@ -0,0 +1,18 @@
+-- データの変更履歴(監査ログ)
+-- 作成・更新・削除した行ごとに、変更と同じトランザクションで記録する。
+-- changes は変更した列ごとの変更前後の値(JSON)で、パスワード・個人情報の列は値を伏せる。
+CREATE TABLE IF NOT EXISTS audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    occurred_at TIMESTAMP NOT NULL,
+    actor_id VARCHAR(36),
+    request_id VARCHAR(64),
+    action VARCHAR(16) NOT NULL,
+    table_name VARCHAR(64) NOT NULL,
+    record_id VARCHAR(255) NOT NULL,
+    changes TEXT NOT NULL
+);
+
+-- 操作したユーザー・変更したレコード・日時による検索に使用する
+CREATE INDEX IF NOT EXISTS audit_log_actor_idx ON audit_log (actor_id, occurred_at);
+CREATE INDEX IF NOT EXISTS audit_log_record_idx ON audit_log (table_name, record_id, occurred_at);
+CREATE INDEX IF NOT EXISTS audit_log_occurred_at_idx ON audit_log (occurred_at);
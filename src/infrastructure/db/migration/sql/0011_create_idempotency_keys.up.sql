@@ -0,0 +1,19 @@
+-- Idempotency-Key を指定した POST リクエストとレスポンス
+-- 同じキーで再送されたリクエストには保存したレスポンスを返す。expires_at を過ぎたレコードは定期的に削除する。
+-- scope はキーを使用できる範囲(メソッド・パス・ユーザー・組織)を表し、他のユーザーのレスポンスを返さないようにする。
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    id BIGSERIAL PRIMARY KEY,
+    scope VARCHAR(255) NOT NULL,
+    idempotency_key VARCHAR(255) NOT NULL,
+    request_hash VARCHAR(64) NOT NULL,
+    response_status INTEGER,
+    response_content_type VARCHAR(255),
+    response_body TEXT,
+    locked_until TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+
+-- 同時に送信された同じキーのリクエストは、一意制約により1件のみ処理する
+CREATE UNIQUE INDEX IF NOT EXISTS idempotency_keys_key ON idempotency_keys (scope, idempotency_key);
+CREATE INDEX IF NOT EXISTS idempotency_keys_expires_at_idx ON idempotency_keys (expires_at);
@@ -0,0 +1,13 @@
+-- 暗号化した値は元の列の長さに収まらないため、事前に reencrypt -decrypt で復号してから適用すること。
+DROP INDEX organization_invitations_email_index_idx;
+ALTER TABLE organization_invitations DROP COLUMN email_index;
+ALTER TABLE organization_invitations ALTER COLUMN email TYPE VARCHAR(255);
+
+ALTER TABLE email_change_requests ALTER COLUMN new_email TYPE VARCHAR(40);
+ALTER TABLE email_change_requests ALTER COLUMN current_email TYPE VARCHAR(40);
+
+DROP INDEX users_email_index_key;
+ALTER TABLE users DROP COLUMN email_index;
+ALTER TABLE users ALTER COLUMN email TYPE VARCHAR(40);
+ALTER TABLE users ALTER COLUMN user_name TYPE VARCHAR(60);
+CREATE UNIQUE INDEX IF NOT EXISTS users_email_key ON users (LOWER(email)) WHERE deleted_at IS NULL;
@@ -0,0 +1,17 @@
+-- 個人情報(氏名・メールアドレス)の暗号化
+-- 暗号化した値は元の値より長くなるため、列の型を TEXT に変更する。
+-- 暗号化したメールアドレスは検索・一意制約に使用できないため、ブラインドインデックス(HMAC)の列を追加して一意制約を置き換える。
+-- 既存のレコードの暗号化・ブラインドインデックスの設定は、適用後に migrate up(起動時のマイグレーションを含む)が reencrypt.Backfill で行う。
+-- ブラインドインデックスが設定されていないレコードが残っている間は、ログイン・重複登録の確認ができないためサーバーは起動しない。
+ALTER TABLE users ALTER COLUMN user_name TYPE TEXT;
+ALTER TABLE users ALTER COLUMN email TYPE TEXT;
+ALTER TABLE users ADD COLUMN email_index CHAR(64);
+DROP INDEX users_email_key;
+CREATE UNIQUE INDEX users_email_index_key ON users (email_index) WHERE deleted_at IS NULL;
+
+ALTER TABLE email_change_requests ALTER COLUMN current_email TYPE TEXT;
+ALTER TABLE email_change_requests ALTER COLUMN new_email TYPE TEXT;
+
+ALTER TABLE organization_invitations ALTER COLUMN email TYPE TEXT;
+ALTER TABLE organization_invitations ADD COLUMN email_index CHAR(64);
+CREATE INDEX organization_invitations_email_index_idx ON organization_invitations (email_index);
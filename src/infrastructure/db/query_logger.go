@@ -0,0 +1,113 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Go_CleanArch/common/correlation"
+	"github.com/Go_CleanArch/infrastructure/config"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// queryLogger は実行したSQLを logrus に出力する gorm のロガー
+// SQLはパラメーターを伏せて(プレースホルダーのまま)出力し、リクエストID・ユーザーIDなどコンテキストの値を付与する。
+// 出力の範囲によらず、実行したSQLはフィンガープリントごとに集計する。
+type queryLogger struct {
+	level         logger.LogLevel
+	slowThreshold time.Duration
+	stats         *queryStatistics
+}
+
+func newQueryLogger(queryLogConfig config.QueryLogConfig) logger.Interface {
+	return &queryLogger{
+		level:         queryLogLevel(queryLogConfig.Level),
+		slowThreshold: queryLogConfig.SlowThreshold,
+		stats:         defaultQueryStatistics,
+	}
+}
+
+// 設定値に対応する gorm のログレベル(未指定の場合は warn)
+func queryLogLevel(level string) logger.LogLevel {
+	switch level {
+	case "silent":
+		return logger.Silent
+	case "error":
+		return logger.Error
+	case "info":
+		return logger.Info
+	default:
+		return logger.Warn
+	}
+}
+
+func (ql *queryLogger) LogMode(level logger.LogLevel) logger.Interface {
+	copied := *ql
+	copied.level = level
+	return &copied
+}
+
+// withoutStatements は実行したSQLを出力しない(エラー・スロークエリは出力する)ロガーを返す
+// 設定でエラーのみ・出力しないとしている場合は、設定を優先する。
+func withoutStatements(l logger.Interface) logger.Interface {
+	if ql, ok := l.(*queryLogger); ok && ql.level < logger.Warn {
+		return l
+	}
+	return l.LogMode(logger.Warn)
+}
+
+func (ql *queryLogger) Info(ctx context.Context, message string, data ...interface{}) {
+	if ql.level >= logger.Info {
+		log.WithFields(correlation.LogFields(ctx)).Infof(message, data...)
+	}
+}
+
+func (ql *queryLogger) Warn(ctx context.Context, message string, data ...interface{}) {
+	if ql.level >= logger.Warn {
+		log.WithFields(correlation.LogFields(ctx)).Warnf(message, data...)
+	}
+}
+
+func (ql *queryLogger) Error(ctx context.Context, message string, data ...interface{}) {
+	if ql.level >= logger.Error {
+		log.WithFields(correlation.LogFields(ctx)).Errorf(message, data...)
+	}
+}
+
+// ParamsFilter はログに出力するSQLからパラメーターを除く
+// gorm はこのメソッドを実装したロガーに対して、戻り値のパラメーターでSQLを組み立てる。
+func (ql *queryLogger) ParamsFilter(ctx context.Context, sql string, params ...interface{}) (string, []interface{}) {
+	return sql, nil
+}
+
+func (ql *queryLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	elapsed := time.Since(begin)
+	sql, rows := fc()
+	// 検索結果が無いことは呼び出し元で扱うため、失敗として扱わない
+	failed := err != nil && !errors.Is(err, gorm.ErrRecordNotFound)
+	ql.stats.record(sql, elapsed, failed)
+
+	if ql.level <= logger.Silent {
+		return
+	}
+	slow := ql.slowThreshold > 0 && elapsed > ql.slowThreshold
+	if !(failed && ql.level >= logger.Error) && !(slow && ql.level >= logger.Warn) && ql.level < logger.Info {
+		return
+	}
+
+	entry := log.WithFields(correlation.LogFields(ctx)).WithFields(log.Fields{
+		"sql":     sql,
+		"rows":    rows,
+		"elapsed": elapsed.String(),
+	})
+	switch {
+	case failed:
+		entry.WithError(err).Error("Query failed")
+	case slow:
+		entry.WithField("slowThreshold", ql.slowThreshold.String()).Warn("Slow query")
+	default:
+		entry.Info("Query executed")
+	}
+}
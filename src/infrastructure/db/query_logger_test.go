@@ -0,0 +1,232 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Go_CleanArch/common/auth"
+	"github.com/Go_CleanArch/common/correlation"
+	"github.com/Go_CleanArch/infrastructure/config"
+	"github.com/glebarez/sqlite"
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type loggedNote struct {
+	NoteId string `gorm:"primaryKey"`
+	Body   string
+}
+
+// newQueryLoggerTestDB は queryLogger と QueryTimeoutPlugin を設定したインメモリデータベースを返す
+// 集計はテストごとに独立させる。
+func newQueryLoggerTestDB(t *testing.T, queryLogConfig config.QueryLogConfig, queryTimeout time.Duration) (*gorm.DB, *queryStatistics) {
+	t.Helper()
+	stats := newQueryStatistics()
+	queryLogger := newQueryLogger(queryLogConfig).(*queryLogger)
+	queryLogger.stats = stats
+	dsn := fmt.Sprintf("file:query_logger_test_%d?mode=memory&cache=shared", testDatabaseSeq.Add(1))
+	gormDB, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: queryLogger})
+	assert.NoError(t, err)
+	assert.NoError(t, gormDB.AutoMigrate(&loggedNote{}))
+	assert.NoError(t, gormDB.Use(QueryTimeoutPlugin{Timeout: queryTimeout}))
+	return gormDB, stats
+}
+
+// リクエストIDが一致するログ(並列に実行する他のテストのログを除く)
+func entriesFor(hook *logtest.Hook, requestId string) []log.Entry {
+	entries := []log.Entry{}
+	for _, entry := range hook.AllEntries() {
+		if entry.Data["requestId"] == requestId {
+			entries = append(entries, *entry)
+		}
+	}
+	return entries
+}
+
+func TestQueryLogger(t *testing.T) {
+	t.Parallel()
+	hook := logtest.NewGlobal()
+
+	t.Run("正常系_パラメーターを伏せてリクエストの情報とともに出力する", func(t *testing.T) {
+		t.Parallel()
+		gormDB, _ := newQueryLoggerTestDB(t, config.QueryLogConfig{Level: "info"}, 0)
+		requestId := uuid.NewString()
+		ctx := auth.WithUserId(correlation.WithRequestId(context.Background(), requestId), "user-1")
+
+		assert.NoError(t, gormDB.WithContext(ctx).Create(&loggedNote{NoteId: "n-1", Body: "secret-body"}).Error)
+
+		entries := entriesFor(hook, requestId)
+		assert.Len(t, entries, 1)
+		assert.Equal(t, "Query executed", entries[0].Message)
+		assert.Equal(t, "user-1", entries[0].Data["userId"])
+		assert.Contains(t, entries[0].Data["sql"], "INSERT INTO `logged_notes`")
+		assert.NotContains(t, entries[0].Data["sql"], "secret-body")
+		assert.Equal(t, int64(1), entries[0].Data["rows"])
+	})
+
+	t.Run("正常系_warnではスロークエリのみ警告する", func(t *testing.T) {
+		t.Parallel()
+		gormDB, _ := newQueryLoggerTestDB(t, config.QueryLogConfig{Level: "warn", SlowThreshold: time.Hour}, 0)
+		requestId := uuid.NewString()
+		ctx := correlation.WithRequestId(context.Background(), requestId)
+
+		var notes []loggedNote
+		assert.NoError(t, gormDB.WithContext(ctx).Find(&notes).Error)
+		assert.Empty(t, entriesFor(hook, requestId))
+
+		slowDB := gormDB.Session(&gorm.Session{Logger: gormDB.Logger.LogMode(queryLogLevel("warn"))})
+		slowDB.Logger.(*queryLogger).slowThreshold = time.Nanosecond
+		assert.NoError(t, slowDB.WithContext(ctx).Where("body = ?", "secret-body").Find(&notes).Error)
+
+		entries := entriesFor(hook, requestId)
+		assert.Len(t, entries, 1)
+		assert.Equal(t, log.WarnLevel, entries[0].Level)
+		assert.Equal(t, "Slow query", entries[0].Message)
+		assert.NotContains(t, entries[0].Data["sql"], "secret-body")
+	})
+
+	t.Run("異常系_失敗したSQLはエラーとして出力し、見つからない場合は出力しない", func(t *testing.T) {
+		t.Parallel()
+		gormDB, stats := newQueryLoggerTestDB(t, config.QueryLogConfig{Level: "error"}, 0)
+		requestId := uuid.NewString()
+		ctx := correlation.WithRequestId(context.Background(), requestId)
+
+		var note loggedNote
+		assert.ErrorIs(t, gormDB.WithContext(ctx).First(&note, "note_id = ?", "missing").Error, gorm.ErrRecordNotFound)
+		assert.Error(t, gormDB.WithContext(ctx).Exec("SELECT * FROM missing_table").Error)
+
+		entries := entriesFor(hook, requestId)
+		assert.Len(t, entries, 1)
+		assert.Equal(t, log.ErrorLevel, entries[0].Level)
+		assert.Equal(t, "Query failed", entries[0].Message)
+		assert.Contains(t, entries[0].Data["sql"], "missing_table")
+		// 出力しない場合も集計する
+		failed := 0
+		for _, stat := range stats.snapshot() {
+			failed += int(stat.Errors)
+		}
+		assert.Equal(t, 1, failed)
+	})
+
+	t.Run("正常系_silentでは出力しない", func(t *testing.T) {
+		t.Parallel()
+		gormDB, stats := newQueryLoggerTestDB(t, config.QueryLogConfig{Level: "silent"}, 0)
+		requestId := uuid.NewString()
+		ctx := correlation.WithRequestId(context.Background(), requestId)
+
+		assert.Error(t, gormDB.WithContext(ctx).Exec("SELECT * FROM missing_table").Error)
+
+		assert.Empty(t, entriesFor(hook, requestId))
+		assert.NotEmpty(t, stats.snapshot())
+		// 実行したSQLの出力のみを抑止する場合も、設定を優先する
+		assert.Same(t, gormDB.Logger, withoutStatements(gormDB.Logger))
+	})
+}
+
+func TestQueryStatistics(t *testing.T) {
+	t.Parallel()
+
+	t.Run("正常系_フィンガープリントごとに実行時間の分布を集計する", func(t *testing.T) {
+		t.Parallel()
+		stats := newQueryStatistics()
+		stats.record("SELECT * FROM users WHERE user_id = $1", 3*time.Millisecond, false)
+		stats.record("SELECT * FROM users WHERE user_id = $1", 7*time.Millisecond, true)
+		stats.record("SELECT * FROM users WHERE user_id = $1", 10*time.Second, false)
+		stats.record("DELETE FROM outbox WHERE id = 1", time.Millisecond, false)
+
+		result := stats.snapshot()
+
+		assert.Len(t, result, 2)
+		// 合計の実行時間が長い順
+		assert.Equal(t, "SELECT * FROM users WHERE user_id = ?", result[0].Fingerprint)
+		assert.Equal(t, int64(3), result[0].Count)
+		assert.Equal(t, int64(1), result[0].Errors)
+		assert.Equal(t, "10.01s", result[0].TotalTime)
+		assert.Equal(t, "10s", result[0].MaxTime)
+		counts := map[string]int64{}
+		for _, bucket := range result[0].Histogram {
+			counts[bucket.UpperBound] = bucket.Count
+		}
+		assert.Equal(t, map[string]int64{"1ms": 0, "5ms": 1, "10ms": 1, "25ms": 0, "50ms": 0, "100ms": 0, "250ms": 0, "500ms": 0, "1s": 0, "2.5s": 0, "5s": 0, "+Inf": 1}, counts)
+		assert.Equal(t, int64(1), result[1].Histogram[0].Count)
+	})
+
+	t.Run("正常系_上限を超えたフィンガープリントはまとめる", func(t *testing.T) {
+		t.Parallel()
+		stats := newQueryStatistics()
+		for i := 0; i < maxFingerprints+5; i++ {
+			stats.record(fmt.Sprintf("SELECT * FROM table_%d", i), time.Millisecond, false)
+		}
+
+		result := stats.snapshot()
+
+		assert.Len(t, result, maxFingerprints+1)
+		for _, stat := range result {
+			if stat.Fingerprint == otherFingerprint {
+				assert.Equal(t, int64(5), stat.Count)
+			}
+		}
+	})
+}
+
+func TestFingerprint(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]string{
+		"SELECT * FROM \"users\" WHERE LOWER(email) = LOWER($1) AND \"deleted_at\" IS NULL ORDER BY \"users\".\"user_id\" LIMIT 1": "SELECT * FROM \"users\" WHERE LOWER(email) = LOWER(?) AND \"deleted_at\" IS NULL ORDER BY \"users\".\"user_id\" LIMIT ?",
+		"SELECT * FROM outbox WHERE id IN ($1,$2,$3)":                        "SELECT * FROM outbox WHERE id IN (?)",
+		"SELECT * FROM outbox WHERE id IN (?)":                               "SELECT * FROM outbox WHERE id IN (?)",
+		"INSERT INTO notes (a,b) VALUES ($1,$2),($3,$4)":                     "INSERT INTO notes (a,b) VALUES (?)",
+		"SELECT 1 FROM outbox\n\t WHERE aggregate_type = 'user' AND n = 1.5": "SELECT ? FROM outbox WHERE aggregate_type = ? AND n = ?",
+		"UPDATE t2 SET body = 'it''s' WHERE id = 10":                         "UPDATE t2 SET body = ? WHERE id = ?",
+	}
+	for sql, expected := range cases {
+		assert.Equal(t, expected, Fingerprint(sql), sql)
+	}
+}
+
+func TestQueryTimeoutPlugin(t *testing.T) {
+	t.Parallel()
+
+	t.Run("異常系_期限の無いコンテキストには上限を設定する", func(t *testing.T) {
+		t.Parallel()
+		gormDB, _ := newQueryLoggerTestDB(t, config.QueryLogConfig{Level: "silent"}, time.Nanosecond)
+
+		var notes []loggedNote
+		err := gormDB.WithContext(context.Background()).Find(&notes).Error
+
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("正常系_呼び出し元の期限を優先する", func(t *testing.T) {
+		t.Parallel()
+		gormDB, _ := newQueryLoggerTestDB(t, config.QueryLogConfig{Level: "silent"}, time.Nanosecond)
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		assert.NoError(t, gormDB.WithContext(ctx).Create(&loggedNote{NoteId: "n-1"}).Error)
+		var notes []loggedNote
+		assert.NoError(t, gormDB.WithContext(ctx).Find(&notes).Error)
+		assert.Len(t, notes, 1)
+	})
+
+	t.Run("正常系_実行後はコンテキストを戻す", func(t *testing.T) {
+		t.Parallel()
+		gormDB, _ := newQueryLoggerTestDB(t, config.QueryLogConfig{Level: "silent"}, time.Minute)
+		// 同じ *gorm.DB で続けて実行する
+		tx := gormDB.WithContext(context.Background()).Where("body = ?", "")
+
+		var notes []loggedNote
+		assert.NoError(t, tx.Find(&notes).Error)
+		assert.NoError(t, tx.Find(&notes).Error)
+		_, hasDeadline := tx.Statement.Context.Deadline()
+		assert.False(t, hasDeadline)
+		assert.False(t, strings.Contains(fmt.Sprint(tx.Statement.Context.Err()), "deadline"))
+	})
+}
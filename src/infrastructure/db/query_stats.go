@@ -0,0 +1,164 @@
+package db
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 集計するフィンガープリントの上限(超えた場合は otherFingerprint にまとめる)
+const maxFingerprints = 1000
+
+const otherFingerprint = "(other)"
+
+// 実行時間のヒストグラムの区切り(各区間の上限)
+var queryLatencyBuckets = []time.Duration{
+	time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+}
+
+// QueryStat はSQLの種類(フィンガープリント)ごとの実行回数・実行時間を表します
+type QueryStat struct {
+	Fingerprint string `json:"fingerprint"`
+	Count       int64  `json:"count"`
+	// 失敗した回数(検索結果が無い場合を除く)
+	Errors      int64  `json:"errors"`
+	TotalTime   string `json:"totalTime"`
+	AverageTime string `json:"averageTime"`
+	MaxTime     string `json:"maxTime"`
+	// 実行時間の分布(各区間の上限と回数、最後の区間は上限なし)
+	Histogram []LatencyBucket `json:"histogram"`
+}
+
+type LatencyBucket struct {
+	UpperBound string `json:"le"`
+	Count      int64  `json:"count"`
+}
+
+// queryStatistics は実行したSQLをフィンガープリントごとに集計します(複数のゴルーチンから同時に使用できる)
+type queryStatistics struct {
+	mu    sync.Mutex
+	stats map[string]*queryStatistic
+}
+
+type queryStatistic struct {
+	count   int64
+	errors  int64
+	total   time.Duration
+	max     time.Duration
+	buckets []int64
+}
+
+// アプリケーション全体で共有する集計
+var defaultQueryStatistics = newQueryStatistics()
+
+func newQueryStatistics() *queryStatistics {
+	return &queryStatistics{stats: map[string]*queryStatistic{}}
+}
+
+func (qs *queryStatistics) record(sql string, elapsed time.Duration, failed bool) {
+	fingerprint := Fingerprint(sql)
+	qs.mu.Lock()
+	defer qs.mu.Unlock()
+	stat, ok := qs.stats[fingerprint]
+	if !ok {
+		if len(qs.stats) >= maxFingerprints {
+			fingerprint = otherFingerprint
+			stat = qs.stats[fingerprint]
+		}
+		if stat == nil {
+			stat = &queryStatistic{buckets: make([]int64, len(queryLatencyBuckets)+1)}
+			qs.stats[fingerprint] = stat
+		}
+	}
+	stat.count++
+	if failed {
+		stat.errors++
+	}
+	stat.total += elapsed
+	if elapsed > stat.max {
+		stat.max = elapsed
+	}
+	stat.buckets[sort.Search(len(queryLatencyBuckets), func(i int) bool {
+		return elapsed <= queryLatencyBuckets[i]
+	})]++
+}
+
+// snapshot は集計結果を合計の実行時間が長い順に返します
+func (qs *queryStatistics) snapshot() []QueryStat {
+	qs.mu.Lock()
+	defer qs.mu.Unlock()
+	type entry struct {
+		fingerprint string
+		stat        queryStatistic
+	}
+	entries := make([]entry, 0, len(qs.stats))
+	for fingerprint, stat := range qs.stats {
+		entries = append(entries, entry{fingerprint: fingerprint, stat: *stat})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].stat.total != entries[j].stat.total {
+			return entries[i].stat.total > entries[j].stat.total
+		}
+		return entries[i].fingerprint < entries[j].fingerprint
+	})
+
+	result := make([]QueryStat, 0, len(entries))
+	for _, e := range entries {
+		histogram := make([]LatencyBucket, 0, len(e.stat.buckets))
+		for i, count := range e.stat.buckets {
+			upperBound := "+Inf"
+			if i < len(queryLatencyBuckets) {
+				upperBound = queryLatencyBuckets[i].String()
+			}
+			histogram = append(histogram, LatencyBucket{UpperBound: upperBound, Count: count})
+		}
+		result = append(result, QueryStat{
+			Fingerprint: e.fingerprint,
+			Count:       e.stat.count,
+			Errors:      e.stat.errors,
+			TotalTime:   e.stat.total.String(),
+			AverageTime: (e.stat.total / time.Duration(e.stat.count)).String(),
+			MaxTime:     e.stat.max.String(),
+			Histogram:   histogram,
+		})
+	}
+	return result
+}
+
+// QueryStats は起動してから実行したSQLの、フィンガープリントごとの集計結果を返します
+// 合計の実行時間が長い順に並べます。
+func QueryStats() []QueryStat {
+	return defaultQueryStatistics.snapshot()
+}
+
+var (
+	fingerprintPlaceholder = regexp.MustCompile(`\$\d+`)
+	fingerprintString      = regexp.MustCompile(`'(?:[^']|'')*'`)
+	fingerprintNumber      = regexp.MustCompile(`\b\d+(?:\.\d+)?\b`)
+	fingerprintList        = regexp.MustCompile(`\(\s*\?(?:\s*,\s*\?)*\s*\)`)
+	fingerprintRows        = regexp.MustCompile(`\(\?\)(?:\s*,\s*\(\?\))+`)
+	fingerprintSpace       = regexp.MustCompile(`\s+`)
+)
+
+// Fingerprint は値の違いを除いたSQLの種類を返します
+// プレースホルダー・文字列・数値を ? に置き換え、IN 句・複数行の VALUES の要素数の違いをまとめます。
+func Fingerprint(sql string) string {
+	fingerprint := fingerprintPlaceholder.ReplaceAllString(sql, "?")
+	fingerprint = fingerprintString.ReplaceAllString(fingerprint, "?")
+	fingerprint = fingerprintNumber.ReplaceAllString(fingerprint, "?")
+	fingerprint = fingerprintList.ReplaceAllString(fingerprint, "(?)")
+	fingerprint = fingerprintRows.ReplaceAllString(fingerprint, "(?)")
+	return strings.TrimSpace(fingerprintSpace.ReplaceAllString(fingerprint, " "))
+}
@@ -0,0 +1,80 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const queryTimeoutKey = "query_timeout:state"
+
+// QueryTimeoutPlugin は呼び出し元のコンテキストに期限が無い場合に、SQL 1件あたりの実行時間の上限を設定する。
+// Row / Rows / Scan は結果の読み取りが gorm の処理の後に行われるため対象外とする。
+type QueryTimeoutPlugin struct {
+	Timeout time.Duration
+}
+
+// queryTimeout は実行中のSQLに設定した期限
+type queryTimeout struct {
+	// 期限を設定する前のコンテキスト(実行後に戻す)
+	parent context.Context
+	cancel context.CancelFunc
+}
+
+func (QueryTimeoutPlugin) Name() string {
+	return "query_timeout"
+}
+
+func (p QueryTimeoutPlugin) Initialize(db *gorm.DB) error {
+	if p.Timeout <= 0 {
+		return nil
+	}
+	callback := db.Callback()
+	// 作成・更新・削除は gorm が開始するトランザクションも含めて期限を設定する
+	registrations := []error{
+		callback.Query().Before("gorm:query").Register("query_timeout:query_start", p.start),
+		callback.Query().After("gorm:after_query").Register("query_timeout:query_stop", stopQueryTimeout),
+		callback.Create().Before("gorm:begin_transaction").Register("query_timeout:create_start", p.start),
+		callback.Create().After("gorm:commit_or_rollback_transaction").Register("query_timeout:create_stop", stopQueryTimeout),
+		callback.Update().Before("gorm:begin_transaction").Register("query_timeout:update_start", p.start),
+		callback.Update().After("gorm:commit_or_rollback_transaction").Register("query_timeout:update_stop", stopQueryTimeout),
+		callback.Delete().Before("gorm:begin_transaction").Register("query_timeout:delete_start", p.start),
+		callback.Delete().After("gorm:commit_or_rollback_transaction").Register("query_timeout:delete_stop", stopQueryTimeout),
+		callback.Raw().Before("gorm:raw").Register("query_timeout:raw_start", p.start),
+		callback.Raw().After("gorm:raw").Register("query_timeout:raw_stop", stopQueryTimeout),
+	}
+	for _, err := range registrations {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p QueryTimeoutPlugin) start(db *gorm.DB) {
+	parent := db.Statement.Context
+	if parent == nil {
+		parent = context.Background()
+	}
+	if _, ok := parent.Deadline(); ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(parent, p.Timeout)
+	db.Statement.Context = ctx
+	db.InstanceSet(queryTimeoutKey, queryTimeout{parent: parent, cancel: cancel})
+}
+
+// stopQueryTimeout は期限を解除し、同じ *gorm.DB で続けて実行するSQLに期限切れのコンテキストが残らないようにする
+func stopQueryTimeout(db *gorm.DB) {
+	value, ok := db.InstanceGet(queryTimeoutKey)
+	if !ok {
+		return
+	}
+	timeout := value.(queryTimeout)
+	timeout.cancel()
+	db.Statement.Context = timeout.parent
+	// InstanceSet で保存した値を削除する(キーは gorm と同じ形式)
+	db.Statement.Settings.Delete(fmt.Sprintf("%p", db.Statement) + queryTimeoutKey)
+}
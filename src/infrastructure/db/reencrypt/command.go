@@ -0,0 +1,118 @@
+package reencrypt
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/Go_CleanArch/infrastructure/config"
+	dbConnect "github.com/Go_CleanArch/infrastructure/db"
+)
+
+// reencrypt サブコマンドの実行
+// go run main.go reencrypt [-batch-size 件数] [-decrypt]
+// 暗号化の鍵のローテーション後に実行し、すべてのレコードを暗号化に使用する鍵で暗号化し直す。
+// (0012_encrypt_personal_data の適用後の暗号化は migrate up・起動時のマイグレーションで Backfill により行う)
+// -decrypt を指定した場合は復号した値を保存する(0012_encrypt_personal_data を取り消す前に実行する)。
+func RunCommand(ctx context.Context, args []string, out io.Writer, loadConfig func() (*config.Config, error)) error {
+	flags := flag.NewFlagSet("reencrypt", flag.ContinueOnError)
+	flags.SetOutput(out)
+	batchSize := flags.Int("batch-size", DefaultBatchSize, "1回のトランザクションで処理するレコードの件数")
+	decrypt := flags.Bool("decrypt", false, "暗号化せずに復号した値を保存する")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() > 0 {
+		return fmt.Errorf("不明な引数です: %v", flags.Args())
+	}
+	if *batchSize <= 0 {
+		return fmt.Errorf("-batch-size は1以上で指定してください")
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	dbConnect.Init(cfg.Database)
+	fieldCipher, err := dbConnect.CurrentFieldCipher()
+	if err != nil {
+		return err
+	}
+	reencrypter, err := newReencrypter(ctx, fieldCipher, *batchSize, *decrypt)
+	if err != nil {
+		return err
+	}
+	return Run(ctx, reencrypter, out)
+}
+
+// Backfill はブラインドインデックスが設定されていないレコード(暗号化する前に保存したレコード)がある場合に、
+// すべてのレコードを暗号化に使用する鍵で暗号化し、ブラインドインデックスを設定します
+// マイグレーションの適用後に、共有の接続を確立(dbConnect.Init)してから呼び出します。
+func Backfill(ctx context.Context, out io.Writer) error {
+	reencrypter, err := sharedReencrypter(ctx)
+	if err != nil {
+		return err
+	}
+	pending, err := reencrypter.Pending(ctx)
+	if err != nil || pending == 0 {
+		return err
+	}
+	fmt.Fprintf(out, "ブラインドインデックスが設定されていないレコードが %d 件あるため、暗号化し直します\n", pending)
+	return Run(ctx, reencrypter, out)
+}
+
+// CheckBlindIndexes はブラインドインデックスが設定されていないレコードが無いことを確認します
+// 設定されていないユーザーはメールアドレスで検索できず(ログインできず)、同じメールアドレスでの登録も防げないため、起動時に確認します。
+func CheckBlindIndexes(ctx context.Context) error {
+	reencrypter, err := sharedReencrypter(ctx)
+	if err != nil {
+		return err
+	}
+	pending, err := reencrypter.Pending(ctx)
+	if err != nil {
+		return err
+	}
+	if pending > 0 {
+		return fmt.Errorf("ブラインドインデックスが設定されていないレコードが %d 件あります(migrate up または reencrypt を実行してください)", pending)
+	}
+	return nil
+}
+
+// sharedReencrypter は設定の鍵で暗号化する Reencrypter を返します
+func sharedReencrypter(ctx context.Context) (*Reencrypter, error) {
+	fieldCipher, err := dbConnect.CurrentFieldCipher()
+	if err != nil {
+		return nil, err
+	}
+	return newReencrypter(ctx, fieldCipher, DefaultBatchSize, false)
+}
+
+// newReencrypter は共有の接続を使用する Reencrypter を返します
+func newReencrypter(ctx context.Context, fieldCipher *dbConnect.FieldCipher, batchSize int, decrypt bool) (*Reencrypter, error) {
+	db, err := dbConnect.NewDBConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	transactionManager, err := dbConnect.NewTransactionManager(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return NewReencrypter(db, transactionManager, fieldCipher, batchSize, decrypt), nil
+}
+
+// Run はすべてのモデルのテーブルを処理し、結果を出力する
+func Run(ctx context.Context, reencrypter *Reencrypter, out io.Writer) error {
+	operation := fmt.Sprintf("鍵ID %s で暗号化", reencrypter.fieldCipher.ActiveKeyId())
+	if reencrypter.decrypt {
+		operation = "復号"
+	}
+	for _, model := range Models {
+		result, err := reencrypter.Run(ctx, model)
+		fmt.Fprintf(out, "%s: %d 件中 %d 件を%sしました\n", result.Table, result.Scanned, result.Updated, operation)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
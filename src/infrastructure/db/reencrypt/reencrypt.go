@@ -0,0 +1,244 @@
+// Package reencrypt は個人情報の列を設定の鍵で暗号化し直します
+// 暗号化する前に保存したレコードの暗号化・ブラインドインデックスの設定、鍵のローテーション後の再暗号化、
+// マイグレーションを取り消す前の復号に使用します。
+package reencrypt
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	dbConnect "github.com/Go_CleanArch/infrastructure/db"
+	"github.com/Go_CleanArch/interface_adapter/gateway/entity"
+	"gorm.io/gorm/schema"
+)
+
+// 1回のトランザクションで処理するレコードの既定の件数
+const DefaultBatchSize = 500
+
+// 暗号化する列を持つモデル(gorm:"serializer:encrypted"・gorm:"serializer:blindindex" を指定した列を処理する)
+var Models = []interface{}{
+	&entity.User{},
+	&entity.EmailChangeRequest{},
+	&entity.OrganizationInvitation{},
+	&entity.OutboxEvent{},
+	&entity.IdempotencyKey{},
+}
+
+// Result はテーブルごとの処理結果
+type Result struct {
+	Table string
+	// 確認したレコードの件数
+	Scanned int
+	// 暗号化(復号)し直したレコードの件数
+	Updated int
+}
+
+// Reencrypter は暗号化する列の値を、暗号化に使用する鍵で暗号化し直します
+type Reencrypter struct {
+	db                 *dbConnect.DBConnection
+	transactionManager *dbConnect.TransactionManager
+	fieldCipher        *dbConnect.FieldCipher
+	batchSize          int
+	// 暗号化せずに復号した値を保存する
+	decrypt bool
+}
+
+func NewReencrypter(db *dbConnect.DBConnection, transactionManager *dbConnect.TransactionManager, fieldCipher *dbConnect.FieldCipher, batchSize int, decrypt bool) *Reencrypter {
+	return &Reencrypter{
+		db:                 db,
+		transactionManager: transactionManager,
+		fieldCipher:        fieldCipher,
+		batchSize:          batchSize,
+		decrypt:            decrypt,
+	}
+}
+
+// target はテーブルの暗号化する列
+type target struct {
+	table      string
+	primaryKey string
+	columns    []string
+	// ブラインドインデックスの列と、計算に使用する列
+	blindIndexes map[string]string
+}
+
+func targetOf(model interface{}) (target, error) {
+	s, err := schema.Parse(model, &sync.Map{}, schema.NamingStrategy{})
+	if err != nil {
+		return target{}, err
+	}
+	if len(s.PrimaryFields) != 1 {
+		return target{}, fmt.Errorf("%s: 主キーが1列のテーブルのみ処理できます", s.Table)
+	}
+	t := target{table: s.Table, primaryKey: s.PrimaryFields[0].DBName, blindIndexes: map[string]string{}}
+	for _, field := range s.Fields {
+		switch field.Serializer.(type) {
+		case dbConnect.EncryptedSerializer:
+			t.columns = append(t.columns, field.DBName)
+		case dbConnect.BlindIndexSerializer:
+			source := s.LookUpField(field.TagSettings["BLINDINDEX"])
+			if source == nil {
+				return target{}, fmt.Errorf("%s.%s: ブラインドインデックスを計算するフィールドが見つかりません", s.Table, field.DBName)
+			}
+			t.blindIndexes[field.DBName] = source.DBName
+		}
+	}
+	return t, nil
+}
+
+// Run はモデルのテーブルのすべてのレコードを処理します
+// 主キーの順に batchSize 件ずつ、トランザクション内で更新します(中断した場合は再実行すると続きから処理します)
+func (r *Reencrypter) Run(ctx context.Context, model interface{}) (Result, error) {
+	t, err := targetOf(model)
+	if err != nil {
+		return Result{}, err
+	}
+	result := Result{Table: t.table}
+	columns := append([]string{t.primaryKey}, t.columns...)
+	for indexColumn := range t.blindIndexes {
+		columns = append(columns, indexColumn)
+	}
+
+	// モデルを指定しないため、テナントによる絞り込み・監査ログの記録は行わない
+	selectSql := fmt.Sprintf("SELECT %s FROM %s", strings.Join(columns, ", "), t.table)
+	var lastKey interface{}
+	for {
+		var rows []map[string]interface{}
+		err := r.transactionManager.RunInTransaction(ctx, func(ctx context.Context) error {
+			var err error
+			if lastKey == nil {
+				err = r.db.FindWithRawJoinQuery(ctx, fmt.Sprintf("%s ORDER BY %s LIMIT ?", selectSql, t.primaryKey), &rows, r.batchSize)
+			} else {
+				err = r.db.FindWithRawJoinQuery(ctx, fmt.Sprintf("%s WHERE %s > ? ORDER BY %s LIMIT ?", selectSql, t.primaryKey, t.primaryKey), &rows, lastKey, r.batchSize)
+			}
+			if err != nil {
+				return err
+			}
+			for _, row := range rows {
+				updates, err := r.updatesOf(t, row)
+				if err != nil {
+					return fmt.Errorf("%s(%s: %v): %w", t.table, t.primaryKey, row[t.primaryKey], err)
+				}
+				if len(updates) == 0 {
+					continue
+				}
+				if err := r.update(ctx, t, row[t.primaryKey], updates); err != nil {
+					return err
+				}
+				result.Updated++
+			}
+			return nil
+		})
+		if err != nil {
+			return result, err
+		}
+		result.Scanned += len(rows)
+		if len(rows) < r.batchSize {
+			return result, nil
+		}
+		lastKey = rows[len(rows)-1][t.primaryKey]
+	}
+}
+
+// Pending はモデルのテーブルのうち、ブラインドインデックスが設定されていないレコードの件数を返します
+// 計算に使用する列が空のレコードはブラインドインデックスを設定しないため、対象としません
+func (r *Reencrypter) Pending(ctx context.Context) (int64, error) {
+	var pending int64
+	for _, model := range Models {
+		t, err := targetOf(model)
+		if err != nil {
+			return 0, err
+		}
+		for indexColumn, sourceColumn := range t.blindIndexes {
+			var counts []struct{ Count int64 }
+			if err := r.db.FindWithRawJoinQuery(ctx, fmt.Sprintf(
+				"SELECT COUNT(*) AS count FROM %s WHERE %s IS NULL AND %s IS NOT NULL AND %s <> ''",
+				t.table, indexColumn, sourceColumn, sourceColumn,
+			), &counts); err != nil {
+				return 0, fmt.Errorf("%s: ブラインドインデックスが設定されていないレコードを数えられません: %w", t.table, err)
+			}
+			if len(counts) > 0 {
+				pending += counts[0].Count
+			}
+		}
+	}
+	return pending, nil
+}
+
+// update はレコードの列を更新します
+func (r *Reencrypter) update(ctx context.Context, t target, key interface{}, updates map[string]interface{}) error {
+	columns := make([]string, 0, len(updates))
+	for column := range updates {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+	assignments := make([]string, 0, len(columns))
+	args := make([]interface{}, 0, len(columns)+1)
+	for _, column := range columns {
+		assignments = append(assignments, column+" = ?")
+		args = append(args, updates[column])
+	}
+	_, err := r.db.Exec(ctx, fmt.Sprintf("UPDATE %s SET %s WHERE %s = ?", t.table, strings.Join(assignments, ", "), t.primaryKey), append(args, key)...)
+	return err
+}
+
+// updatesOf はレコードの更新が必要な列と値を返します
+func (r *Reencrypter) updatesOf(t target, row map[string]interface{}) (map[string]interface{}, error) {
+	updates := map[string]interface{}{}
+	plaintexts := map[string]string{}
+	for _, column := range t.columns {
+		// NULL の列(処理中の冪等キーのレスポンスなど)はそのままとする
+		if row[column] == nil {
+			continue
+		}
+		value := stringOf(row[column])
+		plaintext, err := r.fieldCipher.Decrypt(value)
+		if err != nil {
+			return nil, err
+		}
+		plaintexts[column] = plaintext
+
+		switch {
+		case r.decrypt:
+			if value != plaintext {
+				updates[column] = plaintext
+			}
+		case r.fieldCipher.NeedsReencryption(value):
+			encrypted, err := r.fieldCipher.Encrypt(plaintext)
+			if err != nil {
+				return nil, err
+			}
+			updates[column] = encrypted
+		}
+	}
+	for indexColumn, sourceColumn := range t.blindIndexes {
+		// 値が空の場合は NULL とする(BlindIndexSerializer と同じ)
+		index := ""
+		if plaintext := plaintexts[sourceColumn]; plaintext != "" {
+			index = r.fieldCipher.BlindIndex(plaintext)
+		}
+		if stringOf(row[indexColumn]) == index {
+			continue
+		}
+		if index == "" {
+			updates[indexColumn] = nil
+		} else {
+			updates[indexColumn] = index
+		}
+	}
+	return updates, nil
+}
+
+func stringOf(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,191 @@
+package reencrypt_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Go_CleanArch/infrastructure/config"
+	dbConnect "github.com/Go_CleanArch/infrastructure/db"
+	"github.com/Go_CleanArch/infrastructure/db/reencrypt"
+	"github.com/stretchr/testify/assert"
+)
+
+var testEncryptionConfig = config.EncryptionConfig{
+	Keys:          []string{"k1:MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="},
+	ActiveKeyId:   "k1",
+	BlindIndexKey: "ZmVkY2JhOTg3NjU0MzIxMGZlZGNiYTk4NzY1NDMyMTA=",
+}
+
+// メモリ上の SQLite を共有の接続として使用する
+// すべてのレコードを処理するため、テストは順に実行する
+func TestMain(m *testing.M) {
+	dbConnect.Init(config.DatabaseConfig{
+		Driver:     dbConnect.DriverSqlite,
+		SqlitePath: ":memory:",
+		HealthCheck: config.HealthCheckConfig{
+			Interval:         time.Minute,
+			Timeout:          time.Second,
+			FailureThreshold: 3,
+		},
+		Encryption: testEncryptionConfig,
+	})
+	os.Exit(m.Run())
+}
+
+type storedRow struct {
+	UserName     string
+	Email        string
+	EmailIndex   *string
+	CurrentEmail string
+	NewEmail     string
+}
+
+func storedUser(t *testing.T, db *dbConnect.DBConnection) storedRow {
+	t.Helper()
+	var rows []storedRow
+	assert.NoError(t, db.FindWithRawJoinQuery(context.Background(), `
+SELECT u.user_name, u.email, u.email_index, r.current_email, r.new_email
+FROM users u INNER JOIN email_change_requests r ON r.user_id = u.user_id
+WHERE u.user_id = ?`, &rows, "user-1"))
+	assert.Len(t, rows, 1)
+	return rows[0]
+}
+
+func storedInvitation(t *testing.T, db *dbConnect.DBConnection) storedRow {
+	t.Helper()
+	var rows []storedRow
+	assert.NoError(t, db.FindWithRawJoinQuery(context.Background(), "SELECT email, email_index FROM organization_invitations WHERE invitation_id = ?", &rows, "invitation-1"))
+	assert.Len(t, rows, 1)
+	return rows[0]
+}
+
+func TestReencrypter(t *testing.T) {
+	ctx := context.Background()
+	db, err := dbConnect.NewDBConnection(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	// 暗号化する前に保存したレコード
+	statements := []struct {
+		query string
+		args  []interface{}
+	}{
+		{"INSERT INTO users (user_id, user_name, password, email, created_at) VALUES (?, ?, ?, ?, ?)", []interface{}{"user-1", "山田 太郎", "hashed", "Yamada@example.com", now}},
+		{"INSERT INTO email_change_requests (email_change_request_id, user_id, current_email, new_email, confirm_token_hash, cancel_token_hash, expires_at, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)", []interface{}{"request-1", "user-1", "Yamada@example.com", "new@example.com", "confirm", "cancel", now, now}},
+		{"INSERT INTO organizations (organization_id, name, slug, created_at) VALUES (?, ?, ?, ?)", []interface{}{"organization-1", "組織", "org", now}},
+		{"INSERT INTO organization_invitations (invitation_id, organization_id, email, role, invited_by, token_hash, expires_at, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)", []interface{}{"invitation-1", "organization-1", "yamada@example.com", "member", "user-1", "token", now, now}},
+		{"INSERT INTO outbox (event_id, event_name, aggregate_type, aggregate_id, payload, occurred_at, next_attempt_at, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)", []interface{}{"event-1", "UserRegistered", "user", "user-1", `{"email":"Yamada@example.com"}`, now, now, now}},
+		// 処理中のキー(レスポンスは NULL)と、レスポンスを保存したキー
+		{"INSERT INTO idempotency_keys (scope, idempotency_key, request_hash, expires_at, created_at) VALUES (?, ?, ?, ?, ?)", []interface{}{"POST /api/users", "key-1", "hash", now, now}},
+		{"INSERT INTO idempotency_keys (scope, idempotency_key, request_hash, response_status, response_body, expires_at, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)", []interface{}{"POST /api/users", "key-2", "hash", 201, `{"email":"Yamada@example.com"}`, now, now}},
+	}
+	for _, statement := range statements {
+		if _, err := db.Exec(ctx, statement.query, statement.args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	transactionManager, err := dbConnect.NewTransactionManager(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fieldCipher, err := dbConnect.CurrentFieldCipher()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("異常系_ブラインドインデックスが設定されていないレコードがある場合は起動しない", func(t *testing.T) {
+		pending, err := reencrypt.NewReencrypter(db, transactionManager, fieldCipher, 1, false).Pending(ctx)
+
+		assert.NoError(t, err)
+		// ユーザー・招待のメールアドレス
+		assert.Equal(t, int64(2), pending)
+		assert.ErrorContains(t, reencrypt.CheckBlindIndexes(ctx), "設定されていないレコードが 2 件あります")
+	})
+
+	t.Run("正常系_暗号化されていない値を暗号化し、ブラインドインデックスを設定する", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		assert.NoError(t, reencrypt.Run(ctx, reencrypt.NewReencrypter(db, transactionManager, fieldCipher, 1, false), out))
+
+		assert.Contains(t, out.String(), "users: 1 件中 1 件を鍵ID k1 で暗号化しました")
+		assert.Contains(t, out.String(), "email_change_requests: 1 件中 1 件を")
+		assert.Contains(t, out.String(), "organization_invitations: 1 件中 1 件を")
+		user := storedUser(t, db)
+		for _, value := range []string{user.UserName, user.Email, user.CurrentEmail, user.NewEmail} {
+			assert.True(t, strings.HasPrefix(value, "enc:v1:k1:"))
+		}
+		assert.Equal(t, fieldCipher.BlindIndex("yamada@example.com"), *user.EmailIndex)
+		invitation := storedInvitation(t, db)
+		assert.True(t, strings.HasPrefix(invitation.Email, "enc:v1:k1:"))
+		assert.Contains(t, out.String(), "outbox: 1 件中 1 件を")
+		assert.Contains(t, out.String(), "idempotency_keys: 2 件中 1 件を")
+		var payloads []struct{ Payload string }
+		assert.NoError(t, db.FindWithRawJoinQuery(ctx, "SELECT payload FROM outbox WHERE event_id = ?", &payloads, "event-1"))
+		assert.True(t, strings.HasPrefix(payloads[0].Payload, "enc:v1:k1:"))
+		var responses []struct{ ResponseBody *string }
+		assert.NoError(t, db.FindWithRawJoinQuery(ctx, "SELECT response_body FROM idempotency_keys ORDER BY idempotency_key", &responses))
+		// 処理中のキーのレスポンスは NULL のままとする
+		assert.Nil(t, responses[0].ResponseBody)
+		assert.True(t, strings.HasPrefix(*responses[1].ResponseBody, "enc:v1:k1:"))
+		// 大文字・小文字の違いによらず、ユーザーと招待を照合できる
+		assert.Equal(t, *user.EmailIndex, *invitation.EmailIndex)
+		assert.NoError(t, reencrypt.CheckBlindIndexes(ctx))
+	})
+
+	t.Run("正常系_ブラインドインデックスが設定されていないレコードがある場合のみ暗号化し直す", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		assert.NoError(t, reencrypt.Backfill(ctx, out))
+		assert.Empty(t, out.String())
+
+		// マイグレーションの適用直後と同様に、ブラインドインデックスを削除する
+		_, err := db.Exec(ctx, "UPDATE users SET email_index = NULL WHERE user_id = ?", "user-1")
+		assert.NoError(t, err)
+
+		assert.NoError(t, reencrypt.Backfill(ctx, out))
+
+		assert.Contains(t, out.String(), "設定されていないレコードが 1 件ある")
+		assert.Equal(t, fieldCipher.BlindIndex("yamada@example.com"), *storedUser(t, db).EmailIndex)
+		assert.NoError(t, reencrypt.CheckBlindIndexes(ctx))
+	})
+
+	t.Run("正常系_暗号化に使用する鍵で暗号化済みの場合は更新しない", func(t *testing.T) {
+		before := storedUser(t, db)
+		out := &bytes.Buffer{}
+		assert.NoError(t, reencrypt.Run(ctx, reencrypt.NewReencrypter(db, transactionManager, fieldCipher, reencrypt.DefaultBatchSize, false), out))
+
+		assert.Contains(t, out.String(), "users: 1 件中 0 件を")
+		assert.Equal(t, before, storedUser(t, db))
+	})
+
+	t.Run("正常系_ローテーション後の鍵で暗号化し直す", func(t *testing.T) {
+		rotatedConfig := testEncryptionConfig
+		rotatedConfig.Keys = append([]string{"k2:ZmVkY2JhOTg3NjU0MzIxMGZlZGNiYTk4NzY1NDMyMTA="}, testEncryptionConfig.Keys...)
+		rotatedConfig.ActiveKeyId = "k2"
+		rotated, err := dbConnect.NewFieldCipher(rotatedConfig)
+		assert.NoError(t, err)
+
+		assert.NoError(t, reencrypt.Run(ctx, reencrypt.NewReencrypter(db, transactionManager, rotated, reencrypt.DefaultBatchSize, false), &bytes.Buffer{}))
+
+		user := storedUser(t, db)
+		assert.True(t, strings.HasPrefix(user.Email, "enc:v1:k2:"))
+		email, err := rotated.Decrypt(user.Email)
+		assert.NoError(t, err)
+		assert.Equal(t, "Yamada@example.com", email)
+		assert.True(t, strings.HasPrefix(storedInvitation(t, db).Email, "enc:v1:k2:"))
+
+		// マイグレーションを取り消す前に復号する
+		out := &bytes.Buffer{}
+		assert.NoError(t, reencrypt.Run(ctx, reencrypt.NewReencrypter(db, transactionManager, rotated, reencrypt.DefaultBatchSize, true), out))
+
+		assert.Contains(t, out.String(), "users: 1 件中 1 件を復号しました")
+		user = storedUser(t, db)
+		assert.Equal(t, "山田 太郎", user.UserName)
+		assert.Equal(t, "Yamada@example.com", user.Email)
+		assert.Equal(t, "new@example.com", user.NewEmail)
+		assert.Equal(t, "yamada@example.com", storedInvitation(t, db).Email)
+	})
+}
@@ -0,0 +1,98 @@
+package db
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/Go_CleanArch/infrastructure/config"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const primaryKey contextKey = "readFromPrimary"
+
+// WithPrimary は読み取りもプライマリで行うコンテキストを返します
+// 書き込んだ内容を直後に読み取る場合など、レプリカの反映遅延を避けたい処理で使用します
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey, true)
+}
+
+func readsFromPrimary(ctx context.Context) bool {
+	primary, _ := ctx.Value(primaryKey).(bool)
+	return primary
+}
+
+// replica は読み取り専用のレプリカへの接続
+// 接続できない場合・死活監視で異常を検知した場合は振り分けの対象外とし、死活監視で接続を再試行します
+// (連続して失敗した場合は、プライマリと同様に接続を開き直して差し替えます)
+type replica struct {
+	dbConfig config.DatabaseConfig
+	db       atomic.Pointer[gorm.DB]
+	healthy  atomic.Bool
+	// 直近の疎通確認のエラー
+	lastError atomic.Value // string
+	// 連続して疎通確認に失敗した回数
+	consecutiveFailures atomic.Int64
+	// 接続を開き直した回数
+	reconnects atomic.Int64
+}
+
+type replicaSet struct {
+	replicas []*replica
+	next     atomic.Uint64
+}
+
+var replicaConnections atomic.Pointer[replicaSet]
+
+// establishReplicaConnections はレプリカへの接続を開きます
+// 接続できないレプリカがあっても起動は継続し、読み取りはその他のレプリカまたはプライマリで行います
+func establishReplicaConnections(dbConfigs []config.DatabaseConfig, open func(dbConfig config.DatabaseConfig) (*gorm.DB, error)) {
+	set := &replicaSet{}
+	for _, dbConfig := range dbConfigs {
+		r := &replica{dbConfig: dbConfig}
+		r.lastError.Store("")
+		if newDb, err := open(dbConfig); err != nil {
+			log.WithError(err).WithField("host", dbConfig.Host).Warn("Failed to connect to the database replica")
+			r.lastError.Store(err.Error())
+		} else {
+			r.db.Store(newDb)
+			r.healthy.Store(true)
+		}
+		set.replicas = append(set.replicas, r)
+	}
+	replicaConnections.Store(set)
+}
+
+// pick はラウンドロビンで正常なレプリカを選択します(正常なレプリカが無い場合は nil)
+func (s *replicaSet) pick() *gorm.DB {
+	count := uint64(len(s.replicas))
+	if count == 0 {
+		return nil
+	}
+	start := s.next.Add(1)
+	for i := uint64(0); i < count; i++ {
+		r := s.replicas[(start+i)%count]
+		if !r.healthy.Load() {
+			continue
+		}
+		if replicaDb := r.db.Load(); replicaDb != nil {
+			return replicaDb
+		}
+	}
+	return nil
+}
+
+// readReplica は読み取りに使用するレプリカを返します(使用できない場合は nil)
+func readReplica(ctx context.Context) *gorm.DB {
+	if readsFromPrimary(ctx) {
+		return nil
+	}
+	if InTransaction(ctx) {
+		return nil
+	}
+	set := replicaConnections.Load()
+	if set == nil {
+		return nil
+	}
+	return set.pick()
+}
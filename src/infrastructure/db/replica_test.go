@@ -0,0 +1,163 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Go_CleanArch/infrastructure/config"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// 接続先ごとに異なるレコードを登録し、どの接続で読み取ったか判別できるようにする
+func openReplicaTestDB(t *testing.T, body string) *gorm.DB {
+	t.Helper()
+	gormDB := openHealthTestDB(t)
+	assert.NoError(t, gormDB.Create(&globalNote{NoteId: "n-1", Body: body}).Error)
+	return gormDB
+}
+
+func replicaTestOpen(dbs map[string]*gorm.DB) func(dbConfig config.DatabaseConfig) (*gorm.DB, error) {
+	return func(dbConfig config.DatabaseConfig) (*gorm.DB, error) {
+		if gormDB, ok := dbs[dbConfig.Host]; ok {
+			return gormDB, nil
+		}
+		return nil, errors.New("connection refused")
+	}
+}
+
+func readNote(t *testing.T, ctx context.Context, dbConnect *DBConnection) string {
+	t.Helper()
+	var note globalNote
+	assert.NoError(t, dbConnect.Find(ctx, "note_id = ?", []interface{}{"n-1"}, &note))
+	return note.Body
+}
+
+// 共有の接続・レプリカを差し替えるため、このテストは並列に実行しない
+func TestReplicaRouting(t *testing.T) {
+	ctx := context.Background()
+
+	setup := func(t *testing.T, hosts ...string) map[string]*gorm.DB {
+		t.Helper()
+		storeConnection(openReplicaTestDB(t, "primary"))
+		dbs := map[string]*gorm.DB{}
+		configs := []config.DatabaseConfig{}
+		for _, host := range hosts {
+			dbs[host] = openReplicaTestDB(t, host)
+			configs = append(configs, config.DatabaseConfig{Host: host})
+		}
+		establishReplicaConnections(configs, replicaTestOpen(dbs))
+		return dbs
+	}
+
+	t.Run("正常系_読み取りはレプリカへ振り分ける", func(t *testing.T) {
+		setup(t, "replica-1", "replica-2")
+		dbConnect, err := NewDBConnection(ctx, ReadFromReplica())
+		assert.NoError(t, err)
+
+		read := map[string]int{}
+		for i := 0; i < 4; i++ {
+			read[readNote(t, ctx, dbConnect)]++
+		}
+
+		assert.Equal(t, map[string]int{"replica-1": 2, "replica-2": 2}, read)
+	})
+
+	t.Run("正常系_書き込みはプライマリで行う", func(t *testing.T) {
+		dbs := setup(t, "replica-1")
+		dbConnect, err := NewDBConnection(ctx, ReadFromReplica())
+		assert.NoError(t, err)
+
+		assert.NoError(t, dbConnect.Create(ctx, &globalNote{NoteId: "n-2"}))
+
+		var count int64
+		assert.NoError(t, currentDB().Model(&globalNote{}).Where("note_id = ?", "n-2").Count(&count).Error)
+		assert.Equal(t, int64(1), count)
+		assert.NoError(t, dbs["replica-1"].Model(&globalNote{}).Where("note_id = ?", "n-2").Count(&count).Error)
+		assert.Equal(t, int64(0), count)
+	})
+
+	t.Run("正常系_プライマリで読み取る場合", func(t *testing.T) {
+		setup(t, "replica-1")
+		replicaConnect, err := NewDBConnection(ctx, ReadFromReplica())
+		assert.NoError(t, err)
+		primaryConnect, err := NewDBConnection(ctx)
+		assert.NoError(t, err)
+
+		// 振り分けを指定していない場合
+		assert.Equal(t, "primary", readNote(t, ctx, primaryConnect))
+		// WithPrimary を指定したコンテキスト
+		assert.Equal(t, "primary", readNote(t, WithPrimary(ctx), replicaConnect))
+		// トランザクション中
+		transactionManager := &TransactionManager{}
+		assert.NoError(t, transactionManager.RunInTransaction(ctx, func(ctx context.Context) error {
+			assert.Equal(t, "primary", readNote(t, ctx, replicaConnect))
+			return nil
+		}))
+	})
+
+	t.Run("異常系_異常なレプリカは振り分けの対象外", func(t *testing.T) {
+		dbs := setup(t, "replica-1", "replica-2")
+		closeConnection(dbs["replica-1"])
+		newHealthProber(healthTestConfig(), replicaTestOpen(dbs)).probeReplicas(ctx)
+		dbConnect, err := NewDBConnection(ctx, ReadFromReplica())
+		assert.NoError(t, err)
+
+		for i := 0; i < 3; i++ {
+			assert.Equal(t, "replica-2", readNote(t, ctx, dbConnect))
+		}
+		replicas := Health().Replicas
+		assert.False(t, replicas[0].Healthy)
+		assert.NotEmpty(t, replicas[0].LastError)
+		assert.True(t, replicas[1].Healthy)
+	})
+
+	t.Run("異常系_正常なレプリカが無い場合はプライマリで読み取る", func(t *testing.T) {
+		dbs := setup(t, "replica-1")
+		closeConnection(dbs["replica-1"])
+		newHealthProber(healthTestConfig(), replicaTestOpen(dbs)).probeReplicas(ctx)
+		dbConnect, err := NewDBConnection(ctx, ReadFromReplica())
+		assert.NoError(t, err)
+
+		assert.Equal(t, "primary", readNote(t, ctx, dbConnect))
+	})
+
+	t.Run("正常系_失敗が閾値に達したレプリカは接続を差し替え", func(t *testing.T) {
+		dbs := setup(t, "replica-1")
+		broken := dbs["replica-1"]
+		closeConnection(broken)
+		dbs["replica-1"] = openReplicaTestDB(t, "reconnected")
+		p := newHealthProber(healthTestConfig(), replicaTestOpen(dbs))
+		dbConnect, err := NewDBConnection(ctx, ReadFromReplica())
+		assert.NoError(t, err)
+
+		// 失敗が閾値未満の場合は接続を維持し、プライマリで読み取る
+		p.probeReplicas(ctx)
+		assert.Equal(t, "primary", readNote(t, ctx, dbConnect))
+		assert.Equal(t, 1, Health().Replicas[0].ConsecutiveFailures)
+
+		p.probeReplicas(ctx)
+
+		assert.Equal(t, "reconnected", readNote(t, ctx, dbConnect))
+		replica := Health().Replicas[0]
+		assert.True(t, replica.Healthy)
+		assert.Equal(t, 0, replica.ConsecutiveFailures)
+		assert.Equal(t, 1, replica.Reconnects)
+	})
+
+	t.Run("正常系_起動時に接続できなかったレプリカは死活監視で再接続", func(t *testing.T) {
+		storeConnection(openReplicaTestDB(t, "primary"))
+		dbs := map[string]*gorm.DB{}
+		establishReplicaConnections([]config.DatabaseConfig{{Host: "replica-1"}}, replicaTestOpen(dbs))
+		dbConnect, err := NewDBConnection(ctx, ReadFromReplica())
+		assert.NoError(t, err)
+		assert.Equal(t, "primary", readNote(t, ctx, dbConnect))
+
+		dbs["replica-1"] = openReplicaTestDB(t, "replica-1")
+		newHealthProber(healthTestConfig(), replicaTestOpen(dbs)).probeReplicas(ctx)
+
+		assert.Equal(t, "replica-1", readNote(t, ctx, dbConnect))
+		assert.True(t, Health().Replicas[0].Healthy)
+	})
+}
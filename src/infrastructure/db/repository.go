@@ -0,0 +1,259 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	repository "github.com/Go_CleanArch/usecase/repository_interface"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Repository は T のテーブルに対する型安全なデータ操作を提供します
+// 接続の選択(トランザクション・レプリカ)とテナントによる絞り込みは DBConnection と同様に行われ、
+// すべての操作に呼び出し元のコンテキストを適用します
+type Repository[T any] struct {
+	dbConnect *DBConnection
+}
+
+func NewRepository[T any](dbConnect *DBConnection) *Repository[T] {
+	return &Repository[T]{dbConnect: dbConnect}
+}
+
+// Condition は検索・件数の取得・削除の条件を表します
+type Condition func(tx *gorm.DB) *gorm.DB
+
+// Where は条件式で絞り込みます
+func Where(query string, args ...interface{}) Condition {
+	return func(tx *gorm.DB) *gorm.DB {
+		return tx.Where(query, args...)
+	}
+}
+
+// Eq は列の値が一致するレコードに絞り込みます(value が nil の場合は IS NULL)
+func Eq(column string, value interface{}) Condition {
+	return func(tx *gorm.DB) *gorm.DB {
+		return tx.Where(clause.Eq{Column: clause.Column{Name: column}, Value: value})
+	}
+}
+
+// In は列の値がいずれかに一致するレコードに絞り込みます
+func In[V any](column string, values []V) Condition {
+	in := make([]interface{}, len(values))
+	for i, value := range values {
+		in[i] = value
+	}
+	return func(tx *gorm.DB) *gorm.DB {
+		return tx.Where(clause.IN{Column: clause.Column{Name: column}, Values: in})
+	}
+}
+
+// IsNull は列の値が NULL のレコードに絞り込みます
+func IsNull(column string) Condition {
+	return Eq(column, nil)
+}
+
+// OrderBy は列の昇順に並べます
+func OrderBy(column string) Condition {
+	return order(column, false)
+}
+
+// OrderByDesc は列の降順に並べます
+func OrderByDesc(column string) Condition {
+	return order(column, true)
+}
+
+// Limit は最大 n 件に絞り込みます
+func Limit(n int) Condition {
+	return func(tx *gorm.DB) *gorm.DB {
+		return tx.Limit(n)
+	}
+}
+
+func order(column string, desc bool) Condition {
+	return func(tx *gorm.DB) *gorm.DB {
+		return tx.Order(clause.OrderByColumn{Column: clause.Column{Name: column}, Desc: desc})
+	}
+}
+
+// Page はページ番号(1始まり)と1ページあたりの件数を表します
+type Page struct {
+	Number int
+	Size   int
+}
+
+// PageResult は1ページ分のレコードと条件に一致する全体の件数を表します
+type PageResult[T any] struct {
+	Items  []T   `json:"items"`
+	Total  int64 `json:"total"`
+	Number int   `json:"number"`
+	Size   int   `json:"size"`
+}
+
+func (page Page) validate() error {
+	if page.Number < 1 {
+		return fmt.Errorf("ページ番号は1以上を指定してください: %d", page.Number)
+	}
+	if page.Size < 1 {
+		return fmt.Errorf("1ページあたりの件数は1以上を指定してください: %d", page.Size)
+	}
+	return nil
+}
+
+func apply(tx *gorm.DB, conditions []Condition) *gorm.DB {
+	for _, condition := range conditions {
+		tx = condition(tx)
+	}
+	return tx
+}
+
+// notFound はレコードが見つからなかったエラーを ErrNotFound として判定できるようにします
+func notFound(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("%w: %w", repository.ErrNotFound, err)
+	}
+	return err
+}
+
+func (r *Repository[T]) read(ctx context.Context) *gorm.DB {
+	return r.dbConnect.readConn(ctx).WithContext(ctx)
+}
+
+func (r *Repository[T]) write(ctx context.Context) *gorm.DB {
+	return r.dbConnect.conn(ctx).WithContext(ctx)
+}
+
+// First は条件に一致する最初のレコードを返します
+// 一致するレコードが無い場合は repository.ErrNotFound を返します
+func (r *Repository[T]) First(ctx context.Context, conditions ...Condition) (*T, error) {
+	var out T
+	if err := apply(r.read(ctx), conditions).First(&out).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &out, nil
+}
+
+// Find は条件に一致するレコードをすべて返します
+func (r *Repository[T]) Find(ctx context.Context, conditions ...Condition) ([]T, error) {
+	var out []T
+	if err := apply(r.read(ctx), conditions).Find(&out).Error; err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// FindPage は条件に一致するレコードのうち指定したページのレコードと、全体の件数を返します
+// ページをまたいで順序を保つため、conditions で並び順を指定してください
+func (r *Repository[T]) FindPage(ctx context.Context, page Page, conditions ...Condition) (*PageResult[T], error) {
+	if err := page.validate(); err != nil {
+		return nil, err
+	}
+	total, err := r.Count(ctx, conditions...)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &PageResult[T]{Items: []T{}, Total: total, Number: page.Number, Size: page.Size}
+	offset := (page.Number - 1) * page.Size
+	if int64(offset) >= total {
+		return result, nil
+	}
+	if err := apply(r.read(ctx), conditions).Limit(page.Size).Offset(offset).Find(&result.Items).Error; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Count は条件に一致するレコードの件数を返します
+func (r *Repository[T]) Count(ctx context.Context, conditions ...Condition) (int64, error) {
+	var count int64
+	if err := apply(r.read(ctx).Model(new(T)), conditions).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// Exists は条件に一致するレコードが存在するか返します
+func (r *Repository[T]) Exists(ctx context.Context, conditions ...Condition) (bool, error) {
+	var found []int
+	if err := apply(r.read(ctx).Model(new(T)), conditions).Select("1").Limit(1).Find(&found).Error; err != nil {
+		return false, err
+	}
+	return len(found) > 0, nil
+}
+
+// Create はレコードを作成します
+func (r *Repository[T]) Create(ctx context.Context, value *T) error {
+	if err := r.write(ctx).Create(value).Error; err != nil {
+		return fmt.Errorf("データベースにレコードを作成できませんでした: %w", err)
+	}
+	return nil
+}
+
+// CreateInBatches は batchSize 件ずつに分けてレコードを作成します
+func (r *Repository[T]) CreateInBatches(ctx context.Context, values []T, batchSize int) error {
+	if len(values) == 0 {
+		return nil
+	}
+	if err := r.write(ctx).CreateInBatches(&values, batchSize).Error; err != nil {
+		return fmt.Errorf("データベースにレコードを作成できませんでした: %w", err)
+	}
+	return nil
+}
+
+// Upsert はレコードを作成し、conflictColumns が重複するレコードは updateColumns を更新します
+// updateColumns を指定しない場合は、主キー以外のすべての列を更新します
+func (r *Repository[T]) Upsert(ctx context.Context, values []T, conflictColumns []string, updateColumns ...string) error {
+	if len(values) == 0 {
+		return nil
+	}
+	onConflict := clause.OnConflict{}
+	for _, column := range conflictColumns {
+		onConflict.Columns = append(onConflict.Columns, clause.Column{Name: column})
+	}
+	if len(updateColumns) > 0 {
+		onConflict.DoUpdates = clause.AssignmentColumns(updateColumns)
+	} else {
+		onConflict.UpdateAll = true
+	}
+
+	if err := r.write(ctx).Clauses(onConflict).Create(&values).Error; err != nil {
+		return fmt.Errorf("データベースのレコードを登録・更新できませんでした: %w", err)
+	}
+	return nil
+}
+
+// Update はレコードのすべての列を更新します
+func (r *Repository[T]) Update(ctx context.Context, value *T) error {
+	if err := r.write(ctx).Save(value).Error; err != nil {
+		return fmt.Errorf("データベースのレコードを更新できませんでした: %w", err)
+	}
+	return nil
+}
+
+// UpdateColumns は条件に一致するレコードの指定した列を更新し、更新した件数を返します
+// 条件を指定しない場合は全件の更新を防ぐためエラーとなります
+func (r *Repository[T]) UpdateColumns(ctx context.Context, values map[string]interface{}, conditions ...Condition) (int64, error) {
+	if len(conditions) == 0 {
+		return 0, fmt.Errorf("データベースのレコードを更新できませんでした: %w", gorm.ErrMissingWhereClause)
+	}
+	result := apply(r.write(ctx).Model(new(T)), conditions).Updates(values)
+	if result.Error != nil {
+		return 0, fmt.Errorf("データベースのレコードを更新できませんでした: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// Delete は条件に一致するレコードを削除し、削除した件数を返します
+// 条件を指定しない場合は全件の削除を防ぐためエラーとなります
+func (r *Repository[T]) Delete(ctx context.Context, conditions ...Condition) (int64, error) {
+	if len(conditions) == 0 {
+		return 0, fmt.Errorf("データベースのレコードを削除できませんでした: %w", gorm.ErrMissingWhereClause)
+	}
+	result := apply(r.write(ctx), conditions).Delete(new(T))
+	if result.Error != nil {
+		return 0, fmt.Errorf("データベースのレコードを削除できませんでした: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
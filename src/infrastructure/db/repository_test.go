@@ -0,0 +1,194 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Go_CleanArch/common/tenant"
+	repository "github.com/Go_CleanArch/usecase/repository_interface"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func noteIds(notes []tenantNote) []string {
+	ids := []string{}
+	for _, note := range notes {
+		ids = append(ids, note.NoteId)
+	}
+	return ids
+}
+
+func TestRepository(t *testing.T) {
+	t.Parallel()
+
+	t.Run("正常系_条件に一致する最初のレコード", func(t *testing.T) {
+		t.Parallel()
+		notes := NewRepository[globalNote](newTenantTestConnection(t))
+
+		note, err := notes.First(context.Background(), Eq("note_id", "g-1"))
+
+		assert.NoError(t, err)
+		assert.Equal(t, "共通", note.Body)
+	})
+
+	t.Run("異常系_レコードが見つからない場合はErrNotFound", func(t *testing.T) {
+		t.Parallel()
+		notes := NewRepository[globalNote](newTenantTestConnection(t))
+
+		note, err := notes.First(context.Background(), Eq("note_id", "g-9"))
+
+		assert.Nil(t, note)
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	})
+
+	t.Run("正常系_絞り込みと並び順", func(t *testing.T) {
+		t.Parallel()
+		notes := NewRepository[tenantNote](newTenantTestConnection(t))
+
+		found, err := notes.Find(withTenant(orgA), In("note_id", []string{"a-1", "a-2", "b-1"}), OrderByDesc("note_id"))
+
+		assert.NoError(t, err)
+		// テナントによる絞り込みも適用される
+		assert.Equal(t, []string{"a-2", "a-1"}, noteIds(found))
+	})
+
+	t.Run("正常系_ページングと全体の件数", func(t *testing.T) {
+		t.Parallel()
+		dbConnect := newTenantTestConnection(t)
+		notes := NewRepository[tenantNote](dbConnect)
+		ctx := withTenant(orgA)
+		assert.NoError(t, notes.CreateInBatches(ctx, []tenantNote{
+			{NoteId: "a-3", OrganizationId: orgA},
+			{NoteId: "a-4", OrganizationId: orgA},
+			{NoteId: "a-5", OrganizationId: orgA},
+		}, 2))
+
+		page, err := notes.FindPage(ctx, Page{Number: 2, Size: 2}, OrderBy("note_id"))
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(5), page.Total)
+		assert.Equal(t, []string{"a-3", "a-4"}, noteIds(page.Items))
+
+		// 範囲外のページは空
+		page, err = notes.FindPage(ctx, Page{Number: 4, Size: 2}, OrderBy("note_id"))
+		assert.NoError(t, err)
+		assert.Equal(t, int64(5), page.Total)
+		assert.Empty(t, page.Items)
+	})
+
+	t.Run("異常系_ページの指定が不正", func(t *testing.T) {
+		t.Parallel()
+		notes := NewRepository[tenantNote](newTenantTestConnection(t))
+
+		_, err := notes.FindPage(withTenant(orgA), Page{Number: 0, Size: 10})
+
+		assert.ErrorContains(t, err, "ページ番号")
+	})
+
+	t.Run("正常系_件数と存在確認", func(t *testing.T) {
+		t.Parallel()
+		notes := NewRepository[tenantNote](newTenantTestConnection(t))
+		ctx := withTenant(orgB)
+
+		count, err := notes.Count(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+
+		exists, err := notes.Exists(ctx, Eq("note_id", "b-1"))
+		assert.NoError(t, err)
+		assert.True(t, exists)
+
+		// 他テナントのレコードは存在しないものとして扱う
+		exists, err = notes.Exists(ctx, Eq("note_id", "a-1"))
+		assert.NoError(t, err)
+		assert.False(t, exists)
+	})
+
+	t.Run("正常系_重複するレコードは指定した列を更新", func(t *testing.T) {
+		t.Parallel()
+		notes := NewRepository[globalNote](newTenantTestConnection(t))
+		ctx := context.Background()
+
+		err := notes.Upsert(ctx, []globalNote{{NoteId: "g-1", Body: "更新"}, {NoteId: "g-2", Body: "追加"}}, []string{"note_id"}, "body")
+
+		assert.NoError(t, err)
+		found, err := notes.Find(ctx, OrderBy("note_id"))
+		assert.NoError(t, err)
+		assert.Equal(t, []globalNote{{NoteId: "g-1", Body: "更新"}, {NoteId: "g-2", Body: "追加"}}, found)
+	})
+
+	t.Run("正常系_件数の上限", func(t *testing.T) {
+		t.Parallel()
+		notes := NewRepository[tenantNote](newTenantTestConnection(t))
+		ctx := tenant.WithoutScope(context.Background())
+
+		found, err := notes.Find(ctx, OrderBy("note_id"), Limit(2))
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"a-1", "a-2"}, noteIds(found))
+	})
+
+	t.Run("正常系_条件に一致するレコードの列を更新", func(t *testing.T) {
+		t.Parallel()
+		notes := NewRepository[tenantNote](newTenantTestConnection(t))
+
+		updated, err := notes.UpdateColumns(withTenant(orgA), map[string]interface{}{"body": "更新"}, In("note_id", []string{"a-1", "b-1"}))
+
+		assert.NoError(t, err)
+		// 他テナントのレコードは更新されない
+		assert.Equal(t, int64(1), updated)
+		note, err := notes.First(withTenant(orgA), Eq("note_id", "a-1"))
+		assert.NoError(t, err)
+		assert.Equal(t, "更新", note.Body)
+	})
+
+	t.Run("異常系_条件を指定しない更新はエラー", func(t *testing.T) {
+		t.Parallel()
+		notes := NewRepository[globalNote](newTenantTestConnection(t))
+
+		_, err := notes.UpdateColumns(context.Background(), map[string]interface{}{"body": "更新"})
+
+		assert.ErrorIs(t, err, gorm.ErrMissingWhereClause)
+	})
+
+	t.Run("正常系_条件に一致するレコードを削除", func(t *testing.T) {
+		t.Parallel()
+		notes := NewRepository[tenantNote](newTenantTestConnection(t))
+
+		deleted, err := notes.Delete(withTenant(orgA), IsNull("body"), Eq("note_id", "a-1"))
+		assert.NoError(t, err)
+		assert.Equal(t, int64(0), deleted)
+
+		deleted, err = notes.Delete(withTenant(orgA), In("note_id", []string{"a-1", "b-1"}))
+		assert.NoError(t, err)
+		// 他テナントのレコードは削除されない
+		assert.Equal(t, int64(1), deleted)
+	})
+
+	t.Run("異常系_条件を指定しない削除はエラー", func(t *testing.T) {
+		t.Parallel()
+		dbConnect := newTenantTestConnection(t)
+		notes := NewRepository[globalNote](dbConnect)
+
+		_, err := notes.Delete(context.Background())
+
+		assert.ErrorIs(t, err, gorm.ErrMissingWhereClause)
+		assert.True(t, globalNoteExists(t, dbConnect, "g-1"))
+	})
+
+	t.Run("異常系_キャンセル済みのコンテキスト", func(t *testing.T) {
+		t.Parallel()
+		dbConnect := newTenantTestConnection(t)
+		notes := NewRepository[globalNote](dbConnect)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := notes.Find(ctx)
+		assert.ErrorIs(t, err, context.Canceled)
+
+		var found []globalNote
+		err = dbConnect.FindWithRawJoinQuery(ctx, "SELECT * FROM global_notes", &found)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
@@ -0,0 +1,66 @@
+package db
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/Go_CleanArch/infrastructure/config"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// 接続先のデータベース(config.DatabaseConfig.Driver)
+const (
+	DriverPostgres = "postgres"
+	DriverSqlite   = "sqlite"
+)
+
+// メモリ上に作成する SQLite のデータベース
+const sqliteMemoryPath = ":memory:"
+
+//go:embed sqlite_schema.sql
+var sqliteSchema string
+
+// openSqlite は SQLite のデータベースを開き、スキーマを作成します
+// データベースサーバーを用意せずにアプリケーションを動作させる開発・テスト用途に使用します
+func openSqlite(dbConfig config.DatabaseConfig) (*gorm.DB, error) {
+	if dbConfig.SqlitePath != sqliteMemoryPath {
+		if err := os.MkdirAll(filepath.Dir(dbConfig.SqlitePath), 0o755); err != nil {
+			return nil, fmt.Errorf("SQLite のデータベースファイルを作成できませんでした: %w", err)
+		}
+	}
+	newDb, err := gorm.Open(sqlite.Open(dbConfig.SqlitePath), &gorm.Config{
+		Logger: newQueryLogger(dbConfig.QueryLog),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 書き込みの競合(database is locked)を避けるため、接続は1本に限定します
+	// メモリ上のデータベースは接続を閉じると破棄されるため、接続を維持し続けます
+	sqlDB, err := newDb.DB()
+	if err != nil {
+		return nil, err
+	}
+	sqlDB.SetMaxOpenConns(1)
+	sqlDB.SetMaxIdleConns(1)
+	sqlDB.SetConnMaxLifetime(0)
+	sqlDB.SetConnMaxIdleTime(0)
+
+	// PostgreSQL と同様に外部キー制約を有効にします
+	if err := newDb.Exec("PRAGMA foreign_keys = ON").Error; err != nil {
+		return nil, err
+	}
+	schemaSession := newDb.Session(&gorm.Session{Logger: logger.Default.LogMode(logger.Silent)})
+	if err := schemaSession.Exec(sqliteSchema).Error; err != nil {
+		return nil, fmt.Errorf("SQLite のスキーマを作成できませんでした: %w", err)
+	}
+
+	if err := usePlugins(newDb, dbConfig); err != nil {
+		return nil, err
+	}
+	return newDb, nil
+}
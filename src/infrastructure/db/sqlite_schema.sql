@@ -0,0 +1,160 @@
+-- SQLite のスキーマ(開発・テスト用)
+-- マイグレーション(migration/sql)は PostgreSQL 向けのため、適用後のスキーマを SQLite の構文で定義する。
+-- 接続時に毎回適用するため、作成済みの場合は何もしない。マイグレーションを追加した場合はこのファイルにも反映すること。
+
+CREATE TABLE IF NOT EXISTS users (
+    id INTEGER,
+    user_id VARCHAR(36) NOT NULL PRIMARY KEY,
+    user_name TEXT NOT NULL,
+    password VARCHAR(255) NOT NULL,
+    email TEXT NOT NULL,
+    email_index CHAR(64),
+    avatar_key VARCHAR(255),
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP,
+    deleted_at TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS users_email_index_key ON users (email_index) WHERE deleted_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS email_change_requests (
+    email_change_request_id VARCHAR(36) NOT NULL PRIMARY KEY,
+    user_id VARCHAR(36) NOT NULL REFERENCES users (user_id),
+    current_email TEXT NOT NULL,
+    new_email TEXT NOT NULL,
+    confirm_token_hash CHAR(64) NOT NULL UNIQUE,
+    cancel_token_hash CHAR(64) NOT NULL UNIQUE,
+    expires_at TIMESTAMP NOT NULL,
+    confirmed_at TIMESTAMP,
+    cancelled_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS email_change_requests_user_id_idx ON email_change_requests (user_id);
+
+CREATE TABLE IF NOT EXISTS login_histories (
+    login_history_id VARCHAR(36) NOT NULL PRIMARY KEY,
+    user_id VARCHAR(36) NOT NULL REFERENCES users (user_id),
+    ip_address VARCHAR(45),
+    user_agent VARCHAR(512),
+    logged_in_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS login_histories_user_id_idx ON login_histories (user_id, logged_in_at DESC);
+
+CREATE TABLE IF NOT EXISTS erasure_requests (
+    erasure_request_id VARCHAR(36) NOT NULL PRIMARY KEY,
+    user_id VARCHAR(36) NOT NULL REFERENCES users (user_id),
+    confirm_token_hash CHAR(64) NOT NULL UNIQUE,
+    expires_at TIMESTAMP NOT NULL,
+    confirmed_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS privacy_audit_logs (
+    privacy_audit_log_id VARCHAR(36) NOT NULL PRIMARY KEY,
+    user_id VARCHAR(36) NOT NULL REFERENCES users (user_id),
+    action VARCHAR(32) NOT NULL,
+    ip_address VARCHAR(45),
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS privacy_audit_logs_user_id_idx ON privacy_audit_logs (user_id, created_at DESC);
+
+CREATE TABLE IF NOT EXISTS user_settings (
+    user_id VARCHAR(36) NOT NULL REFERENCES users (user_id),
+    setting_key VARCHAR(64) NOT NULL,
+    setting_value TEXT NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, setting_key)
+);
+
+CREATE TABLE IF NOT EXISTS organizations (
+    organization_id VARCHAR(36) NOT NULL PRIMARY KEY,
+    name VARCHAR(50) NOT NULL,
+    slug VARCHAR(32) NOT NULL UNIQUE,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS organization_memberships (
+    organization_id VARCHAR(36) NOT NULL REFERENCES organizations (organization_id),
+    user_id VARCHAR(36) NOT NULL REFERENCES users (user_id),
+    role VARCHAR(16) NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP,
+    PRIMARY KEY (organization_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS organization_memberships_user_id_idx ON organization_memberships (user_id);
+
+CREATE TABLE IF NOT EXISTS organization_invitations (
+    invitation_id VARCHAR(36) NOT NULL PRIMARY KEY,
+    organization_id VARCHAR(36) NOT NULL REFERENCES organizations (organization_id),
+    email TEXT NOT NULL,
+    email_index CHAR(64),
+    role VARCHAR(16) NOT NULL,
+    invited_by VARCHAR(36) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    expires_at TIMESTAMP NOT NULL,
+    accepted_at TIMESTAMP,
+    accepted_by VARCHAR(36),
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS organization_invitations_organization_id_idx ON organization_invitations (organization_id);
+CREATE INDEX IF NOT EXISTS organization_invitations_email_index_idx ON organization_invitations (email_index);
+
+CREATE TABLE IF NOT EXISTS outbox (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    event_id VARCHAR(36) NOT NULL UNIQUE,
+    event_name VARCHAR(64) NOT NULL,
+    aggregate_type VARCHAR(32) NOT NULL,
+    aggregate_id VARCHAR(64) NOT NULL,
+    payload TEXT NOT NULL,
+    occurred_at TIMESTAMP NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL,
+    locked_until TIMESTAMP,
+    last_error TEXT,
+    published_at TIMESTAMP,
+    dead_lettered_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS outbox_pending_idx ON outbox (next_attempt_at) WHERE published_at IS NULL AND dead_lettered_at IS NULL;
+CREATE INDEX IF NOT EXISTS outbox_aggregate_idx ON outbox (aggregate_type, aggregate_id, id) WHERE published_at IS NULL AND dead_lettered_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS audit_log (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    occurred_at TIMESTAMP NOT NULL,
+    actor_id VARCHAR(36),
+    request_id VARCHAR(64),
+    action VARCHAR(16) NOT NULL,
+    table_name VARCHAR(64) NOT NULL,
+    record_id VARCHAR(255) NOT NULL,
+    changes TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS audit_log_actor_idx ON audit_log (actor_id, occurred_at);
+CREATE INDEX IF NOT EXISTS audit_log_record_idx ON audit_log (table_name, record_id, occurred_at);
+CREATE INDEX IF NOT EXISTS audit_log_occurred_at_idx ON audit_log (occurred_at);
+
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    scope VARCHAR(255) NOT NULL,
+    idempotency_key VARCHAR(255) NOT NULL,
+    request_hash VARCHAR(64) NOT NULL,
+    response_status INTEGER,
+    response_content_type VARCHAR(255),
+    response_body TEXT,
+    locked_until TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idempotency_keys_key ON idempotency_keys (scope, idempotency_key);
+CREATE INDEX IF NOT EXISTS idempotency_keys_expires_at_idx ON idempotency_keys (expires_at);
@@ -0,0 +1,130 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/Go_CleanArch/common/tenant"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// テナント(組織)を識別するカラム。このカラムを持つテーブルはテナント単位のデータとして扱う
+const TenantColumn = "organization_id"
+
+var (
+	ErrTenantRequired = errors.New("テナントが指定されていません")
+	ErrTenantMismatch = errors.New("他のテナントのデータは操作できません")
+)
+
+// TenantScopePlugin は organization_id を持つテーブルへの検索・更新・削除に
+// コンテキストのテナントによる絞り込みを自動で付与し、作成時にはテナントを設定する。
+// テナントが指定されていない場合は、tenant.WithoutScope で明示的に解除されない限りエラーとする。
+// Raw / Exec で記述したSQLは対象外のため、テナント単位のテーブルを参照する場合は条件を明記すること。
+type TenantScopePlugin struct{}
+
+func (TenantScopePlugin) Name() string {
+	return "tenant_scope"
+}
+
+func (TenantScopePlugin) Initialize(db *gorm.DB) error {
+	callback := db.Callback()
+	if err := callback.Query().Before("gorm:query").Register("tenant_scope:query", tenantWhere); err != nil {
+		return err
+	}
+	if err := callback.Row().Before("gorm:row").Register("tenant_scope:row", tenantWhere); err != nil {
+		return err
+	}
+	if err := callback.Update().Before("gorm:update").Register("tenant_scope:update", tenantWhere); err != nil {
+		return err
+	}
+	if err := callback.Delete().Before("gorm:delete").Register("tenant_scope:delete", tenantWhere); err != nil {
+		return err
+	}
+	return callback.Create().Before("gorm:create").Register("tenant_scope:create", tenantAssign)
+}
+
+// 対象のテーブルがテナント単位か判定し、コンテキストのテナントを返す
+// 絞り込みが不要な場合は field に nil を返す
+func resolveTenant(db *gorm.DB) (*schema.Field, string, error) {
+	stmt := db.Statement
+	if stmt.Schema == nil || stmt.SQL.Len() > 0 {
+		return nil, "", nil
+	}
+	field := stmt.Schema.LookUpField(TenantColumn)
+	if field == nil {
+		return nil, "", nil
+	}
+	if tenant.IsUnscoped(stmt.Context) {
+		return nil, "", nil
+	}
+	t, ok := tenant.FromContext(stmt.Context)
+	if !ok {
+		return nil, "", fmt.Errorf("%w: %s", ErrTenantRequired, stmt.Schema.Table)
+	}
+	return field, t.OrganizationId, nil
+}
+
+func tenantWhere(db *gorm.DB) {
+	field, organizationId, err := resolveTenant(db)
+	if err != nil {
+		db.AddError(err)
+		return
+	}
+	if field == nil {
+		return
+	}
+	db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{
+		clause.Eq{
+			Column: clause.Column{Table: db.Statement.Table, Name: field.DBName},
+			Value:  organizationId,
+		},
+	}})
+}
+
+func tenantAssign(db *gorm.DB) {
+	field, organizationId, err := resolveTenant(db)
+	if err != nil {
+		db.AddError(err)
+		return
+	}
+	if field == nil {
+		return
+	}
+
+	ctx := db.Statement.Context
+	assign := func(rv reflect.Value) {
+		value, isZero := field.ValueOf(ctx, rv)
+		if isZero {
+			if err := field.Set(ctx, rv, organizationId); err != nil {
+				db.AddError(err)
+			}
+			return
+		}
+		if value != organizationId {
+			db.AddError(ErrTenantMismatch)
+		}
+	}
+
+	// Save による upsert などで主キーが競合した場合に、他テナントのレコードを上書きしない
+	if c, ok := db.Statement.Clauses["ON CONFLICT"]; ok {
+		if onConflict, ok := c.Expression.(clause.OnConflict); ok && !onConflict.DoNothing {
+			onConflict.Where.Exprs = append(onConflict.Where.Exprs, clause.Eq{
+				Column: clause.Column{Table: db.Statement.Table, Name: field.DBName},
+				Value:  organizationId,
+			})
+			db.Statement.AddClause(onConflict)
+		}
+	}
+
+	switch rv := db.Statement.ReflectValue; rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			assign(reflect.Indirect(rv.Index(i)))
+		}
+	case reflect.Struct:
+		assign(rv)
+	}
+}
@@ -0,0 +1,219 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"github.com/Go_CleanArch/common/tenant"
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// テナント単位のテーブル
+type tenantNote struct {
+	NoteId         string `gorm:"primaryKey"`
+	OrganizationId string `gorm:"not null"`
+	Body           string
+}
+
+// テナントに属さないテーブル
+type globalNote struct {
+	NoteId string `gorm:"primaryKey"`
+	Body   string
+}
+
+var testDatabaseSeq atomic.Int64
+
+const (
+	orgA = "org-a"
+	orgB = "org-b"
+)
+
+func newTenantTestConnection(t *testing.T) *DBConnection {
+	t.Helper()
+	// テストごとに独立したインメモリデータベースを使用する
+	dsn := fmt.Sprintf("file:tenant_test_%d?mode=memory&cache=shared", testDatabaseSeq.Add(1))
+	gormDB, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, gormDB.Use(TenantScopePlugin{}))
+	assert.NoError(t, gormDB.AutoMigrate(&tenantNote{}, &globalNote{}))
+
+	// 各テナントのデータを投入
+	seed := tenant.WithoutScope(context.Background())
+	assert.NoError(t, gormDB.WithContext(seed).Create(&[]tenantNote{
+		{NoteId: "a-1", OrganizationId: orgA, Body: "A社のメモ1"},
+		{NoteId: "a-2", OrganizationId: orgA, Body: "A社のメモ2"},
+		{NoteId: "b-1", OrganizationId: orgB, Body: "B社のメモ"},
+	}).Error)
+	assert.NoError(t, gormDB.Create(&globalNote{NoteId: "g-1", Body: "共通"}).Error)
+
+	return &DBConnection{db: gormDB, ctx: context.Background()}
+}
+
+func withTenant(organizationId string) context.Context {
+	return tenant.WithTenant(context.Background(), tenant.Tenant{OrganizationId: organizationId})
+}
+
+func countNotes(t *testing.T, dbConnect *DBConnection, organizationId string) int64 {
+	t.Helper()
+	var count int64
+	assert.NoError(t, dbConnect.db.WithContext(tenant.WithoutScope(context.Background())).
+		Model(&tenantNote{}).Where("organization_id = ?", organizationId).Count(&count).Error)
+	return count
+}
+
+func TestTenantScope(t *testing.T) {
+	t.Parallel()
+
+	t.Run("テナント分離_一覧は自テナントのレコードのみ", func(t *testing.T) {
+		t.Parallel()
+		dbConnect := newTenantTestConnection(t)
+
+		var notes []tenantNote
+		err := dbConnect.FindAll(withTenant(orgA), "1 = 1", nil, &notes)
+
+		assert.NoError(t, err)
+		assert.Len(t, notes, 2)
+		for _, note := range notes {
+			assert.Equal(t, orgA, note.OrganizationId)
+		}
+	})
+
+	t.Run("テナント分離_他テナントのレコードは主キー指定でも取得できない", func(t *testing.T) {
+		t.Parallel()
+		dbConnect := newTenantTestConnection(t)
+
+		var note tenantNote
+		err := dbConnect.Find(withTenant(orgA), "note_id = ?", []interface{}{"b-1"}, &note)
+
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	})
+
+	t.Run("テナント分離_OR条件でも他テナントのレコードは取得できない", func(t *testing.T) {
+		t.Parallel()
+		dbConnect := newTenantTestConnection(t)
+
+		var notes []tenantNote
+		err := dbConnect.FindAll(withTenant(orgA), "note_id = ? OR 1 = 1", []interface{}{"b-1"}, &notes)
+
+		assert.NoError(t, err)
+		assert.Len(t, notes, 2)
+	})
+
+	t.Run("テナント分離_他テナントのレコードは削除できない", func(t *testing.T) {
+		t.Parallel()
+		dbConnect := newTenantTestConnection(t)
+
+		err := dbConnect.Delete(withTenant(orgA), "note_id = ?", []interface{}{"b-1"}, &tenantNote{})
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), countNotes(t, dbConnect, orgB))
+	})
+
+	t.Run("テナント分離_他テナントのレコードは更新できない", func(t *testing.T) {
+		t.Parallel()
+		dbConnect := newTenantTestConnection(t)
+		ctx := withTenant(orgA)
+
+		err := dbConnect.db.WithContext(ctx).Model(&tenantNote{}).Where("note_id = ?", "b-1").Update("body", "改ざん").Error
+		assert.NoError(t, err)
+
+		var note tenantNote
+		assert.NoError(t, dbConnect.Find(withTenant(orgB), "note_id = ?", []interface{}{"b-1"}, &note))
+		assert.Equal(t, "B社のメモ", note.Body)
+	})
+
+	t.Run("テナント分離_自テナントのレコードは更新できる", func(t *testing.T) {
+		t.Parallel()
+		dbConnect := newTenantTestConnection(t)
+		ctx := withTenant(orgA)
+
+		var note tenantNote
+		assert.NoError(t, dbConnect.Find(ctx, "note_id = ?", []interface{}{"a-1"}, &note))
+		note.Body = "更新後"
+		assert.NoError(t, dbConnect.Update(ctx, &note))
+
+		var updated tenantNote
+		assert.NoError(t, dbConnect.Find(ctx, "note_id = ?", []interface{}{"a-1"}, &updated))
+		assert.Equal(t, "更新後", updated.Body)
+	})
+
+	t.Run("テナント分離_他テナントのレコードは保存による上書きもできない", func(t *testing.T) {
+		t.Parallel()
+		dbConnect := newTenantTestConnection(t)
+
+		// 他テナントの主キーを指定して保存すると、更新対象が無いため upsert となる
+		err := dbConnect.Update(withTenant(orgA), &tenantNote{NoteId: "b-1", Body: "改ざん"})
+		assert.NoError(t, err)
+
+		var note tenantNote
+		assert.NoError(t, dbConnect.Find(withTenant(orgB), "note_id = ?", []interface{}{"b-1"}, &note))
+		assert.Equal(t, "B社のメモ", note.Body)
+	})
+
+	t.Run("テナント分離_作成時はテナントが設定される", func(t *testing.T) {
+		t.Parallel()
+		dbConnect := newTenantTestConnection(t)
+
+		err := dbConnect.Create(withTenant(orgB), &tenantNote{NoteId: "b-2", Body: "B社のメモ2"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), countNotes(t, dbConnect, orgB))
+	})
+
+	t.Run("テナント分離_他テナントのレコードは作成できない", func(t *testing.T) {
+		t.Parallel()
+		dbConnect := newTenantTestConnection(t)
+
+		err := dbConnect.Create(withTenant(orgA), &tenantNote{NoteId: "b-2", OrganizationId: orgB})
+
+		assert.True(t, errors.Is(err, ErrTenantMismatch))
+		assert.Equal(t, int64(1), countNotes(t, dbConnect, orgB))
+	})
+
+	t.Run("テナント分離_テナント未指定の場合はエラー", func(t *testing.T) {
+		t.Parallel()
+		dbConnect := newTenantTestConnection(t)
+		ctx := context.Background()
+
+		var notes []tenantNote
+		assert.ErrorIs(t, dbConnect.FindAll(ctx, "1 = 1", nil, &notes), ErrTenantRequired)
+		assert.ErrorIs(t, dbConnect.Delete(ctx, "note_id = ?", []interface{}{"a-1"}, &tenantNote{}), ErrTenantRequired)
+		assert.ErrorIs(t, dbConnect.Create(ctx, &tenantNote{NoteId: "x-1", OrganizationId: orgA}), ErrTenantRequired)
+		assert.Empty(t, notes)
+		assert.Equal(t, int64(2), countNotes(t, dbConnect, orgA))
+	})
+
+	t.Run("テナント分離_テナントに属さないテーブルは絞り込まない", func(t *testing.T) {
+		t.Parallel()
+		dbConnect := newTenantTestConnection(t)
+
+		var notes []globalNote
+		err := dbConnect.FindAll(context.Background(), "1 = 1", nil, &notes)
+
+		assert.NoError(t, err)
+		assert.Len(t, notes, 1)
+	})
+
+	t.Run("テナント分離_トランザクション内でも絞り込みを行う", func(t *testing.T) {
+		t.Parallel()
+		dbConnect := newTenantTestConnection(t)
+
+		transactionManager := &TransactionManager{db: dbConnect.db}
+
+		err := transactionManager.RunInTransaction(withTenant(orgA), func(ctx context.Context) error {
+			return dbConnect.Delete(ctx, "1 = 1", nil, &tenantNote{})
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(0), countNotes(t, dbConnect, orgA))
+		assert.Equal(t, int64(1), countNotes(t, dbConnect, orgB))
+	})
+}
@@ -0,0 +1,105 @@
+package db
+
+import (
+	"context"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+const afterCommitKey contextKey = "afterCommit"
+
+// TransactionManager はコンテキストにトランザクションを格納し、
+// 同じコンテキストで呼び出した DBConnection の操作をそのトランザクションで実行させる。
+// db が指定されていない場合は共有の接続を使用する。
+type TransactionManager struct {
+	db *gorm.DB
+}
+
+func NewTransactionManager(ctx context.Context) (*TransactionManager, error) {
+	// データベース接続が確立されていることを確認
+	if _, err := getDB(ctx); err != nil {
+		return nil, err
+	}
+	return &TransactionManager{}, nil
+}
+
+func (tm *TransactionManager) RunInTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	hooks, nested := ctx.Value(afterCommitKey).(*afterCommitHooks)
+	if !nested {
+		hooks = &afterCommitHooks{}
+		ctx = context.WithValue(ctx, afterCommitKey, hooks)
+	}
+	err := transaction(ctx, tm.db, func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, dbKey, tx))
+	})
+	// 入れ子の場合は最も外側のトランザクションのコミット後に実行する
+	if err == nil && !nested {
+		hooks.run()
+	}
+	return err
+}
+
+// InTransaction はコンテキストにトランザクションがあるかを返します
+func InTransaction(ctx context.Context) bool {
+	_, ok := ctx.Value(dbKey).(*gorm.DB)
+	return ok
+}
+
+// AfterCommit は fn をコンテキストのトランザクションのコミット後に実行するよう登録します
+// トランザクション外で呼び出した場合はすぐに実行します。ロールバックした場合は実行しません。
+// 入れ子のトランザクション(セーブポイント)の中で登録した場合は、セーブポイントまで戻した場合も
+// 外側のトランザクションのコミット後に実行するため、キャッシュの削除など何度実行しても問題ない処理に使用してください。
+func AfterCommit(ctx context.Context, fn func()) {
+	hooks, ok := ctx.Value(afterCommitKey).(*afterCommitHooks)
+	if !ok {
+		fn()
+		return
+	}
+	hooks.add(fn)
+}
+
+// afterCommitHooks はトランザクションのコミット後に実行する処理
+type afterCommitHooks struct {
+	mu    sync.Mutex
+	hooks []func()
+}
+
+func (h *afterCommitHooks) add(fn func()) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.hooks = append(h.hooks, fn)
+}
+
+func (h *afterCommitHooks) run() {
+	h.mu.Lock()
+	hooks := h.hooks
+	h.hooks = nil
+	h.mu.Unlock()
+	for _, fn := range hooks {
+		fn()
+	}
+}
+
+// 固定の接続が指定されていない場合は、共有の接続(ヘルスチェックで差し替えられた最新の接続)を返す
+func orShared(db *gorm.DB) *gorm.DB {
+	if db != nil {
+		return db
+	}
+	return currentDB()
+}
+
+// コンテキストにトランザクションがある場合はそれを、無い場合は db を返す
+func fromContext(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(dbKey).(*gorm.DB); ok {
+		return tx
+	}
+	return db
+}
+
+// トランザクション内で fn を実行する
+// コンテキストのトランザクション中に呼び出した場合は、gorm によりセーブポイントが作成される。
+// fn がエラーを返した場合・パニックした場合はロールバック(セーブポイントまで戻す)し、パニックは呼び出し元へ伝播する。
+func transaction(ctx context.Context, db *gorm.DB, fn transactionFunc) error {
+	return fromContext(ctx, orShared(db)).WithContext(ctx).Transaction(fn)
+}
@@ -0,0 +1,177 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func globalNoteExists(t *testing.T, dbConnect *DBConnection, noteId string) bool {
+	t.Helper()
+	var count int64
+	assert.NoError(t, dbConnect.db.Model(&globalNote{}).Where("note_id = ?", noteId).Count(&count).Error)
+	return count > 0
+}
+
+func TestTransactionManager(t *testing.T) {
+	t.Parallel()
+	errFailed := errors.New("処理に失敗しました")
+
+	t.Run("正常系_コンテキストのトランザクションでまとめてコミット", func(t *testing.T) {
+		t.Parallel()
+		dbConnect := newTenantTestConnection(t)
+		transactionManager := &TransactionManager{db: dbConnect.db}
+
+		err := transactionManager.RunInTransaction(context.Background(), func(ctx context.Context) error {
+			if err := dbConnect.Create(ctx, &globalNote{NoteId: "g-2"}); err != nil {
+				return err
+			}
+			// 同じコンテキストで検索すると、コミット前の変更が参照できる
+			var note globalNote
+			return dbConnect.Find(ctx, "note_id = ?", []interface{}{"g-2"}, &note)
+		})
+
+		assert.NoError(t, err)
+		assert.True(t, globalNoteExists(t, dbConnect, "g-2"))
+	})
+
+	t.Run("異常系_エラーの場合はすべてロールバック", func(t *testing.T) {
+		t.Parallel()
+		dbConnect := newTenantTestConnection(t)
+		transactionManager := &TransactionManager{db: dbConnect.db}
+
+		err := transactionManager.RunInTransaction(context.Background(), func(ctx context.Context) error {
+			if err := dbConnect.Create(ctx, &globalNote{NoteId: "g-2"}); err != nil {
+				return err
+			}
+			if err := dbConnect.Update(ctx, &globalNote{NoteId: "g-1", Body: "更新"}); err != nil {
+				return err
+			}
+			return errFailed
+		})
+
+		assert.ErrorIs(t, err, errFailed)
+		assert.False(t, globalNoteExists(t, dbConnect, "g-2"))
+		var note globalNote
+		assert.NoError(t, dbConnect.Find(context.Background(), "note_id = ?", []interface{}{"g-1"}, &note))
+		assert.Equal(t, "共通", note.Body)
+	})
+
+	t.Run("異常系_パニックの場合はロールバックして再送出", func(t *testing.T) {
+		t.Parallel()
+		dbConnect := newTenantTestConnection(t)
+		transactionManager := &TransactionManager{db: dbConnect.db}
+
+		assert.Panics(t, func() {
+			_ = transactionManager.RunInTransaction(context.Background(), func(ctx context.Context) error {
+				if err := dbConnect.Create(ctx, &globalNote{NoteId: "g-2"}); err != nil {
+					return err
+				}
+				panic("想定外のエラー")
+			})
+		})
+		assert.False(t, globalNoteExists(t, dbConnect, "g-2"))
+	})
+
+	t.Run("入れ子_内側の失敗はセーブポイントまで戻す", func(t *testing.T) {
+		t.Parallel()
+		dbConnect := newTenantTestConnection(t)
+		transactionManager := &TransactionManager{db: dbConnect.db}
+
+		err := transactionManager.RunInTransaction(context.Background(), func(ctx context.Context) error {
+			if err := dbConnect.Create(ctx, &globalNote{NoteId: "g-2"}); err != nil {
+				return err
+			}
+			innerErr := transactionManager.RunInTransaction(ctx, func(ctx context.Context) error {
+				if err := dbConnect.Create(ctx, &globalNote{NoteId: "g-3"}); err != nil {
+					return err
+				}
+				return errFailed
+			})
+			assert.ErrorIs(t, innerErr, errFailed)
+			// リポジトリ内のトランザクションも外側のトランザクションに参加する
+			return transactionManager.RunInTransaction(ctx, func(ctx context.Context) error {
+				return dbConnect.Create(ctx, &globalNote{NoteId: "g-4"})
+			})
+		})
+
+		assert.NoError(t, err)
+		assert.True(t, globalNoteExists(t, dbConnect, "g-2"))
+		assert.False(t, globalNoteExists(t, dbConnect, "g-3"))
+		assert.True(t, globalNoteExists(t, dbConnect, "g-4"))
+	})
+
+	t.Run("入れ子_外側の失敗は内側の変更もロールバック", func(t *testing.T) {
+		t.Parallel()
+		dbConnect := newTenantTestConnection(t)
+		transactionManager := &TransactionManager{db: dbConnect.db}
+
+		err := transactionManager.RunInTransaction(context.Background(), func(ctx context.Context) error {
+			if err := transactionManager.RunInTransaction(ctx, func(ctx context.Context) error {
+				return dbConnect.Create(ctx, &globalNote{NoteId: "g-3"})
+			}); err != nil {
+				return err
+			}
+			return errFailed
+		})
+
+		assert.ErrorIs(t, err, errFailed)
+		assert.False(t, globalNoteExists(t, dbConnect, "g-3"))
+	})
+}
+
+func TestAfterCommit(t *testing.T) {
+	t.Parallel()
+	errFailed := errors.New("処理に失敗しました")
+
+	t.Run("正常系_コミット後に実行する", func(t *testing.T) {
+		t.Parallel()
+		dbConnect := newTenantTestConnection(t)
+		transactionManager := &TransactionManager{db: dbConnect.db}
+		executed := []string{}
+
+		err := transactionManager.RunInTransaction(context.Background(), func(ctx context.Context) error {
+			assert.True(t, InTransaction(ctx))
+			AfterCommit(ctx, func() { executed = append(executed, "outer") })
+			if err := transactionManager.RunInTransaction(ctx, func(ctx context.Context) error {
+				AfterCommit(ctx, func() { executed = append(executed, "inner") })
+				return nil
+			}); err != nil {
+				return err
+			}
+			// 入れ子のトランザクションのコミットでは実行しない
+			assert.Empty(t, executed)
+			return nil
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"outer", "inner"}, executed)
+	})
+
+	t.Run("異常系_ロールバックした場合は実行しない", func(t *testing.T) {
+		t.Parallel()
+		dbConnect := newTenantTestConnection(t)
+		transactionManager := &TransactionManager{db: dbConnect.db}
+		executed := false
+
+		err := transactionManager.RunInTransaction(context.Background(), func(ctx context.Context) error {
+			AfterCommit(ctx, func() { executed = true })
+			return errFailed
+		})
+
+		assert.ErrorIs(t, err, errFailed)
+		assert.False(t, executed)
+	})
+
+	t.Run("正常系_トランザクション外ではすぐに実行する", func(t *testing.T) {
+		t.Parallel()
+		executed := false
+
+		AfterCommit(context.Background(), func() { executed = true })
+
+		assert.False(t, InTransaction(context.Background()))
+		assert.True(t, executed)
+	})
+}
@@ -0,0 +1,258 @@
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/Go_CleanArch/common/auth"
+	status "github.com/Go_CleanArch/common/const"
+	"github.com/Go_CleanArch/common/errors"
+	"github.com/Go_CleanArch/common/tenant"
+	"github.com/Go_CleanArch/interface_adapter/gateway/entity"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+// 冪等キーのリクエストヘッダー
+const KeyHeader = "Idempotency-Key"
+
+// 保存したレスポンスを返したことを表すレスポンスヘッダー
+const ReplayedHeader = "Idempotent-Replayed"
+
+// 受け付ける冪等キー(UUID などクライアントが生成した値を想定し、使用できる文字・長さを制限する)
+var validKey = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,255}$`)
+
+// 指紋を計算するリクエストボディの上限
+const maxRequestBody = 1 << 20
+
+// キーの登録・引き継ぎを試行する回数(他のリクエストと競合した場合に再試行する)
+const maxAttempts = 3
+
+// Middleware は Idempotency-Key を指定した POST リクエストを1回のみ処理します
+// 処理済みのキーで同じリクエストが再送された場合は保存したレスポンスを返し、
+// 異なるリクエストに同じキーを使用した場合・同じキーのリクエストを処理中の場合は 409 を返します。
+// サーバーエラー(5xx)のレスポンス・Cache-Control: no-store を指定したレスポンス(アクセストークンなどの認証情報を含むもの)は保存せず、
+// 同じキーでの再送を新しいリクエストとして処理します。
+// キーはメソッド・パス・認証済みユーザー(未認証の場合は接続元)・テナントごとに区別するため、AuthMiddleware・TenantMiddleware の後に適用すること。
+func Middleware(store *Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(KeyHeader)
+		if c.Request.Method != "POST" || key == "" {
+			c.Next()
+			return
+		}
+		if !validKey.MatchString(key) {
+			abort(c, "BAD_REQUEST", "Idempotency-Key は英数字と . _ : - の 255文字以内で指定してください")
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxRequestBody+1))
+		if err != nil {
+			log.WithError(err).Error("Failed to read request body")
+			abort(c, "BAD_REQUEST", "リクエストを読み込めませんでした")
+			return
+		}
+		if len(body) > maxRequestBody {
+			abort(c, "PAYLOAD_TOO_LARGE", "Idempotency-Key を指定したリクエストのボディは 1MB 以内としてください")
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		ctx := c.Request.Context()
+		scope := requestScope(c)
+		requestHash := fingerprint(c, body)
+		fields := log.Fields{"idempotencyKey": key, "scope": scope}
+
+		record, err := acquire(ctx, store, c, scope, key, requestHash)
+		if err != nil {
+			log.WithError(err).WithFields(fields).Error("Failed to acquire idempotency key")
+			abort(c, "INTERNAL_SERVER_ERROR", "リクエストを処理できませんでした")
+			return
+		}
+		// 保存したレスポンス・409 を返した場合
+		if record == nil {
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		// 処理の結果はクライアントの切断によらず保存します
+		saveCtx := context.WithoutCancel(ctx)
+		completed := false
+		defer func() {
+			if completed {
+				return
+			}
+			// パニックした場合は処理中のキーを削除してから、リカバリーに委ねます
+			if err := store.release(saveCtx, record); err != nil {
+				log.WithError(err).WithFields(fields).Error("Failed to release idempotency key")
+			}
+		}()
+
+		c.Next()
+
+		responseStatus := c.Writer.Status()
+		if responseStatus >= 500 {
+			return
+		}
+		// 認証情報を含むレスポンスはデータベースに保存しない
+		if noStore(c.Writer.Header()) {
+			log.WithFields(fields).Warn("Idempotent response is not stored because it is marked no-store")
+			return
+		}
+		if err := store.complete(saveCtx, record, responseStatus, c.Writer.Header().Get("Content-Type"), recorder.body.String()); err != nil {
+			// レスポンスは送信済みのため、保存できなかったキーは削除して再送を新しいリクエストとして処理します
+			log.WithError(err).WithFields(fields).Error("Failed to save idempotent response")
+			return
+		}
+		completed = true
+	}
+}
+
+// acquire はキーを処理中として登録し、このリクエストで処理するキーを返します
+// 保存したレスポンス・409 を返した場合は nil を返します
+func acquire(ctx context.Context, store *Store, c *gin.Context, scope string, key string, requestHash string) (*entity.IdempotencyKey, error) {
+	fields := log.Fields{"idempotencyKey": key, "scope": scope}
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		record, reserved, err := store.reserve(ctx, scope, key, requestHash)
+		if err != nil {
+			return nil, err
+		}
+		if reserved {
+			return record, nil
+		}
+
+		record, err = store.find(ctx, scope, key)
+		if isNotFound(err) {
+			// 処理中のリクエストが失敗して削除された場合は登録し直します
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		if store.expired(record) || store.abandoned(record) {
+			tookOver, err := store.takeOver(ctx, record, requestHash)
+			if err != nil {
+				return nil, err
+			}
+			if tookOver {
+				log.WithFields(fields).Info("Took over expired idempotency key")
+				return record, nil
+			}
+			continue
+		}
+
+		switch {
+		case record.RequestHash != requestHash:
+			log.WithFields(fields).Warn("Idempotency key reused with a different request")
+			abort(c, "CONFLICT", "Idempotency-Key が別のリクエストで使用されています")
+		case record.ResponseStatus == nil:
+			log.WithFields(fields).Info("Request with the same idempotency key is in progress")
+			c.Header("Retry-After", "1")
+			abort(c, "CONFLICT", "同じ Idempotency-Key のリクエストを処理中です")
+		default:
+			log.WithFields(fields).Info("Replaying idempotent response")
+			replay(c, record)
+		}
+		return nil, nil
+	}
+	abort(c, "CONFLICT", "同じ Idempotency-Key のリクエストを処理中です")
+	return nil, nil
+}
+
+// requestScope はキーを使用できる範囲を返します
+// 他のユーザー・テナントのリクエストに保存したレスポンスを返さないよう、認証済みユーザー・テナントを含めます
+func requestScope(c *gin.Context) string {
+	ctx := c.Request.Context()
+	scope := []string{c.Request.Method, c.Request.URL.Path}
+	if userId, ok := auth.UserIdFromContext(ctx); ok {
+		scope = append(scope, "user:"+userId)
+	} else {
+		// 未認証のリクエスト(ユーザー登録など)は、他のクライアントが同じキーを使用しても保存したレスポンスを返さないよう、
+		// 接続元のIPアドレス・User-Agent で区別する(個人情報を保存しないようハッシュ値とする)
+		scope = append(scope, "client:"+clientFingerprint(c))
+	}
+	if t, ok := tenant.FromContext(ctx); ok {
+		scope = append(scope, "organization:"+t.OrganizationId)
+	}
+	return strings.Join(scope, " ")
+}
+
+// clientFingerprint は接続元のIPアドレス・User-Agent の SHA-256 を返します
+func clientFingerprint(c *gin.Context) string {
+	hash := sha256.New()
+	hash.Write([]byte(c.ClientIP()))
+	hash.Write([]byte{0})
+	hash.Write([]byte(c.Request.UserAgent()))
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// fingerprint はリクエストのクエリ・ボディの SHA-256 を返します
+func fingerprint(c *gin.Context, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(c.Request.URL.RawQuery))
+	hash.Write([]byte{0})
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// noStore はレスポンスの保存が禁止されているかを返します
+func noStore(header http.Header) bool {
+	for _, directive := range strings.Split(header.Get("Cache-Control"), ",") {
+		if strings.EqualFold(strings.TrimSpace(directive), "no-store") {
+			return true
+		}
+	}
+	return false
+}
+
+// replay は保存したレスポンスを返します
+func replay(c *gin.Context, record *entity.IdempotencyKey) {
+	contentType := ""
+	if record.ResponseContentType != nil {
+		contentType = *record.ResponseContentType
+	}
+	body := ""
+	if record.ResponseBody != nil {
+		body = *record.ResponseBody
+	}
+	c.Header(ReplayedHeader, "true")
+	c.Data(*record.ResponseStatus, contentType, []byte(body))
+	c.Abort()
+}
+
+func abort(c *gin.Context, statusKey string, message string) {
+	apiErr := errors.OutputApiError(
+		[]errors.ApiErrMessage{
+			{
+				Key:   "idempotencyKey",
+				Value: message,
+			},
+		},
+		status.ErrorStatusMap[statusKey].StatusCode,
+		status.ErrorStatusMap[statusKey].StatusName,
+	)
+	c.AbortWithStatusJSON(apiErr.Status, apiErr)
+}
+
+// responseRecorder はクライアントへ送信するレスポンスボディを保存のため複製します
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,349 @@
+package idempotency
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Go_CleanArch/common/auth"
+	"github.com/Go_CleanArch/infrastructure/config"
+	dbConnect "github.com/Go_CleanArch/infrastructure/db"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// メモリ上の SQLite を共有の接続として使用する(キーはテストごとに生成するため並列に実行できる)
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	dbConnect.Init(config.DatabaseConfig{
+		Driver:     dbConnect.DriverSqlite,
+		SqlitePath: ":memory:",
+		HealthCheck: config.HealthCheckConfig{
+			Interval:         time.Minute,
+			Timeout:          time.Second,
+			FailureThreshold: 3,
+		},
+		Encryption: config.EncryptionConfig{
+			Keys:          []string{"test-1:MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="},
+			ActiveKeyId:   "test-1",
+			BlindIndexKey: "ZmVkY2JhOTg3NjU0MzIxMGZlZGNiYTk4NzY1NDMyMTA=",
+		},
+	})
+	os.Exit(m.Run())
+}
+
+var testIdempotencyConfig = config.IdempotencyConfig{
+	TTL:           time.Hour,
+	LockTimeout:   time.Minute,
+	PurgeInterval: time.Hour,
+}
+
+// testClock はキーの期限を進めるための時計
+type testClock struct {
+	now atomic.Pointer[time.Time]
+}
+
+func (c *testClock) Now() time.Time {
+	return *c.now.Load()
+}
+
+func (c *testClock) advance(d time.Duration) {
+	next := c.Now().Add(d)
+	c.now.Store(&next)
+}
+
+// testServer は呼び出された回数を数えるハンドラーに Middleware を適用したサーバー
+type testServer struct {
+	engine *gin.Engine
+	clock  *testClock
+	calls  atomic.Int32
+	// 処理中のリクエストを待機させる場合に設定する
+	block chan struct{}
+	// ハンドラーが返すステータス(既定は 201)
+	status atomic.Int32
+}
+
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+	store, err := NewStore(context.Background(), testIdempotencyConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := &testServer{engine: gin.New(), clock: &testClock{}}
+	now := time.Now().UTC()
+	server.clock.now.Store(&now)
+	store.now = server.clock.Now
+	server.status.Store(http.StatusCreated)
+
+	// X-Test-User を認証済みユーザーとして扱う
+	server.engine.Use(func(c *gin.Context) {
+		if userId := c.GetHeader("X-Test-User"); userId != "" {
+			c.Request = c.Request.WithContext(auth.WithUserId(c.Request.Context(), userId))
+		}
+	})
+	server.engine.Use(Middleware(store))
+	handler := func(c *gin.Context) {
+		call := server.calls.Add(1)
+		if server.block != nil {
+			<-server.block
+		}
+		body, _ := c.GetRawData()
+		// X-Test-No-Store を指定した場合は認証情報を含むレスポンスとして扱う
+		if c.GetHeader("X-Test-No-Store") != "" {
+			c.Header("Cache-Control", "private, no-store")
+		}
+		c.JSON(int(server.status.Load()), gin.H{"call": call, "body": string(body)})
+	}
+	server.engine.POST("/api/users", handler)
+	server.engine.GET("/api/users", handler)
+	return server
+}
+
+func (s *testServer) post(key string, body string, headers ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", "/api/users", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if key != "" {
+		req.Header.Set(KeyHeader, key)
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	recorder := httptest.NewRecorder()
+	s.engine.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestMiddleware(t *testing.T) {
+	t.Parallel()
+
+	t.Run("正常系_再送には保存したレスポンスを返す", func(t *testing.T) {
+		t.Parallel()
+		server := newTestServer(t)
+		key := uuid.NewString()
+
+		first := server.post(key, `{"email":"a@example.com"}`)
+		second := server.post(key, `{"email":"a@example.com"}`)
+
+		assert.Equal(t, http.StatusCreated, first.Code)
+		assert.Equal(t, http.StatusCreated, second.Code)
+		assert.Equal(t, first.Body.String(), second.Body.String())
+		assert.Equal(t, "application/json; charset=utf-8", second.Header().Get("Content-Type"))
+		assert.Empty(t, first.Header().Get(ReplayedHeader))
+		assert.Equal(t, "true", second.Header().Get(ReplayedHeader))
+		assert.Equal(t, int32(1), server.calls.Load())
+	})
+
+	t.Run("正常系_キーを指定しない・POST 以外は毎回処理する", func(t *testing.T) {
+		t.Parallel()
+		server := newTestServer(t)
+
+		server.post("", `{}`)
+		server.post("", `{}`)
+		req := httptest.NewRequest("GET", "/api/users", nil)
+		req.Header.Set(KeyHeader, uuid.NewString())
+		server.engine.ServeHTTP(httptest.NewRecorder(), req)
+		server.engine.ServeHTTP(httptest.NewRecorder(), req)
+
+		assert.Equal(t, int32(4), server.calls.Load())
+	})
+
+	t.Run("異常系_異なるリクエストに同じキーを使用した場合は 409", func(t *testing.T) {
+		t.Parallel()
+		server := newTestServer(t)
+		key := uuid.NewString()
+
+		server.post(key, `{"email":"a@example.com"}`)
+		response := server.post(key, `{"email":"b@example.com"}`)
+
+		assert.Equal(t, http.StatusConflict, response.Code)
+		assert.Contains(t, response.Body.String(), "別のリクエスト")
+		assert.Equal(t, int32(1), server.calls.Load())
+	})
+
+	t.Run("異常系_同じキーのリクエストを処理中の場合は 409", func(t *testing.T) {
+		t.Parallel()
+		server := newTestServer(t)
+		server.block = make(chan struct{})
+		key := uuid.NewString()
+
+		done := make(chan *httptest.ResponseRecorder)
+		go func() {
+			done <- server.post(key, `{}`)
+		}()
+		assert.Eventually(t, func() bool { return server.calls.Load() == 1 }, time.Second, time.Millisecond)
+
+		inFlight := server.post(key, `{}`)
+		close(server.block)
+		first := <-done
+
+		assert.Equal(t, http.StatusConflict, inFlight.Code)
+		assert.Equal(t, "1", inFlight.Header().Get("Retry-After"))
+		assert.Equal(t, http.StatusCreated, first.Code)
+		assert.Equal(t, int32(1), server.calls.Load())
+
+		// 処理の完了後は保存したレスポンスを返す
+		replayed := server.post(key, `{}`)
+		assert.Equal(t, first.Body.String(), replayed.Body.String())
+	})
+
+	t.Run("正常系_同時に送信された同じキーのリクエストは1件のみ処理する", func(t *testing.T) {
+		t.Parallel()
+		server := newTestServer(t)
+		key := uuid.NewString()
+
+		responses := make(chan int, 10)
+		for i := 0; i < cap(responses); i++ {
+			go func() {
+				responses <- server.post(key, `{}`).Code
+			}()
+		}
+		codes := map[int]int{}
+		for i := 0; i < cap(responses); i++ {
+			codes[<-responses]++
+		}
+
+		assert.Equal(t, int32(1), server.calls.Load())
+		assert.Equal(t, cap(responses), codes[http.StatusCreated]+codes[http.StatusConflict])
+	})
+
+	t.Run("正常系_サーバーエラーは保存せず再送を処理する", func(t *testing.T) {
+		t.Parallel()
+		server := newTestServer(t)
+		key := uuid.NewString()
+
+		server.status.Store(http.StatusInternalServerError)
+		first := server.post(key, `{}`)
+		server.status.Store(http.StatusCreated)
+		second := server.post(key, `{}`)
+
+		assert.Equal(t, http.StatusInternalServerError, first.Code)
+		assert.Equal(t, http.StatusCreated, second.Code)
+		assert.Equal(t, int32(2), server.calls.Load())
+	})
+
+	t.Run("正常系_no-store を指定したレスポンスは保存せず再送を処理する", func(t *testing.T) {
+		t.Parallel()
+		server := newTestServer(t)
+		key := uuid.NewString()
+
+		first := server.post(key, `{}`, "X-Test-No-Store", "true")
+		second := server.post(key, `{}`, "X-Test-No-Store", "true")
+
+		assert.Equal(t, http.StatusCreated, first.Code)
+		assert.Equal(t, http.StatusCreated, second.Code)
+		assert.Empty(t, second.Header().Get(ReplayedHeader))
+		assert.NotEqual(t, first.Body.String(), second.Body.String())
+		assert.Equal(t, int32(2), server.calls.Load())
+	})
+
+	t.Run("正常系_キーはユーザーごとに区別する", func(t *testing.T) {
+		t.Parallel()
+		server := newTestServer(t)
+		key := uuid.NewString()
+
+		alice := server.post(key, `{}`, "X-Test-User", "alice")
+		bob := server.post(key, `{}`, "X-Test-User", "bob")
+
+		assert.Equal(t, http.StatusCreated, alice.Code)
+		assert.Equal(t, http.StatusCreated, bob.Code)
+		assert.Empty(t, bob.Header().Get(ReplayedHeader))
+		assert.Equal(t, int32(2), server.calls.Load())
+	})
+
+	t.Run("正常系_未認証のリクエストのキーは接続元ごとに区別する", func(t *testing.T) {
+		t.Parallel()
+		server := newTestServer(t)
+		key := uuid.NewString()
+
+		first := server.post(key, `{}`, "User-Agent", "client-a")
+		other := server.post(key, `{}`, "User-Agent", "client-b")
+		replayed := server.post(key, `{}`, "User-Agent", "client-a")
+
+		// 他のクライアントには保存したレスポンスを返さない
+		assert.Equal(t, http.StatusCreated, other.Code)
+		assert.Empty(t, other.Header().Get(ReplayedHeader))
+		assert.NotEqual(t, first.Body.String(), other.Body.String())
+		// 同じクライアントの再送には保存したレスポンスを返す
+		assert.Equal(t, "true", replayed.Header().Get(ReplayedHeader))
+		assert.Equal(t, first.Body.String(), replayed.Body.String())
+		assert.Equal(t, int32(2), server.calls.Load())
+	})
+
+	t.Run("正常系_保存期間を過ぎたキーは新しいリクエストとして処理する", func(t *testing.T) {
+		t.Parallel()
+		server := newTestServer(t)
+		key := uuid.NewString()
+
+		server.post(key, `{"email":"a@example.com"}`)
+		server.clock.advance(testIdempotencyConfig.TTL)
+		response := server.post(key, `{"email":"b@example.com"}`)
+
+		assert.Equal(t, http.StatusCreated, response.Code)
+		assert.Equal(t, int32(2), server.calls.Load())
+	})
+
+	t.Run("正常系_レスポンスのボディは暗号化して保存する", func(t *testing.T) {
+		t.Parallel()
+		ctx := context.Background()
+		server := newTestServer(t)
+		key := uuid.NewString()
+
+		first := server.post(key, `{"email":"secret@example.com"}`)
+
+		db, err := dbConnect.NewDBConnection(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var rows []struct{ ResponseBody string }
+		assert.NoError(t, db.FindWithRawJoinQuery(ctx, "SELECT response_body FROM idempotency_keys WHERE idempotency_key = ?", &rows, key))
+		assert.Len(t, rows, 1)
+		assert.True(t, strings.HasPrefix(rows[0].ResponseBody, "enc:v1:test-1:"))
+		assert.NotContains(t, rows[0].ResponseBody, "secret@example.com")
+		// 再送には復号したレスポンスを返す
+		second := server.post(key, `{"email":"secret@example.com"}`)
+		assert.Equal(t, first.Body.String(), second.Body.String())
+	})
+
+	t.Run("異常系_不正なキー", func(t *testing.T) {
+		t.Parallel()
+		server := newTestServer(t)
+
+		response := server.post(strings.Repeat("a", 256), `{}`)
+
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+		assert.Equal(t, int32(0), server.calls.Load())
+	})
+}
+
+func TestPurge(t *testing.T) {
+	t.Parallel()
+
+	t.Run("正常系_保存期間を過ぎたキーを削除する", func(t *testing.T) {
+		t.Parallel()
+		ctx := context.Background()
+		store, err := NewStore(ctx, testIdempotencyConfig)
+		assert.NoError(t, err)
+		// 他のテストのキーを削除しないよう、将来の日時で登録する
+		future := time.Now().UTC().Add(100 * 24 * time.Hour)
+		store.now = func() time.Time { return future }
+		scope := fmt.Sprintf("POST /purge/%s", uuid.NewString())
+		_, reserved, err := store.reserve(ctx, scope, "key", "hash")
+		assert.NoError(t, err)
+		assert.True(t, reserved)
+
+		store.now = func() time.Time { return future.Add(testIdempotencyConfig.TTL) }
+		purged, err := store.Purge(ctx)
+
+		assert.NoError(t, err)
+		assert.GreaterOrEqual(t, purged, int64(1))
+		_, err = store.find(ctx, scope, "key")
+		assert.True(t, isNotFound(err))
+	})
+}
@@ -0,0 +1,157 @@
+// Package idempotency は Idempotency-Key を指定した POST リクエストのレスポンスを保存し、再送されたリクエストに同じレスポンスを返します
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Go_CleanArch/infrastructure/config"
+	dbConnect "github.com/Go_CleanArch/infrastructure/db"
+	"github.com/Go_CleanArch/interface_adapter/gateway/entity"
+	repository "github.com/Go_CleanArch/usecase/repository_interface"
+	log "github.com/sirupsen/logrus"
+)
+
+// キーの一意制約(scope と idempotency_key の組)
+const idempotencyKeysUniqueIndex = "idempotency_keys_key"
+
+// Store はリクエストの指紋とレスポンスを idempotency_keys テーブルに保存します
+type Store struct {
+	keys   *dbConnect.Repository[entity.IdempotencyKey]
+	config config.IdempotencyConfig
+	now    func() time.Time
+}
+
+func NewStore(ctx context.Context, cfg config.IdempotencyConfig) (*Store, error) {
+	conn, err := dbConnect.NewDBConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &Store{
+		keys:   dbConnect.NewRepository[entity.IdempotencyKey](conn),
+		config: cfg,
+		// 日時は期限の比較のため UTC で保存します
+		now: func() time.Time { return time.Now().UTC() },
+	}, nil
+}
+
+// reserve はキーを処理中として登録します
+// 同じキーが登録済みの場合は false を返します(同時に送信されたリクエストは一意制約により1件のみ登録します)
+func (s *Store) reserve(ctx context.Context, scope string, key string, requestHash string) (*entity.IdempotencyKey, bool, error) {
+	now := s.now()
+	lockedUntil := now.Add(s.config.LockTimeout)
+	record := &entity.IdempotencyKey{
+		Scope:          scope,
+		IdempotencyKey: key,
+		RequestHash:    requestHash,
+		LockedUntil:    &lockedUntil,
+		ExpiresAt:      now.Add(s.config.TTL),
+		CreatedAt:      now,
+	}
+	if err := s.keys.Create(ctx, record); err != nil {
+		if dbConnect.IsUniqueViolationOnColumns(err, idempotencyKeysUniqueIndex, "idempotency_keys", "scope", "idempotency_key") {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	return record, true, nil
+}
+
+// find は登録済みのキーを返します(削除された場合は repository.ErrNotFound)
+func (s *Store) find(ctx context.Context, scope string, key string) (*entity.IdempotencyKey, error) {
+	return s.keys.First(ctx, dbConnect.Eq("scope", scope), dbConnect.Eq("idempotency_key", key))
+}
+
+// expired は保存期間を過ぎたキーか
+func (s *Store) expired(record *entity.IdempotencyKey) bool {
+	return !record.ExpiresAt.After(s.now())
+}
+
+// abandoned は処理中のまま期限を過ぎたキーか(処理中にサーバーが停止した場合など)
+func (s *Store) abandoned(record *entity.IdempotencyKey) bool {
+	return record.ResponseStatus == nil && record.LockedUntil != nil && !record.LockedUntil.After(s.now())
+}
+
+// takeOver は保存期間を過ぎたキー・処理中のまま期限を過ぎたキーを、新しいリクエストの処理中として登録し直します
+// 他のリクエストが先に登録し直した場合は false を返します
+func (s *Store) takeOver(ctx context.Context, record *entity.IdempotencyKey, requestHash string) (bool, error) {
+	now := s.now()
+	lockedUntil := now.Add(s.config.LockTimeout)
+	updated, err := s.keys.UpdateColumns(ctx,
+		map[string]interface{}{
+			"request_hash":          requestHash,
+			"response_status":       nil,
+			"response_content_type": nil,
+			"response_body":         nil,
+			"locked_until":          lockedUntil,
+			"expires_at":            now.Add(s.config.TTL),
+			"created_at":            now,
+		},
+		dbConnect.Eq("id", record.Id),
+		dbConnect.Where("expires_at <= ? OR (response_status IS NULL AND locked_until <= ?)", now, now),
+	)
+	if err != nil {
+		return false, err
+	}
+	if updated == 1 {
+		record.RequestHash = requestHash
+	}
+	return updated == 1, nil
+}
+
+// complete はレスポンスを保存します(ボディは暗号化して保存します)
+func (s *Store) complete(ctx context.Context, record *entity.IdempotencyKey, status int, contentType string, body string) error {
+	encryptedBody, err := dbConnect.EncryptValue(body)
+	if err != nil {
+		return err
+	}
+	_, err = s.keys.UpdateColumns(ctx,
+		map[string]interface{}{
+			"response_status":       status,
+			"response_content_type": contentType,
+			"response_body":         encryptedBody,
+			"locked_until":          nil,
+		},
+		dbConnect.Eq("id", record.Id),
+	)
+	return err
+}
+
+// release は処理中のキーを削除し、同じキーでの再送を新しいリクエストとして処理できるようにします
+func (s *Store) release(ctx context.Context, record *entity.IdempotencyKey) error {
+	_, err := s.keys.Delete(ctx, dbConnect.Eq("id", record.Id), dbConnect.IsNull("response_status"))
+	return err
+}
+
+// Purge は保存期間を過ぎたキーを削除し、削除した件数を返します
+func (s *Store) Purge(ctx context.Context) (int64, error) {
+	return s.keys.Delete(ctx, dbConnect.Where("expires_at <= ?", s.now()))
+}
+
+// RunPurge は ctx がキャンセルされるまで、purgeInterval ごとに保存期間を過ぎたキーを削除します
+func (s *Store) RunPurge(ctx context.Context) {
+	ticker := time.NewTicker(s.config.PurgeInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		purged, err := s.Purge(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				log.WithError(err).Error("Failed to purge expired idempotency keys")
+			}
+			continue
+		}
+		if purged > 0 {
+			log.WithField("purged", purged).Info("Expired idempotency keys purged")
+		}
+	}
+}
+
+func isNotFound(err error) bool {
+	return errors.Is(err, repository.ErrNotFound)
+}
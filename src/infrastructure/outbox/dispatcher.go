@@ -0,0 +1,291 @@
+// Package outbox はアウトボックスに保存したドメインイベントを、プロセス内の購読者へ配信します
+// 配信は少なくとも1回(at-least-once)であり、同じ集約のイベントは保存した順に配信します
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Go_CleanArch/domain/event"
+	"github.com/Go_CleanArch/infrastructure/config"
+	dbConnect "github.com/Go_CleanArch/infrastructure/db"
+	"github.com/Go_CleanArch/interface_adapter/gateway/entity"
+	log "github.com/sirupsen/logrus"
+)
+
+// Message は購読者へ配信するイベントを表します
+type Message struct {
+	EventId       string
+	EventName     string
+	AggregateType string
+	AggregateId   string
+	// JSON にエンコードしたイベント
+	Payload    []byte
+	OccurredAt time.Time
+	// 配信の試行回数(初回は1)
+	Attempt int
+}
+
+// Handler はイベントを処理します。エラーを返した場合は時間をおいて再試行します
+// 同じイベントが複数回配信される場合があるため、冪等に処理してください
+type Handler func(ctx context.Context, message Message) error
+
+// Handle は Payload をイベントの型に復元して fn を呼び出す Handler を返します
+func Handle[E event.Event](fn func(ctx context.Context, e E) error) Handler {
+	return func(ctx context.Context, message Message) error {
+		var e E
+		if err := json.Unmarshal(message.Payload, &e); err != nil {
+			return fmt.Errorf("イベントを復元できませんでした(%s): %w", message.EventName, err)
+		}
+		return fn(ctx, e)
+	}
+}
+
+// Dispatcher は配信待ちのイベントを定期的に取得し、イベントの種類ごとに登録した購読者へ配信します
+// 複数のプロセスで実行した場合も、イベントごとに配信中の期限(locked_until)を設定するため同時に配信しません
+type Dispatcher struct {
+	outboxEvents *dbConnect.Repository[entity.OutboxEvent]
+	config       config.OutboxConfig
+	now          func() time.Time
+
+	mu       sync.RWMutex
+	handlers map[string][]Handler
+}
+
+func NewDispatcher(ctx context.Context, cfg config.OutboxConfig) (*Dispatcher, error) {
+	// 配信待ちのイベントの確認は pollInterval ごとに行うため、クエリのログは出力しない
+	conn, err := dbConnect.NewDBConnection(ctx, dbConnect.WithoutQueryLog())
+	if err != nil {
+		return nil, err
+	}
+	return &Dispatcher{
+		outboxEvents: dbConnect.NewRepository[entity.OutboxEvent](conn),
+		config:       cfg,
+		now:          func() time.Time { return time.Now().UTC() },
+		handlers:     make(map[string][]Handler),
+	}, nil
+}
+
+// Subscribe は eventName のイベントの購読者を登録します
+// 複数の購読者を登録した場合は登録した順に呼び出し、いずれかが失敗した場合はすべての購読者へ再配信します
+func (d *Dispatcher) Subscribe(eventName string, handler Handler) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.handlers[eventName] = append(d.handlers[eventName], handler)
+}
+
+func (d *Dispatcher) handlersFor(eventName string) []Handler {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.handlers[eventName]
+}
+
+// Run は ctx がキャンセルされるまで、pollInterval ごとに配信待ちのイベントを配信します
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.config.PollInterval)
+	defer ticker.Stop()
+	for {
+		// 取得件数の上限まで配信した場合は、待機せずに残りのイベントを配信します
+		for {
+			dispatched, err := d.DispatchPending(ctx)
+			if err != nil {
+				if ctx.Err() == nil {
+					log.WithError(err).Error("Failed to dispatch outbox events")
+				}
+				break
+			}
+			if dispatched < d.config.BatchSize {
+				break
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// pending は配信できるイベントに絞り込みます(配信済み・デッドレター・再試行の待機中・配信中を除く)
+func pending(now time.Time) dbConnect.Condition {
+	return dbConnect.Where(
+		"published_at IS NULL AND dead_lettered_at IS NULL AND next_attempt_at <= ? AND (locked_until IS NULL OR locked_until <= ?)",
+		now, now,
+	)
+}
+
+// headOfAggregate は集約ごとに未配信の最も古いイベントに絞り込みます
+// 先行するイベントが再試行の待機中・配信中の場合、後続のイベントは配信しません
+// デッドレターとなったイベントは後続のイベントの配信を妨げません
+func headOfAggregate() dbConnect.Condition {
+	return dbConnect.Where(`NOT EXISTS (
+		SELECT 1 FROM outbox AS earlier
+		WHERE earlier.aggregate_type = outbox.aggregate_type
+		AND earlier.aggregate_id = outbox.aggregate_id
+		AND earlier.id < outbox.id
+		AND earlier.published_at IS NULL
+		AND earlier.dead_lettered_at IS NULL
+	)`)
+}
+
+// DispatchPending は配信待ちのイベントを最大 batchSize 件配信し、配信を試行した件数を返します
+// 購読者の失敗は再試行・デッドレターとして記録し、エラーとしては返しません
+func (d *Dispatcher) DispatchPending(ctx context.Context) (int, error) {
+	candidates, err := d.outboxEvents.Find(ctx,
+		pending(d.now()),
+		headOfAggregate(),
+		dbConnect.OrderBy("id"),
+		dbConnect.Limit(d.config.BatchSize),
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	dispatched := 0
+	for _, outboxEvent := range candidates {
+		if err := ctx.Err(); err != nil {
+			return dispatched, err
+		}
+		claimed, err := d.claim(ctx, outboxEvent)
+		if err != nil {
+			return dispatched, err
+		}
+		// 他のディスパッチャーが配信中
+		if !claimed {
+			continue
+		}
+		handleErr := d.deliver(ctx, outboxEvent)
+		// 停止の要求があった場合も配信の結果は記録します
+		if err := d.complete(context.WithoutCancel(ctx), outboxEvent, handleErr); err != nil {
+			return dispatched, err
+		}
+		dispatched++
+	}
+	return dispatched, nil
+}
+
+// claim はイベントに配信中の期限を設定し、他のディスパッチャーが配信しないようにします
+func (d *Dispatcher) claim(ctx context.Context, outboxEvent entity.OutboxEvent) (bool, error) {
+	now := d.now()
+	claimed, err := d.outboxEvents.UpdateColumns(ctx,
+		map[string]interface{}{"locked_until": now.Add(d.config.LockTimeout)},
+		dbConnect.Eq("id", outboxEvent.Id),
+		pending(now),
+	)
+	if err != nil {
+		return false, err
+	}
+	return claimed == 1, nil
+}
+
+// deliver はイベントの種類の購読者を順に呼び出します
+// 購読者の処理時間は配信中の期限までとします
+func (d *Dispatcher) deliver(ctx context.Context, outboxEvent entity.OutboxEvent) error {
+	message := Message{
+		EventId:       outboxEvent.EventId,
+		EventName:     outboxEvent.EventName,
+		AggregateType: outboxEvent.AggregateType,
+		AggregateId:   outboxEvent.AggregateId,
+		Payload:       []byte(outboxEvent.Payload),
+		OccurredAt:    outboxEvent.OccurredAt,
+		Attempt:       outboxEvent.Attempts + 1,
+	}
+	ctx, cancel := context.WithTimeout(ctx, d.config.LockTimeout)
+	defer cancel()
+	for _, handler := range d.handlersFor(outboxEvent.EventName) {
+		if err := safeHandle(ctx, handler, message); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// safeHandle は購読者のパニックを配信の失敗として扱います
+func safeHandle(ctx context.Context, handler Handler, message Message) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("購読者がパニックしました: %v", r)
+		}
+	}()
+	return handler(ctx, message)
+}
+
+// complete は配信の結果を記録します
+// 失敗した場合は再試行の日時を設定し、試行回数が上限に達した場合はデッドレターとします
+func (d *Dispatcher) complete(ctx context.Context, outboxEvent entity.OutboxEvent, handleErr error) error {
+	now := d.now()
+	attempts := outboxEvent.Attempts + 1
+	fields := log.Fields{
+		"eventId":     outboxEvent.EventId,
+		"eventName":   outboxEvent.EventName,
+		"aggregateId": outboxEvent.AggregateId,
+		"attempts":    attempts,
+	}
+	values := map[string]interface{}{
+		"attempts":     attempts,
+		"locked_until": nil,
+	}
+	switch {
+	case handleErr == nil:
+		values["published_at"] = now
+		values["last_error"] = nil
+		log.WithFields(fields).Info("Outbox event published")
+	case attempts >= d.config.MaxAttempts:
+		values["dead_lettered_at"] = now
+		values["last_error"] = handleErr.Error()
+		log.WithFields(fields).WithError(handleErr).Error("Outbox event dead-lettered")
+	default:
+		values["next_attempt_at"] = now.Add(d.retryDelay(attempts))
+		values["last_error"] = handleErr.Error()
+		log.WithFields(fields).WithError(handleErr).Warn("Failed to handle outbox event, will retry")
+	}
+
+	if _, err := d.outboxEvents.UpdateColumns(ctx, values, dbConnect.Eq("id", outboxEvent.Id)); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Purge は保存期間を過ぎた配信済みのイベントを削除し、削除した件数を返します
+// イベントにはメールアドレス・IPアドレスが含まれるため、配信後は保存期間を過ぎたら削除します
+func (d *Dispatcher) Purge(ctx context.Context) (int64, error) {
+	return d.outboxEvents.Delete(ctx, dbConnect.Where("published_at <= ?", d.now().Add(-d.config.Retention)))
+}
+
+// RunPurge は ctx がキャンセルされるまで、purgeInterval ごとに保存期間を過ぎた配信済みのイベントを削除します
+func (d *Dispatcher) RunPurge(ctx context.Context) {
+	ticker := time.NewTicker(d.config.PurgeInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		purged, err := d.Purge(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				log.WithError(err).Error("Failed to purge published outbox events")
+			}
+			continue
+		}
+		if purged > 0 {
+			log.WithField("purged", purged).Info("Published outbox events purged")
+		}
+	}
+}
+
+// retryDelay は attempts 回目の失敗後の待機時間を返します(retryBaseDelay から失敗するごとに2倍)
+func (d *Dispatcher) retryDelay(attempts int) time.Duration {
+	delay := d.config.RetryBaseDelay
+	for i := 1; i < attempts && delay < d.config.RetryMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > d.config.RetryMaxDelay {
+		return d.config.RetryMaxDelay
+	}
+	return delay
+}
@@ -0,0 +1,319 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Go_CleanArch/domain/event"
+	"github.com/Go_CleanArch/infrastructure/config"
+	dbConnect "github.com/Go_CleanArch/infrastructure/db"
+	"github.com/Go_CleanArch/interface_adapter/gateway/entity"
+	gatewayRepository "github.com/Go_CleanArch/interface_adapter/gateway/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+// メモリ上の SQLite を共有の接続として使用する
+// 配信はテーブル全体が対象となるため、テストは並列に実行しない
+func TestMain(m *testing.M) {
+	dbConnect.Init(config.DatabaseConfig{
+		Driver:     dbConnect.DriverSqlite,
+		SqlitePath: ":memory:",
+		HealthCheck: config.HealthCheckConfig{
+			Interval:         time.Minute,
+			Timeout:          time.Second,
+			FailureThreshold: 3,
+		},
+		Encryption: config.EncryptionConfig{
+			Keys:          []string{"test-1:MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="},
+			ActiveKeyId:   "test-1",
+			BlindIndexKey: "ZmVkY2JhOTg3NjU0MzIxMGZlZGNiYTk4NzY1NDMyMTA=",
+		},
+	})
+	os.Exit(m.Run())
+}
+
+var testOutboxConfig = config.OutboxConfig{
+	PollInterval:   time.Second,
+	BatchSize:      10,
+	MaxAttempts:    3,
+	RetryBaseDelay: time.Second,
+	RetryMaxDelay:  10 * time.Second,
+	LockTimeout:    time.Minute,
+	Retention:      time.Hour,
+	PurgeInterval:  time.Hour,
+}
+
+// testClock は配信の日時を進めるための時計
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+// newTestDispatcher は配信待ちのイベントを空にしたうえで、時計を差し替えたディスパッチャーを返す
+func newTestDispatcher(t *testing.T, cfg config.OutboxConfig) (*Dispatcher, *testClock) {
+	t.Helper()
+	ctx := context.Background()
+	dispatcher, err := NewDispatcher(ctx, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dispatcher.outboxEvents.Delete(ctx, dbConnect.Where("1 = 1")); err != nil {
+		t.Fatal(err)
+	}
+	// 保存した直後のイベントを配信できるよう、現在より後の日時から開始する
+	clock := &testClock{now: time.Now().UTC().Add(time.Second)}
+	dispatcher.now = func() time.Time { return clock.now }
+	return dispatcher, clock
+}
+
+func addEvents(t *testing.T, events ...event.Event) {
+	t.Helper()
+	outboxRepository, err := gatewayRepository.NewOutboxRepository(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := outboxRepository.AddEvents(context.Background(), events...); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func loggedIn(userId string, userAgent string) event.UserLoggedIn {
+	return event.UserLoggedIn{UserId: userId, UserAgent: userAgent, LoggedInAt: time.Now()}
+}
+
+// findOutboxEvent はペイロードに userAgent を含むイベントを返す(ペイロードは暗号化して保存するため、復号してから照合する)
+func findOutboxEvent(t *testing.T, dispatcher *Dispatcher, userAgent string) *entity.OutboxEvent {
+	t.Helper()
+	outboxEvents, err := dispatcher.outboxEvents.Find(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, outboxEvent := range outboxEvents {
+		if strings.Contains(outboxEvent.Payload, userAgent) {
+			return &outboxEvent
+		}
+	}
+	t.Fatalf("イベントが見つかりません: %s", userAgent)
+	return nil
+}
+
+func TestDispatcher(t *testing.T) {
+	t.Run("正常系_購読者へ配信し配信済みとする", func(t *testing.T) {
+		ctx := context.Background()
+		dispatcher, _ := newTestDispatcher(t, testOutboxConfig)
+		received := []event.UserLoggedIn{}
+		dispatcher.Subscribe(event.UserLoggedInName, Handle(func(ctx context.Context, e event.UserLoggedIn) error {
+			received = append(received, e)
+			return nil
+		}))
+		addEvents(t, loggedIn("user-1", "first"), event.UserRegistered{UserId: "user-2", Email: "test@example.com", RegisteredAt: time.Now()})
+
+		dispatched, err := dispatcher.DispatchPending(ctx)
+
+		assert.NoError(t, err)
+		// 購読者のいないイベントも配信済みとする
+		assert.Equal(t, 2, dispatched)
+		assert.Len(t, received, 1)
+		assert.Equal(t, "user-1", received[0].UserId)
+		assert.Equal(t, "first", received[0].UserAgent)
+		published := findOutboxEvent(t, dispatcher, "first")
+		assert.NotNil(t, published.PublishedAt)
+		assert.Nil(t, published.LockedUntil)
+		assert.Equal(t, 1, published.Attempts)
+
+		// 配信済みのイベントは再配信しない
+		dispatched, err = dispatcher.DispatchPending(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, dispatched)
+	})
+
+	t.Run("正常系_同じ集約のイベントは保存した順に配信する", func(t *testing.T) {
+		ctx := context.Background()
+		dispatcher, clock := newTestDispatcher(t, testOutboxConfig)
+		received := []string{}
+		failFirst := true
+		dispatcher.Subscribe(event.UserLoggedInName, Handle(func(ctx context.Context, e event.UserLoggedIn) error {
+			if e.UserAgent == "a-1" && failFirst {
+				failFirst = false
+				return fmt.Errorf("temporary failure")
+			}
+			received = append(received, e.UserAgent)
+			return nil
+		}))
+		addEvents(t, loggedIn("user-a", "a-1"), loggedIn("user-a", "a-2"), loggedIn("user-b", "b-1"))
+
+		// a-1 の配信に失敗した場合、a-2 は a-1 の配信まで待機し、他の集約のイベントは配信する
+		_, err := dispatcher.DispatchPending(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"b-1"}, received)
+		failed := findOutboxEvent(t, dispatcher, "a-1")
+		assert.Equal(t, "temporary failure", *failed.LastError)
+
+		// 再試行の日時までは配信しない
+		dispatched, err := dispatcher.DispatchPending(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, dispatched)
+
+		clock.advance(testOutboxConfig.RetryBaseDelay)
+		for i := 0; i < 2; i++ {
+			_, err = dispatcher.DispatchPending(ctx)
+			assert.NoError(t, err)
+		}
+		assert.Equal(t, []string{"b-1", "a-1", "a-2"}, received)
+		assert.Nil(t, findOutboxEvent(t, dispatcher, "a-1").LastError)
+	})
+
+	t.Run("異常系_試行回数の上限に達したイベントはデッドレターとする", func(t *testing.T) {
+		ctx := context.Background()
+		dispatcher, clock := newTestDispatcher(t, testOutboxConfig)
+		received := []string{}
+		dispatcher.Subscribe(event.UserLoggedInName, Handle(func(ctx context.Context, e event.UserLoggedIn) error {
+			if e.UserAgent == "poison" {
+				return fmt.Errorf("cannot handle")
+			}
+			received = append(received, e.UserAgent)
+			return nil
+		}))
+		addEvents(t, loggedIn("user-c", "poison"), loggedIn("user-c", "after"))
+
+		for i := 0; i < testOutboxConfig.MaxAttempts; i++ {
+			_, err := dispatcher.DispatchPending(ctx)
+			assert.NoError(t, err)
+			clock.advance(testOutboxConfig.RetryMaxDelay)
+		}
+
+		deadLettered := findOutboxEvent(t, dispatcher, "poison")
+		assert.NotNil(t, deadLettered.DeadLetteredAt)
+		assert.Nil(t, deadLettered.PublishedAt)
+		assert.Equal(t, testOutboxConfig.MaxAttempts, deadLettered.Attempts)
+		assert.Equal(t, "cannot handle", *deadLettered.LastError)
+		// デッドレターとなったイベントは後続のイベントの配信を妨げない
+		_, err := dispatcher.DispatchPending(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"after"}, received)
+	})
+
+	t.Run("異常系_購読者のパニックは配信の失敗として再試行する", func(t *testing.T) {
+		ctx := context.Background()
+		dispatcher, clock := newTestDispatcher(t, testOutboxConfig)
+		dispatcher.Subscribe(event.UserLoggedInName, func(ctx context.Context, message Message) error {
+			panic("unexpected")
+		})
+		addEvents(t, loggedIn("user-d", "panic"))
+
+		dispatched, err := dispatcher.DispatchPending(ctx)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, dispatched)
+		failed := findOutboxEvent(t, dispatcher, "panic")
+		assert.Contains(t, *failed.LastError, "unexpected")
+		assert.Nil(t, failed.PublishedAt)
+		assert.WithinDuration(t, clock.now.Add(testOutboxConfig.RetryBaseDelay), failed.NextAttemptAt, time.Millisecond)
+	})
+
+	t.Run("正常系_配信中のイベントは期限まで取得しない", func(t *testing.T) {
+		ctx := context.Background()
+		dispatcher, clock := newTestDispatcher(t, testOutboxConfig)
+		received := 0
+		dispatcher.Subscribe(event.UserLoggedInName, func(ctx context.Context, message Message) error {
+			received++
+			return nil
+		})
+		addEvents(t, loggedIn("user-e", "locked"))
+
+		// 他のディスパッチャーが配信中
+		claimed, err := dispatcher.claim(ctx, *findOutboxEvent(t, dispatcher, "locked"))
+		assert.NoError(t, err)
+		assert.True(t, claimed)
+		claimed, err = dispatcher.claim(ctx, *findOutboxEvent(t, dispatcher, "locked"))
+		assert.NoError(t, err)
+		assert.False(t, claimed)
+
+		dispatched, err := dispatcher.DispatchPending(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, dispatched)
+
+		// 配信中の期限を過ぎた場合は、配信に失敗したものとして再度配信する
+		clock.advance(testOutboxConfig.LockTimeout)
+		dispatched, err = dispatcher.DispatchPending(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, dispatched)
+		assert.Equal(t, 1, received)
+	})
+}
+
+func TestOutboxPayload(t *testing.T) {
+	t.Run("正常系_ペイロードを暗号化して保存する", func(t *testing.T) {
+		ctx := context.Background()
+		dispatcher, _ := newTestDispatcher(t, testOutboxConfig)
+		addEvents(t, event.UserRegistered{UserId: "user-f", Email: "secret@example.com", RegisteredAt: time.Now()})
+
+		db, err := dbConnect.NewDBConnection(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var rows []struct{ Payload string }
+		assert.NoError(t, db.FindWithRawJoinQuery(ctx, "SELECT payload FROM outbox WHERE aggregate_id = ?", &rows, "user-f"))
+		assert.Len(t, rows, 1)
+		assert.True(t, strings.HasPrefix(rows[0].Payload, "enc:v1:test-1:"))
+		assert.NotContains(t, rows[0].Payload, "secret@example.com")
+		// 取得時に復号する
+		assert.Contains(t, findOutboxEvent(t, dispatcher, "secret@example.com").Payload, `"userId":"user-f"`)
+	})
+}
+
+func TestPurge(t *testing.T) {
+	t.Run("正常系_保存期間を過ぎた配信済みのイベントのみ削除する", func(t *testing.T) {
+		ctx := context.Background()
+		dispatcher, clock := newTestDispatcher(t, testOutboxConfig)
+		dispatcher.Subscribe(event.UserLoggedInName, Handle(func(ctx context.Context, e event.UserLoggedIn) error {
+			if e.UserAgent == "dead" {
+				return fmt.Errorf("cannot handle")
+			}
+			return nil
+		}))
+		addEvents(t, loggedIn("user-g", "published"), loggedIn("user-h", "dead"))
+		for i := 0; i < testOutboxConfig.MaxAttempts; i++ {
+			_, err := dispatcher.DispatchPending(ctx)
+			assert.NoError(t, err)
+			clock.advance(testOutboxConfig.RetryMaxDelay)
+		}
+		addEvents(t, loggedIn("user-i", "pending"))
+
+		// 保存期間内のイベントは削除しない
+		purged, err := dispatcher.Purge(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(0), purged)
+
+		clock.advance(testOutboxConfig.Retention)
+		purged, err = dispatcher.Purge(ctx)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), purged)
+		remaining, err := dispatcher.outboxEvents.Find(ctx)
+		assert.NoError(t, err)
+		// デッドレター・配信前のイベントは削除しない
+		assert.Len(t, remaining, 2)
+		assert.NotNil(t, findOutboxEvent(t, dispatcher, "dead").DeadLetteredAt)
+		assert.Nil(t, findOutboxEvent(t, dispatcher, "pending").PublishedAt)
+	})
+}
+
+func TestRetryDelay(t *testing.T) {
+	t.Parallel()
+	dispatcher := &Dispatcher{config: testOutboxConfig}
+
+	assert.Equal(t, time.Second, dispatcher.retryDelay(1))
+	assert.Equal(t, 2*time.Second, dispatcher.retryDelay(2))
+	assert.Equal(t, 8*time.Second, dispatcher.retryDelay(4))
+	// 上限を超えない
+	assert.Equal(t, 10*time.Second, dispatcher.retryDelay(5))
+	assert.Equal(t, 10*time.Second, dispatcher.retryDelay(100))
+}
@@ -0,0 +1,100 @@
+package seed
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/Go_CleanArch/infrastructure/config"
+	dbConnect "github.com/Go_CleanArch/infrastructure/db"
+	gatewayRepository "github.com/Go_CleanArch/interface_adapter/gateway/repository"
+	repository "github.com/Go_CleanArch/usecase/repository_interface"
+)
+
+// 投入したユーザーの既定のパスワード(サインアップと同じく英大文字・英小文字・数字を含む)
+const DefaultPassword = "Passw0rd"
+
+// 結果に一覧を表示するユーザーの上限
+const listLimit = 10
+
+// 進捗を表示する間隔(件数)
+const progressInterval = 1000
+
+// Options は seed コマンドの指定
+type Options struct {
+	Profile string
+	// load-test で投入するユーザーの件数
+	Users    int
+	Seed     uint64
+	Password string
+}
+
+// seed サブコマンドの実行
+// go run main.go seed [-profile minimal|demo|load-test] [-users 件数] [-seed 値] [-password パスワード]
+// 本番環境のデータベースには投入しない。
+func RunCommand(ctx context.Context, args []string, out io.Writer, loadConfig func() (*config.Config, error)) error {
+	flags := flag.NewFlagSet("seed", flag.ContinueOnError)
+	flags.SetOutput(out)
+	var options Options
+	flags.StringVar(&options.Profile, "profile", ProfileMinimal, "投入するデータの規模(minimal / demo / load-test)")
+	flags.IntVar(&options.Users, "users", 0, fmt.Sprintf("load-test で投入するユーザーの件数(既定: %d)", DefaultLoadTestUsers))
+	flags.Uint64Var(&options.Seed, "seed", 1, "氏名・メールアドレスを生成する乱数のシード")
+	flags.StringVar(&options.Password, "password", DefaultPassword, "すべてのユーザーに設定するパスワード")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() > 0 {
+		return fmt.Errorf("不明な引数です: %v", flags.Args())
+	}
+	if _, err := userCount(options.Profile, options.Users); err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	if cfg.Profile == config.ProfileProd {
+		return fmt.Errorf("本番環境にはテスト用のデータを投入できません")
+	}
+
+	dbConnect.Init(cfg.Database)
+	userRepository, err := gatewayRepository.NewUserRepository(ctx)
+	if err != nil {
+		return err
+	}
+	return Run(ctx, options, userRepository, out)
+}
+
+// Run はプロファイルのユーザーを登録し、結果を出力する
+func Run(ctx context.Context, options Options, userRepository repository.UserRepositoryInterface, out io.Writer) error {
+	count, err := userCount(options.Profile, options.Users)
+	if err != nil {
+		return err
+	}
+	seeder, err := NewSeeder(userRepository, options.Password)
+	if err != nil {
+		return err
+	}
+
+	fixtures := Fixtures(options.Seed, count)
+	result, err := seeder.Seed(ctx, fixtures, func(done int) {
+		if done%progressInterval == 0 {
+			fmt.Fprintf(out, "%d / %d 件を処理しました\n", done, len(fixtures))
+		}
+	})
+	fmt.Fprintf(out, "ユーザーを %d 件作成しました(登録済み: %d 件)\n", result.Created, result.Skipped)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "パスワード: %s\n", options.Password)
+	for _, fixture := range fixtures[:min(len(fixtures), listLimit)] {
+		fmt.Fprintf(out, "  %s\t%s\n", fixture.Email, fixture.UserName)
+	}
+	if len(fixtures) > listLimit {
+		fmt.Fprintf(out, "  ...ほか %d 件\n", len(fixtures)-listLimit)
+	}
+	return nil
+}
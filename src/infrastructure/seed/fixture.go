@@ -0,0 +1,74 @@
+package seed
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strings"
+)
+
+// Fixture は投入するユーザーの内容
+type Fixture struct {
+	UserName string
+	Email    string
+}
+
+// 氏名(表示名とメールアドレスに使用するローマ字表記)
+type name struct {
+	display string
+	romaji  string
+}
+
+var japaneseFamilyNames = []name{
+	{"佐藤", "sato"}, {"鈴木", "suzuki"}, {"高橋", "takahashi"}, {"田中", "tanaka"},
+	{"伊藤", "ito"}, {"渡辺", "watanabe"}, {"山本", "yamamoto"}, {"中村", "nakamura"},
+	{"小林", "kobayashi"}, {"加藤", "kato"}, {"吉田", "yoshida"}, {"山田", "yamada"},
+	{"松本", "matsumoto"}, {"井上", "inoue"}, {"木村", "kimura"}, {"清水", "shimizu"},
+}
+
+var japaneseGivenNames = []name{
+	{"太郎", "taro"}, {"花子", "hanako"}, {"翔太", "shota"}, {"美咲", "misaki"},
+	{"大輔", "daisuke"}, {"陽菜", "hina"}, {"健太", "kenta"}, {"結衣", "yui"},
+	{"拓也", "takuya"}, {"葵", "aoi"}, {"直樹", "naoki"}, {"さくら", "sakura"},
+	{"蓮", "ren"}, {"愛", "ai"}, {"悠人", "yuto"}, {"彩", "aya"},
+}
+
+var englishFirstNames = []string{
+	"James", "Mary", "John", "Emma", "Oliver", "Sophia", "William", "Olivia",
+	"Liam", "Ava", "Noah", "Mia", "Lucas", "Grace", "Henry", "Chloe",
+}
+
+var englishLastNames = []string{
+	"Smith", "Johnson", "Brown", "Taylor", "Miller", "Wilson", "Moore", "Clark",
+	"Lewis", "Walker", "Hall", "Young", "King", "Wright", "Green", "Baker",
+}
+
+// Fixtures は seed から決まるユーザーを count 件返す
+// 各ユーザーは seed と連番のみから決まるため、件数を増やして再実行しても既存のユーザーは同じ内容になる。
+// メールアドレスは連番を含めて一意とし、送信されないよう予約済みのドメイン(example.com / example.jp)を使用する。
+func Fixtures(seed uint64, count int) []Fixture {
+	fixtures := make([]Fixture, 0, count)
+	for i := 0; i < count; i++ {
+		fixtures = append(fixtures, fixture(seed, i))
+	}
+	return fixtures
+}
+
+func fixture(seed uint64, index int) Fixture {
+	random := rand.New(rand.NewPCG(seed, uint64(index)))
+	number := index + 1
+	// 日本語・英語の氏名を半数ずつ生成する
+	if random.IntN(2) == 0 {
+		family := japaneseFamilyNames[random.IntN(len(japaneseFamilyNames))]
+		given := japaneseGivenNames[random.IntN(len(japaneseGivenNames))]
+		return Fixture{
+			UserName: family.display + " " + given.display,
+			Email:    fmt.Sprintf("%s.%s.%d@example.jp", given.romaji, family.romaji, number),
+		}
+	}
+	first := englishFirstNames[random.IntN(len(englishFirstNames))]
+	last := englishLastNames[random.IntN(len(englishLastNames))]
+	return Fixture{
+		UserName: first + " " + last,
+		Email:    strings.ToLower(fmt.Sprintf("%s.%s.%d@example.com", first, last, number)),
+	}
+}
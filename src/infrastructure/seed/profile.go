@@ -0,0 +1,41 @@
+package seed
+
+import "fmt"
+
+// 投入するデータの規模(seed -profile)
+const (
+	// 動作確認用の最小限のユーザー
+	ProfileMinimal = "minimal"
+	// 画面・APIのデモ用のユーザー
+	ProfileDemo = "demo"
+	// 負荷試験用のユーザー(件数は -users で指定する)
+	ProfileLoadTest = "load-test"
+)
+
+// load-test で投入するユーザーの既定の件数
+const DefaultLoadTestUsers = 10000
+
+// プロファイルごとのユーザーの件数
+var profileUsers = map[string]int{
+	ProfileMinimal:  3,
+	ProfileDemo:     50,
+	ProfileLoadTest: DefaultLoadTestUsers,
+}
+
+// userCount はプロファイルで投入するユーザーの件数を返す(users は load-test でのみ指定できる)
+func userCount(profile string, users int) (int, error) {
+	count, ok := profileUsers[profile]
+	if !ok {
+		return 0, fmt.Errorf("不明なプロファイルです: %s(minimal / demo / load-test を指定してください)", profile)
+	}
+	if users == 0 {
+		return count, nil
+	}
+	if profile != ProfileLoadTest {
+		return 0, fmt.Errorf("ユーザーの件数は load-test でのみ指定できます")
+	}
+	if users < 0 {
+		return 0, fmt.Errorf("ユーザーの件数は1以上を指定してください: %d", users)
+	}
+	return users, nil
+}
@@ -0,0 +1,122 @@
+package seed_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Go_CleanArch/common/crypto"
+	"github.com/Go_CleanArch/infrastructure/config"
+	"github.com/Go_CleanArch/infrastructure/seed"
+	"github.com/Go_CleanArch/interface_adapter/gateway/memory"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFixtures(t *testing.T) {
+	t.Parallel()
+
+	t.Run("正常系_シードが同じ場合は同じユーザーを生成する", func(t *testing.T) {
+		t.Parallel()
+		assert.Equal(t, seed.Fixtures(1, 20), seed.Fixtures(1, 20))
+		assert.NotEqual(t, seed.Fixtures(1, 20), seed.Fixtures(2, 20))
+	})
+
+	t.Run("正常系_件数を増やしても既存のユーザーは変わらない", func(t *testing.T) {
+		t.Parallel()
+		assert.Equal(t, seed.Fixtures(1, 3), seed.Fixtures(1, 50)[:3])
+	})
+
+	t.Run("正常系_日本語・英語の氏名と一意なメールアドレス", func(t *testing.T) {
+		t.Parallel()
+		fixtures := seed.Fixtures(1, 1000)
+
+		emails := map[string]bool{}
+		japanese, english := 0, 0
+		for _, fixture := range fixtures {
+			emails[strings.ToLower(fixture.Email)] = true
+			// サインアップの入力チェックと同じ上限
+			assert.LessOrEqual(t, len(fixture.Email), 40)
+			assert.LessOrEqual(t, len(fixture.UserName), 30)
+			if strings.HasSuffix(fixture.Email, "@example.jp") {
+				japanese++
+			} else {
+				assert.True(t, strings.HasSuffix(fixture.Email, "@example.com"))
+				english++
+			}
+		}
+		assert.Len(t, emails, len(fixtures))
+		assert.Greater(t, japanese, 400)
+		assert.Greater(t, english, 400)
+	})
+}
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	t.Run("正常系_ハッシュ化したパスワードでログインできるユーザーを登録する", func(t *testing.T) {
+		t.Parallel()
+		ctx := context.Background()
+		userRepository := memory.NewUserRepository()
+		var out bytes.Buffer
+
+		err := seed.Run(ctx, seed.Options{Profile: seed.ProfileMinimal, Seed: 1, Password: seed.DefaultPassword}, userRepository, &out)
+
+		assert.NoError(t, err)
+		assert.Contains(t, out.String(), "ユーザーを 3 件作成しました(登録済み: 0 件)")
+		for _, fixture := range seed.Fixtures(1, 3) {
+			user, err := userRepository.FindUserByEmail(ctx, fixture.Email)
+			assert.NoError(t, err)
+			assert.Equal(t, fixture.UserName, user.UserName)
+			assert.NotEmpty(t, user.UserId)
+			assert.NoError(t, crypto.CompareHashAndPassword(user.Password, seed.DefaultPassword))
+		}
+	})
+
+	t.Run("正常系_繰り返し実行しても重複して登録しない", func(t *testing.T) {
+		t.Parallel()
+		ctx := context.Background()
+		userRepository := memory.NewUserRepository()
+
+		assert.NoError(t, seed.Run(ctx, seed.Options{Profile: seed.ProfileMinimal, Seed: 1, Password: seed.DefaultPassword}, userRepository, &bytes.Buffer{}))
+		var out bytes.Buffer
+		err := seed.Run(ctx, seed.Options{Profile: seed.ProfileLoadTest, Users: 5, Seed: 1, Password: seed.DefaultPassword}, userRepository, &out)
+
+		assert.NoError(t, err)
+		assert.Contains(t, out.String(), "ユーザーを 2 件作成しました(登録済み: 3 件)")
+	})
+
+	t.Run("異常系_load-test 以外で件数を指定", func(t *testing.T) {
+		t.Parallel()
+		err := seed.Run(context.Background(), seed.Options{Profile: seed.ProfileDemo, Users: 5, Password: seed.DefaultPassword}, memory.NewUserRepository(), &bytes.Buffer{})
+
+		assert.ErrorContains(t, err, "load-test")
+	})
+}
+
+func TestRunCommand(t *testing.T) {
+	t.Parallel()
+
+	loadConfig := func(profile string) func() (*config.Config, error) {
+		return func() (*config.Config, error) {
+			return &config.Config{Profile: profile}, nil
+		}
+	}
+
+	t.Run("異常系_本番環境には投入しない", func(t *testing.T) {
+		t.Parallel()
+		err := seed.RunCommand(context.Background(), []string{"-profile", "demo"}, &bytes.Buffer{}, loadConfig(config.ProfileProd))
+
+		assert.ErrorContains(t, err, "本番環境")
+	})
+
+	t.Run("異常系_不明なプロファイル", func(t *testing.T) {
+		t.Parallel()
+		err := seed.RunCommand(context.Background(), []string{"-profile", "huge"}, &bytes.Buffer{}, func() (*config.Config, error) {
+			return nil, errors.New("設定を読み込まない")
+		})
+
+		assert.ErrorContains(t, err, "不明なプロファイル")
+	})
+}
@@ -0,0 +1,94 @@
+package seed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Go_CleanArch/common/crypto"
+	createUserFactory "github.com/Go_CleanArch/domain/factory/user/create_user"
+	repository "github.com/Go_CleanArch/usecase/repository_interface"
+	log "github.com/sirupsen/logrus"
+)
+
+// Seeder はサインアップと同じ CreateUserFactory でユーザーを生成し、リポジトリに登録する
+// 登録済みのメールアドレスのユーザーは読み飛ばすため、繰り返し実行しても重複しない。
+type Seeder struct {
+	userRepository repository.UserRepositoryInterface
+	password       string
+	factory        *createUserFactory.CreateUserFactory
+}
+
+// Result は投入の結果
+type Result struct {
+	Created int
+	// 登録済みのため読み飛ばしたユーザー
+	Skipped int
+}
+
+// NewSeeder はすべてのユーザーに password を設定する Seeder を返す
+// bcrypt によるハッシュ化は時間がかかるため、ハッシュは一度だけ計算して共有する。
+func NewSeeder(userRepository repository.UserRepositoryInterface, password string) (*Seeder, error) {
+	hashedPassword, err := crypto.PasswordEncrypt(password)
+	if err != nil {
+		return nil, fmt.Errorf("パスワードをハッシュ化できませんでした: %w", err)
+	}
+	factory := createUserFactory.NewCreateUserFactory()
+	factory.GeneratePassword = func(string) (string, error) {
+		return hashedPassword, nil
+	}
+	return &Seeder{userRepository: userRepository, password: password, factory: factory}, nil
+}
+
+// Seed はユーザーを登録する(progress は1件処理するごとに呼び出す)
+func (s *Seeder) Seed(ctx context.Context, fixtures []Fixture, progress func(done int)) (Result, error) {
+	var result Result
+	for i, fixture := range fixtures {
+		created, err := s.seedUser(ctx, fixture)
+		if err != nil {
+			return result, fmt.Errorf("%s を登録できませんでした: %w", fixture.Email, err)
+		}
+		if created {
+			result.Created++
+		} else {
+			result.Skipped++
+		}
+		if progress != nil {
+			progress(i + 1)
+		}
+	}
+	return result, nil
+}
+
+func (s *Seeder) seedUser(ctx context.Context, fixture Fixture) (bool, error) {
+	if _, err := s.userRepository.FindUserByEmail(ctx, fixture.Email); err == nil {
+		return false, nil
+	} else if !errors.Is(err, repository.ErrNotFound) {
+		return false, err
+	}
+
+	user, apiErr := s.factory.CreateUser(&createUserFactory.CreateUserInitProps{
+		UserName: fixture.UserName,
+		Password: s.password,
+		Email:    fixture.Email,
+	})
+	if apiErr != nil {
+		return false, apiErr.Error()
+	}
+	// 投入したユーザーにはメールの送信などを行わないため、登録のイベントは保存しない
+	user.PullEvents()
+
+	userJson, err := crypto.ConvertStructIntoJson(user)
+	if err != nil {
+		return false, err
+	}
+	if _, err := s.userRepository.CreateUser(ctx, userJson); err != nil {
+		// 削除済みのユーザーがアドレスを使用している場合など
+		if errors.Is(err, repository.ErrEmailAlreadyExists) {
+			log.WithField("email", fixture.Email).Info("Seed user email already exists")
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
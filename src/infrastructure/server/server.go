@@ -2,9 +2,25 @@ package server
 
 import (
 	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"regexp"
+	"runtime/debug"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/Go_CleanArch/common/auth"
+	status "github.com/Go_CleanArch/common/const"
+	"github.com/Go_CleanArch/common/correlation"
+	"github.com/Go_CleanArch/common/crypto"
+	"github.com/Go_CleanArch/common/errors"
+	"github.com/Go_CleanArch/common/tenant"
+	"github.com/Go_CleanArch/infrastructure/config"
 	container "github.com/Go_CleanArch/infrastructure/container"
+	dbConnect "github.com/Go_CleanArch/infrastructure/db"
+	"github.com/Go_CleanArch/infrastructure/idempotency"
 	log "github.com/sirupsen/logrus"
 
 	"github.com/gin-contrib/cors"
@@ -17,10 +33,96 @@ import (
 
 type contextKey string
 
-// Init is initialize server
-func Init() {
-	r := router()
-	r.Run()
+// Server は HTTP サーバーと、サーバーの稼働中に動作させるバックグラウンド処理
+type Server struct {
+	httpServer *http.Server
+	// 終了時に処理中のリクエストの完了を待つ上限時間
+	shutdownTimeout time.Duration
+	// ctx がキャンセルされるまで動作するバックグラウンド処理
+	workers []func(ctx context.Context)
+}
+
+// New はルーティング・依存関係を初期化します(リクエストの受け付けは Run で開始します)
+func New(cfg *config.Config) (*Server, error) {
+	route, cont, err := router(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return newServer(route, cfg.Server,
+		// ドメインイベントの配信
+		cont.EventDispatcher.Run,
+		// 保存期間を過ぎた配信済みのイベントの削除
+		cont.EventDispatcher.RunPurge,
+		// 保存期間を過ぎた冪等キーの削除
+		cont.IdempotencyStore.RunPurge,
+	), nil
+}
+
+func newServer(handler http.Handler, serverConfig config.ServerConfig, workers ...func(ctx context.Context)) *Server {
+	return &Server{
+		httpServer: &http.Server{
+			Addr:         fmt.Sprintf(":%d", serverConfig.Port),
+			Handler:      handler,
+			ReadTimeout:  serverConfig.ReadTimeout,
+			WriteTimeout: serverConfig.WriteTimeout,
+			IdleTimeout:  serverConfig.IdleTimeout,
+		},
+		shutdownTimeout: serverConfig.ShutdownTimeout,
+		workers:         workers,
+	}
+}
+
+// Run は ctx がキャンセルされるまでリクエストを受け付けます
+// キャンセルされた場合は新しい接続の受け付けを止め、処理中のリクエストの完了を待ってから
+// バックグラウンド処理を停止し、停止が完了してから返ります。
+func (s *Server) Run(ctx context.Context) error {
+	listener, err := net.Listen("tcp", s.httpServer.Addr)
+	if err != nil {
+		return fmt.Errorf("%s で待ち受けを開始できません: %w", s.httpServer.Addr, err)
+	}
+	return s.serve(ctx, listener)
+}
+
+func (s *Server) serve(ctx context.Context, listener net.Listener) error {
+	// バックグラウンド処理はリクエストの処理が完了してから停止する(処理中のリクエストが発行したイベントも配信する)
+	workerCtx, stopWorkers := context.WithCancel(context.WithoutCancel(ctx))
+	var workers sync.WaitGroup
+	for _, worker := range s.workers {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			worker(workerCtx)
+		}()
+	}
+	defer func() {
+		stopWorkers()
+		workers.Wait()
+		log.Info("Background workers stopped")
+	}()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- s.httpServer.Serve(listener)
+	}()
+	log.WithField("addr", listener.Addr().String()).Info("Server started")
+
+	select {
+	case err := <-serveErr:
+		// 停止の要求を受ける前に待ち受けが終了した
+		return fmt.Errorf("サーバーが停止しました: %w", err)
+	case <-ctx.Done():
+	}
+
+	log.WithField("timeout", s.shutdownTimeout.String()).Info("Shutting down server")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout)
+	defer cancel()
+	if err := s.httpServer.Shutdown(shutdownCtx); err != nil {
+		// 上限時間内に完了しなかったリクエストの接続は切断する
+		s.httpServer.Close()
+		return fmt.Errorf("処理中のリクエストが %s 以内に完了しませんでした: %w", s.shutdownTimeout, err)
+	}
+	log.Info("Server stopped")
+	return nil
 }
 
 func GinContextToContextMiddleware() gin.HandlerFunc {
@@ -31,17 +133,265 @@ func GinContextToContextMiddleware() gin.HandlerFunc {
 	}
 }
 
-func CustomLoggingMiddleware() gin.HandlerFunc {
+// リクエストIDのリクエスト・レスポンスヘッダー
+const requestIdHeader = "X-Request-Id"
+
+// 呼び出し元から受け付けるリクエストID(ログへの混入を防ぐため、使用できる文字・長さを制限する)
+var validRequestId = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// RequestIdMiddleware はリクエストIDをコンテキストに格納し、レスポンスヘッダーで返す
+// 呼び出し元が X-Request-Id を指定した場合はその値を、指定しない場合は新たに生成した値を使用する。
+func RequestIdMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestId := c.GetHeader(requestIdHeader)
+		if !validRequestId.MatchString(requestId) {
+			generated, err := crypto.GenerateId()
+			if err != nil {
+				log.WithError(err).Error("Failed to generate request id")
+			}
+			requestId = generated
+		}
+		c.Header(requestIdHeader, requestId)
+		c.Request = c.Request.WithContext(correlation.WithRequestId(c.Request.Context(), requestId))
+		c.Next()
+	}
+}
+
+// ErrorMiddleware はハンドラーが c.Error で登録したエラーをレスポンスに変換し、パニックを同じ形式のレスポンスに変換する
+// ApiErr を保持するエラー(ApiErr.Error で生成したエラー)はそのステータス・内容で返し、
+// それ以外のエラー・サーバーエラー(5xx)の ApiErr・パニックは内部の情報を含めずに 500 を返す(詳細はリクエストIDとともにログに出力する)。
+// 既にレスポンスを書き込んだ場合は変換しない。RequestIdMiddleware の後に適用すること。
+func ErrorMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			// クライアントの切断などで処理を中断した場合は、net/http に委ねる
+			if recovered == http.ErrAbortHandler {
+				panic(recovered)
+			}
+			log.WithFields(correlation.LogFields(c.Request.Context())).WithFields(log.Fields{
+				"panic": recovered,
+				"stack": string(debug.Stack()),
+			}).Error("Recovered from panic")
+			c.Abort()
+			respondError(c, errors.InternalServerError())
+		}()
+
+		c.Next()
+
+		// 内側の ErrorMiddleware で変換済みの場合
+		if len(c.Errors) == 0 || c.GetBool(errorHandledKey) {
+			return
+		}
+		c.Set(errorHandledKey, true)
+		err := c.Errors.Last().Err
+		entry := log.WithFields(correlation.LogFields(c.Request.Context())).WithError(err)
+		if c.Writer.Written() {
+			entry.Warn("Error occurred after the response was written")
+			return
+		}
+		apiErr, ok := errors.ApiErrFrom(err)
+		if !ok || apiErr.Status >= http.StatusInternalServerError {
+			entry.Error("Unexpected error occurred")
+			apiErr = errors.InternalServerError()
+		}
+		respondError(c, apiErr)
+	}
+}
+
+// エラーをレスポンスに変換したことを表す gin.Context のキー
+const errorHandledKey = "errorHandled"
+
+// respondError はエラーのレスポンスにリクエストIDを付与して返す
+func respondError(c *gin.Context, apiErr *errors.ApiErr) {
+	if c.Writer.Written() {
+		return
+	}
+	if requestId, ok := correlation.RequestIdFromContext(c.Request.Context()); ok {
+		apiErr.RequestId = requestId
+	}
+	c.JSON(apiErr.Status, apiErr)
+}
+
+// ReadYourWritesMiddleware は更新系のリクエストの処理中に行う読み取りをプライマリで行う
+// 同じリクエスト内で書き込んだ内容を、レプリカの反映遅延により読み取れない事象を防ぐ。
+func ReadYourWritesMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case "GET", "HEAD", "OPTIONS":
+		default:
+			c.Request = c.Request.WithContext(dbConnect.WithPrimary(c.Request.Context()))
+		}
+		c.Next()
+	}
+}
 
-		// 日本時間のタイムゾーンを取得
-		loc, _ := time.LoadLocation("Asia/Tokyo")
+// AuthMiddleware は Authorization: Bearer のアクセストークンを検証し、認証済みユーザーIDをコンテキストに格納する
+func AuthMiddleware(tokenIssuer *auth.TokenIssuer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, found := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !found || token == "" {
+			abortUnauthorized(c, "ログインしてください")
+			return
+		}
+		claims, err := tokenIssuer.Parse(token)
+		if err != nil {
+			log.WithError(err).Warn("Invalid access token")
+			abortUnauthorized(c, err.Error())
+			return
+		}
+
+		ctx := auth.WithUserId(c.Request.Context(), claims.Subject)
+		if claims.OrganizationId != "" {
+			ctx = auth.WithOrganizationId(ctx, claims.OrganizationId)
+		}
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// 操作対象の組織を指定するリクエストヘッダー
+const organizationIdHeader = "X-Organization-Id"
+
+// TenantResolver は組織の指定とユーザーの所属から、リクエストの対象となるテナントを解決する
+type TenantResolver interface {
+	ResolveTenant(ctx context.Context, userId string, organizationId string, slug string) (tenant.Tenant, *errors.ApiErr)
+}
+
+// TenantMiddleware はリクエストの対象となる組織を解決し、テナントとしてコンテキストに格納する
+// 組織は X-Organization-Id ヘッダー、サブドメイン(<スラッグ>.<baseDomain>)、アクセストークンの順に参照する。
+// AuthMiddleware の後に適用すること。
+func TenantMiddleware(resolver TenantResolver, baseDomain string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		userId, ok := auth.UserIdFromContext(ctx)
+		if !ok {
+			abortUnauthorized(c, "ログインしてください")
+			return
+		}
 
-		// リクエスト開始時の日本時間
+		organizationId := c.GetHeader(organizationIdHeader)
+		slug := ""
+		if organizationId == "" {
+			slug = subdomain(c.Request.Host, baseDomain)
+		}
+		if organizationId == "" && slug == "" {
+			organizationId, _ = auth.OrganizationIdFromContext(ctx)
+		}
+
+		resolved, apiErr := resolver.ResolveTenant(ctx, userId, organizationId, slug)
+		if apiErr != nil {
+			c.Abort()
+			respondError(c, apiErr)
+			return
+		}
+
+		c.Request = c.Request.WithContext(tenant.WithTenant(ctx, resolved))
+		c.Next()
+	}
+}
+
+// HealthController はデータベースに接続できるかのみを返します(接続できない場合は 503)
+func HealthController(c *gin.Context) {
+	healthy := dbConnect.Health().Healthy
+	if !healthy {
+		c.JSON(503, gin.H{"healthy": false})
+		return
+	}
+	c.JSON(200, gin.H{"healthy": true})
+}
+
+// DatabaseHealthController はデータベース接続・レプリカの状態とコネクションプールの利用状況を返します(接続できない場合は 503)
+func DatabaseHealthController(c *gin.Context) {
+	health := dbConnect.Health()
+	if !health.Healthy {
+		c.JSON(503, gin.H{
+			"message":  "Service Unavailable",
+			"database": health,
+		})
+		return
+	}
+	c.JSON(200, gin.H{
+		"message":  "OK",
+		"database": health,
+	})
+}
+
+// AdminMiddleware は認証済みユーザーが管理者の場合のみリクエストを許可する
+// AuthMiddleware の後に適用すること。
+func AdminMiddleware(adminUserIds []string) gin.HandlerFunc {
+	admins := make(map[string]bool, len(adminUserIds))
+	for _, userId := range adminUserIds {
+		admins[userId] = true
+	}
+	return func(c *gin.Context) {
+		userId, ok := auth.UserIdFromContext(c.Request.Context())
+		if !ok {
+			abortUnauthorized(c, "ログインしてください")
+			return
+		}
+		if !admins[userId] {
+			log.WithField("userId", userId).Warn("Non-admin user requested admin API")
+			apiErr := errors.OutputApiError(
+				[]errors.ApiErrMessage{
+					{
+						Key:   "authorization",
+						Value: "管理者のみ操作できます",
+					},
+				},
+				status.ErrorStatusMap["FORBIDDEN"].StatusCode,
+				status.ErrorStatusMap["FORBIDDEN"].StatusName,
+			)
+			c.Abort()
+			respondError(c, apiErr)
+			return
+		}
+		c.Next()
+	}
+}
+
+// ホスト名からサブドメインを取り出す(baseDomain が未設定、または一致しない場合は空文字)
+func subdomain(host string, baseDomain string) string {
+	if baseDomain == "" {
+		return ""
+	}
+	if h, _, found := strings.Cut(host, ":"); found {
+		host = h
+	}
+	sub, found := strings.CutSuffix(strings.ToLower(host), "."+strings.ToLower(baseDomain))
+	if !found || sub == "" || strings.Contains(sub, ".") {
+		return ""
+	}
+	return sub
+}
+
+func abortUnauthorized(c *gin.Context, message string) {
+	apiErr := errors.OutputApiError(
+		[]errors.ApiErrMessage{
+			{
+				Key:   "authorization",
+				Value: message,
+			},
+		},
+		status.ErrorStatusMap["UNAUTHORIZED"].StatusCode,
+		status.ErrorStatusMap["UNAUTHORIZED"].StatusName,
+	)
+	c.Abort()
+	respondError(c, apiErr)
+}
+
+// loc はログに出力する日時のタイムゾーン
+func CustomLoggingMiddleware(loc *time.Location) gin.HandlerFunc {
+	return func(c *gin.Context) {
+
+		// リクエスト開始時の日時
 		startTime := time.Now().In(loc)
 
 		// リクエスト開始前のログ
-		log.WithFields(log.Fields{
+		log.WithFields(correlation.LogFields(c.Request.Context())).WithFields(log.Fields{
 			"メッセージ":  "リクエスト開始",
 			"method": c.Request.Method,
 			"path":   c.Request.URL.Path,
@@ -50,7 +400,7 @@ func CustomLoggingMiddleware() gin.HandlerFunc {
 		// リクエスト処理
 		c.Next()
 
-		// リクエスト終了時の日本時間
+		// リクエスト終了時の日時
 		endTime := time.Now().In(loc)
 
 		// ステータスコードに基づいてログレベルを決定
@@ -65,32 +415,40 @@ func CustomLoggingMiddleware() gin.HandlerFunc {
 			"実行終了時刻":       endTime.Format("2006-01-02 15:04:05"),
 			"処理時間":         endTime.Sub(startTime).String(), // 処理時間
 		}
+		// 認証・テナントの解決後のコンテキストから、ユーザーID・組織IDも出力する
+		entry := log.WithFields(correlation.LogFields(c.Request.Context())).WithFields(fields)
 		if statusCode >= 400 && statusCode < 500 {
-			log.WithFields(fields).Warn("Client error")
+			entry.Warn("Client error")
 		} else if statusCode >= 500 {
-			log.WithFields(fields).Error("Server error")
+			entry.Error("Server error")
 		} else {
-			log.WithFields(fields).Info("Completed request")
+			entry.Info("Completed request")
 		}
 	}
 }
 
-func router() *gin.Engine {
-	route := gin.Default()
+func router(cfg *config.Config) (*gin.Engine, *container.Container, error) {
+	// パニックは ErrorMiddleware で他のエラーと同じ形式のレスポンスに変換する(gin.Recovery は使用しない)
+	route := gin.New()
+	route.Use(gin.Logger())
+	route.Use(RequestIdMiddleware())
+	route.Use(ErrorMiddleware())
 
 	route.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
 	route.Use(GinContextToContextMiddleware())
-	route.Use(CustomLoggingMiddleware())
+	route.Use(CustomLoggingMiddleware(cfg.Server.LogLocation()))
+	route.Use(ReadYourWritesMiddleware())
 
 	route.Use(cors.New(cors.Config{
 		// アクセスを許可したいアクセス元
-		AllowOrigins: []string{
-			"*",
-		},
+		AllowOrigins: cfg.Server.CorsAllowOrigins,
 		// アクセスを許可したいHTTPメソッド
 		AllowMethods: []string{
+			"GET",
 			"POST",
+			"PUT",
+			"PATCH",
 			"OPTIONS",
 		},
 		// 許可したいHTTPリクエストヘッダ
@@ -101,6 +459,14 @@ func router() *gin.Engine {
 			"Content-Length",
 			"Accept-Encoding",
 			"Authorization",
+			organizationIdHeader,
+			requestIdHeader,
+			idempotency.KeyHeader,
+		},
+		// ブラウザから参照できるレスポンスヘッダ
+		ExposeHeaders: []string{
+			requestIdHeader,
+			idempotency.ReplayedHeader,
 		},
 		// cookieなどの情報を必要とするかどうか
 		AllowCredentials: true,
@@ -110,9 +476,10 @@ func router() *gin.Engine {
 
 	// Initialize dependencies
 	ctx := context.Background()
-	cont, err := container.NewContainer(ctx)
+	cont, err := container.NewContainer(ctx, cfg)
 	if err != nil {
-		return nil
+		log.WithError(err).Error("Failed to initialize container")
+		return nil, nil, err
 	}
 
 	// ヘルスチェックエンドポイント
@@ -123,14 +490,104 @@ func router() *gin.Engine {
 				"message": "OK",
 			})
 		})
+		// データベースに接続できるか(接続できない場合は 503)
+		// 接続先・エラーの内容などは認証なしに公開しないため、詳細は管理者向けの /api/admin/health で返す
+		healthRoute.GET("health", HealthController)
+	}
+
+	// リソースを作成する POST リクエストは Idempotency-Key を指定して再送できる(認証・テナントの解決後に適用する)
+	// レスポンスはデータベースに保存するため、ログイン・トークンによる確定などの認証情報を扱うリクエストには適用しない
+	// エラーのレスポンスも保存・再送の対象とするため、冪等キーの処理の内側でもエラーをレスポンスに変換する
+	idempotent := func(handler gin.HandlerFunc) []gin.HandlerFunc {
+		return []gin.HandlerFunc{idempotency.Middleware(cont.IdempotencyStore), ErrorMiddleware(), handler}
 	}
 
 	userRoute := route.Group("/api/users")
 	{
 		ctrl := cont.UserContainer.UserController
 		userRoute.POST("/login", ctrl.LoginControler)
-		userRoute.POST("", ctrl.UserController)
+		userRoute.POST("", idempotent(ctrl.UserController)...)
+
+		// メールアドレス変更の確定・取消(メールのリンクから遷移するため認証不要)
+		emailChangeCtrl := cont.UserContainer.EmailChangeController
+		userRoute.POST("/email/confirm", emailChangeCtrl.ConfirmEmailChangeController)
+		userRoute.POST("/email/cancel", emailChangeCtrl.CancelEmailChangeController)
+
+		// 個人データ削除の確定(メールのリンクから遷移するため認証不要)
+		privacyCtrl := cont.UserContainer.PrivacyController
+		userRoute.POST("/erasure/confirm", privacyCtrl.ConfirmErasureController)
+	}
+
+	// ローカルファイルシステムに保存したアップロードファイルの配信
+	if cfg.Storage.Driver == "local" {
+		route.Static("/storage", cfg.Storage.LocalDir)
+	}
+
+	// ログインユーザー自身に対する操作
+	meRoute := route.Group("/api/users/me")
+	meRoute.Use(AuthMiddleware(cont.TokenIssuer))
+	{
+		// プロフィール・アバター画像
+		profileCtrl := cont.UserContainer.ProfileController
+		meRoute.GET("", profileCtrl.GetProfileController)
+		meRoute.PUT("/avatar", profileCtrl.UploadAvatarController)
+
+		// 設定
+		settingCtrl := cont.UserContainer.SettingController
+		meRoute.GET("/settings", settingCtrl.GetSettingsController)
+		meRoute.PATCH("/settings", settingCtrl.UpdateSettingsController)
+
+		emailChangeCtrl := cont.UserContainer.EmailChangeController
+		meRoute.POST("/email", idempotent(emailChangeCtrl.RequestEmailChangeController)...)
+
+		// 個人データの開示・削除請求
+		privacyCtrl := cont.UserContainer.PrivacyController
+		meRoute.GET("/data-export", privacyCtrl.DataExportController)
+		meRoute.POST("/erasure-requests", idempotent(privacyCtrl.RequestErasureController)...)
+
+		// 所属組織の一覧・操作対象の組織の切り替え
+		organizationCtrl := cont.OrganizationContainer.OrganizationController
+		meRoute.GET("/organizations", organizationCtrl.ListMyOrganizationsController)
+		meRoute.POST("/organizations/:organizationId/token", organizationCtrl.SwitchOrganizationController)
+		// 組織への招待の承諾
+		meRoute.POST("/invitations/accept", organizationCtrl.AcceptInvitationController)
+	}
+
+	organizationRoute := route.Group("/api/organizations")
+	organizationRoute.Use(AuthMiddleware(cont.TokenIssuer))
+	{
+		organizationCtrl := cont.OrganizationContainer.OrganizationController
+		organizationRoute.POST("", idempotent(organizationCtrl.CreateOrganizationController)...)
+	}
+
+	// 操作対象の組織(テナント)に対する操作
+	tenantRoute := route.Group("/api/organization")
+	tenantRoute.Use(AuthMiddleware(cont.TokenIssuer))
+	tenantRoute.Use(TenantMiddleware(cont.OrganizationContainer.TenantResolver, cfg.Server.TenantBaseDomain))
+	{
+		organizationCtrl := cont.OrganizationContainer.OrganizationController
+		tenantRoute.GET("/members", organizationCtrl.ListMembersController)
+		tenantRoute.POST("/members", idempotent(organizationCtrl.AddMemberController)...)
+		tenantRoute.POST("/invitations", idempotent(organizationCtrl.InviteMemberController)...)
+	}
+
+	// 管理者向けの操作
+	adminRoute := route.Group("/api/admin")
+	adminRoute.Use(AuthMiddleware(cont.TokenIssuer))
+	adminRoute.Use(AdminMiddleware(cfg.Auth.AdminUserIds))
+	{
+		// データの変更の監査ログ
+		auditLogCtrl := cont.AuditContainer.AuditLogController
+		adminRoute.GET("/audit-logs", auditLogCtrl.SearchAuditLogsController)
+		// データベース接続・レプリカの状態とコネクションプールの利用状況
+		adminRoute.GET("/health", DatabaseHealthController)
+		// 実行したSQLの種類ごとの実行回数・実行時間の分布(パラメーターは含まない)
+		adminRoute.GET("/health/queries", func(c *gin.Context) {
+			c.JSON(200, gin.H{
+				"queries": dbConnect.QueryStats(),
+			})
+		})
 	}
 
-	return route
+	return route, cont, nil
 }
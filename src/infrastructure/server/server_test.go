@@ -0,0 +1,264 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Go_CleanArch/common/errors"
+	"github.com/Go_CleanArch/infrastructure/config"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+var testServerConfig = config.ServerConfig{
+	ReadTimeout:     time.Second,
+	WriteTimeout:    5 * time.Second,
+	IdleTimeout:     time.Second,
+	ShutdownTimeout: 5 * time.Second,
+}
+
+// startTestServer はサーバーを起動し、サーバーの URL と serve の戻り値を返します
+func startTestServer(t *testing.T, srv *Server, ctx context.Context) (string, <-chan error) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- srv.serve(ctx, listener)
+	}()
+	return "http://" + listener.Addr().String(), done
+}
+
+func TestServerShutdown(t *testing.T) {
+	t.Parallel()
+
+	t.Run("正常系_処理中のリクエストの完了を待ってからバックグラウンド処理を停止する", func(t *testing.T) {
+		t.Parallel()
+		started := make(chan struct{})
+		release := make(chan struct{})
+		var requestCompleted, workerStoppedAfterRequest atomic.Bool
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			close(started)
+			<-release
+			requestCompleted.Store(true)
+			w.WriteHeader(http.StatusOK)
+		})
+		worker := func(ctx context.Context) {
+			<-ctx.Done()
+			workerStoppedAfterRequest.Store(requestCompleted.Load())
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		url, done := startTestServer(t, newServer(handler, testServerConfig, worker), ctx)
+
+		responses := make(chan *http.Response, 1)
+		go func() {
+			res, err := http.Get(url)
+			assert.NoError(t, err)
+			responses <- res
+		}()
+		<-started
+		// 停止の要求後も、処理中のリクエストは完了するまで待つ
+		cancel()
+		select {
+		case err := <-done:
+			t.Fatalf("処理中のリクエストの完了前に停止しました: %v", err)
+		case <-time.After(100 * time.Millisecond):
+		}
+		close(release)
+
+		res := <-responses
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		res.Body.Close()
+		assert.NoError(t, <-done)
+		assert.True(t, workerStoppedAfterRequest.Load())
+		// 停止後は新しい接続を受け付けない
+		_, err := http.Get(url)
+		assert.Error(t, err)
+	})
+
+	t.Run("異常系_上限時間内に完了しないリクエストは切断する", func(t *testing.T) {
+		t.Parallel()
+		started := make(chan struct{})
+		release := make(chan struct{})
+		defer close(release)
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			close(started)
+			<-release
+		})
+		serverConfig := testServerConfig
+		serverConfig.ShutdownTimeout = 50 * time.Millisecond
+		var workerStopped atomic.Bool
+		worker := func(ctx context.Context) {
+			<-ctx.Done()
+			workerStopped.Store(true)
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		url, done := startTestServer(t, newServer(handler, serverConfig, worker), ctx)
+
+		go func() {
+			if res, err := http.Get(url); err == nil {
+				res.Body.Close()
+			}
+		}()
+		<-started
+		cancel()
+
+		assert.ErrorContains(t, <-done, "50ms 以内に完了しませんでした")
+		assert.True(t, workerStopped.Load())
+	})
+
+	t.Run("異常系_待ち受けできない場合はエラーを返す", func(t *testing.T) {
+		t.Parallel()
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer listener.Close()
+		serverConfig := testServerConfig
+		serverConfig.Port = listener.Addr().(*net.TCPAddr).Port
+		srv := newServer(http.NotFoundHandler(), serverConfig)
+		srv.httpServer.Addr = listener.Addr().String()
+
+		err = srv.Run(context.Background())
+
+		assert.ErrorContains(t, err, "待ち受けを開始できません")
+	})
+}
+
+// newErrorTestRouter は RequestIdMiddleware・ErrorMiddleware を適用したルーターを返します
+func newErrorTestRouter(handlers ...gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	route := gin.New()
+	route.Use(RequestIdMiddleware(), ErrorMiddleware())
+	route.GET("/test", handlers...)
+	return route
+}
+
+func serveErrorTest(t *testing.T, route *gin.Engine) (*httptest.ResponseRecorder, errors.ApiErr) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/test", nil)
+	req.Header.Set(requestIdHeader, "request-1")
+	route.ServeHTTP(w, req)
+	var apiErr errors.ApiErr
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &apiErr), w.Body.String())
+	return w, apiErr
+}
+
+func TestErrorMiddleware(t *testing.T) {
+	t.Parallel()
+
+	t.Run("正常系_ApiErr を保持するエラーはそのステータス・内容で返す", func(t *testing.T) {
+		t.Parallel()
+		notFound := errors.OutputApiError([]errors.ApiErrMessage{{Key: "userId", Value: "ユーザーが存在しません"}}, http.StatusNotFound, "Not Found")
+		route := newErrorTestRouter(func(c *gin.Context) {
+			c.Error(fmt.Errorf("ユーザーを取得できません: %w", notFound.Error()))
+		})
+
+		w, apiErr := serveErrorTest(t, route)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Equal(t, notFound.Messages, apiErr.Messages)
+		assert.Equal(t, "Not Found", apiErr.Detail)
+		assert.Equal(t, "request-1", apiErr.RequestId)
+	})
+
+	t.Run("正常系_想定外のエラーは内部の情報を含めずに 500 を返す", func(t *testing.T) {
+		t.Parallel()
+		route := newErrorTestRouter(func(c *gin.Context) {
+			c.Error(fmt.Errorf("dial tcp 10.0.0.1:5432: connection refused"))
+		})
+
+		w, apiErr := serveErrorTest(t, route)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.NotContains(t, w.Body.String(), "10.0.0.1")
+		assert.Equal(t, errors.InternalServerError().Messages, apiErr.Messages)
+		assert.Equal(t, "request-1", apiErr.RequestId)
+	})
+
+	t.Run("正常系_サーバーエラーの ApiErr は内部の情報を含めずに 500 を返す", func(t *testing.T) {
+		t.Parallel()
+		leaked := errors.OutputApiError([]errors.ApiErrMessage{{Key: "undefined", Value: "dial tcp 10.0.0.1:5432: connection refused"}}, http.StatusServiceUnavailable, "Service Unavailable")
+		route := newErrorTestRouter(func(c *gin.Context) {
+			c.Error(leaked.Error())
+		})
+
+		w, apiErr := serveErrorTest(t, route)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.NotContains(t, w.Body.String(), "10.0.0.1")
+		assert.Equal(t, errors.InternalServerError().Messages, apiErr.Messages)
+		assert.Equal(t, "request-1", apiErr.RequestId)
+	})
+
+	t.Run("正常系_パニックは 500 のレスポンスに変換する", func(t *testing.T) {
+		t.Parallel()
+		route := newErrorTestRouter(func(c *gin.Context) {
+			var user *struct{ Name string }
+			_ = user.Name
+		})
+
+		w, apiErr := serveErrorTest(t, route)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.NotContains(t, w.Body.String(), "nil pointer")
+		assert.Equal(t, errors.InternalServerError().Messages, apiErr.Messages)
+		assert.Equal(t, "request-1", apiErr.RequestId)
+	})
+
+	t.Run("正常系_変換済みのエラー・書き込み済みのレスポンスは変換しない", func(t *testing.T) {
+		t.Parallel()
+		badRequest := errors.InvalidRequestError()
+		// 冪等キーの処理の内側と同様に、ErrorMiddleware を重ねて適用する
+		route := newErrorTestRouter(ErrorMiddleware(), func(c *gin.Context) {
+			c.Error(badRequest.Error())
+		})
+		route.GET("/written", func(c *gin.Context) {
+			c.JSON(http.StatusAccepted, gin.H{"message": "accepted"})
+			c.Error(fmt.Errorf("レスポンスの書き込み後のエラー"))
+		})
+
+		w, apiErr := serveErrorTest(t, route)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, badRequest.Messages, apiErr.Messages)
+
+		w = httptest.NewRecorder()
+		route.ServeHTTP(w, httptest.NewRequest("GET", "/written", nil))
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		assert.JSONEq(t, `{"message":"accepted"}`, w.Body.String())
+	})
+}
+
+func TestHealthController(t *testing.T) {
+	t.Parallel()
+
+	t.Run("正常系_公開するヘルスチェックは状態のみを返す", func(t *testing.T) {
+		t.Parallel()
+		route := gin.New()
+		route.GET("/health", HealthController)
+		route.GET("/api/admin/health", DatabaseHealthController)
+
+		// データベースに接続していないため異常となる
+		public := httptest.NewRecorder()
+		route.ServeHTTP(public, httptest.NewRequest("GET", "/health", nil))
+		admin := httptest.NewRecorder()
+		route.ServeHTTP(admin, httptest.NewRequest("GET", "/api/admin/health", nil))
+
+		assert.Equal(t, http.StatusServiceUnavailable, public.Code)
+		assert.JSONEq(t, `{"healthy":false}`, public.Body.String())
+		// 接続の状態・コネクションプールの利用状況は管理者向けのエンドポイントで返す
+		assert.Equal(t, http.StatusServiceUnavailable, admin.Code)
+		assert.Contains(t, admin.Body.String(), `"pool"`)
+	})
+}
@@ -0,0 +1,33 @@
+package controller
+
+import (
+	status "github.com/Go_CleanArch/common/const"
+	inputAudit "github.com/Go_CleanArch/usecase/input/audit"
+	auditService "github.com/Go_CleanArch/usecase/service/audit"
+	"github.com/gin-gonic/gin"
+)
+
+type AuditLogController struct {
+	auditLogService auditService.AuditLogService
+}
+
+func NewAuditLogController(auditLogService auditService.AuditLogService) *AuditLogController {
+	return &AuditLogController{auditLogService: auditLogService}
+}
+
+func (ac *AuditLogController) SearchAuditLogsController(c *gin.Context) {
+	ctx := c.Request.Context()
+	var searchAuditLogsForm inputAudit.SearchAuditLogsForm
+	if !bindQuery(c, &searchAuditLogsForm) || !validate(c, searchAuditLogsForm.SearchAuditLogsValidate()) {
+		return
+	}
+	result, err := ac.auditLogService.SearchAuditLogsService(ctx, searchAuditLogsForm)
+	if err != nil {
+		c.Error(err)
+	} else {
+		c.JSON(
+			status.SuccessStatusMap["OK"].StatusCode,
+			result,
+		)
+	}
+}
@@ -0,0 +1,67 @@
+package controller
+
+import (
+	status "github.com/Go_CleanArch/common/const"
+	inputUser "github.com/Go_CleanArch/usecase/input/user"
+	userService "github.com/Go_CleanArch/usecase/service/user"
+	"github.com/gin-gonic/gin"
+)
+
+type EmailChangeController struct {
+	emailChangeService userService.EmailChangeService
+}
+
+func NewEmailChangeController(emailChangeService userService.EmailChangeService) *EmailChangeController {
+	return &EmailChangeController{emailChangeService: emailChangeService}
+}
+
+func (ec *EmailChangeController) RequestEmailChangeController(c *gin.Context) {
+	ctx := c.Request.Context()
+	var changeEmailForm inputUser.ChangeEmailForm
+	if !bindJSON(c, &changeEmailForm) || !validate(c, changeEmailForm.ChangeEmailValidate()) {
+		return
+	}
+	result, err := ec.emailChangeService.RequestEmailChangeService(ctx, changeEmailForm)
+	if err != nil {
+		c.Error(err)
+	} else {
+		c.JSON(
+			status.SuccessStatusMap["ACCEPTED"].StatusCode,
+			result,
+		)
+	}
+}
+
+func (ec *EmailChangeController) ConfirmEmailChangeController(c *gin.Context) {
+	ctx := c.Request.Context()
+	var tokenForm inputUser.EmailChangeTokenForm
+	if !bindJSON(c, &tokenForm) || !validate(c, tokenForm.EmailChangeTokenValidate()) {
+		return
+	}
+	result, err := ec.emailChangeService.ConfirmEmailChangeService(ctx, tokenForm)
+	if err != nil {
+		c.Error(err)
+	} else {
+		c.JSON(
+			status.SuccessStatusMap["OK"].StatusCode,
+			result,
+		)
+	}
+}
+
+func (ec *EmailChangeController) CancelEmailChangeController(c *gin.Context) {
+	ctx := c.Request.Context()
+	var tokenForm inputUser.EmailChangeTokenForm
+	if !bindJSON(c, &tokenForm) || !validate(c, tokenForm.EmailChangeTokenValidate()) {
+		return
+	}
+	result, err := ec.emailChangeService.CancelEmailChangeService(ctx, tokenForm)
+	if err != nil {
+		c.Error(err)
+	} else {
+		c.JSON(
+			status.SuccessStatusMap["OK"].StatusCode,
+			result,
+		)
+	}
+}
@@ -0,0 +1,125 @@
+package controller
+
+import (
+	status "github.com/Go_CleanArch/common/const"
+	inputOrganization "github.com/Go_CleanArch/usecase/input/organization"
+	organizationService "github.com/Go_CleanArch/usecase/service/organization"
+	"github.com/gin-gonic/gin"
+)
+
+type OrganizationController struct {
+	organizationService organizationService.OrganizationService
+}
+
+func NewOrganizationController(organizationService organizationService.OrganizationService) *OrganizationController {
+	return &OrganizationController{organizationService: organizationService}
+}
+
+func (oc *OrganizationController) CreateOrganizationController(c *gin.Context) {
+	ctx := c.Request.Context()
+	var createOrganizationForm inputOrganization.CreateOrganizationForm
+	if !bindJSON(c, &createOrganizationForm) || !validate(c, createOrganizationForm.CreateOrganizationValidate()) {
+		return
+	}
+	result, err := oc.organizationService.CreateOrganizationService(ctx, createOrganizationForm)
+	if err != nil {
+		c.Error(err)
+	} else {
+		c.JSON(
+			status.SuccessStatusMap["CREATED"].StatusCode,
+			result,
+		)
+	}
+}
+
+func (oc *OrganizationController) ListMyOrganizationsController(c *gin.Context) {
+	ctx := c.Request.Context()
+	result, err := oc.organizationService.ListMyOrganizationsService(ctx)
+	if err != nil {
+		c.Error(err)
+	} else {
+		c.JSON(
+			status.SuccessStatusMap["OK"].StatusCode,
+			result,
+		)
+	}
+}
+
+func (oc *OrganizationController) SwitchOrganizationController(c *gin.Context) {
+	ctx := c.Request.Context()
+	result, err := oc.organizationService.SwitchOrganizationService(ctx, c.Param("organizationId"))
+	if err != nil {
+		c.Error(err)
+	} else {
+		// アクセストークンを含むため、キャッシュ・冪等キーのレスポンスとして保存させない
+		c.Header("Cache-Control", "no-store")
+		c.JSON(
+			status.SuccessStatusMap["OK"].StatusCode,
+			result,
+		)
+	}
+}
+
+func (oc *OrganizationController) ListMembersController(c *gin.Context) {
+	ctx := c.Request.Context()
+	result, err := oc.organizationService.ListMembersService(ctx)
+	if err != nil {
+		c.Error(err)
+	} else {
+		c.JSON(
+			status.SuccessStatusMap["OK"].StatusCode,
+			result,
+		)
+	}
+}
+
+func (oc *OrganizationController) AddMemberController(c *gin.Context) {
+	ctx := c.Request.Context()
+	var addMemberForm inputOrganization.AddMemberForm
+	if !bindJSON(c, &addMemberForm) || !validate(c, addMemberForm.AddMemberValidate()) {
+		return
+	}
+	result, err := oc.organizationService.AddMemberService(ctx, addMemberForm)
+	if err != nil {
+		c.Error(err)
+	} else {
+		c.JSON(
+			status.SuccessStatusMap["CREATED"].StatusCode,
+			result,
+		)
+	}
+}
+
+func (oc *OrganizationController) InviteMemberController(c *gin.Context) {
+	ctx := c.Request.Context()
+	var inviteMemberForm inputOrganization.InviteMemberForm
+	if !bindJSON(c, &inviteMemberForm) || !validate(c, inviteMemberForm.InviteMemberValidate()) {
+		return
+	}
+	result, err := oc.organizationService.InviteMemberService(ctx, inviteMemberForm)
+	if err != nil {
+		c.Error(err)
+	} else {
+		c.JSON(
+			status.SuccessStatusMap["CREATED"].StatusCode,
+			result,
+		)
+	}
+}
+
+func (oc *OrganizationController) AcceptInvitationController(c *gin.Context) {
+	ctx := c.Request.Context()
+	var acceptInvitationForm inputOrganization.AcceptInvitationForm
+	if !bindJSON(c, &acceptInvitationForm) || !validate(c, acceptInvitationForm.AcceptInvitationValidate()) {
+		return
+	}
+	result, err := oc.organizationService.AcceptInvitationService(ctx, acceptInvitationForm)
+	if err != nil {
+		c.Error(err)
+	} else {
+		c.JSON(
+			status.SuccessStatusMap["OK"].StatusCode,
+			result,
+		)
+	}
+}
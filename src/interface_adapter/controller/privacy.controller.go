@@ -0,0 +1,71 @@
+package controller
+
+import (
+	"fmt"
+
+	status "github.com/Go_CleanArch/common/const"
+	inputUser "github.com/Go_CleanArch/usecase/input/user"
+	userService "github.com/Go_CleanArch/usecase/service/user"
+	"github.com/gin-gonic/gin"
+)
+
+type PrivacyController struct {
+	privacyService userService.PrivacyService
+}
+
+func NewPrivacyController(privacyService userService.PrivacyService) *PrivacyController {
+	return &PrivacyController{privacyService: privacyService}
+}
+
+func (pc *PrivacyController) DataExportController(c *gin.Context) {
+	ctx := c.Request.Context()
+	// 監査記録に残す接続元
+	dataExportForm := inputUser.DataExportForm{IpAddress: c.ClientIP()}
+	result, err := pc.privacyService.DataExportService(ctx, dataExportForm)
+	if err != nil {
+		c.Error(err)
+	} else {
+		// JSONアーカイブとしてダウンロードさせる
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="data-export-%s.json"`, result.Profile.UserId))
+		c.IndentedJSON(
+			status.SuccessStatusMap["OK"].StatusCode,
+			result,
+		)
+	}
+}
+
+func (pc *PrivacyController) RequestErasureController(c *gin.Context) {
+	ctx := c.Request.Context()
+	var erasureRequestForm inputUser.ErasureRequestForm
+	if !bindJSON(c, &erasureRequestForm) || !validate(c, erasureRequestForm.ErasureRequestValidate()) {
+		return
+	}
+	// 監査記録に残す接続元
+	erasureRequestForm.IpAddress = c.ClientIP()
+	result, err := pc.privacyService.RequestErasureService(ctx, erasureRequestForm)
+	if err != nil {
+		c.Error(err)
+	} else {
+		c.JSON(
+			status.SuccessStatusMap["ACCEPTED"].StatusCode,
+			result,
+		)
+	}
+}
+
+func (pc *PrivacyController) ConfirmErasureController(c *gin.Context) {
+	ctx := c.Request.Context()
+	var erasureConfirmForm inputUser.ErasureConfirmForm
+	if !bindJSON(c, &erasureConfirmForm) || !validate(c, erasureConfirmForm.ErasureConfirmValidate()) {
+		return
+	}
+	result, err := pc.privacyService.ConfirmErasureService(ctx, erasureConfirmForm)
+	if err != nil {
+		c.Error(err)
+	} else {
+		c.JSON(
+			status.SuccessStatusMap["OK"].StatusCode,
+			result,
+		)
+	}
+}
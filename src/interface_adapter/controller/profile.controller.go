@@ -0,0 +1,93 @@
+package controller
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+
+	status "github.com/Go_CleanArch/common/const"
+	"github.com/Go_CleanArch/common/errors"
+	uploadAvatarFactory "github.com/Go_CleanArch/domain/factory/user/upload_avatar"
+	userService "github.com/Go_CleanArch/usecase/service/user"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+// multipartのフォーム項目名
+const avatarFormField = "avatar"
+
+type ProfileController struct {
+	profileService userService.ProfileService
+}
+
+func NewProfileController(profileService userService.ProfileService) *ProfileController {
+	return &ProfileController{profileService: profileService}
+}
+
+func (pc *ProfileController) GetProfileController(c *gin.Context) {
+	ctx := c.Request.Context()
+	result, err := pc.profileService.GetProfileService(ctx)
+	if err != nil {
+		c.Error(err)
+	} else {
+		c.JSON(
+			status.SuccessStatusMap["OK"].StatusCode,
+			result,
+		)
+	}
+}
+
+func (pc *ProfileController) UploadAvatarController(c *gin.Context) {
+	ctx := c.Request.Context()
+	data, err := readAvatarFile(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	result, err := pc.profileService.UploadAvatarService(ctx, data)
+	if err != nil {
+		c.Error(err)
+	} else {
+		c.JSON(
+			status.SuccessStatusMap["OK"].StatusCode,
+			result,
+		)
+	}
+}
+
+// multipartリクエストから画像ファイルを読み込む
+func readAvatarFile(c *gin.Context) ([]byte, error) {
+	// 上限を大きく超えるリクエストは読み込まない(multipartの境界等の分を見込んで1MBの余裕を持たせる)
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, uploadAvatarFactory.MaxAvatarBytes+(1<<20))
+
+	fileHeader, err := c.FormFile(avatarFormField)
+	if err != nil {
+		log.WithError(err).Info("Avatar file is not specified")
+		apiErr := errors.OutputApiError(
+			[]errors.ApiErrMessage{
+				{
+					Key:   avatarFormField,
+					Value: "画像ファイルを選択してください(5MB以下)",
+				},
+			},
+			status.ErrorStatusMap["BAD_REQUEST"].StatusCode,
+			status.ErrorStatusMap["BAD_REQUEST"].StatusName,
+		)
+		return nil, apiErr.Error()
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		log.WithError(err).Error("Failed to open avatar file")
+		return nil, fmt.Errorf("アバター画像を開けませんでした: %w", err)
+	}
+	defer file.Close()
+
+	// 上限+1バイトまで読み込み、サイズ超過はドメイン側で判定する
+	data, err := io.ReadAll(io.LimitReader(file, uploadAvatarFactory.MaxAvatarBytes+1))
+	if err != nil {
+		log.WithError(err).Error("Failed to read avatar file")
+		return nil, fmt.Errorf("アバター画像を読み込めませんでした: %w", err)
+	}
+	return data, nil
+}
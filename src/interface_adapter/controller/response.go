@@ -0,0 +1,50 @@
+package controller
+
+import (
+	status "github.com/Go_CleanArch/common/const"
+	"github.com/Go_CleanArch/common/errors"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+// コントローラーはリクエストの変換・入力チェックを行ってからユースケースを呼び出し、
+// ユースケースが返したエラーを c.Error で登録する。
+// レスポンスへの変換は ErrorMiddleware(infrastructure/server)で行う。
+
+// bindJSON はリクエストボディを form に変換します
+// 変換できない場合はエラーを登録し、false を返します
+func bindJSON(c *gin.Context, form interface{}) bool {
+	if err := c.ShouldBindJSON(form); err != nil {
+		log.WithError(err).Error("Failed to bind JSON request body")
+		c.Error(errors.InvalidRequestError().Error())
+		return false
+	}
+	return true
+}
+
+// bindQuery はクエリパラメーターを form に変換します
+// 変換できない場合はエラーを登録し、false を返します
+func bindQuery(c *gin.Context, form interface{}) bool {
+	if err := c.ShouldBindQuery(form); err != nil {
+		log.WithError(err).Error("Failed to bind query parameters")
+		c.Error(errors.InvalidRequestError().Error())
+		return false
+	}
+	return true
+}
+
+// validate は入力チェックの結果を確認します
+// エラーがある場合は 400 のエラーを登録し、false を返します
+func validate(c *gin.Context, apiErrMessages []errors.ApiErrMessage) bool {
+	if len(apiErrMessages) == 0 {
+		return true
+	}
+	apiErr := errors.OutputApiError(
+		apiErrMessages,
+		status.ErrorStatusMap["BAD_REQUEST"].StatusCode,
+		status.ErrorStatusMap["BAD_REQUEST"].StatusName,
+	)
+	log.WithField("apiErr", apiErr).Error("Validation error occurred")
+	c.Error(apiErr.Error())
+	return false
+}
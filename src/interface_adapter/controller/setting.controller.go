@@ -0,0 +1,46 @@
+package controller
+
+import (
+	status "github.com/Go_CleanArch/common/const"
+	inputUser "github.com/Go_CleanArch/usecase/input/user"
+	userService "github.com/Go_CleanArch/usecase/service/user"
+	"github.com/gin-gonic/gin"
+)
+
+type SettingController struct {
+	settingService userService.SettingService
+}
+
+func NewSettingController(settingService userService.SettingService) *SettingController {
+	return &SettingController{settingService: settingService}
+}
+
+func (sc *SettingController) GetSettingsController(c *gin.Context) {
+	ctx := c.Request.Context()
+	result, err := sc.settingService.GetSettingsService(ctx)
+	if err != nil {
+		c.Error(err)
+	} else {
+		c.JSON(
+			status.SuccessStatusMap["OK"].StatusCode,
+			result,
+		)
+	}
+}
+
+func (sc *SettingController) UpdateSettingsController(c *gin.Context) {
+	ctx := c.Request.Context()
+	var updateUserSettingsForm inputUser.UpdateUserSettingsForm
+	if !bindJSON(c, &updateUserSettingsForm) || !validate(c, updateUserSettingsForm.UpdateUserSettingsValidate()) {
+		return
+	}
+	result, err := sc.settingService.UpdateSettingsService(ctx, updateUserSettingsForm)
+	if err != nil {
+		c.Error(err)
+	} else {
+		c.JSON(
+			status.SuccessStatusMap["OK"].StatusCode,
+			result,
+		)
+	}
+}
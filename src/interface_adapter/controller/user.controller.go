@@ -1,9 +1,8 @@
 package controller
 
 import (
-	"fmt"
-
 	status "github.com/Go_CleanArch/common/const"
+	inputUser "github.com/Go_CleanArch/usecase/input/user"
 	userService "github.com/Go_CleanArch/usecase/service/user"
 	"github.com/gin-gonic/gin"
 )
@@ -18,9 +17,13 @@ func NewUserController(userService userService.UserService) *UserController {
 
 func (uc *UserController) UserController(c *gin.Context) {
 	ctx := c.Request.Context()
-	result, err := uc.userService.CreateUserService(ctx, c)
+	var createUserForm inputUser.CreateUserForm
+	if !bindJSON(c, &createUserForm) || !validate(c, createUserForm.CreateUserValidate()) {
+		return
+	}
+	result, err := uc.userService.CreateUserService(ctx, createUserForm)
 	if err != nil {
-		fmt.Println(err)
+		c.Error(err)
 	} else {
 		c.JSON(
 			status.SuccessStatusMap["CREATED"].StatusCode,
@@ -31,10 +34,19 @@ func (uc *UserController) UserController(c *gin.Context) {
 
 func (uc *UserController) LoginControler(c *gin.Context) {
 	ctx := c.Request.Context()
-	result, err := uc.userService.LoginService(ctx, c)
+	var loginForm inputUser.LoginForm
+	if !bindJSON(c, &loginForm) || !validate(c, loginForm.LoginValidate()) {
+		return
+	}
+	// ログイン履歴に記録する接続元
+	loginForm.IpAddress = c.ClientIP()
+	loginForm.UserAgent = c.Request.UserAgent()
+	result, err := uc.userService.LoginService(ctx, loginForm)
 	if err != nil {
-		fmt.Println(err)
+		c.Error(err)
 	} else {
+		// アクセストークンを含むため、キャッシュ・冪等キーのレスポンスとして保存させない
+		c.Header("Cache-Control", "no-store")
 		c.JSON(
 			status.SuccessStatusMap["OK"].StatusCode,
 			result,
@@ -0,0 +1,32 @@
+package cache
+
+import (
+	"context"
+
+	"github.com/Go_CleanArch/interface_adapter/gateway/entity"
+	repository "github.com/Go_CleanArch/usecase/repository_interface"
+)
+
+// erasureRequestRepository は個人データの削除でユーザーを匿名化した場合に、ユーザーのキャッシュを削除するリポジトリ(デコレーター)
+// 削除の申請の検索結果はキャッシュしない。
+type erasureRequestRepository struct {
+	repository.ErasureRequestRepositoryInterface
+	store Store
+}
+
+// コンストラクタ
+func NewErasureRequestRepository(next repository.ErasureRequestRepositoryInterface, store Store) repository.ErasureRequestRepositoryInterface {
+	return &erasureRequestRepository{
+		ErasureRequestRepositoryInterface: next,
+		store:                             store,
+	}
+}
+
+func (er *erasureRequestRepository) EraseUserData(ctx context.Context, request *entity.ErasureRequest, anonymizedUserJson []byte, auditLogJson []byte) error {
+	if err := er.ErasureRequestRepositoryInterface.EraseUserData(ctx, request, anonymizedUserJson, auditLogJson); err != nil {
+		return err
+	}
+	// 変更前のアドレスのキャッシュは、ユーザーが見つからないため使用されない
+	invalidate(ctx, er.store, userIdKey(request.UserId))
+	return nil
+}
@@ -0,0 +1,45 @@
+package cache_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Go_CleanArch/interface_adapter/gateway/cache"
+	"github.com/Go_CleanArch/interface_adapter/gateway/entity"
+	repository "github.com/Go_CleanArch/usecase/repository_interface"
+	"github.com/stretchr/testify/assert"
+)
+
+// erasingRepository は個人データの削除として、キャッシュを経由せずにユーザーのメールアドレスを匿名化する
+type erasingRepository struct {
+	repository.ErasureRequestRepositoryInterface
+	userRepository repository.UserRepositoryInterface
+}
+
+func (er *erasingRepository) EraseUserData(ctx context.Context, request *entity.ErasureRequest, anonymizedUserJson []byte, auditLogJson []byte) error {
+	return er.userRepository.UpdateUserEmail(ctx, request.UserId, "erased-"+request.UserId[:8]+"@example.com")
+}
+
+func TestErasureRequestRepository(t *testing.T) {
+	t.Parallel()
+
+	t.Run("正常系_個人データの削除でユーザーのキャッシュを削除する", func(t *testing.T) {
+		t.Parallel()
+		ctx := context.Background()
+		store := cache.NewMemoryStore(100)
+		userRepository, next := newCachedUserRepository(store)
+		user := createUser(t, userRepository)
+		_, err := userRepository.FindUserByEmail(ctx, user.Email)
+		assert.NoError(t, err)
+		erasureRequestRepository := cache.NewErasureRequestRepository(&erasingRepository{userRepository: next.UserRepositoryInterface}, store)
+
+		err = erasureRequestRepository.EraseUserData(ctx, &entity.ErasureRequest{UserId: user.UserId}, nil, nil)
+
+		assert.NoError(t, err)
+		found, err := userRepository.FindUserById(ctx, user.UserId)
+		assert.NoError(t, err)
+		assert.Equal(t, "erased-"+user.UserId[:8]+"@example.com", found.Email)
+		_, err = userRepository.FindUserByEmail(ctx, user.Email)
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+	})
+}
@@ -0,0 +1,90 @@
+package cache
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// memoryStore はプロセスのメモリ上に保持するキャッシュ(LRU)
+// 最大件数を超えた場合は最も長く使用していないエントリから削除する。
+// サーバーごとに保持するため、複数のサーバーで実行する場合は他のサーバーでの変更が有効期限まで反映されない。
+type memoryStore struct {
+	mu       sync.Mutex
+	capacity int
+	entries  map[string]*list.Element
+	// 先頭ほど最近使用したエントリ
+	order *list.List
+	now   func() time.Time
+}
+
+type memoryEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+// コンストラクタ
+func NewMemoryStore(capacity int) Store {
+	return &memoryStore{
+		capacity: capacity,
+		entries:  make(map[string]*list.Element),
+		order:    list.New(),
+		now:      time.Now,
+	}
+}
+
+func (ms *memoryStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	element, ok := ms.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	entry := element.Value.(*memoryEntry)
+	if !ms.now().Before(entry.expiresAt) {
+		ms.remove(element)
+		return nil, false, nil
+	}
+	ms.order.MoveToFront(element)
+	// 呼び出し元での変更が保持している値に影響しないよう、複製を返す
+	return bytes.Clone(entry.value), true, nil
+}
+
+func (ms *memoryStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	if element, ok := ms.entries[key]; ok {
+		ms.remove(element)
+	}
+	if ttl <= 0 {
+		return nil
+	}
+	ms.entries[key] = ms.order.PushFront(&memoryEntry{
+		key:       key,
+		value:     bytes.Clone(value),
+		expiresAt: ms.now().Add(ttl),
+	})
+	for ms.order.Len() > ms.capacity {
+		ms.remove(ms.order.Back())
+	}
+	return nil
+}
+
+func (ms *memoryStore) Delete(ctx context.Context, keys ...string) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	for _, key := range keys {
+		if element, ok := ms.entries[key]; ok {
+			ms.remove(element)
+		}
+	}
+	return nil
+}
+
+func (ms *memoryStore) remove(element *list.Element) {
+	ms.order.Remove(element)
+	delete(ms.entries, element.Value.(*memoryEntry).key)
+}
@@ -0,0 +1,114 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestMemoryStore(capacity int) (*memoryStore, *time.Time) {
+	now := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	store := NewMemoryStore(capacity).(*memoryStore)
+	store.now = func() time.Time { return now }
+	return store, &now
+}
+
+func get(t *testing.T, store Store, key string) (string, bool) {
+	t.Helper()
+	value, found, err := store.Get(context.Background(), key)
+	assert.NoError(t, err)
+	return string(value), found
+}
+
+func TestMemoryStore(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	t.Run("正常系_保存した値を有効期限まで返す", func(t *testing.T) {
+		t.Parallel()
+		store, now := newTestMemoryStore(10)
+		assert.NoError(t, store.Set(ctx, "a", []byte("1"), time.Minute))
+
+		value, found := get(t, store, "a")
+		assert.True(t, found)
+		assert.Equal(t, "1", value)
+
+		*now = now.Add(time.Minute)
+		_, found = get(t, store, "a")
+		assert.False(t, found)
+		assert.Equal(t, 0, store.order.Len())
+	})
+
+	t.Run("正常系_最大件数を超えた場合は最も長く使用していない値を削除する", func(t *testing.T) {
+		t.Parallel()
+		store, _ := newTestMemoryStore(2)
+		assert.NoError(t, store.Set(ctx, "a", []byte("1"), time.Minute))
+		assert.NoError(t, store.Set(ctx, "b", []byte("2"), time.Minute))
+		// a を使用したため、b が最も長く使用していない値となる
+		get(t, store, "a")
+		assert.NoError(t, store.Set(ctx, "c", []byte("3"), time.Minute))
+
+		_, found := get(t, store, "b")
+		assert.False(t, found)
+		_, found = get(t, store, "a")
+		assert.True(t, found)
+		_, found = get(t, store, "c")
+		assert.True(t, found)
+	})
+
+	t.Run("正常系_上書きと削除", func(t *testing.T) {
+		t.Parallel()
+		store, _ := newTestMemoryStore(10)
+		assert.NoError(t, store.Set(ctx, "a", []byte("1"), time.Minute))
+		assert.NoError(t, store.Set(ctx, "a", []byte("2"), time.Minute))
+		assert.NoError(t, store.Set(ctx, "b", []byte("3"), time.Minute))
+
+		value, _ := get(t, store, "a")
+		assert.Equal(t, "2", value)
+
+		assert.NoError(t, store.Delete(ctx, "a", "b", "missing"))
+		_, found := get(t, store, "a")
+		assert.False(t, found)
+		_, found = get(t, store, "b")
+		assert.False(t, found)
+
+		// 有効期限が 0 の場合は保存しない(保存済みの値も削除する)
+		assert.NoError(t, store.Set(ctx, "c", []byte("4"), time.Minute))
+		assert.NoError(t, store.Set(ctx, "c", []byte("5"), 0))
+		_, found = get(t, store, "c")
+		assert.False(t, found)
+	})
+
+	t.Run("正常系_返した値の変更は保存した値に影響しない", func(t *testing.T) {
+		t.Parallel()
+		store, _ := newTestMemoryStore(10)
+		original := []byte("1")
+		assert.NoError(t, store.Set(ctx, "a", original, time.Minute))
+		original[0] = 'x'
+
+		value, _, _ := store.Get(ctx, "a")
+		value[0] = 'y'
+
+		stored, _ := get(t, store, "a")
+		assert.Equal(t, "1", stored)
+	})
+}
+
+func TestNewStore(t *testing.T) {
+	t.Parallel()
+
+	store, err := NewStore(StoreConfig{Driver: "none"})
+	assert.NoError(t, err)
+	assert.Nil(t, store)
+
+	store, err = NewStore(StoreConfig{Driver: "memory", Capacity: 10})
+	assert.NoError(t, err)
+	assert.NotNil(t, store)
+
+	_, err = NewStore(StoreConfig{Driver: "memory"})
+	assert.Error(t, err)
+	_, err = NewStore(StoreConfig{Driver: "redis"})
+	assert.ErrorContains(t, err, "未対応のキャッシュ方式です")
+}
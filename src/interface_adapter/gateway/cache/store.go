@@ -0,0 +1,136 @@
+// Package cache はリポジトリの検索結果をキャッシュするデコレーターを提供します
+// デコレーターはリポジトリのインターフェースを実装するため、呼び出し元はキャッシュの有無を意識せずに使用できます。
+// キャッシュの保存先は Store として差し替えられます。
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	dbConnect "github.com/Go_CleanArch/infrastructure/db"
+	log "github.com/sirupsen/logrus"
+)
+
+// Store はキャッシュの保存先
+// 値はエンコードしたバイト列で保持し、プロセス外の保存先(Redis など)にも置き換えられるようにする。
+type Store interface {
+	// 見つからない場合・有効期限を過ぎた場合は found に false を返す
+	Get(ctx context.Context, key string) (value []byte, found bool, err error)
+	// ttl が 0 以下の場合は保存しない
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// StoreConfig はキャッシュの保存先の設定を表す。
+type StoreConfig struct {
+	// 保存先(none / memory)
+	Driver string
+	// memory の場合に保持する最大件数
+	Capacity int
+}
+
+// コンストラクタ
+// 設定の保存先により切り替える(none / memory)。none の場合はキャッシュしないため nil を返す。
+func NewStore(config StoreConfig) (Store, error) {
+	switch driver := config.Driver; driver {
+	case "", "none":
+		return nil, nil
+	case "memory":
+		if config.Capacity <= 0 {
+			return nil, fmt.Errorf("キャッシュの最大件数は 1 以上で指定してください: %d", config.Capacity)
+		}
+		return NewMemoryStore(config.Capacity), nil
+	default:
+		return nil, fmt.Errorf("未対応のキャッシュ方式です: %s", driver)
+	}
+}
+
+// load は key のキャッシュを value に復元し、復元できたかを返す
+// キャッシュを使用できない場合もリポジトリから検索できるよう、保存先のエラー・復元できない値はキャッシュが無いものとして扱う。
+func load(ctx context.Context, store Store, key string, value interface{}) bool {
+	data, found := fetch(ctx, store, key)
+	return found && decode(key, data, value)
+}
+
+// save は value を key のキャッシュとして保存する(保存できない場合もエラーとしない)
+func save(ctx context.Context, store Store, key string, value interface{}, ttl time.Duration) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		log.WithError(err).WithField("key", key).Warn("Failed to encode cache")
+		return
+	}
+	put(ctx, store, key, data, ttl)
+}
+
+// loadEncrypted は saveEncrypted で保存したキャッシュを復号して value に復元し、復元できたかを返す
+func loadEncrypted(ctx context.Context, store Store, key string, value interface{}) bool {
+	data, found := fetch(ctx, store, key)
+	if !found {
+		return false
+	}
+	plaintext, err := dbConnect.DecryptValue(string(data))
+	if err != nil {
+		log.WithError(err).WithField("key", key).Warn("Failed to decrypt cache")
+		return false
+	}
+	return decode(key, []byte(plaintext), value)
+}
+
+// saveEncrypted は value を暗号化して key のキャッシュとして保存する
+// 個人情報・パスワードのハッシュを含む値は、保存先(プロセス外の保存先を含む)に平文で保持しないよう暗号化する。
+func saveEncrypted(ctx context.Context, store Store, key string, value interface{}, ttl time.Duration) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		log.WithError(err).WithField("key", key).Warn("Failed to encode cache")
+		return
+	}
+	encrypted, err := dbConnect.EncryptValue(string(data))
+	if err != nil {
+		log.WithError(err).WithField("key", key).Warn("Failed to encrypt cache")
+		return
+	}
+	put(ctx, store, key, []byte(encrypted), ttl)
+}
+
+func fetch(ctx context.Context, store Store, key string) ([]byte, bool) {
+	data, found, err := store.Get(ctx, key)
+	if err != nil {
+		log.WithError(err).WithField("key", key).Warn("Failed to get cache")
+		return nil, false
+	}
+	return data, found
+}
+
+func put(ctx context.Context, store Store, key string, data []byte, ttl time.Duration) {
+	if err := store.Set(ctx, key, data, ttl); err != nil {
+		log.WithError(err).WithField("key", key).Warn("Failed to set cache")
+	}
+}
+
+func decode(key string, data []byte, value interface{}) bool {
+	if err := json.Unmarshal(data, value); err != nil {
+		log.WithError(err).WithField("key", key).Warn("Failed to decode cache")
+		return false
+	}
+	return true
+}
+
+// invalidate は変更したレコードのキャッシュを削除する
+// トランザクション内の場合、コミットまでの間に他のリクエストが変更前の値をキャッシュする場合があるため、コミット後にも削除する。
+func invalidate(ctx context.Context, store Store, keys ...string) {
+	evict(ctx, store, keys...)
+	if dbConnect.InTransaction(ctx) {
+		dbConnect.AfterCommit(ctx, func() {
+			evict(context.WithoutCancel(ctx), store, keys...)
+		})
+	}
+}
+
+func evict(ctx context.Context, store Store, keys ...string) {
+	if err := store.Delete(ctx, keys...); err != nil {
+		// 削除できない場合、有効期限までは変更前の値を返す
+		log.WithError(err).WithField("keys", keys).Error("Failed to delete cache")
+	}
+}
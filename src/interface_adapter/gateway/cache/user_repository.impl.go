@@ -0,0 +1,143 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	domainEntity "github.com/Go_CleanArch/domain/entity"
+	dbConnect "github.com/Go_CleanArch/infrastructure/db"
+	"github.com/Go_CleanArch/interface_adapter/gateway/entity"
+	repository "github.com/Go_CleanArch/usecase/repository_interface"
+)
+
+// userRepository はユーザーの検索結果をキャッシュするリポジトリ(デコレーター)
+// ユーザーIDごとのユーザーと、メールアドレスからユーザーIDへの対応をキャッシュする。
+// 存在しない場合も negativeTTL の間キャッシュし、ユーザーを作成・変更した場合は該当するキャッシュを削除する。
+type userRepository struct {
+	next        repository.UserRepositoryInterface
+	store       Store
+	ttl         time.Duration
+	negativeTTL time.Duration
+}
+
+// ユーザーIDごとのキャッシュ
+// ユーザーには氏名・メールアドレス・パスワードのハッシュが含まれるため、暗号化して保存する(saveEncrypted)
+type cachedUser struct {
+	Found bool         `json:"found"`
+	User  *entity.User `json:"user,omitempty"`
+}
+
+// メールアドレスごとのキャッシュ(ユーザーは cachedUser から取得する)
+type cachedUserEmail struct {
+	Found  bool   `json:"found"`
+	UserId string `json:"userId,omitempty"`
+}
+
+// コンストラクタ
+func NewUserRepository(next repository.UserRepositoryInterface, store Store, ttl time.Duration, negativeTTL time.Duration) repository.UserRepositoryInterface {
+	return &userRepository{
+		next:        next,
+		store:       store,
+		ttl:         ttl,
+		negativeTTL: negativeTTL,
+	}
+}
+
+func userIdKey(userId string) string {
+	return "user:id:" + userId
+}
+
+// メールアドレスは大文字・小文字を区別せずに検索するため、正規化したアドレスをキーとする
+func userEmailKey(email string) string {
+	return "user:email:" + domainEntity.NormalizeEmail(email)
+}
+
+// ユーザーレコード作成
+func (ur *userRepository) CreateUser(ctx context.Context, userJson []byte) (*entity.User, error) {
+	user, err := ur.next.CreateUser(ctx, userJson)
+	if err != nil {
+		return nil, err
+	}
+	// 作成前に検索した「存在しない」キャッシュを削除する
+	invalidate(ctx, ur.store, userIdKey(user.UserId), userEmailKey(user.Email))
+	return user, nil
+}
+
+// Userの存在チェック
+func (ur *userRepository) FindUserByEmail(ctx context.Context, email string) (*entity.User, error) {
+	// トランザクション内ではコミット前の変更を参照するため、キャッシュを使用しない
+	if dbConnect.InTransaction(ctx) {
+		return ur.next.FindUserByEmail(ctx, email)
+	}
+
+	key := userEmailKey(email)
+	var cached cachedUserEmail
+	if load(ctx, ur.store, key, &cached) {
+		if !cached.Found {
+			return nil, repository.ErrNotFound
+		}
+		// 変更前のアドレスの対応が残っている場合があるため、ユーザーのアドレスと一致する場合のみ使用する
+		user, err := ur.FindUserById(ctx, cached.UserId)
+		if err == nil && domainEntity.NormalizeEmail(user.Email) == domainEntity.NormalizeEmail(email) {
+			return user, nil
+		}
+	}
+
+	// キャッシュする値がレプリカの反映遅延の影響を受けないよう、プライマリから読み取る
+	user, err := ur.next.FindUserByEmail(dbConnect.WithPrimary(ctx), email)
+	if errors.Is(err, repository.ErrNotFound) {
+		save(ctx, ur.store, key, cachedUserEmail{Found: false}, ur.negativeTTL)
+		return nil, err
+	} else if err != nil {
+		return nil, err
+	}
+	save(ctx, ur.store, key, cachedUserEmail{Found: true, UserId: user.UserId}, ur.ttl)
+	saveEncrypted(ctx, ur.store, userIdKey(user.UserId), cachedUser{Found: true, User: user}, ur.ttl)
+	return user, nil
+}
+
+// ユーザーIDによる検索
+func (ur *userRepository) FindUserById(ctx context.Context, userId string) (*entity.User, error) {
+	if dbConnect.InTransaction(ctx) {
+		return ur.next.FindUserById(ctx, userId)
+	}
+
+	key := userIdKey(userId)
+	var cached cachedUser
+	if loadEncrypted(ctx, ur.store, key, &cached) {
+		if !cached.Found || cached.User == nil {
+			return nil, repository.ErrNotFound
+		}
+		return cached.User, nil
+	}
+
+	user, err := ur.next.FindUserById(dbConnect.WithPrimary(ctx), userId)
+	if errors.Is(err, repository.ErrNotFound) {
+		saveEncrypted(ctx, ur.store, key, cachedUser{Found: false}, ur.negativeTTL)
+		return nil, err
+	} else if err != nil {
+		return nil, err
+	}
+	saveEncrypted(ctx, ur.store, key, cachedUser{Found: true, User: user}, ur.ttl)
+	return user, nil
+}
+
+// メールアドレスの変更
+// 変更前のアドレスのキャッシュは、検索時にユーザーのアドレスと一致しないため使用されない。
+func (ur *userRepository) UpdateUserEmail(ctx context.Context, userId string, email string) error {
+	if err := ur.next.UpdateUserEmail(ctx, userId, email); err != nil {
+		return err
+	}
+	invalidate(ctx, ur.store, userIdKey(userId), userEmailKey(email))
+	return nil
+}
+
+// アバター画像の変更
+func (ur *userRepository) UpdateUserAvatarKey(ctx context.Context, userId string, avatarKey string) error {
+	if err := ur.next.UpdateUserAvatarKey(ctx, userId, avatarKey); err != nil {
+		return err
+	}
+	invalidate(ctx, ur.store, userIdKey(userId))
+	return nil
+}
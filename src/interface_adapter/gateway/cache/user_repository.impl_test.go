@@ -0,0 +1,214 @@
+package cache_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Go_CleanArch/infrastructure/config"
+	dbConnect "github.com/Go_CleanArch/infrastructure/db"
+	"github.com/Go_CleanArch/interface_adapter/gateway/cache"
+	"github.com/Go_CleanArch/interface_adapter/gateway/contract"
+	"github.com/Go_CleanArch/interface_adapter/gateway/entity"
+	"github.com/Go_CleanArch/interface_adapter/gateway/memory"
+	repository "github.com/Go_CleanArch/usecase/repository_interface"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// ユーザーのキャッシュは暗号化して保存するため、暗号化の鍵を設定する
+func TestMain(m *testing.M) {
+	dbConnect.Init(config.DatabaseConfig{
+		Driver:     dbConnect.DriverSqlite,
+		SqlitePath: ":memory:",
+		HealthCheck: config.HealthCheckConfig{
+			Interval:         time.Minute,
+			Timeout:          time.Second,
+			FailureThreshold: 3,
+		},
+		Encryption: config.EncryptionConfig{
+			Keys:          []string{"test-1:MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="},
+			ActiveKeyId:   "test-1",
+			BlindIndexKey: "ZmVkY2JhOTg3NjU0MzIxMGZlZGNiYTk4NzY1NDMyMTA=",
+		},
+	})
+	os.Exit(m.Run())
+}
+
+// countingUserRepository はキャッシュを経由せずに検索した回数を数える
+type countingUserRepository struct {
+	repository.UserRepositoryInterface
+	finds atomic.Int32
+}
+
+func (cr *countingUserRepository) FindUserByEmail(ctx context.Context, email string) (*entity.User, error) {
+	cr.finds.Add(1)
+	return cr.UserRepositoryInterface.FindUserByEmail(ctx, email)
+}
+
+func (cr *countingUserRepository) FindUserById(ctx context.Context, userId string) (*entity.User, error) {
+	cr.finds.Add(1)
+	return cr.UserRepositoryInterface.FindUserById(ctx, userId)
+}
+
+func newCachedUserRepository(store cache.Store) (repository.UserRepositoryInterface, *countingUserRepository) {
+	next := &countingUserRepository{UserRepositoryInterface: memory.NewUserRepository()}
+	return cache.NewUserRepository(next, store, time.Minute, time.Minute), next
+}
+
+func createUser(t *testing.T, userRepository repository.UserRepositoryInterface) entity.User {
+	t.Helper()
+	userId := uuid.NewString()
+	user := entity.User{UserId: userId, UserName: "cache", Password: "hashed-password", Email: userId[:8] + "@example.com"}
+	userJson, err := json.Marshal(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := userRepository.CreateUser(context.Background(), userJson); err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
+
+func TestUserRepository(t *testing.T) {
+	t.Parallel()
+
+	// キャッシュの有無によらず、リポジトリと同じ振る舞いをする
+	contract.UserRepository(t, func(t *testing.T) repository.UserRepositoryInterface {
+		userRepository, _ := newCachedUserRepository(cache.NewMemoryStore(100))
+		return userRepository
+	})
+}
+
+func TestUserRepositoryCache(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	t.Run("正常系_検索結果をキャッシュする", func(t *testing.T) {
+		t.Parallel()
+		userRepository, next := newCachedUserRepository(cache.NewMemoryStore(100))
+		user := createUser(t, userRepository)
+
+		for i := 0; i < 3; i++ {
+			found, err := userRepository.FindUserByEmail(ctx, strings.ToUpper(user.Email))
+			assert.NoError(t, err)
+			assert.Equal(t, user.UserId, found.UserId)
+			found, err = userRepository.FindUserById(ctx, user.UserId)
+			assert.NoError(t, err)
+			assert.Equal(t, user.Email, found.Email)
+		}
+
+		// メールアドレスで検索したユーザーはIDでの検索にも使用する
+		assert.Equal(t, int32(1), next.finds.Load())
+	})
+
+	t.Run("正常系_ユーザーは暗号化してキャッシュする", func(t *testing.T) {
+		t.Parallel()
+		store := cache.NewMemoryStore(100)
+		userRepository, _ := newCachedUserRepository(store)
+		user := createUser(t, userRepository)
+		_, err := userRepository.FindUserById(ctx, user.UserId)
+		assert.NoError(t, err)
+
+		data, found, err := store.Get(ctx, "user:id:"+user.UserId)
+
+		assert.NoError(t, err)
+		assert.True(t, found)
+		assert.True(t, strings.HasPrefix(string(data), "enc:v1:test-1:"))
+		assert.NotContains(t, string(data), user.Password)
+		assert.NotContains(t, string(data), user.Email)
+	})
+
+	t.Run("正常系_メールアドレスは正規化して照合する", func(t *testing.T) {
+		t.Parallel()
+		userRepository, next := newCachedUserRepository(cache.NewMemoryStore(100))
+		user := createUser(t, userRepository)
+		_, err := userRepository.FindUserByEmail(ctx, user.Email)
+		assert.NoError(t, err)
+
+		found, err := userRepository.FindUserByEmail(ctx, "  "+strings.ToUpper(user.Email)+" ")
+
+		assert.NoError(t, err)
+		assert.Equal(t, user.UserId, found.UserId)
+		assert.Equal(t, int32(1), next.finds.Load())
+	})
+
+	t.Run("正常系_存在しないことをキャッシュし作成で削除する", func(t *testing.T) {
+		t.Parallel()
+		userRepository, next := newCachedUserRepository(cache.NewMemoryStore(100))
+		userId := uuid.NewString()
+		email := userId[:8] + "@example.com"
+
+		for i := 0; i < 2; i++ {
+			_, err := userRepository.FindUserByEmail(ctx, email)
+			assert.ErrorIs(t, err, repository.ErrNotFound)
+		}
+		assert.Equal(t, int32(1), next.finds.Load())
+
+		userJson, _ := json.Marshal(entity.User{UserId: userId, Email: email, Password: "hashed-password"})
+		_, err := userRepository.CreateUser(ctx, userJson)
+		assert.NoError(t, err)
+
+		found, err := userRepository.FindUserByEmail(ctx, email)
+		assert.NoError(t, err)
+		assert.Equal(t, userId, found.UserId)
+	})
+
+	t.Run("正常系_変更した場合はキャッシュを削除する", func(t *testing.T) {
+		t.Parallel()
+		userRepository, _ := newCachedUserRepository(cache.NewMemoryStore(100))
+		user := createUser(t, userRepository)
+		_, err := userRepository.FindUserByEmail(ctx, user.Email)
+		assert.NoError(t, err)
+		newEmail := uuid.NewString()[:8] + "@example.com"
+		_, err = userRepository.FindUserByEmail(ctx, newEmail)
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+
+		assert.NoError(t, userRepository.UpdateUserEmail(ctx, user.UserId, newEmail))
+		avatarKey := "avatars/" + user.UserId
+		assert.NoError(t, userRepository.UpdateUserAvatarKey(ctx, user.UserId, avatarKey))
+
+		found, err := userRepository.FindUserByEmail(ctx, newEmail)
+		assert.NoError(t, err)
+		assert.Equal(t, &avatarKey, found.AvatarKey)
+		// 変更前のアドレスのキャッシュは使用しない
+		_, err = userRepository.FindUserByEmail(ctx, user.Email)
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+	})
+
+	t.Run("異常系_保存先のエラーはキャッシュが無いものとして検索する", func(t *testing.T) {
+		t.Parallel()
+		next := &countingUserRepository{UserRepositoryInterface: memory.NewUserRepository()}
+		userRepository := cache.NewUserRepository(next, failingStore{}, time.Minute, time.Minute)
+		user := createUser(t, userRepository)
+
+		for i := 0; i < 2; i++ {
+			found, err := userRepository.FindUserById(ctx, user.UserId)
+			assert.NoError(t, err)
+			assert.Equal(t, user.Email, found.Email)
+		}
+		assert.Equal(t, int32(2), next.finds.Load())
+	})
+}
+
+// failingStore は接続できない保存先
+type failingStore struct{}
+
+var errUnavailable = errors.New("connection refused")
+
+func (failingStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	return nil, false, errUnavailable
+}
+
+func (failingStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return errUnavailable
+}
+
+func (failingStore) Delete(ctx context.Context, keys ...string) error {
+	return errUnavailable
+}
@@ -0,0 +1,179 @@
+// Package contract はリポジトリのインターフェースの実装(データベース・メモリなど)が
+// 同じ振る舞いをすることを検証する共通のテストを提供します。
+package contract
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Go_CleanArch/interface_adapter/gateway/entity"
+	repository "github.com/Go_CleanArch/usecase/repository_interface"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// UserRepository は UserRepositoryInterface の実装を検証します
+// newRepository は検証ごとに呼び出されます。検証ごとに異なるユーザーID・メールアドレスを使用するため、
+// 返すリポジトリは他の検証とデータを共有していても構いません。
+func UserRepository(t *testing.T, newRepository func(t *testing.T) repository.UserRepositoryInterface) {
+	ctx := context.Background()
+
+	t.Run("正常系_作成したユーザーをIDとメールアドレスで検索できる", func(t *testing.T) {
+		t.Parallel()
+		userRepository := newRepository(t)
+		user := newUser()
+
+		created, err := userRepository.CreateUser(ctx, userJson(t, user))
+		assert.NoError(t, err)
+		assert.Equal(t, user.UserId, created.UserId)
+		assert.False(t, created.CreatedAt.IsZero())
+
+		found, err := userRepository.FindUserById(ctx, user.UserId)
+		assert.NoError(t, err)
+		assert.Equal(t, user.Email, found.Email)
+		assert.Equal(t, user.Password, found.Password)
+		assert.WithinDuration(t, created.CreatedAt, found.CreatedAt, time.Second)
+
+		// 大文字・小文字を区別せずに検索する
+		found, err = userRepository.FindUserByEmail(ctx, strings.ToUpper(user.Email))
+		assert.NoError(t, err)
+		assert.Equal(t, user.UserId, found.UserId)
+	})
+
+	t.Run("異常系_存在しないユーザーはErrNotFound", func(t *testing.T) {
+		t.Parallel()
+		userRepository := newRepository(t)
+		user := newUser()
+
+		_, err := userRepository.FindUserById(ctx, user.UserId)
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+		_, err = userRepository.FindUserByEmail(ctx, user.Email)
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+		err = userRepository.UpdateUserEmail(ctx, user.UserId, user.Email)
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+		err = userRepository.UpdateUserAvatarKey(ctx, user.UserId, "avatars/"+user.UserId)
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+	})
+
+	t.Run("異常系_登録済みのメールアドレスで作成", func(t *testing.T) {
+		t.Parallel()
+		userRepository := newRepository(t)
+		user := newUser()
+		_, err := userRepository.CreateUser(ctx, userJson(t, user))
+		assert.NoError(t, err)
+
+		duplicate := newUser()
+		duplicate.Email = strings.ToUpper(user.Email)
+		_, err = userRepository.CreateUser(ctx, userJson(t, duplicate))
+
+		assert.ErrorIs(t, err, repository.ErrEmailAlreadyExists)
+		_, err = userRepository.FindUserById(ctx, duplicate.UserId)
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+	})
+
+	t.Run("正常系_削除済みのユーザーは検索の対象外", func(t *testing.T) {
+		t.Parallel()
+		userRepository := newRepository(t)
+		user := newUser()
+		deletedAt := time.Now()
+		user.DeletedAt = &deletedAt
+		_, err := userRepository.CreateUser(ctx, userJson(t, user))
+		assert.NoError(t, err)
+
+		_, err = userRepository.FindUserById(ctx, user.UserId)
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+		_, err = userRepository.FindUserByEmail(ctx, user.Email)
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+
+		// 削除済みのユーザーのメールアドレスは再度使用できる
+		reused := newUser()
+		reused.Email = user.Email
+		_, err = userRepository.CreateUser(ctx, userJson(t, reused))
+		assert.NoError(t, err)
+	})
+
+	t.Run("正常系_メールアドレスの変更", func(t *testing.T) {
+		t.Parallel()
+		userRepository := newRepository(t)
+		user := newUser()
+		_, err := userRepository.CreateUser(ctx, userJson(t, user))
+		assert.NoError(t, err)
+		newEmail := newUser().Email
+
+		assert.NoError(t, userRepository.UpdateUserEmail(ctx, user.UserId, newEmail))
+
+		found, err := userRepository.FindUserByEmail(ctx, newEmail)
+		assert.NoError(t, err)
+		assert.Equal(t, user.UserId, found.UserId)
+		_, err = userRepository.FindUserByEmail(ctx, user.Email)
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+	})
+
+	t.Run("異常系_他のユーザーのメールアドレスへ変更", func(t *testing.T) {
+		t.Parallel()
+		userRepository := newRepository(t)
+		user, other := newUser(), newUser()
+		for _, u := range []entity.User{user, other} {
+			_, err := userRepository.CreateUser(ctx, userJson(t, u))
+			assert.NoError(t, err)
+		}
+
+		err := userRepository.UpdateUserEmail(ctx, user.UserId, strings.ToUpper(other.Email))
+
+		assert.ErrorIs(t, err, repository.ErrEmailAlreadyExists)
+		found, err := userRepository.FindUserById(ctx, user.UserId)
+		assert.NoError(t, err)
+		assert.Equal(t, user.Email, found.Email)
+	})
+
+	t.Run("正常系_アバター画像の変更", func(t *testing.T) {
+		t.Parallel()
+		userRepository := newRepository(t)
+		user := newUser()
+		_, err := userRepository.CreateUser(ctx, userJson(t, user))
+		assert.NoError(t, err)
+		avatarKey := "avatars/" + user.UserId
+
+		assert.NoError(t, userRepository.UpdateUserAvatarKey(ctx, user.UserId, avatarKey))
+
+		found, err := userRepository.FindUserById(ctx, user.UserId)
+		assert.NoError(t, err)
+		assert.Equal(t, &avatarKey, found.AvatarKey)
+	})
+
+	t.Run("異常系_キャンセル済みのコンテキスト", func(t *testing.T) {
+		t.Parallel()
+		userRepository := newRepository(t)
+		user := newUser()
+		canceled, cancel := context.WithCancel(ctx)
+		cancel()
+
+		_, err := userRepository.CreateUser(canceled, userJson(t, user))
+		assert.ErrorIs(t, err, context.Canceled)
+		_, err = userRepository.FindUserById(canceled, user.UserId)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+// 検証ごとに異なるユーザーID・メールアドレスのユーザー
+func newUser() entity.User {
+	userId := uuid.NewString()
+	return entity.User{
+		UserId:   userId,
+		UserName: "contract",
+		Password: "hashed-password",
+		Email:    userId[:8] + "@example.com",
+	}
+}
+
+func userJson(t *testing.T, user entity.User) []byte {
+	t.Helper()
+	userJson, err := json.Marshal(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return userJson
+}
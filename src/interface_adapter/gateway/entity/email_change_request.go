@@ -0,0 +1,19 @@
+package entity
+
+import "time"
+
+// EmailChangeRequest is email change request models property
+// メールアドレスは暗号化して保存する
+type EmailChangeRequest struct {
+	EmailChangeRequestId string     `gorm:"primaryKey" json:"emailChangeRequestId"`
+	UserId               string     `gorm:"not null" json:"userId"`
+	CurrentEmail         string     `gorm:"not null;serializer:encrypted" json:"currentEmail"`
+	NewEmail             string     `gorm:"not null;serializer:encrypted" json:"newEmail"`
+	ConfirmTokenHash     string     `gorm:"not null" json:"confirmTokenHash"`
+	CancelTokenHash      string     `gorm:"not null" json:"cancelTokenHash"`
+	ExpiresAt            time.Time  `gorm:"not null" json:"expiresAt"`
+	ConfirmedAt          *time.Time `json:"confirmedAt,omitempty"`
+	CancelledAt          *time.Time `json:"cancelledAt,omitempty"`
+	CreatedAt            time.Time
+	UpdatedAt            time.Time
+}
@@ -0,0 +1,14 @@
+package entity
+
+import "time"
+
+// ErasureRequest is personal data erasure request models property
+type ErasureRequest struct {
+	ErasureRequestId string     `gorm:"primaryKey" json:"erasureRequestId"`
+	UserId           string     `gorm:"not null" json:"userId"`
+	ConfirmTokenHash string     `gorm:"not null" json:"confirmTokenHash"`
+	ExpiresAt        time.Time  `gorm:"not null" json:"expiresAt"`
+	ConfirmedAt      *time.Time `json:"confirmedAt,omitempty"`
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+}
@@ -0,0 +1,19 @@
+package entity
+
+import "time"
+
+// IdempotencyKey is Idempotency-Key request and response models property
+// レスポンスにはメールアドレスなどが含まれるため、ボディは暗号化して保存する
+type IdempotencyKey struct {
+	Id             int64  `gorm:"primaryKey;autoIncrement" json:"id"`
+	Scope          string `gorm:"not null" json:"scope"`
+	IdempotencyKey string `gorm:"not null" json:"idempotencyKey"`
+	RequestHash    string `gorm:"not null" json:"requestHash"`
+	// 処理中は nil
+	ResponseStatus      *int       `json:"responseStatus"`
+	ResponseContentType *string    `json:"responseContentType"`
+	ResponseBody        *string    `gorm:"serializer:encrypted" json:"responseBody"`
+	LockedUntil         *time.Time `json:"lockedUntil"`
+	ExpiresAt           time.Time  `gorm:"not null" json:"expiresAt"`
+	CreatedAt           time.Time  `json:"createdAt"`
+}
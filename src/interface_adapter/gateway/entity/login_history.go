@@ -0,0 +1,12 @@
+package entity
+
+import "time"
+
+// LoginHistory is login history models property
+type LoginHistory struct {
+	LoginHistoryId string    `gorm:"primaryKey" json:"loginHistoryId"`
+	UserId         string    `gorm:"not null" json:"userId"`
+	IpAddress      string    `json:"ipAddress"`
+	UserAgent      string    `json:"userAgent"`
+	LoggedInAt     time.Time `gorm:"not null" json:"loggedInAt"`
+}
@@ -0,0 +1,38 @@
+package entity
+
+import "time"
+
+// Organization is organization models property
+type Organization struct {
+	OrganizationId string `gorm:"primaryKey" json:"organizationId"`
+	Name           string `gorm:"not null" json:"name"`
+	Slug           string `gorm:"not null" json:"slug"`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// OrganizationMembership is organization membership models property
+type OrganizationMembership struct {
+	OrganizationId string `gorm:"primaryKey" json:"organizationId"`
+	UserId         string `gorm:"primaryKey" json:"userId"`
+	Role           string `gorm:"not null" json:"role"`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// OrganizationInvitation is organization invitation models property
+// 宛先のメールアドレスは暗号化して保存し、ユーザーとの照合にはブラインドインデックスを使用する
+type OrganizationInvitation struct {
+	InvitationId   string     `gorm:"primaryKey" json:"invitationId"`
+	OrganizationId string     `gorm:"not null" json:"organizationId"`
+	Email          string     `gorm:"not null;serializer:encrypted" json:"email"`
+	EmailIndex     string     `gorm:"serializer:blindindex;blindindex:Email" json:"-"`
+	Role           string     `gorm:"not null" json:"role"`
+	InvitedBy      string     `gorm:"not null" json:"invitedBy"`
+	TokenHash      string     `gorm:"not null" json:"tokenHash"`
+	ExpiresAt      time.Time  `gorm:"not null" json:"expiresAt"`
+	AcceptedAt     *time.Time `json:"acceptedAt,omitempty"`
+	AcceptedBy     *string    `json:"acceptedBy,omitempty"`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
@@ -0,0 +1,26 @@
+package entity
+
+import "time"
+
+// OutboxEvent is domain event outbox models property
+// イベントにはメールアドレス・IPアドレスが含まれるため、ペイロードは暗号化して保存する
+type OutboxEvent struct {
+	Id             int64      `gorm:"primaryKey;autoIncrement" json:"id"`
+	EventId        string     `gorm:"not null;unique" json:"eventId"`
+	EventName      string     `gorm:"not null" json:"eventName"`
+	AggregateType  string     `gorm:"not null" json:"aggregateType"`
+	AggregateId    string     `gorm:"not null" json:"aggregateId"`
+	Payload        string     `gorm:"not null;serializer:encrypted" json:"payload"`
+	OccurredAt     time.Time  `gorm:"not null" json:"occurredAt"`
+	Attempts       int        `gorm:"not null" json:"attempts"`
+	NextAttemptAt  time.Time  `gorm:"not null" json:"nextAttemptAt"`
+	LockedUntil    *time.Time `json:"lockedUntil"`
+	LastError      *string    `json:"lastError"`
+	PublishedAt    *time.Time `json:"publishedAt"`
+	DeadLetteredAt *time.Time `json:"deadLetteredAt"`
+	CreatedAt      time.Time  `json:"createdAt"`
+}
+
+func (OutboxEvent) TableName() string {
+	return "outbox"
+}
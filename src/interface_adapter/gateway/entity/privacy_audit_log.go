@@ -0,0 +1,12 @@
+package entity
+
+import "time"
+
+// PrivacyAuditLog is personal data request audit models property
+type PrivacyAuditLog struct {
+	PrivacyAuditLogId string    `gorm:"primaryKey" json:"privacyAuditLogId"`
+	UserId            string    `gorm:"not null" json:"userId"`
+	Action            string    `gorm:"not null" json:"action"`
+	IpAddress         string    `json:"ipAddress"`
+	CreatedAt         time.Time `json:"createdAt"`
+}
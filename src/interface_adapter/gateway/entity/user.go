@@ -3,12 +3,15 @@ package entity
 import "time"
 
 // User is user models property
+// 氏名・メールアドレスは暗号化して保存し、メールアドレスの検索にはブラインドインデックスを使用する
 type User struct {
-	UserId    string `gorm:"primaryKey" json:"userId" `
-	UserName  string `gorm:"not null" json:"userName,omitempty"`
-	Password  string `gorm:"not null" json:"password,omitempty"`
-	Email     string `gorm:"not null" json:"email,omitempty"`
-	CreatedAt time.Time
-	UpdatedAt time.Time
-	DeletedAt *time.Time
+	UserId     string  `gorm:"primaryKey" json:"userId" `
+	UserName   string  `gorm:"not null;serializer:encrypted" json:"userName,omitempty"`
+	Password   string  `gorm:"not null" json:"password,omitempty"`
+	Email      string  `gorm:"not null;serializer:encrypted" json:"email,omitempty"`
+	EmailIndex string  `gorm:"serializer:blindindex;blindindex:Email" json:"-"`
+	AvatarKey  *string `json:"avatarKey,omitempty"`
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	DeletedAt  *time.Time
 }
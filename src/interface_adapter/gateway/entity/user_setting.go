@@ -0,0 +1,12 @@
+package entity
+
+import "time"
+
+// UserSetting is user setting models property
+// SettingValue は設定値をJSONエンコードした文字列で保持する
+type UserSetting struct {
+	UserId       string    `gorm:"primaryKey" json:"userId"`
+	SettingKey   string    `gorm:"primaryKey" json:"settingKey"`
+	SettingValue string    `gorm:"not null" json:"settingValue"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
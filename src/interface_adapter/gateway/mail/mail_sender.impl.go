@@ -0,0 +1,41 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+
+	repository "github.com/Go_CleanArch/usecase/repository_interface"
+)
+
+// MailConfig はメール送信方式の設定を表す。
+type MailConfig struct {
+	// 送信方式(smtp / log)
+	Driver string
+	Smtp   SmtpConfig
+}
+
+// コンストラクタ
+// 設定の送信方式により切り替える(smtp / log)。未指定の場合はログ出力のみを行う。
+func NewMailSender(config MailConfig) (repository.MailSenderInterface, error) {
+	switch driver := config.Driver; driver {
+	case "smtp":
+		return NewSmtpMailSender(config.Smtp)
+	case "", "log":
+		return NewLogMailSender(), nil
+	default:
+		return nil, fmt.Errorf("未対応のメール送信方式です: %s", driver)
+	}
+}
+
+// ログ出力のみを行うメール送信(ローカル開発用)
+type logMailSender struct{}
+
+func NewLogMailSender() repository.MailSenderInterface {
+	return &logMailSender{}
+}
+
+func (lm *logMailSender) SendMail(ctx context.Context, message repository.MailMessage) error {
+	// ローカル開発で確認リンクを参照できるよう本文も出力する
+	logMail(message).WithField("body", message.Body).Info("Mail sent (log driver)")
+	return nil
+}
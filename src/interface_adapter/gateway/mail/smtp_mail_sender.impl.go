@@ -0,0 +1,72 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+
+	repository "github.com/Go_CleanArch/usecase/repository_interface"
+	log "github.com/sirupsen/logrus"
+)
+
+type SmtpConfig struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+type smtpMailSender struct {
+	config SmtpConfig
+}
+
+func NewSmtpMailSender(config SmtpConfig) (repository.MailSenderInterface, error) {
+	if config.Host == "" || config.Port == "" || config.From == "" {
+		return nil, fmt.Errorf("SMTPの接続先(SMTP_HOST / SMTP_PORT / MAIL_FROM)が設定されていません")
+	}
+	return &smtpMailSender{config: config}, nil
+}
+
+func (sm *smtpMailSender) SendMail(ctx context.Context, message repository.MailMessage) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if sm.config.Username != "" {
+		auth = smtp.PlainAuth("", sm.config.Username, sm.config.Password, sm.config.Host)
+	}
+
+	addr := net.JoinHostPort(sm.config.Host, sm.config.Port)
+	if err := smtp.SendMail(addr, auth, sm.config.From, []string{message.To}, sm.buildMessage(message)); err != nil {
+		logMail(message).WithError(err).Error("Failed to send mail")
+		return fmt.Errorf("メールの送信に失敗しました: %w", err)
+	}
+
+	logMail(message).Info("Mail sent successfully")
+	return nil
+}
+
+func (sm *smtpMailSender) buildMessage(message repository.MailMessage) []byte {
+	var builder strings.Builder
+	builder.WriteString("From: " + sm.config.From + "\r\n")
+	builder.WriteString("To: " + message.To + "\r\n")
+	builder.WriteString("Subject: " + mime.BEncoding.Encode("UTF-8", message.Subject) + "\r\n")
+	builder.WriteString("MIME-Version: 1.0\r\n")
+	builder.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	builder.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	builder.WriteString("\r\n")
+	builder.WriteString(strings.ReplaceAll(message.Body, "\n", "\r\n"))
+	return []byte(builder.String())
+}
+
+func logMail(message repository.MailMessage) *log.Entry {
+	return log.WithFields(log.Fields{
+		"to":      message.To,
+		"subject": message.Subject,
+	})
+}
@@ -0,0 +1,83 @@
+package memory
+
+import (
+	"errors"
+	"sync"
+
+	repository "github.com/Go_CleanArch/usecase/repository_interface"
+)
+
+// 主キーが重複するレコードを登録しようとした場合のエラー
+var errDuplicateKey = errors.New("主キーが重複するレコードが存在します")
+
+// table はレコードをメモリ上に保持するテーブル(複数のゴルーチンから同時に使用できる)
+// 呼び出し元での変更が保持しているレコードに影響しないよう、レコードは値で保持し、値で返す。
+// 一意制約は conflict で判定し、判定と登録・更新を同じロックの中で行う。
+type table[T any] struct {
+	mu   sync.RWMutex
+	rows map[string]T
+	// 登録順(検索結果の順序を一定にする)
+	keys []string
+}
+
+func newTable[T any]() *table[T] {
+	return &table[T]{rows: map[string]T{}}
+}
+
+// insert はレコードを登録する
+func (t *table[T]) insert(key string, row T, conflict func(existing T) error) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, exists := t.rows[key]; exists {
+		return errDuplicateKey
+	}
+	if err := t.check(key, conflict); err != nil {
+		return err
+	}
+	t.rows[key] = row
+	t.keys = append(t.keys, key)
+	return nil
+}
+
+// update は登録済みのレコードを置き換える
+func (t *table[T]) update(key string, row T, conflict func(existing T) error) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, exists := t.rows[key]; !exists {
+		return repository.ErrNotFound
+	}
+	if err := t.check(key, conflict); err != nil {
+		return err
+	}
+	t.rows[key] = row
+	return nil
+}
+
+// find は条件に一致する最初のレコードを返す
+func (t *table[T]) find(match func(row T) bool) (T, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	for _, key := range t.keys {
+		if row := t.rows[key]; match(row) {
+			return row, true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+// 自身(key)以外のレコードとの一意制約の違反を判定する
+func (t *table[T]) check(key string, conflict func(existing T) error) error {
+	if conflict == nil {
+		return nil
+	}
+	for _, existingKey := range t.keys {
+		if existingKey == key {
+			continue
+		}
+		if err := conflict(t.rows[existingKey]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
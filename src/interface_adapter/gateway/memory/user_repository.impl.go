@@ -0,0 +1,113 @@
+package memory
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/Go_CleanArch/common/crypto"
+	"github.com/Go_CleanArch/interface_adapter/gateway/entity"
+	repository "github.com/Go_CleanArch/usecase/repository_interface"
+	log "github.com/sirupsen/logrus"
+)
+
+// userRepository はユーザーをメモリ上に保持するリポジトリ
+// データベースを用意せずにサービスを動作させるテスト・開発用途に使用し、トランザクションには参加しない。
+type userRepository struct {
+	users *table[entity.User]
+}
+
+// コンストラクタ
+func NewUserRepository() repository.UserRepositoryInterface {
+	return &userRepository{
+		users: newTable[entity.User](),
+	}
+}
+
+// ユーザーレコード作成
+func (ur *userRepository) CreateUser(ctx context.Context, userJson []byte) (*entity.User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	var user entity.User
+	if err := crypto.CopyBeans(userJson, &user); err != nil {
+		log.WithError(err).Error("Failed to copy user data")
+		return nil, err
+	}
+
+	// データベースと同様に、未指定の作成日時・更新日時は現在日時とする
+	now := time.Now()
+	if user.CreatedAt.IsZero() {
+		user.CreatedAt = now
+	}
+	if user.UpdatedAt.IsZero() {
+		user.UpdatedAt = now
+	}
+	if err := ur.users.insert(user.UserId, user, emailConflict(user)); err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// Userの存在チェック(大文字・小文字を区別しない)
+func (ur *userRepository) FindUserByEmail(ctx context.Context, email string) (*entity.User, error) {
+	return ur.findOne(ctx, func(user entity.User) bool {
+		return strings.EqualFold(user.Email, email)
+	})
+}
+
+// ユーザーIDによる検索
+func (ur *userRepository) FindUserById(ctx context.Context, userId string) (*entity.User, error) {
+	return ur.findOne(ctx, func(user entity.User) bool {
+		return user.UserId == userId
+	})
+}
+
+// メールアドレスの変更
+func (ur *userRepository) UpdateUserEmail(ctx context.Context, userId string, email string) error {
+	user, err := ur.FindUserById(ctx, userId)
+	if err != nil {
+		return err
+	}
+
+	user.Email = email
+	user.UpdatedAt = time.Now()
+	return ur.users.update(user.UserId, *user, emailConflict(*user))
+}
+
+// アバター画像の変更
+func (ur *userRepository) UpdateUserAvatarKey(ctx context.Context, userId string, avatarKey string) error {
+	user, err := ur.FindUserById(ctx, userId)
+	if err != nil {
+		return err
+	}
+
+	user.AvatarKey = &avatarKey
+	user.UpdatedAt = time.Now()
+	return ur.users.update(user.UserId, *user, nil)
+}
+
+// 削除(匿名化)済みのユーザーは検索の対象外とする
+func (ur *userRepository) findOne(ctx context.Context, match func(user entity.User) bool) (*entity.User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	user, found := ur.users.find(func(user entity.User) bool {
+		return user.DeletedAt == nil && match(user)
+	})
+	if !found {
+		return nil, repository.ErrNotFound
+	}
+	return &user, nil
+}
+
+// メールアドレスの一意制約(データベースの一意インデックスと同様に、削除済みのユーザーは対象外)
+func emailConflict(user entity.User) func(existing entity.User) error {
+	return func(existing entity.User) error {
+		if user.DeletedAt == nil && existing.DeletedAt == nil && strings.EqualFold(existing.Email, user.Email) {
+			return repository.ErrEmailAlreadyExists
+		}
+		return nil
+	}
+}
@@ -0,0 +1,17 @@
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/Go_CleanArch/interface_adapter/gateway/contract"
+	"github.com/Go_CleanArch/interface_adapter/gateway/memory"
+	repository "github.com/Go_CleanArch/usecase/repository_interface"
+)
+
+func TestUserRepository(t *testing.T) {
+	t.Parallel()
+
+	contract.UserRepository(t, func(t *testing.T) repository.UserRepositoryInterface {
+		return memory.NewUserRepository()
+	})
+}
@@ -0,0 +1,84 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Go_CleanArch/common/crypto"
+	dbConnect "github.com/Go_CleanArch/infrastructure/db"
+	"github.com/Go_CleanArch/interface_adapter/gateway/entity"
+	repository "github.com/Go_CleanArch/usecase/repository_interface"
+	log "github.com/sirupsen/logrus"
+)
+
+type emailChangeRequestRepository struct {
+	requests *dbConnect.Repository[entity.EmailChangeRequest]
+}
+
+// コンストラクタ
+func NewEmailChangeRequestRepository(ctx context.Context) (repository.EmailChangeRequestRepositoryInterface, error) {
+	conn, err := dbConnect.NewDBConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := emailChangeRequestRepository{
+		requests: dbConnect.NewRepository[entity.EmailChangeRequest](conn),
+	}
+
+	return &result, nil
+}
+
+// メールアドレス変更申請レコード作成
+func (er *emailChangeRequestRepository) CreateEmailChangeRequest(ctx context.Context, requestJson []byte) (*entity.EmailChangeRequest, error) {
+	var request entity.EmailChangeRequest
+	if err := crypto.CopyBeans(requestJson, &request); err != nil {
+		log.WithError(err).Error("Failed to copy email change request data")
+		return nil, err
+	}
+
+	if err := er.requests.Create(ctx, &request); err != nil {
+		log.WithError(err).Error("Failed to create email change request in the database")
+		return nil, err
+	}
+
+	log.WithField("emailChangeRequestId", request.EmailChangeRequestId).Info("Email change request created successfully")
+	return &request, nil
+}
+
+// 確認トークンによる検索
+func (er *emailChangeRequestRepository) FindEmailChangeRequestByConfirmTokenHash(ctx context.Context, tokenHash string) (*entity.EmailChangeRequest, error) {
+	return er.findOne(ctx, "confirm_token_hash", tokenHash)
+}
+
+// 取消トークンによる検索
+func (er *emailChangeRequestRepository) FindEmailChangeRequestByCancelTokenHash(ctx context.Context, tokenHash string) (*entity.EmailChangeRequest, error) {
+	return er.findOne(ctx, "cancel_token_hash", tokenHash)
+}
+
+// メールアドレス変更申請レコード更新
+func (er *emailChangeRequestRepository) UpdateEmailChangeRequest(ctx context.Context, request *entity.EmailChangeRequest) error {
+	if err := er.requests.Update(ctx, request); err != nil {
+		log.WithError(err).Error("Failed to update email change request in the database")
+		return err
+	}
+
+	log.WithField("emailChangeRequestId", request.EmailChangeRequestId).Info("Email change request updated successfully")
+	return nil
+}
+
+func (er *emailChangeRequestRepository) findOne(ctx context.Context, column string, tokenHash string) (*entity.EmailChangeRequest, error) {
+	request, err := er.requests.First(ctx, dbConnect.Eq(column, tokenHash))
+	if errors.Is(err, repository.ErrNotFound) {
+		// レコードが見つからなかったエラー
+		log.Info("Email change request not found")
+		return nil, err
+	} else if err != nil {
+		// その他のエラー
+		log.WithError(err).Error("Failed to find email change request in the database")
+		return nil, fmt.Errorf("DB検索に失敗しました: %w", err)
+	}
+
+	log.WithField("emailChangeRequestId", request.EmailChangeRequestId).Info("Email change request found successfully")
+	return request, nil
+}
@@ -0,0 +1,194 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Go_CleanArch/common/crypto"
+	"github.com/Go_CleanArch/common/tenant"
+	"github.com/Go_CleanArch/domain/event"
+	dbConnect "github.com/Go_CleanArch/infrastructure/db"
+	"github.com/Go_CleanArch/interface_adapter/gateway/entity"
+	repository "github.com/Go_CleanArch/usecase/repository_interface"
+	log "github.com/sirupsen/logrus"
+)
+
+type erasureRequestRepository struct {
+	db                 *dbConnect.DBConnection
+	transactionManager *dbConnect.TransactionManager
+	requests           *dbConnect.Repository[entity.ErasureRequest]
+}
+
+// コンストラクタ
+func NewErasureRequestRepository(ctx context.Context) (repository.ErasureRequestRepositoryInterface, error) {
+	conn, err := dbConnect.NewDBConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	transactionManager, err := dbConnect.NewTransactionManager(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := erasureRequestRepository{
+		db:                 conn,
+		transactionManager: transactionManager,
+		requests:           dbConnect.NewRepository[entity.ErasureRequest](conn),
+	}
+
+	return &result, nil
+}
+
+// 個人データ削除申請レコード作成
+func (er *erasureRequestRepository) CreateErasureRequest(ctx context.Context, requestJson []byte) (*entity.ErasureRequest, error) {
+	var request entity.ErasureRequest
+	if err := crypto.CopyBeans(requestJson, &request); err != nil {
+		log.WithError(err).Error("Failed to copy erasure request data")
+		return nil, err
+	}
+
+	if err := er.requests.Create(ctx, &request); err != nil {
+		log.WithError(err).Error("Failed to create erasure request in the database")
+		return nil, err
+	}
+
+	log.WithField("erasureRequestId", request.ErasureRequestId).Info("Erasure request created successfully")
+	return &request, nil
+}
+
+// 確認トークンによる検索
+func (er *erasureRequestRepository) FindErasureRequestByConfirmTokenHash(ctx context.Context, tokenHash string) (*entity.ErasureRequest, error) {
+	request, err := er.requests.First(ctx, dbConnect.Eq("confirm_token_hash", tokenHash))
+	if errors.Is(err, repository.ErrNotFound) {
+		// レコードが見つからなかったエラー
+		log.Info("Erasure request not found")
+		return nil, err
+	} else if err != nil {
+		// その他のエラー
+		log.WithError(err).Error("Failed to find erasure request in the database")
+		return nil, fmt.Errorf("DB検索に失敗しました: %w", err)
+	}
+
+	log.WithField("erasureRequestId", request.ErasureRequestId).Info("Erasure request found successfully")
+	return request, nil
+}
+
+// 個人データの匿名化
+// ユーザーIDは参照整合性を保つため維持し、関連テーブルの個人情報も同一トランザクションで置き換える。
+func (er *erasureRequestRepository) EraseUserData(ctx context.Context, request *entity.ErasureRequest, anonymizedUserJson []byte, auditLogJson []byte) error {
+	var anonymizedUser entity.User
+	if err := crypto.CopyBeans(anonymizedUserJson, &anonymizedUser); err != nil {
+		log.WithError(err).Error("Failed to copy anonymized user data")
+		return err
+	}
+	var auditLog entity.PrivacyAuditLog
+	if err := crypto.CopyBeans(auditLogJson, &auditLog); err != nil {
+		log.WithError(err).Error("Failed to copy privacy audit log data")
+		return err
+	}
+
+	// 列を指定した更新では暗号化されないため、匿名化した値も暗号化してから保存する
+	anonymizedName, err := dbConnect.EncryptValue(anonymizedUser.UserName)
+	if err != nil {
+		log.WithError(err).Error("Failed to encrypt anonymized user data")
+		return err
+	}
+	anonymizedEmail, err := dbConnect.EncryptValue(anonymizedUser.Email)
+	if err != nil {
+		log.WithError(err).Error("Failed to encrypt anonymized user data")
+		return err
+	}
+	anonymizedEmailIndex, err := dbConnect.BlindIndex(anonymizedUser.Email)
+	if err != nil {
+		log.WithError(err).Error("Failed to compute anonymized email blind index")
+		return err
+	}
+
+	users := dbConnect.NewRepository[entity.User](er.db)
+	invitations := dbConnect.NewRepository[entity.OrganizationInvitation](er.db)
+	loginHistories := dbConnect.NewRepository[entity.LoginHistory](er.db)
+	emailChangeRequests := dbConnect.NewRepository[entity.EmailChangeRequest](er.db)
+	settings := dbConnect.NewRepository[entity.UserSetting](er.db)
+	outboxEvents := dbConnect.NewRepository[entity.OutboxEvent](er.db)
+	memberships := dbConnect.NewRepository[entity.OrganizationMembership](er.db)
+	auditLogs := dbConnect.NewRepository[entity.PrivacyAuditLog](er.db)
+	idempotencyKeys := dbConnect.NewRepository[entity.IdempotencyKey](er.db)
+
+	erasedAt := time.Now()
+	// 呼び出し元のトランザクション中の場合は、そのトランザクションに参加する
+	err = er.transactionManager.RunInTransaction(ctx, func(ctx context.Context) error {
+		// 招待は宛先のメールアドレス(ブラインドインデックス)で照合するため、usersの匿名化より先に置き換える
+		// 招待は全組織が対象となるため、テナントによる絞り込みを解除する
+		if _, err := invitations.UpdateColumns(tenant.WithoutScope(ctx), map[string]interface{}{
+			"email":       anonymizedEmail,
+			"email_index": anonymizedEmailIndex,
+			"updated_at":  erasedAt,
+		}, dbConnect.Where("accepted_by = ? OR email_index = (SELECT email_index FROM users WHERE user_id = ?)", request.UserId, request.UserId)); err != nil {
+			return fmt.Errorf("organization_invitationsの匿名化に失敗しました: %w", err)
+		}
+		if _, err := users.UpdateColumns(ctx, map[string]interface{}{
+			"user_name":   anonymizedName,
+			"email":       anonymizedEmail,
+			"email_index": anonymizedEmailIndex,
+			"password":    anonymizedUser.Password,
+			"avatar_key":  nil,
+			"updated_at":  erasedAt,
+			"deleted_at":  erasedAt,
+		}, dbConnect.Eq("user_id", request.UserId)); err != nil {
+			return fmt.Errorf("usersの匿名化に失敗しました: %w", err)
+		}
+		if _, err := loginHistories.UpdateColumns(ctx, map[string]interface{}{
+			"ip_address": "",
+			"user_agent": "",
+		}, dbConnect.Eq("user_id", request.UserId)); err != nil {
+			return fmt.Errorf("login_historiesの匿名化に失敗しました: %w", err)
+		}
+		if _, err := emailChangeRequests.UpdateColumns(ctx, map[string]interface{}{
+			"current_email": anonymizedEmail,
+			"new_email":     anonymizedEmail,
+			"updated_at":    erasedAt,
+		}, dbConnect.Eq("user_id", request.UserId)); err != nil {
+			return fmt.Errorf("email_change_requestsの匿名化に失敗しました: %w", err)
+		}
+		if _, err := settings.Delete(ctx, dbConnect.Eq("user_id", request.UserId)); err != nil {
+			return fmt.Errorf("user_settingsの削除に失敗しました: %w", err)
+		}
+		// ドメインイベントにはメールアドレス・IPアドレスが含まれるため、配信前のイベントも含めて削除する
+		if _, err := outboxEvents.Delete(ctx, dbConnect.Eq("aggregate_type", event.AggregateUser), dbConnect.Eq("aggregate_id", request.UserId)); err != nil {
+			return fmt.Errorf("outboxの削除に失敗しました: %w", err)
+		}
+		// 所属は全組織が対象となるため、テナントによる絞り込みを解除する
+		if _, err := memberships.Delete(tenant.WithoutScope(ctx), dbConnect.Eq("user_id", request.UserId)); err != nil {
+			return fmt.Errorf("organization_membershipsの削除に失敗しました: %w", err)
+		}
+		// 保存したレスポンスにはメールアドレスなどが含まれるため、ユーザーのリクエストのキーを削除する
+		// スコープは「メソッド パス user:<ユーザーID> ...」の形式のため、空白で区切った要素として照合する
+		if _, err := idempotencyKeys.Delete(ctx, dbConnect.Where("' ' || scope || ' ' LIKE ?", "% user:"+request.UserId+" %")); err != nil {
+			return fmt.Errorf("idempotency_keysの削除に失敗しました: %w", err)
+		}
+		// 監査記録は残し、以前の請求のIPアドレスのみ消去する
+		if _, err := auditLogs.UpdateColumns(ctx, map[string]interface{}{
+			"ip_address": "",
+		}, dbConnect.Eq("user_id", request.UserId)); err != nil {
+			return fmt.Errorf("privacy_audit_logsの匿名化に失敗しました: %w", err)
+		}
+		if _, err := er.requests.UpdateColumns(ctx, map[string]interface{}{
+			"confirmed_at": erasedAt,
+			"updated_at":   erasedAt,
+		}, dbConnect.Eq("erasure_request_id", request.ErasureRequestId)); err != nil {
+			return fmt.Errorf("erasure_requestsの更新に失敗しました: %w", err)
+		}
+		if err := auditLogs.Create(ctx, &auditLog); err != nil {
+			return fmt.Errorf("privacy_audit_logsの作成に失敗しました: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		log.WithError(err).Error("Failed to erase user data")
+		return err
+	}
+
+	log.WithField("userId", request.UserId).Info("User data erased successfully")
+	return nil
+}
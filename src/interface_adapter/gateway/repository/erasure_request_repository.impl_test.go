@@ -0,0 +1,85 @@
+package user_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	dbConnect "github.com/Go_CleanArch/infrastructure/db"
+	"github.com/Go_CleanArch/interface_adapter/gateway/entity"
+	gatewayRepository "github.com/Go_CleanArch/interface_adapter/gateway/repository"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEraseUserData(t *testing.T) {
+	t.Parallel()
+
+	t.Run("正常系_ユーザーの冪等キーを削除し、監査記録のIPアドレスを消去する", func(t *testing.T) {
+		t.Parallel()
+		ctx := context.Background()
+		db, err := dbConnect.NewDBConnection(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		userRepository, err := gatewayRepository.NewUserRepository(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		erasureRequestRepository, err := gatewayRepository.NewErasureRequestRepository(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		userId := uuid.NewString()
+		otherUserId := uuid.NewString()
+		for _, id := range []string{userId, otherUserId} {
+			userJson, _ := json.Marshal(entity.User{UserId: id, UserName: "削除", Password: "hashed-password", Email: id[:8] + "@example.com"})
+			if _, err := userRepository.CreateUser(ctx, userJson); err != nil {
+				t.Fatal(err)
+			}
+		}
+		now := time.Now()
+		statements := []struct {
+			query string
+			args  []interface{}
+		}{
+			{"INSERT INTO idempotency_keys (scope, idempotency_key, request_hash, expires_at, created_at) VALUES (?, ?, ?, ?, ?)", []interface{}{"POST /api/users/me/email user:" + userId, "key-1", "hash", now, now}},
+			{"INSERT INTO idempotency_keys (scope, idempotency_key, request_hash, expires_at, created_at) VALUES (?, ?, ?, ?, ?)", []interface{}{"POST /api/organizations user:" + userId + " organization:org-1", "key-2", "hash", now, now}},
+			{"INSERT INTO idempotency_keys (scope, idempotency_key, request_hash, expires_at, created_at) VALUES (?, ?, ?, ?, ?)", []interface{}{"POST /api/users/me/email user:" + otherUserId, "key-1", "hash", now, now}},
+			{"INSERT INTO privacy_audit_logs (privacy_audit_log_id, user_id, action, ip_address, created_at) VALUES (?, ?, ?, ?, ?)", []interface{}{uuid.NewString(), userId, "DATA_EXPORT", "192.0.2.1", now}},
+			{"INSERT INTO privacy_audit_logs (privacy_audit_log_id, user_id, action, ip_address, created_at) VALUES (?, ?, ?, ?, ?)", []interface{}{uuid.NewString(), otherUserId, "DATA_EXPORT", "192.0.2.2", now}},
+			{"INSERT INTO erasure_requests (erasure_request_id, user_id, confirm_token_hash, expires_at, created_at) VALUES (?, ?, ?, ?, ?)", []interface{}{uuid.NewString(), userId, uuid.NewString(), now.Add(time.Hour), now}},
+		}
+		for _, statement := range statements {
+			if _, err := db.Exec(ctx, statement.query, statement.args...); err != nil {
+				t.Fatal(err)
+			}
+		}
+		anonymizedUserJson, _ := json.Marshal(entity.User{UserName: "削除済みユーザー", Email: "erased-" + userId[:8] + "@invalid", Password: "erased"})
+		auditLogJson, _ := json.Marshal(entity.PrivacyAuditLog{PrivacyAuditLogId: uuid.NewString(), UserId: userId, Action: "ERASURE_CONFIRMED", CreatedAt: now})
+
+		err = erasureRequestRepository.EraseUserData(ctx, &entity.ErasureRequest{ErasureRequestId: uuid.NewString(), UserId: userId}, anonymizedUserJson, auditLogJson)
+
+		assert.NoError(t, err)
+		var scopes []struct{ Scope string }
+		assert.NoError(t, db.FindWithRawJoinQuery(ctx, "SELECT scope FROM idempotency_keys WHERE scope LIKE ?", &scopes, "%user:"+userId+"%"))
+		assert.Empty(t, scopes)
+		// 他のユーザーのキーは削除しない
+		assert.NoError(t, db.FindWithRawJoinQuery(ctx, "SELECT scope FROM idempotency_keys WHERE scope LIKE ?", &scopes, "%user:"+otherUserId))
+		assert.Len(t, scopes, 1)
+		var auditLogs []struct {
+			UserId    string
+			IpAddress string
+		}
+		assert.NoError(t, db.FindWithRawJoinQuery(ctx, "SELECT user_id, ip_address FROM privacy_audit_logs WHERE user_id IN (?, ?) AND action = ?", &auditLogs, userId, otherUserId, "DATA_EXPORT"))
+		assert.Len(t, auditLogs, 2)
+		for _, auditLog := range auditLogs {
+			if auditLog.UserId == userId {
+				assert.Empty(t, auditLog.IpAddress)
+			} else {
+				assert.Equal(t, "192.0.2.2", auditLog.IpAddress)
+			}
+		}
+	})
+}
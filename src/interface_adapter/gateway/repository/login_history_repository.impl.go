@@ -0,0 +1,45 @@
+package user
+
+import (
+	"context"
+
+	"github.com/Go_CleanArch/common/crypto"
+	dbConnect "github.com/Go_CleanArch/infrastructure/db"
+	"github.com/Go_CleanArch/interface_adapter/gateway/entity"
+	repository "github.com/Go_CleanArch/usecase/repository_interface"
+	log "github.com/sirupsen/logrus"
+)
+
+type loginHistoryRepository struct {
+	loginHistories *dbConnect.Repository[entity.LoginHistory]
+}
+
+// コンストラクタ
+func NewLoginHistoryRepository(ctx context.Context) (repository.LoginHistoryRepositoryInterface, error) {
+	conn, err := dbConnect.NewDBConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := loginHistoryRepository{
+		loginHistories: dbConnect.NewRepository[entity.LoginHistory](conn),
+	}
+
+	return &result, nil
+}
+
+// ログイン履歴レコード作成
+func (lr *loginHistoryRepository) CreateLoginHistory(ctx context.Context, loginHistoryJson []byte) error {
+	var loginHistory entity.LoginHistory
+	if err := crypto.CopyBeans(loginHistoryJson, &loginHistory); err != nil {
+		log.WithError(err).Error("Failed to copy login history data")
+		return err
+	}
+
+	if err := lr.loginHistories.Create(ctx, &loginHistory); err != nil {
+		log.WithError(err).Error("Failed to create login history in the database")
+		return err
+	}
+
+	log.WithField("userId", loginHistory.UserId).Info("Login history created successfully")
+	return nil
+}
@@ -0,0 +1,114 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Go_CleanArch/common/crypto"
+	"github.com/Go_CleanArch/common/tenant"
+	dbConnect "github.com/Go_CleanArch/infrastructure/db"
+	"github.com/Go_CleanArch/interface_adapter/gateway/entity"
+	repository "github.com/Go_CleanArch/usecase/repository_interface"
+	log "github.com/sirupsen/logrus"
+)
+
+type organizationInvitationRepository struct {
+	transactionManager *dbConnect.TransactionManager
+	invitations        *dbConnect.Repository[entity.OrganizationInvitation]
+	memberships        *dbConnect.Repository[entity.OrganizationMembership]
+}
+
+// コンストラクタ
+func NewOrganizationInvitationRepository(ctx context.Context) (repository.OrganizationInvitationRepositoryInterface, error) {
+	conn, err := dbConnect.NewDBConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	transactionManager, err := dbConnect.NewTransactionManager(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := organizationInvitationRepository{
+		transactionManager: transactionManager,
+		invitations:        dbConnect.NewRepository[entity.OrganizationInvitation](conn),
+		memberships:        dbConnect.NewRepository[entity.OrganizationMembership](conn),
+	}
+
+	return &result, nil
+}
+
+// 招待レコード作成
+func (ir *organizationInvitationRepository) CreateInvitation(ctx context.Context, invitationJson []byte) (*entity.OrganizationInvitation, error) {
+	var invitation entity.OrganizationInvitation
+	if err := crypto.CopyBeans(invitationJson, &invitation); err != nil {
+		log.WithError(err).Error("Failed to copy organization invitation data")
+		return nil, err
+	}
+
+	if err := ir.invitations.Create(ctx, &invitation); err != nil {
+		log.WithError(err).Error("Failed to create organization invitation in the database")
+		return nil, err
+	}
+
+	log.WithField("invitationId", invitation.InvitationId).Info("Organization invitation created successfully")
+	return &invitation, nil
+}
+
+// 招待トークンによる検索
+func (ir *organizationInvitationRepository) FindInvitationByTokenHash(ctx context.Context, tokenHash string) (*entity.OrganizationInvitation, error) {
+	invitation, err := ir.invitations.First(tenant.WithoutScope(ctx), dbConnect.Eq("token_hash", tokenHash))
+	if errors.Is(err, repository.ErrNotFound) {
+		// レコードが見つからなかったエラー
+		log.Info("Organization invitation not found")
+		return nil, err
+	} else if err != nil {
+		// その他のエラー
+		log.WithError(err).Error("Failed to find organization invitation in the database")
+		return nil, fmt.Errorf("DB検索に失敗しました: %w", err)
+	}
+
+	log.WithField("invitationId", invitation.InvitationId).Info("Organization invitation found successfully")
+	return invitation, nil
+}
+
+// 招待の承諾
+// 同じ招待が同時に承諾された場合に備え、未承諾の場合のみ更新する。
+func (ir *organizationInvitationRepository) AcceptInvitation(ctx context.Context, invitation *entity.OrganizationInvitation, membershipJson []byte) error {
+	var membership entity.OrganizationMembership
+	if err := crypto.CopyBeans(membershipJson, &membership); err != nil {
+		log.WithError(err).Error("Failed to copy organization membership data")
+		return err
+	}
+
+	acceptedAt := time.Now()
+	// 呼び出し元のトランザクション中の場合は、そのトランザクションに参加する
+	err := ir.transactionManager.RunInTransaction(ctx, func(ctx context.Context) error {
+		updated, err := ir.invitations.UpdateColumns(ctx, map[string]interface{}{
+			"accepted_at": acceptedAt,
+			"accepted_by": membership.UserId,
+			"updated_at":  acceptedAt,
+		}, dbConnect.Eq("invitation_id", invitation.InvitationId), dbConnect.IsNull("accepted_at"))
+		if err != nil {
+			return fmt.Errorf("organization_invitationsの更新に失敗しました: %w", err)
+		}
+		if updated == 0 {
+			return repository.ErrInvitationAlreadyAccepted
+		}
+		if err := ir.memberships.Create(ctx, &membership); err != nil {
+			return fmt.Errorf("organization_membershipsの作成に失敗しました: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		log.WithError(err).Error("Failed to accept organization invitation")
+		return err
+	}
+
+	log.WithFields(log.Fields{
+		"invitationId": invitation.InvitationId,
+		"userId":       membership.UserId,
+	}).Info("Organization invitation accepted successfully")
+	return nil
+}
@@ -0,0 +1,66 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Go_CleanArch/common/crypto"
+	dbConnect "github.com/Go_CleanArch/infrastructure/db"
+	"github.com/Go_CleanArch/interface_adapter/gateway/entity"
+	repository "github.com/Go_CleanArch/usecase/repository_interface"
+	log "github.com/sirupsen/logrus"
+)
+
+type organizationMembershipRepository struct {
+	memberships *dbConnect.Repository[entity.OrganizationMembership]
+}
+
+// コンストラクタ
+func NewOrganizationMembershipRepository(ctx context.Context) (repository.OrganizationMembershipRepositoryInterface, error) {
+	conn, err := dbConnect.NewDBConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := organizationMembershipRepository{
+		memberships: dbConnect.NewRepository[entity.OrganizationMembership](conn),
+	}
+
+	return &result, nil
+}
+
+// 所属レコード作成
+func (mr *organizationMembershipRepository) CreateMembership(ctx context.Context, membershipJson []byte) (*entity.OrganizationMembership, error) {
+	var membership entity.OrganizationMembership
+	if err := crypto.CopyBeans(membershipJson, &membership); err != nil {
+		log.WithError(err).Error("Failed to copy organization membership data")
+		return nil, err
+	}
+
+	if err := mr.memberships.Create(ctx, &membership); err != nil {
+		log.WithError(err).Error("Failed to create organization membership in the database")
+		return nil, err
+	}
+
+	log.WithFields(log.Fields{
+		"organizationId": membership.OrganizationId,
+		"userId":         membership.UserId,
+	}).Info("Organization membership created successfully")
+	return &membership, nil
+}
+
+// コンテキストのテナントへの所属の検索
+func (mr *organizationMembershipRepository) FindMembership(ctx context.Context, userId string) (*entity.OrganizationMembership, error) {
+	membership, err := mr.memberships.First(ctx, dbConnect.Eq("user_id", userId))
+	if errors.Is(err, repository.ErrNotFound) {
+		// レコードが見つからなかったエラー
+		log.WithField("userId", userId).Info("Organization membership not found")
+		return nil, err
+	} else if err != nil {
+		// その他のエラー
+		log.WithError(err).Error("Failed to find organization membership in the database")
+		return nil, fmt.Errorf("DB検索に失敗しました: %w", err)
+	}
+
+	return membership, nil
+}
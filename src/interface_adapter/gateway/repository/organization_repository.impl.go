@@ -0,0 +1,105 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Go_CleanArch/common/crypto"
+	"github.com/Go_CleanArch/common/tenant"
+	dbConnect "github.com/Go_CleanArch/infrastructure/db"
+	"github.com/Go_CleanArch/interface_adapter/gateway/entity"
+	repository "github.com/Go_CleanArch/usecase/repository_interface"
+	log "github.com/sirupsen/logrus"
+)
+
+type organizationRepository struct {
+	transactionManager *dbConnect.TransactionManager
+	organizations      *dbConnect.Repository[entity.Organization]
+	memberships        *dbConnect.Repository[entity.OrganizationMembership]
+}
+
+// コンストラクタ
+func NewOrganizationRepository(ctx context.Context) (repository.OrganizationRepositoryInterface, error) {
+	conn, err := dbConnect.NewDBConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	transactionManager, err := dbConnect.NewTransactionManager(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := organizationRepository{
+		transactionManager: transactionManager,
+		organizations:      dbConnect.NewRepository[entity.Organization](conn),
+		memberships:        dbConnect.NewRepository[entity.OrganizationMembership](conn),
+	}
+
+	return &result, nil
+}
+
+// 組織レコード作成
+func (or *organizationRepository) CreateOrganization(ctx context.Context, organizationJson []byte, ownerMembershipJson []byte) (*entity.Organization, error) {
+	var organization entity.Organization
+	if err := crypto.CopyBeans(organizationJson, &organization); err != nil {
+		log.WithError(err).Error("Failed to copy organization data")
+		return nil, err
+	}
+	var owner entity.OrganizationMembership
+	if err := crypto.CopyBeans(ownerMembershipJson, &owner); err != nil {
+		log.WithError(err).Error("Failed to copy organization membership data")
+		return nil, err
+	}
+
+	// 呼び出し元のトランザクション中の場合は、そのトランザクションに参加する
+	err := or.transactionManager.RunInTransaction(ctx, func(ctx context.Context) error {
+		if err := or.organizations.Create(ctx, &organization); err != nil {
+			return fmt.Errorf("organizationsの作成に失敗しました: %w", err)
+		}
+		if err := or.memberships.Create(ctx, &owner); err != nil {
+			return fmt.Errorf("organization_membershipsの作成に失敗しました: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		log.WithError(err).Error("Failed to create organization in the database")
+		return nil, err
+	}
+
+	log.WithField("organizationId", organization.OrganizationId).Info("Organization created successfully")
+	return &organization, nil
+}
+
+// スラッグによる検索
+func (or *organizationRepository) FindOrganizationBySlug(ctx context.Context, slug string) (*entity.Organization, error) {
+	organization, err := or.organizations.First(tenant.WithoutScope(ctx), dbConnect.Eq("slug", slug))
+	if errors.Is(err, repository.ErrNotFound) {
+		// レコードが見つからなかったエラー
+		log.WithField("slug", slug).Info("Organization not found")
+		return nil, err
+	} else if err != nil {
+		// その他のエラー
+		log.WithError(err).Error("Failed to find organization in the database")
+		return nil, fmt.Errorf("DB検索に失敗しました: %w", err)
+	}
+
+	log.WithField("organizationId", organization.OrganizationId).Info("Organization found successfully")
+	return organization, nil
+}
+
+// 組織IDによる検索
+func (or *organizationRepository) FindOrganizationById(ctx context.Context, organizationId string) (*entity.Organization, error) {
+	organization, err := or.organizations.First(ctx, dbConnect.Eq("organization_id", organizationId))
+	if errors.Is(err, repository.ErrNotFound) {
+		// レコードが見つからなかったエラー
+		log.WithField("organizationId", organizationId).Info("Organization not found")
+		return nil, err
+	} else if err != nil {
+		// その他のエラー
+		log.WithError(err).Error("Failed to find organization in the database")
+		return nil, fmt.Errorf("DB検索に失敗しました: %w", err)
+	}
+
+	log.WithField("organizationId", organization.OrganizationId).Info("Organization found successfully")
+	return organization, nil
+}
@@ -0,0 +1,84 @@
+package user_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/Go_CleanArch/common/tenant"
+	dbConnect "github.com/Go_CleanArch/infrastructure/db"
+	"github.com/Go_CleanArch/interface_adapter/gateway/entity"
+	gatewayRepository "github.com/Go_CleanArch/interface_adapter/gateway/repository"
+	repository "github.com/Go_CleanArch/usecase/repository_interface"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrganizationRepositoryTransaction(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	organizationRepository, err := gatewayRepository.NewOrganizationRepository(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	transactionManager, err := dbConnect.NewTransactionManager(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	userRepository, err := gatewayRepository.NewUserRepository(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 所有者として所属させるユーザー
+	ownerId := uuid.NewString()
+	ownerJson, _ := json.Marshal(entity.User{UserId: ownerId, UserName: "所有者", Password: "hashed-password", Email: "owner-" + ownerId[:8] + "@example.com"})
+	if _, err := userRepository.CreateUser(ctx, ownerJson); err != nil {
+		t.Fatal(err)
+	}
+	errFailed := errors.New("処理に失敗しました")
+
+	createOrganization := func(ctx context.Context, slug string) error {
+		organizationId := uuid.NewString()
+		organizationJson, _ := json.Marshal(entity.Organization{OrganizationId: organizationId, Name: "組織", Slug: slug})
+		membershipJson, _ := json.Marshal(entity.OrganizationMembership{OrganizationId: organizationId, UserId: ownerId, Role: "owner"})
+		_, err := organizationRepository.CreateOrganization(tenant.WithTenant(ctx, tenant.Tenant{OrganizationId: organizationId}), organizationJson, membershipJson)
+		return err
+	}
+
+	t.Run("正常系_呼び出し元のトランザクションに参加し、コミット後の処理はコミットまで待つ", func(t *testing.T) {
+		t.Parallel()
+		slug := "committed-" + uuid.NewString()[:8]
+		committed := false
+
+		err := transactionManager.RunInTransaction(ctx, func(ctx context.Context) error {
+			if err := createOrganization(ctx, slug); err != nil {
+				return err
+			}
+			dbConnect.AfterCommit(ctx, func() { committed = true })
+			assert.False(t, committed)
+			return nil
+		})
+
+		assert.NoError(t, err)
+		assert.True(t, committed)
+		_, err = organizationRepository.FindOrganizationBySlug(ctx, slug)
+		assert.NoError(t, err)
+	})
+
+	t.Run("異常系_呼び出し元のトランザクションがロールバックされた場合は作成しない", func(t *testing.T) {
+		t.Parallel()
+		slug := "rolled-back-" + uuid.NewString()[:8]
+
+		err := transactionManager.RunInTransaction(ctx, func(ctx context.Context) error {
+			if err := createOrganization(ctx, slug); err != nil {
+				return err
+			}
+			return errFailed
+		})
+
+		assert.ErrorIs(t, err, errFailed)
+		_, err = organizationRepository.FindOrganizationBySlug(ctx, slug)
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+	})
+}
@@ -0,0 +1,75 @@
+package user
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Go_CleanArch/common/crypto"
+	"github.com/Go_CleanArch/domain/event"
+	dbConnect "github.com/Go_CleanArch/infrastructure/db"
+	"github.com/Go_CleanArch/interface_adapter/gateway/entity"
+	repository "github.com/Go_CleanArch/usecase/repository_interface"
+	log "github.com/sirupsen/logrus"
+)
+
+type outboxRepository struct {
+	outboxEvents *dbConnect.Repository[entity.OutboxEvent]
+}
+
+// コンストラクタ
+func NewOutboxRepository(ctx context.Context) (repository.OutboxRepositoryInterface, error) {
+	conn, err := dbConnect.NewDBConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := outboxRepository{
+		outboxEvents: dbConnect.NewRepository[entity.OutboxEvent](conn),
+	}
+
+	return &result, nil
+}
+
+// イベントを配信待ちのレコードとして作成
+// 日時はディスパッチャーでの比較のため UTC で保存する。
+func (obr *outboxRepository) AddEvents(ctx context.Context, events ...event.Event) error {
+	now := time.Now().UTC()
+	outboxEvents := make([]entity.OutboxEvent, 0, len(events))
+	for _, e := range events {
+		payload, err := json.Marshal(e)
+		if err != nil {
+			log.WithError(err).Error("Failed to encode domain event")
+			return fmt.Errorf("イベントをエンコードできませんでした(%s): %w", e.EventName(), err)
+		}
+		eventId, err := crypto.GenerateId()
+		if err != nil {
+			log.WithError(err).Error("Failed to generate event id")
+			return err
+		}
+		outboxEvents = append(outboxEvents, entity.OutboxEvent{
+			EventId:       eventId,
+			EventName:     e.EventName(),
+			AggregateType: e.AggregateType(),
+			AggregateId:   e.AggregateId(),
+			Payload:       string(payload),
+			OccurredAt:    e.OccurredAt().UTC(),
+			NextAttemptAt: now,
+			CreatedAt:     now,
+		})
+	}
+
+	if err := obr.outboxEvents.CreateInBatches(ctx, outboxEvents, 100); err != nil {
+		log.WithError(err).Error("Failed to create outbox events in the database")
+		return err
+	}
+
+	for _, outboxEvent := range outboxEvents {
+		log.WithFields(log.Fields{
+			"eventId":     outboxEvent.EventId,
+			"eventName":   outboxEvent.EventName,
+			"aggregateId": outboxEvent.AggregateId,
+		}).Info("Domain event added to outbox")
+	}
+	return nil
+}
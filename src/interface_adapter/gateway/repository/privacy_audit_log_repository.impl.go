@@ -0,0 +1,48 @@
+package user
+
+import (
+	"context"
+
+	"github.com/Go_CleanArch/common/crypto"
+	dbConnect "github.com/Go_CleanArch/infrastructure/db"
+	"github.com/Go_CleanArch/interface_adapter/gateway/entity"
+	repository "github.com/Go_CleanArch/usecase/repository_interface"
+	log "github.com/sirupsen/logrus"
+)
+
+type privacyAuditLogRepository struct {
+	auditLogs *dbConnect.Repository[entity.PrivacyAuditLog]
+}
+
+// コンストラクタ
+func NewPrivacyAuditLogRepository(ctx context.Context) (repository.PrivacyAuditLogRepositoryInterface, error) {
+	conn, err := dbConnect.NewDBConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := privacyAuditLogRepository{
+		auditLogs: dbConnect.NewRepository[entity.PrivacyAuditLog](conn),
+	}
+
+	return &result, nil
+}
+
+// 監査記録レコード作成
+func (pr *privacyAuditLogRepository) CreatePrivacyAuditLog(ctx context.Context, auditLogJson []byte) error {
+	var auditLog entity.PrivacyAuditLog
+	if err := crypto.CopyBeans(auditLogJson, &auditLog); err != nil {
+		log.WithError(err).Error("Failed to copy privacy audit log data")
+		return err
+	}
+
+	if err := pr.auditLogs.Create(ctx, &auditLog); err != nil {
+		log.WithError(err).Error("Failed to create privacy audit log in the database")
+		return err
+	}
+
+	log.WithFields(log.Fields{
+		"userId": auditLog.UserId,
+		"action": auditLog.Action,
+	}).Info("Privacy audit log created successfully")
+	return nil
+}
@@ -2,6 +2,7 @@ package user
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/Go_CleanArch/common/crypto"
@@ -9,21 +10,23 @@ import (
 	"github.com/Go_CleanArch/interface_adapter/gateway/entity"
 	repository "github.com/Go_CleanArch/usecase/repository_interface"
 	log "github.com/sirupsen/logrus"
-	"gorm.io/gorm"
 )
 
+// メールアドレスの一意制約(ブラインドインデックスに対する一意インデックス)
+const usersEmailUniqueIndex = "users_email_index_key"
+
 type userRepository struct {
-	db  *dbConnect.DBConnection
+	users *dbConnect.Repository[entity.User]
 }
 
 // コンストラクタ
 func NewUserRepository(ctx context.Context) (repository.UserRepositoryInterface, error) {
-	dbConnect, err := dbConnect.NewDBConnection(ctx)
+	conn, err := dbConnect.NewDBConnection(ctx)
 	if err != nil {
 		return nil, err
 	}
 	result := userRepository{
-		db: dbConnect,
+		users: dbConnect.NewRepository[entity.User](conn),
 	}
 
 	return &result, nil
@@ -37,8 +40,12 @@ func (ur *userRepository) CreateUser(ctx context.Context, userJson []byte) (*ent
 		return nil, err
 	}
 
-	// 新しいCreateメソッドを使用してデータベースにユーザーを作成
-	if err := ur.db.Create(ctx, &user); err != nil {
+	if err := ur.users.Create(ctx, &user); err != nil {
+		if isEmailUniqueViolation(err) {
+			// 同時に同じアドレスで登録された場合
+			log.WithError(err).Info("User email already exists")
+			return nil, repository.ErrEmailAlreadyExists
+		}
 		log.WithError(err).Error("Failed to create user in the database")
 		return nil, err
 	}
@@ -49,14 +56,17 @@ func (ur *userRepository) CreateUser(ctx context.Context, userJson []byte) (*ent
 
 // Userの存在チェック
 func (ur *userRepository) FindUserByEmail(ctx context.Context, email string) (*entity.User, error) {
-	var user entity.User
-
-	// dbConnectのFind関数を使用
-	err := ur.db.Find(ctx, "email = ?", []interface{}{email}, &user)
-	if err == gorm.ErrRecordNotFound {
+	// メールアドレスは暗号化して保存するため、ブラインドインデックスで検索する(大文字・小文字は区別しない)
+	emailIndex, err := dbConnect.BlindIndex(email)
+	if err != nil {
+		log.WithError(err).Error("Failed to compute email blind index")
+		return nil, err
+	}
+	user, err := ur.users.First(ctx, dbConnect.Eq("email_index", emailIndex), dbConnect.IsNull("deleted_at"))
+	if errors.Is(err, repository.ErrNotFound) {
 		// レコードが見つからなかったエラー
 		log.WithField("email", email).Info("User not found")
-		return nil, fmt.Errorf("条件に一致するレコードが見つかりません: %w", err)
+		return nil, err
 	} else if err != nil {
 		// その他のエラー
 		log.WithError(err).Error("Failed to find user in the database")
@@ -64,5 +74,64 @@ func (ur *userRepository) FindUserByEmail(ctx context.Context, email string) (*e
 	}
 
 	log.WithField("userId", user.UserId).Info("User found successfully")
-	return &user, nil
+	return user, nil
+}
+
+// ユーザーIDによる検索
+func (ur *userRepository) FindUserById(ctx context.Context, userId string) (*entity.User, error) {
+	user, err := ur.users.First(ctx, dbConnect.Eq("user_id", userId), dbConnect.IsNull("deleted_at"))
+	if errors.Is(err, repository.ErrNotFound) {
+		// レコードが見つからなかったエラー
+		log.WithField("userId", userId).Info("User not found")
+		return nil, err
+	} else if err != nil {
+		// その他のエラー
+		log.WithError(err).Error("Failed to find user in the database")
+		return nil, fmt.Errorf("DB検索に失敗しました: %w", err)
+	}
+
+	log.WithField("userId", user.UserId).Info("User found successfully")
+	return user, nil
+}
+
+// メールアドレスの変更
+func (ur *userRepository) UpdateUserEmail(ctx context.Context, userId string, email string) error {
+	user, err := ur.FindUserById(ctx, userId)
+	if err != nil {
+		return err
+	}
+
+	user.Email = email
+	if err := ur.users.Update(ctx, user); err != nil {
+		if isEmailUniqueViolation(err) {
+			log.WithError(err).Info("User email already exists")
+			return repository.ErrEmailAlreadyExists
+		}
+		log.WithError(err).Error("Failed to update user email in the database")
+		return err
+	}
+
+	log.WithField("userId", userId).Info("User email updated successfully")
+	return nil
+}
+
+// アバター画像の変更
+func (ur *userRepository) UpdateUserAvatarKey(ctx context.Context, userId string, avatarKey string) error {
+	user, err := ur.FindUserById(ctx, userId)
+	if err != nil {
+		return err
+	}
+
+	user.AvatarKey = &avatarKey
+	if err := ur.users.Update(ctx, user); err != nil {
+		log.WithError(err).Error("Failed to update user avatar in the database")
+		return err
+	}
+
+	log.WithField("userId", userId).Info("User avatar updated successfully")
+	return nil
+}
+
+func isEmailUniqueViolation(err error) bool {
+	return dbConnect.IsUniqueViolationOnColumns(err, usersEmailUniqueIndex, "users", "email_index")
 }
@@ -0,0 +1,106 @@
+package user_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Go_CleanArch/infrastructure/config"
+	dbConnect "github.com/Go_CleanArch/infrastructure/db"
+	"github.com/Go_CleanArch/interface_adapter/gateway/contract"
+	"github.com/Go_CleanArch/interface_adapter/gateway/entity"
+	gatewayRepository "github.com/Go_CleanArch/interface_adapter/gateway/repository"
+	repository "github.com/Go_CleanArch/usecase/repository_interface"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// メモリ上の SQLite を共有の接続として使用する
+func TestMain(m *testing.M) {
+	dbConnect.Init(config.DatabaseConfig{
+		Driver:     dbConnect.DriverSqlite,
+		SqlitePath: ":memory:",
+		HealthCheck: config.HealthCheckConfig{
+			Interval:         time.Minute,
+			Timeout:          time.Second,
+			FailureThreshold: 3,
+		},
+		Encryption: config.EncryptionConfig{
+			Keys:          []string{"test-1:MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="},
+			ActiveKeyId:   "test-1",
+			BlindIndexKey: "ZmVkY2JhOTg3NjU0MzIxMGZlZGNiYTk4NzY1NDMyMTA=",
+		},
+	})
+	os.Exit(m.Run())
+}
+
+func TestUserRepository(t *testing.T) {
+	t.Parallel()
+
+	contract.UserRepository(t, func(t *testing.T) repository.UserRepositoryInterface {
+		userRepository, err := gatewayRepository.NewUserRepository(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return userRepository
+	})
+}
+
+func TestUserRepositoryEncryption(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	userRepository, err := gatewayRepository.NewUserRepository(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("正常系_氏名・メールアドレスを暗号化して保存する", func(t *testing.T) {
+		t.Parallel()
+		userId := uuid.NewString()
+		email := "Encrypted-" + userId[:8] + "@example.com"
+		userJson, _ := json.Marshal(entity.User{UserId: userId, UserName: "山田 太郎", Password: "hashed-password", Email: email})
+		_, err := userRepository.CreateUser(ctx, userJson)
+		assert.NoError(t, err)
+
+		var stored []struct {
+			UserName   string
+			Email      string
+			EmailIndex string
+		}
+		conn, err := dbConnect.NewDBConnection(ctx)
+		assert.NoError(t, err)
+		assert.NoError(t, conn.FindWithRawJoinQuery(ctx, "SELECT user_name, email, email_index FROM users WHERE user_id = ?", &stored, userId))
+		assert.Len(t, stored, 1)
+		assert.True(t, strings.HasPrefix(stored[0].UserName, "enc:v1:test-1:"))
+		assert.True(t, strings.HasPrefix(stored[0].Email, "enc:v1:test-1:"))
+		assert.NotContains(t, stored[0].Email, userId[:8])
+		assert.Len(t, stored[0].EmailIndex, 64)
+
+		// 大文字・小文字を区別せずにブラインドインデックスで検索し、復号した値を返す
+		found, err := userRepository.FindUserByEmail(ctx, strings.ToLower(email))
+		assert.NoError(t, err)
+		assert.Equal(t, userId, found.UserId)
+		assert.Equal(t, "山田 太郎", found.UserName)
+		assert.Equal(t, email, found.Email)
+	})
+
+	t.Run("正常系_メールアドレスを変更するとブラインドインデックスも更新する", func(t *testing.T) {
+		t.Parallel()
+		userId := uuid.NewString()
+		userJson, _ := json.Marshal(entity.User{UserId: userId, UserName: "before", Password: "hashed-password", Email: "before-" + userId[:8] + "@example.com"})
+		_, err := userRepository.CreateUser(ctx, userJson)
+		assert.NoError(t, err)
+
+		newEmail := "after-" + userId[:8] + "@example.com"
+		assert.NoError(t, userRepository.UpdateUserEmail(ctx, userId, newEmail))
+
+		_, err = userRepository.FindUserByEmail(ctx, "before-"+userId[:8]+"@example.com")
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+		found, err := userRepository.FindUserByEmail(ctx, newEmail)
+		assert.NoError(t, err)
+		assert.Equal(t, userId, found.UserId)
+	})
+}
@@ -0,0 +1,73 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Go_CleanArch/common/crypto"
+	dbConnect "github.com/Go_CleanArch/infrastructure/db"
+	"github.com/Go_CleanArch/interface_adapter/gateway/entity"
+	repository "github.com/Go_CleanArch/usecase/repository_interface"
+	log "github.com/sirupsen/logrus"
+)
+
+type userSettingRepository struct {
+	settings *dbConnect.Repository[entity.UserSetting]
+}
+
+// コンストラクタ
+func NewUserSettingRepository(ctx context.Context) (repository.UserSettingRepositoryInterface, error) {
+	conn, err := dbConnect.NewDBConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := userSettingRepository{
+		settings: dbConnect.NewRepository[entity.UserSetting](conn),
+	}
+
+	return &result, nil
+}
+
+// ユーザーの設定値の取得(未設定の項目はレコードが存在しない)
+func (sr *userSettingRepository) FindUserSettings(ctx context.Context, userId string) ([]entity.UserSetting, error) {
+	settings, err := sr.settings.Find(ctx, dbConnect.Eq("user_id", userId))
+	if err != nil {
+		log.WithError(err).Error("Failed to find user settings in the database")
+		return nil, fmt.Errorf("DB検索に失敗しました: %w", err)
+	}
+
+	return settings, nil
+}
+
+// 設定値の登録・更新
+func (sr *userSettingRepository) SaveUserSettings(ctx context.Context, userId string, settingsJson []byte) error {
+	var values map[string]string
+	if err := crypto.CopyBeans(settingsJson, &values); err != nil {
+		log.WithError(err).Error("Failed to copy user settings data")
+		return err
+	}
+
+	updatedAt := time.Now()
+	settings := make([]entity.UserSetting, 0, len(values))
+	for key, value := range values {
+		settings = append(settings, entity.UserSetting{
+			UserId:       userId,
+			SettingKey:   key,
+			SettingValue: value,
+			UpdatedAt:    updatedAt,
+		})
+	}
+	if len(settings) == 0 {
+		return nil
+	}
+
+	err := sr.settings.Upsert(ctx, settings, []string{"user_id", "setting_key"}, "setting_value", "updated_at")
+	if err != nil {
+		log.WithError(err).Error("Failed to save user settings in the database")
+		return err
+	}
+
+	log.WithField("userId", userId).Info("User settings saved successfully")
+	return nil
+}
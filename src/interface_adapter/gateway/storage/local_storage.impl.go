@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	repository "github.com/Go_CleanArch/usecase/repository_interface"
+	log "github.com/sirupsen/logrus"
+)
+
+// ローカルファイルシステムへの保存(ローカル開発・単一サーバー構成用)
+type localStorage struct {
+	baseDir       string
+	publicBaseUrl string
+}
+
+func NewLocalStorage(baseDir string, publicBaseUrl string) (repository.ObjectStorageInterface, error) {
+	if err := os.MkdirAll(baseDir, 0o755); err != nil {
+		return nil, fmt.Errorf("保存先ディレクトリを作成できませんでした: %w", err)
+	}
+	return &localStorage{
+		baseDir:       baseDir,
+		publicBaseUrl: strings.TrimSuffix(publicBaseUrl, "/"),
+	}, nil
+}
+
+func (ls *localStorage) PutObject(ctx context.Context, key string, contentType string, body []byte) error {
+	filePath, err := ls.filePath(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
+		log.WithError(err).Error("Failed to create object directory")
+		return err
+	}
+
+	// 書き込み途中のファイルが参照されないよう、一時ファイルに書き込んでから置き換える
+	tmp, err := os.CreateTemp(filepath.Dir(filePath), ".upload-*")
+	if err != nil {
+		log.WithError(err).Error("Failed to create temporary file")
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(body); err != nil {
+		tmp.Close()
+		log.WithError(err).Error("Failed to write object")
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), filePath); err != nil {
+		log.WithError(err).Error("Failed to store object")
+		return err
+	}
+
+	log.WithField("key", key).Info("Object stored successfully")
+	return nil
+}
+
+func (ls *localStorage) DeleteObject(ctx context.Context, key string) error {
+	filePath, err := ls.filePath(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(filePath); err != nil && !os.IsNotExist(err) {
+		log.WithError(err).Error("Failed to delete object")
+		return err
+	}
+
+	log.WithField("key", key).Info("Object deleted successfully")
+	return nil
+}
+
+func (ls *localStorage) ObjectUrl(key string) string {
+	return ls.publicBaseUrl + "/" + key
+}
+
+// キーから保存先のパスを求める(保存先ディレクトリ外への書き込みは許可しない)
+func (ls *localStorage) filePath(key string) (string, error) {
+	cleaned := path.Clean("/" + key)
+	if cleaned == "/" || cleaned != "/"+key {
+		return "", fmt.Errorf("不正なオブジェクトキーです: %s", key)
+	}
+	return filepath.Join(ls.baseDir, filepath.FromSlash(cleaned)), nil
+}
@@ -0,0 +1,39 @@
+package storage
+
+import (
+	"fmt"
+
+	repository "github.com/Go_CleanArch/usecase/repository_interface"
+)
+
+// ローカルファイルシステムに保存する場合の既定の保存先
+const DefaultLocalDir = "./storage"
+
+// StorageConfig は保存先の設定を表す。
+type StorageConfig struct {
+	// 保存先(local / s3)
+	Driver string
+	// ローカルファイルシステムに保存する場合の保存先
+	LocalDir      string
+	PublicBaseUrl string
+	S3            S3Config
+}
+
+// コンストラクタ
+// 設定の保存先により切り替える(local / s3)。未指定の場合はローカルファイルシステムに保存する。
+func NewObjectStorage(config StorageConfig) (repository.ObjectStorageInterface, error) {
+	switch driver := config.Driver; driver {
+	case "", "local":
+		dir := config.LocalDir
+		if dir == "" {
+			dir = DefaultLocalDir
+		}
+		return NewLocalStorage(dir, config.PublicBaseUrl)
+	case "s3":
+		s3Config := config.S3
+		s3Config.PublicBaseUrl = config.PublicBaseUrl
+		return NewS3Storage(s3Config)
+	default:
+		return nil, fmt.Errorf("未対応のストレージ方式です: %s", driver)
+	}
+}
@@ -0,0 +1,213 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	repository "github.com/Go_CleanArch/usecase/repository_interface"
+	log "github.com/sirupsen/logrus"
+)
+
+type S3Config struct {
+	// S3互換ストレージのエンドポイント(例: https://s3.ap-northeast-1.amazonaws.com, http://minio:9000)
+	Endpoint        string
+	Region          string
+	Bucket          string
+	AccessKeyId     string
+	SecretAccessKey string
+	// 公開URLのベース(未指定の場合は エンドポイント/バケット)
+	PublicBaseUrl string
+}
+
+// S3互換ストレージへの保存
+// パス形式(エンドポイント/バケット/キー)でアクセスし、リクエストは AWS Signature Version 4 で署名する。
+type s3Storage struct {
+	config     S3Config
+	endpoint   *url.URL
+	httpClient *http.Client
+	now        func() time.Time
+}
+
+func NewS3Storage(config S3Config) (repository.ObjectStorageInterface, error) {
+	if config.Endpoint == "" || config.Region == "" || config.Bucket == "" || config.AccessKeyId == "" || config.SecretAccessKey == "" {
+		return nil, fmt.Errorf("S3の接続情報(S3_ENDPOINT / S3_REGION / S3_BUCKET / S3_ACCESS_KEY_ID / S3_SECRET_ACCESS_KEY)が設定されていません")
+	}
+	endpoint, err := url.Parse(strings.TrimSuffix(config.Endpoint, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("S3_ENDPOINT が不正です: %w", err)
+	}
+	if config.PublicBaseUrl == "" {
+		config.PublicBaseUrl = endpoint.String() + "/" + config.Bucket
+	}
+	config.PublicBaseUrl = strings.TrimSuffix(config.PublicBaseUrl, "/")
+
+	return &s3Storage{
+		config:     config,
+		endpoint:   endpoint,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		now:        time.Now,
+	}, nil
+}
+
+func (ss *s3Storage) PutObject(ctx context.Context, key string, contentType string, body []byte) error {
+	req, err := ss.newRequest(ctx, http.MethodPut, key, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	if err := ss.do(req, body); err != nil {
+		log.WithError(err).WithField("key", key).Error("Failed to put object")
+		return err
+	}
+
+	log.WithField("key", key).Info("Object stored successfully")
+	return nil
+}
+
+func (ss *s3Storage) DeleteObject(ctx context.Context, key string) error {
+	req, err := ss.newRequest(ctx, http.MethodDelete, key, nil)
+	if err != nil {
+		return err
+	}
+	if err := ss.do(req, nil); err != nil {
+		log.WithError(err).WithField("key", key).Error("Failed to delete object")
+		return err
+	}
+
+	log.WithField("key", key).Info("Object deleted successfully")
+	return nil
+}
+
+func (ss *s3Storage) ObjectUrl(key string) string {
+	return ss.config.PublicBaseUrl + "/" + key
+}
+
+func (ss *s3Storage) newRequest(ctx context.Context, method string, key string, body []byte) (*http.Request, error) {
+	objectUrl := *ss.endpoint
+	objectUrl.Path = ss.endpoint.Path + "/" + ss.config.Bucket + "/" + key
+	req, err := http.NewRequestWithContext(ctx, method, objectUrl.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = int64(len(body))
+	return req, nil
+}
+
+func (ss *s3Storage) do(req *http.Request, body []byte) error {
+	signRequest(req, body, ss.config.Region, ss.config.AccessKeyId, ss.config.SecretAccessKey, ss.now())
+
+	resp, err := ss.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("S3へのリクエストに失敗しました: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// 削除対象が存在しない場合はエラーとしない
+	if resp.StatusCode/100 == 2 || (req.Method == http.MethodDelete && resp.StatusCode == http.StatusNotFound) {
+		return nil
+	}
+	detail, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("S3がエラーを返しました(status: %d): %s", resp.StatusCode, detail)
+}
+
+// signRequest は AWS Signature Version 4 でリクエストに署名する。
+// host と、リクエストに設定済みのヘッダーをすべて署名対象とする。
+func signRequest(req *http.Request, body []byte, region string, accessKeyId string, secretAccessKey string, now time.Time) {
+	const service = "s3"
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		canonicalUri(req.URL.Path),
+		req.URL.Query().Encode(),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := strings.Join([]string{date, region, service, "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	signingKey := hmacSha256([]byte("AWS4"+secretAccessKey), date)
+	signingKey = hmacSha256(signingKey, region)
+	signingKey = hmacSha256(signingKey, service)
+	signingKey = hmacSha256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSha256(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKeyId, scope, signedHeaders, signature,
+	))
+}
+
+// パスの各セグメントをRFC 3986の非予約文字以外すべてエンコードする
+func canonicalUri(path string) string {
+	if path == "" {
+		return "/"
+	}
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = uriEncode(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+func uriEncode(value string) string {
+	var builder strings.Builder
+	for _, b := range []byte(value) {
+		if ('A' <= b && b <= 'Z') || ('a' <= b && b <= 'z') || ('0' <= b && b <= '9') || b == '-' || b == '_' || b == '.' || b == '~' {
+			builder.WriteByte(b)
+		} else {
+			fmt.Fprintf(&builder, "%%%02X", b)
+		}
+	}
+	return builder.String()
+}
+
+func sha256Hex(data []byte) string {
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:])
+}
+
+func hmacSha256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
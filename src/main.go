@@ -1,17 +1,92 @@
 package main
 
 import (
+	"context"
 	"fmt"
-	"net/http"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
 
+	"github.com/Go_CleanArch/infrastructure/config"
 	"github.com/Go_CleanArch/infrastructure/db"
+	"github.com/Go_CleanArch/infrastructure/db/migration"
+	"github.com/Go_CleanArch/infrastructure/db/reencrypt"
+	"github.com/Go_CleanArch/infrastructure/seed"
 	"github.com/Go_CleanArch/infrastructure/server"
 )
 
 func main() {
+	ctx := context.Background()
+
+	// go run main.go migrate <up|down|status|create> でマイグレーションを実行する
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := migration.RunCommand(ctx, os.Args[2:], os.Stdout, config.Load); err != nil {
+			log.Fatalf("マイグレーションエラー: %v", err)
+		}
+		return
+	}
+
+	// go run main.go seed [-profile minimal|demo|load-test] でテスト用のユーザーを投入する
+	if len(os.Args) > 1 && os.Args[1] == "seed" {
+		if err := seed.RunCommand(ctx, os.Args[2:], os.Stdout, config.Load); err != nil {
+			log.Fatalf("データ投入エラー: %v", err)
+		}
+		return
+	}
+
+	// go run main.go reencrypt [-batch-size 件数] [-decrypt] で個人情報の列を暗号化し直す
+	if len(os.Args) > 1 && os.Args[1] == "reencrypt" {
+		if err := reencrypt.RunCommand(ctx, os.Args[2:], os.Stdout, config.Load); err != nil {
+			log.Fatalf("再暗号化エラー: %v", err)
+		}
+		return
+	}
 
 	fmt.Println("Starting Server...")
-	db.Init()
-	server.Init()
-	http.ListenAndServe(":8080", nil)
+	// 設定に誤りがある場合は起動しない
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("設定の読み込みエラー: %v", err)
+	}
+	if cfg.Database.AutoMigrate {
+		applied, err := migration.AutoMigrate(ctx, cfg.Database)
+		if err != nil {
+			log.Fatalf("マイグレーションエラー: %v", err)
+		}
+		fmt.Printf("マイグレーションを %d 件適用しました\n", len(applied))
+	}
+	db.Init(cfg.Database)
+	if cfg.Database.AutoMigrate {
+		// 起動時にマイグレーションを適用する場合は、暗号化する前に保存したレコードも暗号化する
+		if err := reencrypt.Backfill(ctx, os.Stdout); err != nil {
+			db.Close()
+			log.Fatalf("再暗号化エラー: %v", err)
+		}
+	}
+	// ブラインドインデックスが設定されていないユーザーはログインできないため、設定されるまで起動しない
+	if err := reencrypt.CheckBlindIndexes(ctx); err != nil {
+		db.Close()
+		log.Fatalf("起動前の確認エラー: %v", err)
+	}
+	srv, err := server.New(cfg)
+	if err != nil {
+		db.Close()
+		log.Fatalf("サーバーの初期化エラー: %v", err)
+	}
+
+	// SIGTERM・SIGINT を受けたら、処理中のリクエストの完了を待ってから終了する
+	ctx, stop := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		// 2回目のシグナルでは完了を待たずに終了する
+		stop()
+	}()
+	err = srv.Run(ctx)
+	// リクエストの処理・バックグラウンド処理が停止してから接続を閉じる
+	db.Close()
+	if err != nil {
+		log.Fatalf("サーバーの実行エラー: %v", err)
+	}
+	fmt.Println("Server stopped")
 }
@@ -0,0 +1,55 @@
+package audit
+
+import (
+	"time"
+
+	"github.com/Go_CleanArch/common/errors"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+// 1ページあたりの件数の既定値・上限
+const (
+	DefaultPerPage = 50
+	MaxPerPage     = 200
+)
+
+// 監査ログの検索(クエリパラメーター)
+type SearchAuditLogsForm struct {
+	ActorId  string `form:"actorId" json:"actorId"`
+	Table    string `form:"table" json:"table"`
+	RecordId string `form:"recordId" json:"recordId"`
+	// 期間(RFC3339 形式、from 以上 to 未満)
+	From    string `form:"from" json:"from"`
+	To      string `form:"to" json:"to"`
+	Page    int    `form:"page" json:"page"`
+	PerPage int    `form:"perPage" json:"perPage"`
+}
+
+// SearchAuditLogsForm専用入力バリデーション
+func (searchAuditLogsForm SearchAuditLogsForm) SearchAuditLogsValidate() []errors.ApiErrMessage {
+	var apiErrMessages []errors.ApiErrMessage
+	searchAuditLogsFormValidation := validation.ValidateStruct(&searchAuditLogsForm,
+		validation.Field(
+			&searchAuditLogsForm.From,
+			validation.Date(time.RFC3339).Error("開始日時は 2006-01-02T15:04:05+09:00 形式で入力してください"),
+		),
+		validation.Field(
+			&searchAuditLogsForm.To,
+			validation.Date(time.RFC3339).Error("終了日時は 2006-01-02T15:04:05+09:00 形式で入力してください"),
+		),
+		validation.Field(
+			&searchAuditLogsForm.Page,
+			validation.Min(0).Error("ページ番号は 1以上で入力してください"),
+		),
+		validation.Field(
+			&searchAuditLogsForm.PerPage,
+			validation.Min(0).Error("1ページあたりの件数は 1以上で入力してください"),
+			validation.Max(MaxPerPage).Error("1ページあたりの件数は 200件以下で入力してください"),
+		),
+	)
+	if err := searchAuditLogsFormValidation; err != nil {
+		errors.AddValidationErrors(&apiErrMessages, err, nil)
+		return apiErrMessages
+	}
+	return nil
+}
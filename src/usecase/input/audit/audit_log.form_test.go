@@ -0,0 +1,32 @@
+package audit_test
+
+import (
+	"testing"
+
+	inputAudit "github.com/Go_CleanArch/usecase/input/audit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchAuditLogsValidate(t *testing.T) {
+	t.Parallel()
+
+	t.Run("正常系_条件の指定なし", func(t *testing.T) {
+		t.Parallel()
+		searchAuditLogsForm := inputAudit.SearchAuditLogsForm{}
+
+		assert.Empty(t, searchAuditLogsForm.SearchAuditLogsValidate())
+	})
+
+	t.Run("異常系_日時の形式・件数の上限", func(t *testing.T) {
+		t.Parallel()
+		searchAuditLogsForm := inputAudit.SearchAuditLogsForm{From: "2026-10-01", PerPage: 1000}
+
+		apiErrMessages := searchAuditLogsForm.SearchAuditLogsValidate()
+
+		keys := make([]string, 0, len(apiErrMessages))
+		for _, apiErrMessage := range apiErrMessages {
+			keys = append(keys, apiErrMessage.Key)
+		}
+		assert.ElementsMatch(t, []string{"from", "perPage"}, keys)
+	})
+}
@@ -0,0 +1,113 @@
+package organization
+
+import (
+	"regexp"
+
+	"github.com/Go_CleanArch/common/errors"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	is "github.com/go-ozzo/ozzo-validation/v4/is"
+)
+
+// 組織の作成
+type CreateOrganizationForm struct {
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+}
+
+// CreateOrganizationForm専用入力バリデーション
+func (createOrganizationForm CreateOrganizationForm) CreateOrganizationValidate() []errors.ApiErrMessage {
+	var apiErrMessages []errors.ApiErrMessage
+	createOrganizationFormValidation := validation.ValidateStruct(&createOrganizationForm,
+		validation.Field(
+			&createOrganizationForm.Name,
+			validation.Required.Error("組織名を入力してください"),
+			validation.RuneLength(1, 50).Error("組織名は 50文字以内で入力してください"),
+		),
+		validation.Field(
+			&createOrganizationForm.Slug,
+			validation.Required.Error("スラッグを入力してください"),
+			// サブドメインとして使用するため、英小文字・数字・ハイフンのみとする
+			validation.Match(regexp.MustCompile(`^[a-z0-9][a-z0-9-]{1,30}[a-z0-9]$`)).Error("スラッグは英小文字・数字・ハイフンの 3～32文字で入力してください"),
+		),
+	)
+	if err := createOrganizationFormValidation; err != nil {
+		errors.AddValidationErrors(&apiErrMessages, err, nil)
+		return apiErrMessages
+	}
+	return nil
+}
+
+// メンバーの追加
+type AddMemberForm struct {
+	Email string `json:"email"`
+	Role  string `json:"role"`
+}
+
+// AddMemberForm専用入力バリデーション
+func (addMemberForm AddMemberForm) AddMemberValidate() []errors.ApiErrMessage {
+	var apiErrMessages []errors.ApiErrMessage
+	addMemberFormValidation := validation.ValidateStruct(&addMemberForm,
+		validation.Field(
+			&addMemberForm.Email,
+			validation.Required.Error("メールアドレスを入力してください"),
+			is.Email.Error("正しいメールアドレスを入力してください"),
+		),
+		validation.Field(
+			&addMemberForm.Role,
+			validation.Required.Error("ロールを入力してください"),
+		),
+	)
+	if err := addMemberFormValidation; err != nil {
+		errors.AddValidationErrors(&apiErrMessages, err, nil)
+		return apiErrMessages
+	}
+	return nil
+}
+
+// メンバーの招待
+type InviteMemberForm struct {
+	Email string `json:"email"`
+	Role  string `json:"role"`
+}
+
+// InviteMemberForm専用入力バリデーション
+func (inviteMemberForm InviteMemberForm) InviteMemberValidate() []errors.ApiErrMessage {
+	var apiErrMessages []errors.ApiErrMessage
+	inviteMemberFormValidation := validation.ValidateStruct(&inviteMemberForm,
+		validation.Field(
+			&inviteMemberForm.Email,
+			validation.Required.Error("メールアドレスを入力してください"),
+			is.Email.Error("正しいメールアドレスを入力してください"),
+		),
+		validation.Field(
+			&inviteMemberForm.Role,
+			validation.Required.Error("ロールを入力してください"),
+		),
+	)
+	if err := inviteMemberFormValidation; err != nil {
+		errors.AddValidationErrors(&apiErrMessages, err, nil)
+		return apiErrMessages
+	}
+	return nil
+}
+
+// 招待の承諾
+type AcceptInvitationForm struct {
+	Token string `json:"token"`
+}
+
+// AcceptInvitationForm専用入力バリデーション
+func (acceptInvitationForm AcceptInvitationForm) AcceptInvitationValidate() []errors.ApiErrMessage {
+	var apiErrMessages []errors.ApiErrMessage
+	acceptInvitationFormValidation := validation.ValidateStruct(&acceptInvitationForm,
+		validation.Field(
+			&acceptInvitationForm.Token,
+			validation.Required.Error("招待トークンを入力してください"),
+		),
+	)
+	if err := acceptInvitationFormValidation; err != nil {
+		errors.AddValidationErrors(&apiErrMessages, err, nil)
+		return apiErrMessages
+	}
+	return nil
+}
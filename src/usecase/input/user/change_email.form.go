@@ -0,0 +1,51 @@
+package user
+
+import (
+	"github.com/Go_CleanArch/common/errors"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	is "github.com/go-ozzo/ozzo-validation/v4/is"
+)
+
+// メールアドレス変更申請
+type ChangeEmailForm struct {
+	Email string `json:"email"`
+}
+
+// ChangeEmailForm専用入力バリデーション
+func (changeEmailForm ChangeEmailForm) ChangeEmailValidate() []errors.ApiErrMessage {
+	var apiErrMessages []errors.ApiErrMessage
+	changeEmailFormValidation := validation.ValidateStruct(&changeEmailForm,
+		validation.Field(
+			&changeEmailForm.Email,
+			validation.Required.Error("メールアドレスを入力してください"),
+			is.Email.Error("正しいメールアドレスを入力してください"),
+			validation.RuneLength(5, 40).Error("メールアドレスは 5～40文字です"),
+		),
+	)
+	if err := changeEmailFormValidation; err != nil {
+		errors.AddValidationErrors(&apiErrMessages, err, nil)
+		return apiErrMessages
+	}
+	return nil
+}
+
+// メールアドレス変更の確定・取消
+type EmailChangeTokenForm struct {
+	Token string `json:"token"`
+}
+
+// EmailChangeTokenForm専用入力バリデーション
+func (tokenForm EmailChangeTokenForm) EmailChangeTokenValidate() []errors.ApiErrMessage {
+	var apiErrMessages []errors.ApiErrMessage
+	tokenFormValidation := validation.ValidateStruct(&tokenForm,
+		validation.Field(
+			&tokenForm.Token,
+			validation.Required.Error("トークンを入力してください"),
+		),
+	)
+	if err := tokenFormValidation; err != nil {
+		errors.AddValidationErrors(&apiErrMessages, err, nil)
+		return apiErrMessages
+	}
+	return nil
+}
@@ -13,6 +13,8 @@ type CreateUserForm struct {
 	Password   string `json:"password"`
 	Email      string `json:"email"`
 	CreatedFlg bool   `json:"createdFlg"`
+	// 組織からの招待を承諾してサインアップする場合に指定する
+	InvitationToken string `json:"invitationToken"`
 }
 
 // CreateUserForm専用入力バリデーション
@@ -0,0 +1,41 @@
+package user_test
+
+import (
+	"testing"
+
+	inputUser "github.com/Go_CleanArch/usecase/input/user"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateUserValidate(t *testing.T) {
+	t.Parallel()
+
+	t.Run("正常系_入力エラーなし", func(t *testing.T) {
+		t.Parallel()
+		createUserForm := inputUser.CreateUserForm{
+			Email:    "test@example.com",
+			UserName: "testuser",
+			Password: "Password123",
+		}
+
+		assert.Empty(t, createUserForm.CreateUserValidate())
+	})
+
+	t.Run("異常系_メールアドレス・ユーザー名が不正", func(t *testing.T) {
+		t.Parallel()
+		createUserForm := inputUser.CreateUserForm{
+			Email:    "invalid_email",
+			UserName: "",
+			Password: "password",
+		}
+
+		apiErrMessages := createUserForm.CreateUserValidate()
+
+		keys := make([]string, 0, len(apiErrMessages))
+		for _, apiErrMessage := range apiErrMessages {
+			keys = append(keys, apiErrMessage.Key)
+		}
+		assert.Contains(t, keys, "email")
+		assert.Contains(t, keys, "userName")
+	})
+}
@@ -0,0 +1,56 @@
+package user
+
+import (
+	"github.com/Go_CleanArch/common/errors"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+// 個人データの開示(エクスポート)
+type DataExportForm struct {
+	// 監査記録に残す接続元(リクエストから呼び出し元が設定する)
+	IpAddress string `json:"-"`
+}
+
+// 個人データ削除申請(本人確認のためパスワードを再入力させる)
+type ErasureRequestForm struct {
+	Password string `json:"password"`
+	// 監査記録に残す接続元(リクエストから呼び出し元が設定する)
+	IpAddress string `json:"-"`
+}
+
+// ErasureRequestForm専用入力バリデーション
+func (erasureRequestForm ErasureRequestForm) ErasureRequestValidate() []errors.ApiErrMessage {
+	var apiErrMessages []errors.ApiErrMessage
+	erasureRequestFormValidation := validation.ValidateStruct(&erasureRequestForm,
+		validation.Field(
+			&erasureRequestForm.Password,
+			validation.Required.Error("パスワードを入力してください"),
+		),
+	)
+	if err := erasureRequestFormValidation; err != nil {
+		errors.AddValidationErrors(&apiErrMessages, err, nil)
+		return apiErrMessages
+	}
+	return nil
+}
+
+// 個人データ削除の確定
+type ErasureConfirmForm struct {
+	Token string `json:"token"`
+}
+
+// ErasureConfirmForm専用入力バリデーション
+func (erasureConfirmForm ErasureConfirmForm) ErasureConfirmValidate() []errors.ApiErrMessage {
+	var apiErrMessages []errors.ApiErrMessage
+	erasureConfirmFormValidation := validation.ValidateStruct(&erasureConfirmForm,
+		validation.Field(
+			&erasureConfirmForm.Token,
+			validation.Required.Error("トークンを入力してください"),
+		),
+	)
+	if err := erasureConfirmFormValidation; err != nil {
+		errors.AddValidationErrors(&apiErrMessages, err, nil)
+		return apiErrMessages
+	}
+	return nil
+}
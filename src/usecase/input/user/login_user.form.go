@@ -11,6 +11,9 @@ import (
 type LoginForm struct {
 	Password string `json:"password"`
 	Email    string `json:"email"`
+	// ログイン履歴に記録する接続元(リクエストから呼び出し元が設定する)
+	IpAddress string `json:"-"`
+	UserAgent string `json:"-"`
 }
 
 // loginForm専用入力バリデーション
@@ -0,0 +1,35 @@
+package user_test
+
+import (
+	"testing"
+
+	inputUser "github.com/Go_CleanArch/usecase/input/user"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoginValidate(t *testing.T) {
+	t.Parallel()
+
+	t.Run("正常系_入力エラーなし", func(t *testing.T) {
+		t.Parallel()
+		loginForm := inputUser.LoginForm{
+			Email:    "test@example.com",
+			Password: "Password1234",
+		}
+
+		assert.Empty(t, loginForm.LoginValidate())
+	})
+
+	t.Run("異常系_メールアドレスが不正", func(t *testing.T) {
+		t.Parallel()
+		loginForm := inputUser.LoginForm{
+			Email:    "invalid_email",
+			Password: "password",
+		}
+
+		apiErrMessages := loginForm.LoginValidate()
+
+		assert.NotEmpty(t, apiErrMessages)
+		assert.Equal(t, "email", apiErrMessages[0].Key)
+	})
+}
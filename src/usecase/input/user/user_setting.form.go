@@ -0,0 +1,24 @@
+package user
+
+import (
+	"encoding/json"
+
+	"github.com/Go_CleanArch/common/errors"
+)
+
+// 設定値の変更(変更する項目のみを指定する)
+// 値の型・内容はドメインのスキーマで検証する
+type UpdateUserSettingsForm map[string]json.RawMessage
+
+// UpdateUserSettingsForm専用入力バリデーション
+func (updateUserSettingsForm UpdateUserSettingsForm) UpdateUserSettingsValidate() []errors.ApiErrMessage {
+	if len(updateUserSettingsForm) == 0 {
+		return []errors.ApiErrMessage{
+			{
+				Key:   "settings",
+				Value: "変更する設定項目を指定してください",
+			},
+		}
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package user_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	inputUser "github.com/Go_CleanArch/usecase/input/user"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateUserSettingsValidate(t *testing.T) {
+	t.Parallel()
+
+	t.Run("正常系_変更項目あり", func(t *testing.T) {
+		t.Parallel()
+		updateUserSettingsForm := inputUser.UpdateUserSettingsForm{"timezone": json.RawMessage(`"UTC"`)}
+
+		assert.Empty(t, updateUserSettingsForm.UpdateUserSettingsValidate())
+	})
+
+	t.Run("異常系_変更項目なし", func(t *testing.T) {
+		t.Parallel()
+		updateUserSettingsForm := inputUser.UpdateUserSettingsForm{}
+
+		apiErrMessages := updateUserSettingsForm.UpdateUserSettingsValidate()
+
+		assert.Len(t, apiErrMessages, 1)
+		assert.Equal(t, "settings", apiErrMessages[0].Key)
+	})
+}
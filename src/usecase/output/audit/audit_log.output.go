@@ -0,0 +1,28 @@
+package audit
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// データの変更の監査ログ
+type AuditLogPresenter struct {
+	Id         int64     `json:"id"`
+	OccurredAt time.Time `json:"occurredAt"`
+	// 操作したユーザー(認証済みのリクエスト以外の操作は null)
+	ActorId   *string `json:"actorId"`
+	RequestId *string `json:"requestId"`
+	Action    string  `json:"action"`
+	Table     string  `json:"table"`
+	RecordId  string  `json:"recordId"`
+	// 列ごとの変更前後の値(パスワード・個人情報は伏せる)
+	Changes json.RawMessage `json:"changes"`
+}
+
+// 監査ログの検索結果
+type AuditLogPagePresenter struct {
+	Items   []AuditLogPresenter `json:"items"`
+	Total   int64               `json:"total"`
+	Page    int                 `json:"page"`
+	PerPage int                 `json:"perPage"`
+}
@@ -0,0 +1,36 @@
+package organization
+
+import "time"
+
+// 組織(ログインユーザーのロールを含む)
+type OrganizationPresenter struct {
+	OrganizationId string `json:"organizationId"`
+	Name           string `json:"name"`
+	Slug           string `json:"slug"`
+	Role           string `json:"role"`
+}
+
+// 組織の切り替え
+type SwitchOrganizationPresenter struct {
+	OrganizationId string `json:"organizationId"`
+	Role           string `json:"role"`
+	// 切り替え後の組織を操作対象とするアクセストークン
+	AccessToken string `json:"accessToken"`
+}
+
+// 組織のメンバー
+type MemberPresenter struct {
+	UserId   string    `json:"userId"`
+	UserName string    `json:"userName"`
+	Email    string    `json:"email"`
+	Role     string    `json:"role"`
+	JoinedAt time.Time `json:"joinedAt"`
+}
+
+// 組織への招待
+type InvitationPresenter struct {
+	InvitationId string    `json:"invitationId"`
+	Email        string    `json:"email"`
+	Role         string    `json:"role"`
+	ExpiresAt    time.Time `json:"expiresAt"`
+}
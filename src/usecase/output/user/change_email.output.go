@@ -0,0 +1,15 @@
+package user
+
+import "time"
+
+// メールアドレス変更申請
+type EmailChangeRequestPresenter struct {
+	NewEmail  string    `json:"newEmail"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// メールアドレス変更の確定・取消
+type EmailChangeResultPresenter struct {
+	UserId string `json:"userId"`
+	Email  string `json:"email"`
+}
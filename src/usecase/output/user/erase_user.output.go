@@ -0,0 +1,13 @@
+package user
+
+import "time"
+
+// 個人データ削除申請
+type ErasureRequestPresenter struct {
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// 個人データ削除の確定
+type ErasureResultPresenter struct {
+	UserId string `json:"userId"`
+}
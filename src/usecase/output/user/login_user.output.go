@@ -5,4 +5,6 @@ type LoginPresenter struct {
 	UserId   string `json:"userId"`
 	UserName string `json:"userName"`
 	Email    string `json:"email"`
+	// 認証が必要なAPIで Authorization: Bearer に指定するトークン
+	AccessToken string `json:"accessToken"`
 }
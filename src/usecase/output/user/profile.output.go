@@ -0,0 +1,16 @@
+package user
+
+// プロフィール
+type UserProfilePresenter struct {
+	UserId   string           `json:"userId"`
+	UserName string           `json:"userName"`
+	Email    string           `json:"email"`
+	Avatar   *AvatarPresenter `json:"avatar"`
+}
+
+// アバター画像
+type AvatarPresenter struct {
+	OriginalUrl string `json:"originalUrl"`
+	LargeUrl    string `json:"largeUrl"`
+	SmallUrl    string `json:"smallUrl"`
+}
@@ -0,0 +1,6 @@
+package user
+
+// 設定値(未設定の項目は既定値)
+type UserSettingsPresenter struct {
+	Settings map[string]interface{} `json:"settings"`
+}
@@ -0,0 +1,40 @@
+package entity
+
+import "time"
+
+// AuditLog はデータの変更の監査ログ
+type AuditLog struct {
+	Id         int64
+	OccurredAt time.Time
+	ActorId    *string
+	RequestId  *string
+	Action     string
+	Table      string `gorm:"column:table_name"`
+	RecordId   string
+	// 列ごとの変更前後の値(JSON)
+	Changes string
+}
+
+func (AuditLog) TableName() string {
+	return "audit_log"
+}
+
+// AuditLogFilter は監査ログの検索条件(未指定の項目は絞り込まない)
+type AuditLogFilter struct {
+	ActorId  string
+	Table    string
+	RecordId string
+	// 期間(From 以上 To 未満)
+	From    *time.Time
+	To      *time.Time
+	Page    int
+	PerPage int
+}
+
+// AuditLogPage は監査ログの1ページ分の検索結果
+type AuditLogPage struct {
+	Items   []AuditLog
+	Total   int64
+	Page    int
+	PerPage int
+}
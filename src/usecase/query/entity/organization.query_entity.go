@@ -0,0 +1,22 @@
+package entity
+
+import "time"
+
+// UserOrganization はログインユーザーが所属する組織
+type UserOrganization struct {
+	OrganizationId string
+	Name           string
+	Slug           string
+	Role           string
+	JoinedAt       time.Time
+}
+
+// OrganizationMember は組織に所属するユーザー
+// 氏名・メールアドレスは暗号化して保存しているため、取得時に復号する
+type OrganizationMember struct {
+	UserId   string
+	UserName string `gorm:"serializer:encrypted"`
+	Email    string `gorm:"serializer:encrypted"`
+	Role     string
+	JoinedAt time.Time
+}
@@ -0,0 +1,71 @@
+package entity
+
+import "time"
+
+// UserDataExport は個人データ開示請求で出力するアーカイブ
+type UserDataExport struct {
+	ExportedAt          time.Time                      `json:"exportedAt"`
+	Profile             UserProfileRecord              `json:"profile"`
+	LoginHistories      []LoginHistoryRecord           `json:"loginHistories"`
+	EmailChangeRequests []EmailChangeRequestRecord     `json:"emailChangeRequests"`
+	UserSettings        []UserSettingRecord            `json:"userSettings"`
+	Memberships         []OrganizationMembershipRecord `json:"memberships"`
+	Invitations         []OrganizationInvitationRecord `json:"invitations"`
+	PrivacyAuditLogs    []PrivacyAuditLogRecord        `json:"privacyAuditLogs"`
+}
+
+// 氏名・メールアドレスは暗号化して保存しているため、取得時に復号する
+type UserProfileRecord struct {
+	UserId    string     `json:"userId"`
+	UserName  string     `json:"userName" gorm:"serializer:encrypted"`
+	Email     string     `json:"email" gorm:"serializer:encrypted"`
+	AvatarKey *string    `json:"avatarKey"`
+	CreatedAt time.Time  `json:"createdAt"`
+	UpdatedAt *time.Time `json:"updatedAt"`
+}
+
+type LoginHistoryRecord struct {
+	IpAddress  string    `json:"ipAddress"`
+	UserAgent  string    `json:"userAgent"`
+	LoggedInAt time.Time `json:"loggedInAt"`
+}
+
+// 確認・取消トークンのハッシュ値は本人のデータではないため出力しない
+type EmailChangeRequestRecord struct {
+	CurrentEmail string     `json:"currentEmail" gorm:"serializer:encrypted"`
+	NewEmail     string     `json:"newEmail" gorm:"serializer:encrypted"`
+	ExpiresAt    time.Time  `json:"expiresAt"`
+	ConfirmedAt  *time.Time `json:"confirmedAt"`
+	CancelledAt  *time.Time `json:"cancelledAt"`
+	CreatedAt    time.Time  `json:"createdAt"`
+}
+
+// 設定値はJSONエンコードされた値のまま出力する(既定値のままの項目は含まない)
+type UserSettingRecord struct {
+	SettingKey   string    `json:"settingKey"`
+	SettingValue string    `json:"settingValue"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
+
+type OrganizationMembershipRecord struct {
+	OrganizationName string    `json:"organizationName"`
+	OrganizationSlug string    `json:"organizationSlug"`
+	Role             string    `json:"role"`
+	JoinedAt         time.Time `json:"joinedAt"`
+}
+
+// ユーザー宛て、またはユーザーが承諾した組織への招待
+type OrganizationInvitationRecord struct {
+	OrganizationName string     `json:"organizationName"`
+	Email            string     `json:"email" gorm:"serializer:encrypted"`
+	Role             string     `json:"role"`
+	ExpiresAt        time.Time  `json:"expiresAt"`
+	AcceptedAt       *time.Time `json:"acceptedAt"`
+	CreatedAt        time.Time  `json:"createdAt"`
+}
+
+type PrivacyAuditLogRecord struct {
+	Action    string    `json:"action"`
+	IpAddress string    `json:"ipAddress"`
+	CreatedAt time.Time `json:"createdAt"`
+}
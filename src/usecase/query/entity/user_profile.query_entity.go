@@ -0,0 +1,13 @@
+package entity
+
+import "time"
+
+// UserProfile はログインユーザーのプロフィール
+// 氏名・メールアドレスは暗号化して保存しているため、取得時に復号する
+type UserProfile struct {
+	UserId    string
+	UserName  string `gorm:"serializer:encrypted"`
+	Email     string `gorm:"serializer:encrypted"`
+	AvatarKey *string
+	CreatedAt time.Time
+}
@@ -0,0 +1,59 @@
+package query
+
+import (
+	"context"
+	"fmt"
+
+	dbConnect "github.com/Go_CleanArch/infrastructure/db"
+	queryEntity "github.com/Go_CleanArch/usecase/query/entity"
+	queryInterface "github.com/Go_CleanArch/usecase/query/service/query_interface"
+	log "github.com/sirupsen/logrus"
+)
+
+type auditLogQuery struct {
+	auditLogs *dbConnect.Repository[queryEntity.AuditLog]
+}
+
+// コンストラクタ
+func NewAuditLogQuery(ctx context.Context) (queryInterface.AuditLogQueryInterface, error) {
+	// 参照系のためレプリカで読み取る
+	conn, err := dbConnect.NewDBConnection(ctx, dbConnect.ReadFromReplica())
+	if err != nil {
+		return nil, err
+	}
+	return &auditLogQuery{auditLogs: dbConnect.NewRepository[queryEntity.AuditLog](conn)}, nil
+}
+
+// 監査ログの検索(新しい順)
+func (aq *auditLogQuery) SearchAuditLogs(ctx context.Context, filter queryEntity.AuditLogFilter) (*queryEntity.AuditLogPage, error) {
+	conditions := []dbConnect.Condition{}
+	if filter.ActorId != "" {
+		conditions = append(conditions, dbConnect.Eq("actor_id", filter.ActorId))
+	}
+	if filter.Table != "" {
+		conditions = append(conditions, dbConnect.Eq("table_name", filter.Table))
+	}
+	if filter.RecordId != "" {
+		conditions = append(conditions, dbConnect.Eq("record_id", filter.RecordId))
+	}
+	// 監査ログの日時は UTC で記録している
+	if filter.From != nil {
+		conditions = append(conditions, dbConnect.Where("occurred_at >= ?", filter.From.UTC()))
+	}
+	if filter.To != nil {
+		conditions = append(conditions, dbConnect.Where("occurred_at < ?", filter.To.UTC()))
+	}
+	conditions = append(conditions, dbConnect.OrderByDesc("occurred_at"), dbConnect.OrderByDesc("id"))
+
+	result, err := aq.auditLogs.FindPage(ctx, dbConnect.Page{Number: filter.Page, Size: filter.PerPage}, conditions...)
+	if err != nil {
+		log.WithError(err).Error("Failed to search audit logs")
+		return nil, fmt.Errorf("DB検索に失敗しました: %w", err)
+	}
+	return &queryEntity.AuditLogPage{
+		Items:   result.Items,
+		Total:   result.Total,
+		Page:    result.Number,
+		PerPage: result.Size,
+	}, nil
+}
@@ -0,0 +1,62 @@
+package query
+
+import (
+	"context"
+	"fmt"
+
+	dbConnect "github.com/Go_CleanArch/infrastructure/db"
+	queryEntity "github.com/Go_CleanArch/usecase/query/entity"
+	queryInterface "github.com/Go_CleanArch/usecase/query/service/query_interface"
+	log "github.com/sirupsen/logrus"
+)
+
+// Raw のクエリにはテナントによる絞り込みが付与されないため、条件を明記する
+const (
+	selectUserOrganizationsQuery = `
+SELECT o.organization_id, o.name, o.slug, m.role, m.created_at AS joined_at
+FROM organization_memberships m
+INNER JOIN organizations o ON o.organization_id = m.organization_id
+WHERE m.user_id = ?
+ORDER BY m.created_at`
+
+	selectOrganizationMembersQuery = `
+SELECT u.user_id, u.user_name, u.email, m.role, m.created_at AS joined_at
+FROM organization_memberships m
+INNER JOIN users u ON u.user_id = m.user_id
+WHERE m.organization_id = ? AND u.deleted_at IS NULL
+ORDER BY m.created_at`
+)
+
+type organizationQuery struct {
+	db *dbConnect.DBConnection
+}
+
+// コンストラクタ
+func NewOrganizationQuery(ctx context.Context) (queryInterface.OrganizationQueryInterface, error) {
+	// 参照系のためレプリカで読み取る
+	dbConnect, err := dbConnect.NewDBConnection(ctx, dbConnect.ReadFromReplica())
+	if err != nil {
+		return nil, err
+	}
+	return &organizationQuery{db: dbConnect}, nil
+}
+
+// ユーザーが所属する組織の一覧
+func (oq *organizationQuery) FindUserOrganizations(ctx context.Context, userId string) ([]queryEntity.UserOrganization, error) {
+	organizations := []queryEntity.UserOrganization{}
+	if err := oq.db.FindWithRawJoinQuery(ctx, selectUserOrganizationsQuery, &organizations, userId); err != nil {
+		log.WithError(err).Error("Failed to find user organizations")
+		return nil, fmt.Errorf("DB検索に失敗しました: %w", err)
+	}
+	return organizations, nil
+}
+
+// 組織に所属するユーザーの一覧
+func (oq *organizationQuery) FindOrganizationMembers(ctx context.Context, organizationId string) ([]queryEntity.OrganizationMember, error) {
+	members := []queryEntity.OrganizationMember{}
+	if err := oq.db.FindWithRawJoinQuery(ctx, selectOrganizationMembersQuery, &members, organizationId); err != nil {
+		log.WithError(err).Error("Failed to find organization members")
+		return nil, fmt.Errorf("DB検索に失敗しました: %w", err)
+	}
+	return members, nil
+}
@@ -0,0 +1,11 @@
+package queryinterface
+
+import (
+	"context"
+
+	queryEntity "github.com/Go_CleanArch/usecase/query/entity"
+)
+
+type AuditLogQueryInterface interface {
+	SearchAuditLogs(ctx context.Context, filter queryEntity.AuditLogFilter) (*queryEntity.AuditLogPage, error)
+}
@@ -0,0 +1,12 @@
+package queryinterface
+
+import (
+	"context"
+
+	queryEntity "github.com/Go_CleanArch/usecase/query/entity"
+)
+
+type OrganizationQueryInterface interface {
+	FindUserOrganizations(ctx context.Context, userId string) ([]queryEntity.UserOrganization, error)
+	FindOrganizationMembers(ctx context.Context, organizationId string) ([]queryEntity.OrganizationMember, error)
+}
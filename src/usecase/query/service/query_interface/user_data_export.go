@@ -0,0 +1,11 @@
+package queryinterface
+
+import (
+	"context"
+
+	queryEntity "github.com/Go_CleanArch/usecase/query/entity"
+)
+
+type UserDataExportQueryInterface interface {
+	FindUserDataExport(ctx context.Context, userId string) (*queryEntity.UserDataExport, error)
+}
@@ -0,0 +1,11 @@
+package queryinterface
+
+import (
+	"context"
+
+	queryEntity "github.com/Go_CleanArch/usecase/query/entity"
+)
+
+type UserProfileQueryInterface interface {
+	FindUserProfile(ctx context.Context, userId string) (*queryEntity.UserProfile, error)
+}
@@ -0,0 +1,123 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	dbConnect "github.com/Go_CleanArch/infrastructure/db"
+	queryEntity "github.com/Go_CleanArch/usecase/query/entity"
+	queryInterface "github.com/Go_CleanArch/usecase/query/service/query_interface"
+	repository "github.com/Go_CleanArch/usecase/repository_interface"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	selectUserProfileQuery = `
+SELECT user_id, user_name, email, avatar_key, created_at, updated_at
+FROM users
+WHERE user_id = ? AND deleted_at IS NULL`
+
+	selectLoginHistoriesQuery = `
+SELECT ip_address, user_agent, logged_in_at
+FROM login_histories
+WHERE user_id = ?
+ORDER BY logged_in_at DESC`
+
+	selectEmailChangeRequestsQuery = `
+SELECT current_email, new_email, expires_at, confirmed_at, cancelled_at, created_at
+FROM email_change_requests
+WHERE user_id = ?
+ORDER BY created_at DESC`
+
+	selectUserSettingsQuery = `
+SELECT setting_key, setting_value, updated_at
+FROM user_settings
+WHERE user_id = ?
+ORDER BY setting_key`
+
+	selectOrganizationMembershipsQuery = `
+SELECT o.name AS organization_name, o.slug AS organization_slug, m.role, m.created_at AS joined_at
+FROM organization_memberships m
+INNER JOIN organizations o ON o.organization_id = m.organization_id
+WHERE m.user_id = ?
+ORDER BY m.created_at`
+
+	selectOrganizationInvitationsQuery = `
+SELECT o.name AS organization_name, i.email, i.role, i.expires_at, i.accepted_at, i.created_at
+FROM organization_invitations i
+INNER JOIN organizations o ON o.organization_id = i.organization_id
+WHERE i.accepted_by = ? OR i.email_index = (SELECT email_index FROM users WHERE user_id = ?)
+ORDER BY i.created_at`
+
+	selectPrivacyAuditLogsQuery = `
+SELECT action, ip_address, created_at
+FROM privacy_audit_logs
+WHERE user_id = ?
+ORDER BY created_at DESC`
+)
+
+type userDataExportQuery struct {
+	db *dbConnect.DBConnection
+}
+
+// コンストラクタ
+func NewUserDataExportQuery(ctx context.Context) (queryInterface.UserDataExportQueryInterface, error) {
+	// 参照系のためレプリカで読み取る
+	dbConnect, err := dbConnect.NewDBConnection(ctx, dbConnect.ReadFromReplica())
+	if err != nil {
+		return nil, err
+	}
+	return &userDataExportQuery{db: dbConnect}, nil
+}
+
+// ユーザーに紐づく個人データの取得
+func (uq *userDataExportQuery) FindUserDataExport(ctx context.Context, userId string) (*queryEntity.UserDataExport, error) {
+	var profiles []queryEntity.UserProfileRecord
+	if err := uq.db.FindWithRawJoinQuery(ctx, selectUserProfileQuery, &profiles, userId); err != nil {
+		log.WithError(err).Error("Failed to find user profile")
+		return nil, fmt.Errorf("DB検索に失敗しました: %w", err)
+	}
+	if len(profiles) == 0 {
+		log.WithField("userId", userId).Info("User not found")
+		return nil, repository.ErrNotFound
+	}
+
+	export := queryEntity.UserDataExport{
+		ExportedAt:          time.Now(),
+		Profile:             profiles[0],
+		LoginHistories:      []queryEntity.LoginHistoryRecord{},
+		EmailChangeRequests: []queryEntity.EmailChangeRequestRecord{},
+		UserSettings:        []queryEntity.UserSettingRecord{},
+		Memberships:         []queryEntity.OrganizationMembershipRecord{},
+		Invitations:         []queryEntity.OrganizationInvitationRecord{},
+		PrivacyAuditLogs:    []queryEntity.PrivacyAuditLogRecord{},
+	}
+	if err := uq.db.FindWithRawJoinQuery(ctx, selectLoginHistoriesQuery, &export.LoginHistories, userId); err != nil {
+		log.WithError(err).Error("Failed to find login histories")
+		return nil, fmt.Errorf("DB検索に失敗しました: %w", err)
+	}
+	if err := uq.db.FindWithRawJoinQuery(ctx, selectEmailChangeRequestsQuery, &export.EmailChangeRequests, userId); err != nil {
+		log.WithError(err).Error("Failed to find email change requests")
+		return nil, fmt.Errorf("DB検索に失敗しました: %w", err)
+	}
+	if err := uq.db.FindWithRawJoinQuery(ctx, selectUserSettingsQuery, &export.UserSettings, userId); err != nil {
+		log.WithError(err).Error("Failed to find user settings")
+		return nil, fmt.Errorf("DB検索に失敗しました: %w", err)
+	}
+	if err := uq.db.FindWithRawJoinQuery(ctx, selectOrganizationMembershipsQuery, &export.Memberships, userId); err != nil {
+		log.WithError(err).Error("Failed to find organization memberships")
+		return nil, fmt.Errorf("DB検索に失敗しました: %w", err)
+	}
+	if err := uq.db.FindWithRawJoinQuery(ctx, selectOrganizationInvitationsQuery, &export.Invitations, userId, userId); err != nil {
+		log.WithError(err).Error("Failed to find organization invitations")
+		return nil, fmt.Errorf("DB検索に失敗しました: %w", err)
+	}
+	if err := uq.db.FindWithRawJoinQuery(ctx, selectPrivacyAuditLogsQuery, &export.PrivacyAuditLogs, userId); err != nil {
+		log.WithError(err).Error("Failed to find privacy audit logs")
+		return nil, fmt.Errorf("DB検索に失敗しました: %w", err)
+	}
+
+	log.WithField("userId", userId).Info("User data export found successfully")
+	return &export, nil
+}
@@ -0,0 +1,47 @@
+package query
+
+import (
+	"context"
+	"fmt"
+
+	dbConnect "github.com/Go_CleanArch/infrastructure/db"
+	queryEntity "github.com/Go_CleanArch/usecase/query/entity"
+	queryInterface "github.com/Go_CleanArch/usecase/query/service/query_interface"
+	repository "github.com/Go_CleanArch/usecase/repository_interface"
+	log "github.com/sirupsen/logrus"
+)
+
+const selectProfileQuery = `
+SELECT user_id, user_name, email, avatar_key, created_at
+FROM users
+WHERE user_id = ? AND deleted_at IS NULL`
+
+type userProfileQuery struct {
+	db *dbConnect.DBConnection
+}
+
+// コンストラクタ
+func NewUserProfileQuery(ctx context.Context) (queryInterface.UserProfileQueryInterface, error) {
+	// 参照系のためレプリカで読み取る
+	dbConnect, err := dbConnect.NewDBConnection(ctx, dbConnect.ReadFromReplica())
+	if err != nil {
+		return nil, err
+	}
+	return &userProfileQuery{db: dbConnect}, nil
+}
+
+// プロフィールの取得
+func (uq *userProfileQuery) FindUserProfile(ctx context.Context, userId string) (*queryEntity.UserProfile, error) {
+	var profiles []queryEntity.UserProfile
+	if err := uq.db.FindWithRawJoinQuery(ctx, selectProfileQuery, &profiles, userId); err != nil {
+		log.WithError(err).Error("Failed to find user profile")
+		return nil, fmt.Errorf("DB検索に失敗しました: %w", err)
+	}
+	if len(profiles) == 0 {
+		log.WithField("userId", userId).Info("User not found")
+		return nil, repository.ErrNotFound
+	}
+
+	log.WithField("userId", userId).Info("User profile found successfully")
+	return &profiles[0], nil
+}
@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"context"
+
+	entity "github.com/Go_CleanArch/interface_adapter/gateway/entity"
+)
+
+type EmailChangeRequestRepositoryInterface interface {
+	CreateEmailChangeRequest(ctx context.Context, requestJson []byte) (*entity.EmailChangeRequest, error)
+	FindEmailChangeRequestByConfirmTokenHash(ctx context.Context, tokenHash string) (*entity.EmailChangeRequest, error)
+	FindEmailChangeRequestByCancelTokenHash(ctx context.Context, tokenHash string) (*entity.EmailChangeRequest, error)
+	UpdateEmailChangeRequest(ctx context.Context, request *entity.EmailChangeRequest) error
+}
@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"context"
+
+	entity "github.com/Go_CleanArch/interface_adapter/gateway/entity"
+)
+
+type ErasureRequestRepositoryInterface interface {
+	CreateErasureRequest(ctx context.Context, requestJson []byte) (*entity.ErasureRequest, error)
+	FindErasureRequestByConfirmTokenHash(ctx context.Context, tokenHash string) (*entity.ErasureRequest, error)
+	// 個人データの匿名化、削除申請の確定、監査記録の作成を同一トランザクションで行う
+	EraseUserData(ctx context.Context, request *entity.ErasureRequest, anonymizedUserJson []byte, auditLogJson []byte) error
+}
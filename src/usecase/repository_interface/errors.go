@@ -0,0 +1,7 @@
+package repository
+
+import "errors"
+
+// 条件に一致するレコードが存在しない場合のエラー
+// データベースの実装に依存せず errors.Is で判定できるよう、各リポジトリはこのエラーを返す
+var ErrNotFound = errors.New("条件に一致するレコードが見つかりません")
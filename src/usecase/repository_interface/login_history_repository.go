@@ -0,0 +1,7 @@
+package repository
+
+import "context"
+
+type LoginHistoryRepositoryInterface interface {
+	CreateLoginHistory(ctx context.Context, loginHistoryJson []byte) error
+}
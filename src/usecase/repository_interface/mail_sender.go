@@ -0,0 +1,14 @@
+package repository
+
+import "context"
+
+// MailMessage は送信するメールの内容を表す。
+type MailMessage struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+type MailSenderInterface interface {
+	SendMail(ctx context.Context, message MailMessage) error
+}
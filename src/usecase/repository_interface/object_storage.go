@@ -0,0 +1,11 @@
+package repository
+
+import "context"
+
+type ObjectStorageInterface interface {
+	PutObject(ctx context.Context, key string, contentType string, body []byte) error
+	// 存在しないキーを指定した場合もエラーとしない
+	DeleteObject(ctx context.Context, key string) error
+	// クライアントから参照するための公開URL
+	ObjectUrl(key string) string
+}
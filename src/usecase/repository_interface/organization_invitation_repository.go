@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	entity "github.com/Go_CleanArch/interface_adapter/gateway/entity"
+)
+
+// 承諾済みの招待を再度承諾しようとした場合のエラー
+var ErrInvitationAlreadyAccepted = errors.New("この招待はすでに承諾されています")
+
+type OrganizationInvitationRepositoryInterface interface {
+	CreateInvitation(ctx context.Context, invitationJson []byte) (*entity.OrganizationInvitation, error)
+	// 招待の承諾時は組織が未確定のため、テナントによる絞り込みを行わない
+	FindInvitationByTokenHash(ctx context.Context, tokenHash string) (*entity.OrganizationInvitation, error)
+	// 招待の承諾と所属の作成を同一トランザクションで行う(コンテキストのテナントは招待元の組織とする)
+	AcceptInvitation(ctx context.Context, invitation *entity.OrganizationInvitation, membershipJson []byte) error
+}
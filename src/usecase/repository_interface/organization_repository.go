@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+
+	entity "github.com/Go_CleanArch/interface_adapter/gateway/entity"
+)
+
+// 組織・所属のテーブルはテナント単位のため、コンテキストのテナントの範囲でのみ操作できる
+type OrganizationRepositoryInterface interface {
+	// 組織とオーナーの所属を同一トランザクションで作成する(コンテキストのテナントは作成する組織とする)
+	CreateOrganization(ctx context.Context, organizationJson []byte, ownerMembershipJson []byte) (*entity.Organization, error)
+	// サブドメインからの組織の解決に使用するため、テナントによる絞り込みを行わない
+	FindOrganizationBySlug(ctx context.Context, slug string) (*entity.Organization, error)
+	FindOrganizationById(ctx context.Context, organizationId string) (*entity.Organization, error)
+}
+
+type OrganizationMembershipRepositoryInterface interface {
+	CreateMembership(ctx context.Context, membershipJson []byte) (*entity.OrganizationMembership, error)
+	FindMembership(ctx context.Context, userId string) (*entity.OrganizationMembership, error)
+}
@@ -0,0 +1,13 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Go_CleanArch/domain/event"
+)
+
+type OutboxRepositoryInterface interface {
+	// イベントを配信待ちとして保存する
+	// 状態の変更と同じトランザクションで保存するため、RunInTransaction の fn から呼び出す。
+	AddEvents(ctx context.Context, events ...event.Event) error
+}
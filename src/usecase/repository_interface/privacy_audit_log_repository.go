@@ -0,0 +1,7 @@
+package repository
+
+import "context"
+
+type PrivacyAuditLogRepositoryInterface interface {
+	CreatePrivacyAuditLog(ctx context.Context, auditLogJson []byte) error
+}
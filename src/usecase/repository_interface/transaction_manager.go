@@ -0,0 +1,11 @@
+package repository
+
+import "context"
+
+// TransactionManagerInterface は複数のリポジトリへの操作を1つのトランザクションで実行する。
+type TransactionManagerInterface interface {
+	// fn に渡されたコンテキストでリポジトリを呼び出すと、すべて同じトランザクションで実行される。
+	// fn がエラーを返した場合・パニックした場合はロールバックし、それ以外はコミットする。
+	// トランザクション中に呼び出した場合はセーブポイントを作成し、fn の失敗時はセーブポイントまで戻す。
+	RunInTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
@@ -2,11 +2,18 @@ package repository
 
 import (
 	"context"
+	"errors"
 
 	entity "github.com/Go_CleanArch/interface_adapter/gateway/entity"
 )
 
+// 登録済みのメールアドレスで作成・変更しようとした場合のエラー(大文字・小文字の違いは同一とみなす)
+var ErrEmailAlreadyExists = errors.New("すでに登録されているアドレスです")
+
 type UserRepositoryInterface interface {
 	FindUserByEmail(ctx context.Context, email string) (*entity.User, error)
+	FindUserById(ctx context.Context, userId string) (*entity.User, error)
 	CreateUser(ctx context.Context, userJson []byte) (*entity.User, error)
+	UpdateUserEmail(ctx context.Context, userId string, email string) error
+	UpdateUserAvatarKey(ctx context.Context, userId string, avatarKey string) error
 }
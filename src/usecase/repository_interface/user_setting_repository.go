@@ -0,0 +1,13 @@
+package repository
+
+import (
+	"context"
+
+	entity "github.com/Go_CleanArch/interface_adapter/gateway/entity"
+)
+
+type UserSettingRepositoryInterface interface {
+	FindUserSettings(ctx context.Context, userId string) ([]entity.UserSetting, error)
+	// 設定値(キー → JSONエンコードされた値)を登録・更新する
+	SaveUserSettings(ctx context.Context, userId string, settingsJson []byte) error
+}
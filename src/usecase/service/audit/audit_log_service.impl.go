@@ -0,0 +1,81 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	inputAudit "github.com/Go_CleanArch/usecase/input/audit"
+	outputAudit "github.com/Go_CleanArch/usecase/output/audit"
+	queryEntity "github.com/Go_CleanArch/usecase/query/entity"
+	queryInterface "github.com/Go_CleanArch/usecase/query/service/query_interface"
+	log "github.com/sirupsen/logrus"
+)
+
+// AuditLogService provides audit log behavior for administrators
+type AuditLogService struct {
+	auditLogQuery queryInterface.AuditLogQueryInterface
+}
+
+// Constructor
+func NewAuditLogService(auditLogQuery queryInterface.AuditLogQueryInterface) *AuditLogService {
+	return &AuditLogService{auditLogQuery: auditLogQuery}
+}
+
+// 監査ログの検索
+// 操作したユーザー・対象のテーブルとレコード・期間で絞り込み、新しい順に返す。
+func (as *AuditLogService) SearchAuditLogsService(ctx context.Context, searchAuditLogsForm inputAudit.SearchAuditLogsForm) (outputAudit.AuditLogPagePresenter, error) {
+	var presenter outputAudit.AuditLogPagePresenter
+
+	filter := queryEntity.AuditLogFilter{
+		ActorId:  searchAuditLogsForm.ActorId,
+		Table:    searchAuditLogsForm.Table,
+		RecordId: searchAuditLogsForm.RecordId,
+		From:     parseTime(searchAuditLogsForm.From),
+		To:       parseTime(searchAuditLogsForm.To),
+		Page:     searchAuditLogsForm.Page,
+		PerPage:  searchAuditLogsForm.PerPage,
+	}
+	if filter.Page == 0 {
+		filter.Page = 1
+	}
+	if filter.PerPage == 0 {
+		filter.PerPage = inputAudit.DefaultPerPage
+	}
+
+	page, err := as.auditLogQuery.SearchAuditLogs(ctx, filter)
+	if err != nil {
+		log.WithError(err).Error("Failed to search audit logs")
+		return presenter, err
+	}
+
+	presenter.Items = make([]outputAudit.AuditLogPresenter, 0, len(page.Items))
+	for _, auditLog := range page.Items {
+		presenter.Items = append(presenter.Items, outputAudit.AuditLogPresenter{
+			Id:         auditLog.Id,
+			OccurredAt: auditLog.OccurredAt,
+			ActorId:    auditLog.ActorId,
+			RequestId:  auditLog.RequestId,
+			Action:     auditLog.Action,
+			Table:      auditLog.Table,
+			RecordId:   auditLog.RecordId,
+			Changes:    json.RawMessage(auditLog.Changes),
+		})
+	}
+	presenter.Total = page.Total
+	presenter.Page = page.Page
+	presenter.PerPage = page.PerPage
+	return presenter, nil
+}
+
+// 入力チェック済みの日時(未指定の場合は nil)
+func parseTime(value string) *time.Time {
+	if value == "" {
+		return nil
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil
+	}
+	return &parsed
+}
@@ -0,0 +1,116 @@
+package audit_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	apiErrors "github.com/Go_CleanArch/common/errors"
+	inputAudit "github.com/Go_CleanArch/usecase/input/audit"
+	queryEntity "github.com/Go_CleanArch/usecase/query/entity"
+	audit_service_impl "github.com/Go_CleanArch/usecase/service/audit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAuditLogQuery struct {
+	mock.Mock
+}
+
+func (m *MockAuditLogQuery) SearchAuditLogs(ctx context.Context, filter queryEntity.AuditLogFilter) (*queryEntity.AuditLogPage, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*queryEntity.AuditLogPage), args.Error(1)
+}
+
+// responseStatusOf はエラーを ErrorMiddleware でレスポンスに変換した場合のステータスを返す(ApiErr を保持しないエラーは 500)
+func responseStatusOf(err error) int {
+	if apiErr, ok := apiErrors.ApiErrFrom(err); ok {
+		return apiErr.Status
+	}
+	return 500
+}
+
+func TestSearchAuditLogsService(t *testing.T) {
+	t.Parallel()
+
+	actorId := "0192a7e4-5c1b-7d2e-9f3a-4b5c6d7e8f90"
+
+	t.Run("監査ログ検索_条件で絞り込んだ結果を返す", func(t *testing.T) {
+		t.Parallel()
+		ctx := context.Background()
+		mockQuery := new(MockAuditLogQuery)
+		service := audit_service_impl.NewAuditLogService(mockQuery)
+
+		from := time.Date(2026, 10, 1, 0, 0, 0, 0, time.FixedZone("JST", 9*60*60))
+		mockQuery.On("SearchAuditLogs", ctx, mock.MatchedBy(func(filter queryEntity.AuditLogFilter) bool {
+			return filter.ActorId == actorId && filter.Table == "users" && filter.RecordId == "user-1" &&
+				filter.From.Equal(from) && filter.To == nil && filter.Page == 2 && filter.PerPage == 10
+		})).Return(&queryEntity.AuditLogPage{
+			Items: []queryEntity.AuditLog{{
+				Id:       1,
+				ActorId:  &actorId,
+				Action:   "update",
+				Table:    "users",
+				RecordId: "user-1",
+				Changes:  `{"email":{"before":"***","after":"***"}}`,
+			}},
+			Total:   11,
+			Page:    2,
+			PerPage: 10,
+		}, nil)
+
+		searchAuditLogsForm := inputAudit.SearchAuditLogsForm{
+			ActorId:  actorId,
+			Table:    "users",
+			RecordId: "user-1",
+			From:     "2026-10-01T00:00:00+09:00",
+			Page:     2,
+			PerPage:  10,
+		}
+
+		presenter, err := service.SearchAuditLogsService(ctx, searchAuditLogsForm)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(11), presenter.Total)
+		assert.Len(t, presenter.Items, 1)
+		assert.Equal(t, "users", presenter.Items[0].Table)
+		assert.JSONEq(t, `{"email":{"before":"***","after":"***"}}`, string(presenter.Items[0].Changes))
+		mockQuery.AssertExpectations(t)
+	})
+
+	t.Run("監査ログ検索_ページの指定が無い場合は既定値", func(t *testing.T) {
+		t.Parallel()
+		ctx := context.Background()
+		mockQuery := new(MockAuditLogQuery)
+		service := audit_service_impl.NewAuditLogService(mockQuery)
+
+		mockQuery.On("SearchAuditLogs", ctx, queryEntity.AuditLogFilter{Page: 1, PerPage: 50}).
+			Return(&queryEntity.AuditLogPage{Page: 1, PerPage: 50}, nil)
+
+		presenter, err := service.SearchAuditLogsService(ctx, inputAudit.SearchAuditLogsForm{})
+
+		assert.NoError(t, err)
+		assert.NotNil(t, presenter.Items)
+		body, _ := json.Marshal(presenter)
+		assert.Contains(t, string(body), `"items":[]`)
+	})
+
+	t.Run("監査ログ検索_DBエラー", func(t *testing.T) {
+		t.Parallel()
+		ctx := context.Background()
+		mockQuery := new(MockAuditLogQuery)
+		service := audit_service_impl.NewAuditLogService(mockQuery)
+
+		mockQuery.On("SearchAuditLogs", ctx, mock.Anything).Return(nil, errors.New("DB検索に失敗しました"))
+
+		_, err := service.SearchAuditLogsService(ctx, inputAudit.SearchAuditLogsForm{})
+
+		assert.Error(t, err)
+		assert.Equal(t, 500, responseStatusOf(err))
+	})
+}
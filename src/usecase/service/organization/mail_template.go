@@ -0,0 +1,25 @@
+package organization
+
+import (
+	"fmt"
+	"time"
+
+	repository "github.com/Go_CleanArch/usecase/repository_interface"
+)
+
+// 招待先アドレスへ送る招待メール
+func invitationMail(to string, organizationName string, acceptLink string, expiresAt time.Time) repository.MailMessage {
+	return repository.MailMessage{
+		To:      to,
+		Subject: fmt.Sprintf("【招待】%s への招待が届いています", organizationName),
+		Body: fmt.Sprintf(`%s へのメンバー招待が届いています。
+以下のリンクから招待を承諾してください。アカウントをお持ちでない場合は、このメールアドレスで登録すると組織に参加できます。
+
+%s
+
+有効期限: %s
+
+お心当たりのない場合は、このメールを破棄してください。
+`, organizationName, acceptLink, expiresAt.Format("2006-01-02 15:04:05 MST")),
+	}
+}
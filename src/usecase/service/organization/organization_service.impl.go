@@ -0,0 +1,504 @@
+package organization
+
+import (
+	"context"
+	goErrors "errors"
+	"fmt"
+	"time"
+
+	"github.com/Go_CleanArch/common/auth"
+	status "github.com/Go_CleanArch/common/const"
+	"github.com/Go_CleanArch/common/crypto"
+	"github.com/Go_CleanArch/common/errors"
+	"github.com/Go_CleanArch/common/tenant"
+	domainEntity "github.com/Go_CleanArch/domain/entity"
+	createOrganizationFactory "github.com/Go_CleanArch/domain/factory/organization/create_organization"
+	inviteMemberFactory "github.com/Go_CleanArch/domain/factory/organization/invite_member"
+	"github.com/Go_CleanArch/interface_adapter/gateway/entity"
+	inputOrganization "github.com/Go_CleanArch/usecase/input/organization"
+	outputOrganization "github.com/Go_CleanArch/usecase/output/organization"
+	queryInterface "github.com/Go_CleanArch/usecase/query/service/query_interface"
+	repository "github.com/Go_CleanArch/usecase/repository_interface"
+	log "github.com/sirupsen/logrus"
+)
+
+// OrganizationService provides organization and membership behavior
+type OrganizationService struct {
+	organizationRepository           repository.OrganizationRepositoryInterface
+	organizationMembershipRepository repository.OrganizationMembershipRepositoryInterface
+	organizationInvitationRepository repository.OrganizationInvitationRepositoryInterface
+	userRepository                   repository.UserRepositoryInterface
+	organizationQuery                queryInterface.OrganizationQueryInterface
+	mailSender                       repository.MailSenderInterface
+	tokenIssuer                      *auth.TokenIssuer
+	// 招待メールに記載する承諾リンクのベースURL
+	linkBaseUrl       string
+	factory           *createOrganizationFactory.CreateOrganizationFactory
+	invitationFactory *inviteMemberFactory.InviteMemberFactory
+}
+
+// Constructor
+func NewOrganizationService(
+	organizationRepository repository.OrganizationRepositoryInterface,
+	organizationMembershipRepository repository.OrganizationMembershipRepositoryInterface,
+	organizationInvitationRepository repository.OrganizationInvitationRepositoryInterface,
+	userRepository repository.UserRepositoryInterface,
+	organizationQuery queryInterface.OrganizationQueryInterface,
+	mailSender repository.MailSenderInterface,
+	tokenIssuer *auth.TokenIssuer,
+	linkBaseUrl string,
+) *OrganizationService {
+	return &OrganizationService{
+		organizationRepository:           organizationRepository,
+		organizationMembershipRepository: organizationMembershipRepository,
+		organizationInvitationRepository: organizationInvitationRepository,
+		userRepository:                   userRepository,
+		organizationQuery:                organizationQuery,
+		mailSender:                       mailSender,
+		tokenIssuer:                      tokenIssuer,
+		linkBaseUrl:                      linkBaseUrl,
+		factory:                          createOrganizationFactory.NewCreateOrganizationFactory(),
+		invitationFactory:                inviteMemberFactory.NewInviteMemberFactory(),
+	}
+}
+
+// 組織の作成
+// 作成したユーザーを組織のオーナーとして所属させる。
+func (orgs *OrganizationService) CreateOrganizationService(ctx context.Context, createOrganizationForm inputOrganization.CreateOrganizationForm) (outputOrganization.OrganizationPresenter, error) {
+	var presenter outputOrganization.OrganizationPresenter
+
+	userId, ok := auth.UserIdFromContext(ctx)
+	if !ok {
+		apiErr := unauthorizedError()
+		return presenter, apiErr.Error()
+	}
+
+	// スラッグの重複チェック
+	if _, err := orgs.organizationRepository.FindOrganizationBySlug(ctx, createOrganizationForm.Slug); err == nil {
+		apiErr := errors.OutputApiError(
+			[]errors.ApiErrMessage{createOrganizationFactory.SlugAlreadyExistsMessage()},
+			status.ErrorStatusMap["ENABLE_CHECK_ERROR"].StatusCode,
+			status.ErrorStatusMap["ENABLE_CHECK_ERROR"].StatusName,
+		)
+		log.WithField("slug", createOrganizationForm.Slug).Info("Organization slug already exists")
+		return presenter, apiErr.Error()
+	}
+
+	organization, owner, apiErr := orgs.factory.CreateOrganization(userId, createOrganizationForm.Name, createOrganizationForm.Slug)
+	if apiErr != nil {
+		log.WithField("apiErr", apiErr).Error("Failed to build organization")
+		return presenter, apiErr.Error()
+	}
+
+	organizationJson, err := crypto.ConvertStructIntoJson(organization)
+	if err != nil {
+		log.WithError(err).Error("Failed to convert organization into JSON")
+		return presenter, err
+	}
+	ownerJson, err := crypto.ConvertStructIntoJson(owner)
+	if err != nil {
+		log.WithError(err).Error("Failed to convert organization membership into JSON")
+		return presenter, err
+	}
+
+	// 作成する組織をテナントとして登録する
+	tenantCtx := tenant.WithTenant(ctx, tenant.Tenant{OrganizationId: organization.OrganizationID, Role: owner.Role})
+	if _, err := orgs.organizationRepository.CreateOrganization(tenantCtx, organizationJson, ownerJson); err != nil {
+		log.WithError(err).Error("Failed to create organization")
+		return presenter, err
+	}
+
+	presenter.OrganizationId = organization.OrganizationID
+	presenter.Name = organization.Name
+	presenter.Slug = organization.Slug
+	presenter.Role = owner.Role
+	return presenter, nil
+}
+
+// 所属している組織の一覧
+func (orgs *OrganizationService) ListMyOrganizationsService(ctx context.Context) ([]outputOrganization.OrganizationPresenter, error) {
+	presenters := []outputOrganization.OrganizationPresenter{}
+
+	userId, ok := auth.UserIdFromContext(ctx)
+	if !ok {
+		apiErr := unauthorizedError()
+		return presenters, apiErr.Error()
+	}
+
+	organizations, err := orgs.organizationQuery.FindUserOrganizations(ctx, userId)
+	if err != nil {
+		log.WithError(err).Error("Failed to find user organizations")
+		return presenters, err
+	}
+
+	for _, organization := range organizations {
+		presenters = append(presenters, outputOrganization.OrganizationPresenter{
+			OrganizationId: organization.OrganizationId,
+			Name:           organization.Name,
+			Slug:           organization.Slug,
+			Role:           organization.Role,
+		})
+	}
+	return presenters, nil
+}
+
+// 操作対象の組織の切り替え
+// 所属を確認したうえで、組織を指定したアクセストークンを発行する。
+func (orgs *OrganizationService) SwitchOrganizationService(ctx context.Context, organizationId string) (outputOrganization.SwitchOrganizationPresenter, error) {
+	var presenter outputOrganization.SwitchOrganizationPresenter
+
+	userId, ok := auth.UserIdFromContext(ctx)
+	if !ok {
+		apiErr := unauthorizedError()
+		return presenter, apiErr.Error()
+	}
+
+	resolved, apiErr := orgs.ResolveTenant(ctx, userId, organizationId, "")
+	if apiErr != nil {
+		return presenter, apiErr.Error()
+	}
+
+	accessToken, err := orgs.tokenIssuer.IssueForOrganization(userId, resolved.OrganizationId)
+	if err != nil {
+		log.WithError(err).Error("Failed to issue access token")
+		return presenter, err
+	}
+
+	presenter.OrganizationId = resolved.OrganizationId
+	presenter.Role = resolved.Role
+	presenter.AccessToken = accessToken
+	return presenter, nil
+}
+
+// 組織のメンバー一覧
+func (orgs *OrganizationService) ListMembersService(ctx context.Context) ([]outputOrganization.MemberPresenter, error) {
+	presenters := []outputOrganization.MemberPresenter{}
+
+	current, ok := tenant.FromContext(ctx)
+	if !ok {
+		apiErr := tenantRequiredError()
+		return presenters, apiErr.Error()
+	}
+
+	members, err := orgs.organizationQuery.FindOrganizationMembers(ctx, current.OrganizationId)
+	if err != nil {
+		log.WithError(err).Error("Failed to find organization members")
+		return presenters, err
+	}
+
+	for _, member := range members {
+		presenters = append(presenters, outputOrganization.MemberPresenter{
+			UserId:   member.UserId,
+			UserName: member.UserName,
+			Email:    member.Email,
+			Role:     member.Role,
+			JoinedAt: member.JoinedAt,
+		})
+	}
+	return presenters, nil
+}
+
+// メンバーの追加(オーナー・管理者のみ)
+func (orgs *OrganizationService) AddMemberService(ctx context.Context, addMemberForm inputOrganization.AddMemberForm) (outputOrganization.MemberPresenter, error) {
+	var presenter outputOrganization.MemberPresenter
+
+	current, ok := tenant.FromContext(ctx)
+	if !ok {
+		apiErr := tenantRequiredError()
+		return presenter, apiErr.Error()
+	}
+
+	user, err := orgs.userRepository.FindUserByEmail(ctx, addMemberForm.Email)
+	if err != nil {
+		log.WithError(err).Info("Failed to find user by email")
+		apiErr := notFoundError("email", "ユーザーが存在しません")
+		return presenter, apiErr.Error()
+	}
+
+	membership, apiErr := orgs.factory.AddMember(current.Role, current.OrganizationId, user.UserId, addMemberForm.Role)
+	if apiErr != nil {
+		log.WithField("apiErr", apiErr).Error("Failed to build organization membership")
+		return presenter, apiErr.Error()
+	}
+
+	// 所属済みチェック
+	if _, err := orgs.organizationMembershipRepository.FindMembership(ctx, user.UserId); err == nil {
+		apiErr := errors.OutputApiError(
+			[]errors.ApiErrMessage{createOrganizationFactory.MemberAlreadyExistsMessage()},
+			status.ErrorStatusMap["ENABLE_CHECK_ERROR"].StatusCode,
+			status.ErrorStatusMap["ENABLE_CHECK_ERROR"].StatusName,
+		)
+		return presenter, apiErr.Error()
+	}
+
+	membershipJson, err := crypto.ConvertStructIntoJson(membership)
+	if err != nil {
+		log.WithError(err).Error("Failed to convert organization membership into JSON")
+		return presenter, err
+	}
+	created, err := orgs.organizationMembershipRepository.CreateMembership(ctx, membershipJson)
+	if err != nil {
+		log.WithError(err).Error("Failed to create organization membership")
+		return presenter, err
+	}
+
+	presenter.UserId = user.UserId
+	presenter.UserName = user.UserName
+	presenter.Email = user.Email
+	presenter.Role = membership.Role
+	presenter.JoinedAt = created.CreatedAt
+	return presenter, nil
+}
+
+// メンバーの招待(オーナー・管理者のみ)
+// 招待先アドレスへ承諾リンク付きの招待メールを送信する。
+func (orgs *OrganizationService) InviteMemberService(ctx context.Context, inviteMemberForm inputOrganization.InviteMemberForm) (outputOrganization.InvitationPresenter, error) {
+	var presenter outputOrganization.InvitationPresenter
+
+	userId, ok := auth.UserIdFromContext(ctx)
+	if !ok {
+		apiErr := unauthorizedError()
+		return presenter, apiErr.Error()
+	}
+	current, ok := tenant.FromContext(ctx)
+	if !ok {
+		apiErr := tenantRequiredError()
+		return presenter, apiErr.Error()
+	}
+
+	invitation, apiErr := orgs.invitationFactory.Invite(current.Role, current.OrganizationId, userId, inviteMemberForm.Email, inviteMemberForm.Role)
+	if apiErr != nil {
+		log.WithField("apiErr", apiErr).Error("Failed to build organization invitation")
+		return presenter, apiErr.Error()
+	}
+
+	// 所属済みチェック
+	if user, err := orgs.userRepository.FindUserByEmail(ctx, inviteMemberForm.Email); err == nil {
+		if _, err := orgs.organizationMembershipRepository.FindMembership(ctx, user.UserId); err == nil {
+			apiErr := errors.OutputApiError(
+				[]errors.ApiErrMessage{createOrganizationFactory.MemberAlreadyExistsMessage()},
+				status.ErrorStatusMap["ENABLE_CHECK_ERROR"].StatusCode,
+				status.ErrorStatusMap["ENABLE_CHECK_ERROR"].StatusName,
+			)
+			return presenter, apiErr.Error()
+		}
+	}
+
+	organization, err := orgs.organizationRepository.FindOrganizationById(ctx, current.OrganizationId)
+	if err != nil {
+		log.WithError(err).Error("Failed to find organization")
+		return presenter, err
+	}
+
+	invitationJson, err := crypto.ConvertStructIntoJson(invitation)
+	if err != nil {
+		log.WithError(err).Error("Failed to convert organization invitation into JSON")
+		return presenter, err
+	}
+	if _, err := orgs.organizationInvitationRepository.CreateInvitation(ctx, invitationJson); err != nil {
+		log.WithError(err).Error("Failed to create organization invitation")
+		return presenter, err
+	}
+
+	acceptLink := fmt.Sprintf("%s/invitations/accept?token=%s", orgs.linkBaseUrl, invitation.Token)
+	if err := orgs.mailSender.SendMail(ctx, invitationMail(invitation.Email, organization.Name, acceptLink, invitation.ExpiresAt.In(invitationLocation()))); err != nil {
+		log.WithError(err).Error("Failed to send invitation mail")
+		return presenter, err
+	}
+
+	presenter.InvitationId = invitation.InvitationID
+	presenter.Email = invitation.Email
+	presenter.Role = invitation.Role
+	presenter.ExpiresAt = invitation.ExpiresAt
+	return presenter, nil
+}
+
+// 招待の承諾(登録済みのユーザー)
+// 招待先のメールアドレスで登録しているユーザーのみが承諾できる。
+func (orgs *OrganizationService) AcceptInvitationService(ctx context.Context, acceptInvitationForm inputOrganization.AcceptInvitationForm) (outputOrganization.OrganizationPresenter, error) {
+	var presenter outputOrganization.OrganizationPresenter
+
+	userId, ok := auth.UserIdFromContext(ctx)
+	if !ok {
+		apiErr := unauthorizedError()
+		return presenter, apiErr.Error()
+	}
+
+	user, err := orgs.userRepository.FindUserById(ctx, userId)
+	if err != nil {
+		log.WithError(err).Error("Failed to find user")
+		return presenter, err
+	}
+
+	membership, err := orgs.AcceptInvitation(ctx, acceptInvitationForm.Token, user.UserId, user.Email)
+	if err != nil {
+		return presenter, err
+	}
+
+	tenantCtx := tenant.WithTenant(ctx, tenant.Tenant{OrganizationId: membership.OrganizationID, Role: membership.Role})
+	organization, err := orgs.organizationRepository.FindOrganizationById(tenantCtx, membership.OrganizationID)
+	if err != nil {
+		log.WithError(err).Error("Failed to find organization")
+		return presenter, err
+	}
+
+	presenter.OrganizationId = organization.OrganizationId
+	presenter.Name = organization.Name
+	presenter.Slug = organization.Slug
+	presenter.Role = membership.Role
+	return presenter, nil
+}
+
+// 招待の承諾可否チェック
+// 新規登録時は、ユーザーの作成前に招待が有効であることを確認するために使用する。
+func (orgs *OrganizationService) CheckInvitation(ctx context.Context, token string, email string) *errors.ApiErr {
+	_, apiErr := orgs.findAcceptableInvitation(ctx, token, email)
+	return apiErr
+}
+
+// 招待の承諾
+// 招待元の組織へ、招待時に指定されたロールで所属させる。
+// 承諾できない場合は ApiErr を保持するエラー(errors.ApiErrFrom で取り出せる)、または想定外のエラーを返す
+func (orgs *OrganizationService) AcceptInvitation(ctx context.Context, token string, userId string, email string) (*domainEntity.OrganizationMembership, error) {
+	invitation, apiErr := orgs.findAcceptableInvitation(ctx, token, email)
+	if apiErr != nil {
+		return nil, apiErr.Error()
+	}
+
+	state := invitationState(invitation)
+	tenantCtx := tenant.WithTenant(ctx, tenant.Tenant{OrganizationId: state.OrganizationID})
+
+	// 所属済みチェック
+	if _, err := orgs.organizationMembershipRepository.FindMembership(tenantCtx, userId); err == nil {
+		apiErr := errors.OutputApiError(
+			[]errors.ApiErrMessage{createOrganizationFactory.MemberAlreadyExistsMessage()},
+			status.ErrorStatusMap["ENABLE_CHECK_ERROR"].StatusCode,
+			status.ErrorStatusMap["ENABLE_CHECK_ERROR"].StatusName,
+		)
+		return nil, apiErr.Error()
+	}
+
+	membership := orgs.invitationFactory.AcceptedMembership(state, userId)
+	membershipJson, err := crypto.ConvertStructIntoJson(membership)
+	if err != nil {
+		log.WithError(err).Error("Failed to convert organization membership into JSON")
+		return nil, fmt.Errorf("所属情報の変換に失敗しました: %w", err)
+	}
+	if err := orgs.organizationInvitationRepository.AcceptInvitation(tenantCtx, invitation, membershipJson); err != nil {
+		if goErrors.Is(err, repository.ErrInvitationAlreadyAccepted) {
+			return nil, inviteMemberFactory.AlreadyAcceptedError().Error()
+		}
+		log.WithError(err).Error("Failed to accept organization invitation")
+		return nil, fmt.Errorf("招待の承諾に失敗しました: %w", err)
+	}
+
+	return membership, nil
+}
+
+func (orgs *OrganizationService) findAcceptableInvitation(ctx context.Context, token string, email string) (*entity.OrganizationInvitation, *errors.ApiErr) {
+	invitation, err := orgs.organizationInvitationRepository.FindInvitationByTokenHash(ctx, crypto.HashToken(token))
+	if err != nil {
+		log.WithError(err).Info("Failed to find organization invitation")
+		return nil, inviteMemberFactory.InvalidInvitationError()
+	}
+	if apiErr := orgs.invitationFactory.CheckAcceptable(invitationState(invitation), email); apiErr != nil {
+		log.WithField("invitationId", invitation.InvitationId).Info("Organization invitation is not acceptable")
+		return nil, apiErr
+	}
+	return invitation, nil
+}
+
+func invitationState(invitation *entity.OrganizationInvitation) inviteMemberFactory.InvitationState {
+	return inviteMemberFactory.InvitationState{
+		OrganizationID: invitation.OrganizationId,
+		Email:          invitation.Email,
+		Role:           invitation.Role,
+		ExpiresAt:      invitation.ExpiresAt,
+		AcceptedAt:     invitation.AcceptedAt,
+	}
+}
+
+// 招待メールの日時表示に使用するタイムゾーン
+// 招待先は未登録の場合があるため、設定の既定値を使用する。
+func invitationLocation() *time.Location {
+	settings, apiErr := domainEntity.NewUserSettings()
+	if apiErr != nil {
+		return time.UTC
+	}
+	return settings.Location()
+}
+
+// リクエストの対象となるテナントの解決
+// 組織IDまたはスラッグで指定された組織に、ユーザーが所属していることを確認する。
+func (orgs *OrganizationService) ResolveTenant(ctx context.Context, userId string, organizationId string, slug string) (tenant.Tenant, *errors.ApiErr) {
+	if organizationId == "" && slug != "" {
+		organization, err := orgs.organizationRepository.FindOrganizationBySlug(ctx, slug)
+		if err != nil {
+			log.WithError(err).Info("Failed to find organization by slug")
+			return tenant.Tenant{}, notFoundError("organization", "組織が存在しません")
+		}
+		organizationId = organization.OrganizationId
+	}
+	if organizationId == "" {
+		return tenant.Tenant{}, tenantRequiredError()
+	}
+
+	membership, err := orgs.organizationMembershipRepository.FindMembership(
+		tenant.WithTenant(ctx, tenant.Tenant{OrganizationId: organizationId}),
+		userId,
+	)
+	if err != nil {
+		log.WithError(err).WithField("organizationId", organizationId).Warn("User is not a member of the organization")
+		return tenant.Tenant{}, errors.OutputApiError(
+			[]errors.ApiErrMessage{
+				{
+					Key:   "organization",
+					Value: "この組織へのアクセス権がありません",
+				},
+			},
+			status.ErrorStatusMap["FORBIDDEN"].StatusCode,
+			status.ErrorStatusMap["FORBIDDEN"].StatusName,
+		)
+	}
+
+	return tenant.Tenant{OrganizationId: organizationId, Role: membership.Role}, nil
+}
+
+func unauthorizedError() *errors.ApiErr {
+	return errors.OutputApiError(
+		[]errors.ApiErrMessage{
+			{
+				Key:   "authorization",
+				Value: "ログインしてください",
+			},
+		},
+		status.ErrorStatusMap["UNAUTHORIZED"].StatusCode,
+		status.ErrorStatusMap["UNAUTHORIZED"].StatusName,
+	)
+}
+
+func tenantRequiredError() *errors.ApiErr {
+	return errors.OutputApiError(
+		[]errors.ApiErrMessage{
+			{
+				Key:   "organization",
+				Value: "組織を指定してください",
+			},
+		},
+		status.ErrorStatusMap["BAD_REQUEST"].StatusCode,
+		status.ErrorStatusMap["BAD_REQUEST"].StatusName,
+	)
+}
+
+func notFoundError(key string, value string) *errors.ApiErr {
+	return errors.OutputApiError(
+		[]errors.ApiErrMessage{
+			{
+				Key:   key,
+				Value: value,
+			},
+		},
+		status.ErrorStatusMap["NOT_FOUND"].StatusCode,
+		status.ErrorStatusMap["NOT_FOUND"].StatusName,
+	)
+}
@@ -0,0 +1,537 @@
+package organization_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Go_CleanArch/common/auth"
+	"github.com/Go_CleanArch/common/crypto"
+	"github.com/Go_CleanArch/common/errors"
+	"github.com/Go_CleanArch/common/tenant"
+	domainEntity "github.com/Go_CleanArch/domain/entity"
+	"github.com/Go_CleanArch/interface_adapter/gateway/entity"
+	inputOrganization "github.com/Go_CleanArch/usecase/input/organization"
+	queryEntity "github.com/Go_CleanArch/usecase/query/entity"
+	repository "github.com/Go_CleanArch/usecase/repository_interface"
+	organization_service_impl "github.com/Go_CleanArch/usecase/service/organization"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockOrganizationRepository struct {
+	mock.Mock
+}
+
+func (m *MockOrganizationRepository) CreateOrganization(ctx context.Context, organizationJson []byte, ownerMembershipJson []byte) (*entity.Organization, error) {
+	args := m.Called(ctx, organizationJson, ownerMembershipJson)
+	return args.Get(0).(*entity.Organization), args.Error(1)
+}
+
+func (m *MockOrganizationRepository) FindOrganizationBySlug(ctx context.Context, slug string) (*entity.Organization, error) {
+	args := m.Called(ctx, slug)
+	return args.Get(0).(*entity.Organization), args.Error(1)
+}
+
+func (m *MockOrganizationRepository) FindOrganizationById(ctx context.Context, organizationId string) (*entity.Organization, error) {
+	args := m.Called(ctx, organizationId)
+	return args.Get(0).(*entity.Organization), args.Error(1)
+}
+
+type MockOrganizationMembershipRepository struct {
+	mock.Mock
+}
+
+func (m *MockOrganizationMembershipRepository) CreateMembership(ctx context.Context, membershipJson []byte) (*entity.OrganizationMembership, error) {
+	args := m.Called(ctx, membershipJson)
+	return args.Get(0).(*entity.OrganizationMembership), args.Error(1)
+}
+
+func (m *MockOrganizationMembershipRepository) FindMembership(ctx context.Context, userId string) (*entity.OrganizationMembership, error) {
+	args := m.Called(ctx, userId)
+	return args.Get(0).(*entity.OrganizationMembership), args.Error(1)
+}
+
+type MockOrganizationInvitationRepository struct {
+	mock.Mock
+}
+
+func (m *MockOrganizationInvitationRepository) CreateInvitation(ctx context.Context, invitationJson []byte) (*entity.OrganizationInvitation, error) {
+	args := m.Called(ctx, invitationJson)
+	return args.Get(0).(*entity.OrganizationInvitation), args.Error(1)
+}
+
+func (m *MockOrganizationInvitationRepository) FindInvitationByTokenHash(ctx context.Context, tokenHash string) (*entity.OrganizationInvitation, error) {
+	args := m.Called(ctx, tokenHash)
+	return args.Get(0).(*entity.OrganizationInvitation), args.Error(1)
+}
+
+func (m *MockOrganizationInvitationRepository) AcceptInvitation(ctx context.Context, invitation *entity.OrganizationInvitation, membershipJson []byte) error {
+	args := m.Called(ctx, invitation, membershipJson)
+	return args.Error(0)
+}
+
+type MockMailSender struct {
+	mock.Mock
+}
+
+func (m *MockMailSender) SendMail(ctx context.Context, message repository.MailMessage) error {
+	args := m.Called(ctx, message)
+	return args.Error(0)
+}
+
+type MockUserRepository struct {
+	mock.Mock
+}
+
+func (m *MockUserRepository) FindUserByEmail(ctx context.Context, email string) (*entity.User, error) {
+	args := m.Called(ctx, email)
+	return args.Get(0).(*entity.User), args.Error(1)
+}
+
+func (m *MockUserRepository) FindUserById(ctx context.Context, userId string) (*entity.User, error) {
+	args := m.Called(ctx, userId)
+	return args.Get(0).(*entity.User), args.Error(1)
+}
+
+func (m *MockUserRepository) CreateUser(ctx context.Context, userJson []byte) (*entity.User, error) {
+	args := m.Called(ctx, userJson)
+	return args.Get(0).(*entity.User), args.Error(1)
+}
+
+func (m *MockUserRepository) UpdateUserEmail(ctx context.Context, userId string, email string) error {
+	args := m.Called(ctx, userId, email)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) UpdateUserAvatarKey(ctx context.Context, userId string, avatarKey string) error {
+	args := m.Called(ctx, userId, avatarKey)
+	return args.Error(0)
+}
+
+type MockOrganizationQuery struct {
+	mock.Mock
+}
+
+func (m *MockOrganizationQuery) FindUserOrganizations(ctx context.Context, userId string) ([]queryEntity.UserOrganization, error) {
+	args := m.Called(ctx, userId)
+	return args.Get(0).([]queryEntity.UserOrganization), args.Error(1)
+}
+
+func (m *MockOrganizationQuery) FindOrganizationMembers(ctx context.Context, organizationId string) ([]queryEntity.OrganizationMember, error) {
+	args := m.Called(ctx, organizationId)
+	return args.Get(0).([]queryEntity.OrganizationMember), args.Error(1)
+}
+
+type organizationServiceMocks struct {
+	organizationRepo *MockOrganizationRepository
+	membershipRepo   *MockOrganizationMembershipRepository
+	invitationRepo   *MockOrganizationInvitationRepository
+	userRepo         *MockUserRepository
+	organizationQry  *MockOrganizationQuery
+	mailSender       *MockMailSender
+}
+
+func newOrganizationService(t *testing.T) (*organization_service_impl.OrganizationService, organizationServiceMocks, *auth.TokenIssuer) {
+	mocks := organizationServiceMocks{
+		organizationRepo: new(MockOrganizationRepository),
+		membershipRepo:   new(MockOrganizationMembershipRepository),
+		invitationRepo:   new(MockOrganizationInvitationRepository),
+		userRepo:         new(MockUserRepository),
+		organizationQry:  new(MockOrganizationQuery),
+		mailSender:       new(MockMailSender),
+	}
+	tokenIssuer, err := auth.NewTokenIssuer("test-secret-0123456789", time.Hour)
+	assert.NoError(t, err)
+	service := organization_service_impl.NewOrganizationService(
+		mocks.organizationRepo,
+		mocks.membershipRepo,
+		mocks.invitationRepo,
+		mocks.userRepo,
+		mocks.organizationQry,
+		mocks.mailSender,
+		tokenIssuer,
+		"https://app.example.com",
+	)
+	return service, mocks, tokenIssuer
+}
+
+// コンテキストのテナントが指定した組織であるか
+func tenantOf(organizationId string) interface{} {
+	return mock.MatchedBy(func(ctx context.Context) bool {
+		current, ok := tenant.FromContext(ctx)
+		return ok && current.OrganizationId == organizationId
+	})
+}
+
+const (
+	userId         = "0192a7e4-5c1b-7d2e-9f3a-4b5c6d7e8f90"
+	organizationId = "0192a7e4-0000-7000-8000-000000000001"
+)
+
+// responseStatusOf はエラーを ErrorMiddleware でレスポンスに変換した場合のステータスを返す(ApiErr を保持しないエラーは 500)
+func responseStatusOf(err error) int {
+	if apiErr, ok := errors.ApiErrFrom(err); ok {
+		return apiErr.Status
+	}
+	return 500
+}
+
+func TestCreateOrganizationService(t *testing.T) {
+	t.Parallel()
+
+	t.Run("組織作成_正常系", func(t *testing.T) {
+		t.Parallel()
+		ctx := auth.WithUserId(context.Background(), userId)
+		service, mocks, _ := newOrganizationService(t)
+		createOrganizationForm := inputOrganization.CreateOrganizationForm{Name: "株式会社サンプル", Slug: "sample"}
+
+		mocks.organizationRepo.On("FindOrganizationBySlug", ctx, "sample").Return((*entity.Organization)(nil), fmt.Errorf("条件に一致するレコードが見つかりません: record not found"))
+		// 作成する組織をテナントとして、作成者をオーナーとして登録する
+		mocks.organizationRepo.On("CreateOrganization", mock.MatchedBy(func(ctx context.Context) bool {
+			current, ok := tenant.FromContext(ctx)
+			return ok && current.OrganizationId != "" && current.Role == domainEntity.OrganizationRoleOwner
+		}), mock.Anything, mock.MatchedBy(func(ownerJson []byte) bool {
+			var owner entity.OrganizationMembership
+			return json.Unmarshal(ownerJson, &owner) == nil && owner.UserId == userId && owner.Role == domainEntity.OrganizationRoleOwner
+		})).Return(&entity.Organization{}, nil)
+
+		presenter, err := service.CreateOrganizationService(ctx, createOrganizationForm)
+
+		assert.NoError(t, err)
+		assert.NotEmpty(t, presenter.OrganizationId)
+		assert.Equal(t, "sample", presenter.Slug)
+		assert.Equal(t, domainEntity.OrganizationRoleOwner, presenter.Role)
+		mocks.organizationRepo.AssertExpectations(t)
+	})
+
+	t.Run("組織作成_スラッグ重複", func(t *testing.T) {
+		t.Parallel()
+		ctx := auth.WithUserId(context.Background(), userId)
+		service, mocks, _ := newOrganizationService(t)
+		createOrganizationForm := inputOrganization.CreateOrganizationForm{Name: "株式会社サンプル", Slug: "sample"}
+
+		mocks.organizationRepo.On("FindOrganizationBySlug", ctx, "sample").Return(&entity.Organization{OrganizationId: organizationId}, nil)
+
+		_, err := service.CreateOrganizationService(ctx, createOrganizationForm)
+
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusBadRequest, responseStatusOf(err))
+		mocks.organizationRepo.AssertNotCalled(t, "CreateOrganization")
+	})
+
+	t.Run("組織作成_予約済みのスラッグ", func(t *testing.T) {
+		t.Parallel()
+		ctx := auth.WithUserId(context.Background(), userId)
+		service, mocks, _ := newOrganizationService(t)
+		createOrganizationForm := inputOrganization.CreateOrganizationForm{Name: "管理", Slug: "admin"}
+
+		mocks.organizationRepo.On("FindOrganizationBySlug", ctx, "admin").Return((*entity.Organization)(nil), fmt.Errorf("条件に一致するレコードが見つかりません: record not found"))
+
+		_, err := service.CreateOrganizationService(ctx, createOrganizationForm)
+
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusBadRequest, responseStatusOf(err))
+		mocks.organizationRepo.AssertNotCalled(t, "CreateOrganization")
+	})
+}
+
+func TestResolveTenant(t *testing.T) {
+	t.Parallel()
+
+	t.Run("テナント解決_スラッグから組織を解決する", func(t *testing.T) {
+		t.Parallel()
+		ctx := context.Background()
+		service, mocks, _ := newOrganizationService(t)
+
+		mocks.organizationRepo.On("FindOrganizationBySlug", ctx, "sample").Return(&entity.Organization{OrganizationId: organizationId}, nil)
+		mocks.membershipRepo.On("FindMembership", tenantOf(organizationId), userId).Return(&entity.OrganizationMembership{
+			OrganizationId: organizationId,
+			UserId:         userId,
+			Role:           domainEntity.OrganizationRoleAdmin,
+		}, nil)
+
+		resolved, apiErr := service.ResolveTenant(ctx, userId, "", "sample")
+
+		assert.Nil(t, apiErr)
+		assert.Equal(t, tenant.Tenant{OrganizationId: organizationId, Role: domainEntity.OrganizationRoleAdmin}, resolved)
+	})
+
+	t.Run("テナント解決_所属していない組織", func(t *testing.T) {
+		t.Parallel()
+		ctx := context.Background()
+		service, mocks, _ := newOrganizationService(t)
+
+		mocks.membershipRepo.On("FindMembership", tenantOf(organizationId), userId).Return((*entity.OrganizationMembership)(nil), fmt.Errorf("条件に一致するレコードが見つかりません: record not found"))
+
+		_, apiErr := service.ResolveTenant(ctx, userId, organizationId, "")
+
+		assert.NotNil(t, apiErr)
+		assert.Equal(t, http.StatusForbidden, apiErr.Status)
+	})
+
+	t.Run("テナント解決_組織の指定なし", func(t *testing.T) {
+		t.Parallel()
+		service, mocks, _ := newOrganizationService(t)
+
+		_, apiErr := service.ResolveTenant(context.Background(), userId, "", "")
+
+		assert.NotNil(t, apiErr)
+		assert.Equal(t, http.StatusBadRequest, apiErr.Status)
+		mocks.membershipRepo.AssertNotCalled(t, "FindMembership")
+	})
+}
+
+func TestSwitchOrganizationService(t *testing.T) {
+	t.Parallel()
+
+	t.Run("組織切り替え_組織を指定したアクセストークンを発行する", func(t *testing.T) {
+		t.Parallel()
+		ctx := auth.WithUserId(context.Background(), userId)
+		service, mocks, tokenIssuer := newOrganizationService(t)
+
+		mocks.membershipRepo.On("FindMembership", tenantOf(organizationId), userId).Return(&entity.OrganizationMembership{
+			OrganizationId: organizationId,
+			UserId:         userId,
+			Role:           domainEntity.OrganizationRoleMember,
+		}, nil)
+
+		presenter, err := service.SwitchOrganizationService(ctx, organizationId)
+
+		assert.NoError(t, err)
+		claims, err := tokenIssuer.Parse(presenter.AccessToken)
+		assert.NoError(t, err)
+		assert.Equal(t, userId, claims.Subject)
+		assert.Equal(t, organizationId, claims.OrganizationId)
+	})
+}
+
+func TestAddMemberService(t *testing.T) {
+	t.Parallel()
+
+	memberId := "0192a7e4-5c1b-7d2e-9f3a-000000000002"
+
+	t.Run("メンバー追加_正常系", func(t *testing.T) {
+		t.Parallel()
+		ctx := tenant.WithTenant(auth.WithUserId(context.Background(), userId), tenant.Tenant{
+			OrganizationId: organizationId,
+			Role:           domainEntity.OrganizationRoleOwner,
+		})
+		service, mocks, _ := newOrganizationService(t)
+		addMemberForm := inputOrganization.AddMemberForm{Email: "member@example.com", Role: domainEntity.OrganizationRoleMember}
+
+		mocks.userRepo.On("FindUserByEmail", ctx, "member@example.com").Return(&entity.User{UserId: memberId, Email: "member@example.com"}, nil)
+		mocks.membershipRepo.On("FindMembership", ctx, memberId).Return((*entity.OrganizationMembership)(nil), fmt.Errorf("条件に一致するレコードが見つかりません: record not found"))
+		mocks.membershipRepo.On("CreateMembership", ctx, mock.MatchedBy(func(membershipJson []byte) bool {
+			var membership entity.OrganizationMembership
+			return json.Unmarshal(membershipJson, &membership) == nil &&
+				membership.OrganizationId == organizationId &&
+				membership.UserId == memberId &&
+				membership.Role == domainEntity.OrganizationRoleMember
+		})).Return(&entity.OrganizationMembership{CreatedAt: time.Now()}, nil)
+
+		presenter, err := service.AddMemberService(ctx, addMemberForm)
+
+		assert.NoError(t, err)
+		assert.Equal(t, memberId, presenter.UserId)
+		mocks.membershipRepo.AssertExpectations(t)
+	})
+
+	t.Run("メンバー追加_一般メンバーは追加できない", func(t *testing.T) {
+		t.Parallel()
+		ctx := tenant.WithTenant(auth.WithUserId(context.Background(), userId), tenant.Tenant{
+			OrganizationId: organizationId,
+			Role:           domainEntity.OrganizationRoleMember,
+		})
+		service, mocks, _ := newOrganizationService(t)
+		addMemberForm := inputOrganization.AddMemberForm{Email: "member@example.com", Role: domainEntity.OrganizationRoleMember}
+
+		mocks.userRepo.On("FindUserByEmail", ctx, "member@example.com").Return(&entity.User{UserId: memberId}, nil)
+
+		_, err := service.AddMemberService(ctx, addMemberForm)
+
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusForbidden, responseStatusOf(err))
+		mocks.membershipRepo.AssertNotCalled(t, "CreateMembership")
+	})
+
+	t.Run("メンバー追加_オーナーは付与できない", func(t *testing.T) {
+		t.Parallel()
+		ctx := tenant.WithTenant(auth.WithUserId(context.Background(), userId), tenant.Tenant{
+			OrganizationId: organizationId,
+			Role:           domainEntity.OrganizationRoleAdmin,
+		})
+		service, mocks, _ := newOrganizationService(t)
+		addMemberForm := inputOrganization.AddMemberForm{Email: "member@example.com", Role: domainEntity.OrganizationRoleOwner}
+
+		mocks.userRepo.On("FindUserByEmail", ctx, "member@example.com").Return(&entity.User{UserId: memberId}, nil)
+
+		_, err := service.AddMemberService(ctx, addMemberForm)
+
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusBadRequest, responseStatusOf(err))
+		mocks.membershipRepo.AssertNotCalled(t, "CreateMembership")
+	})
+}
+
+func TestInviteMemberService(t *testing.T) {
+	t.Parallel()
+
+	t.Run("メンバー招待_正常系", func(t *testing.T) {
+		t.Parallel()
+		ctx := tenant.WithTenant(auth.WithUserId(context.Background(), userId), tenant.Tenant{
+			OrganizationId: organizationId,
+			Role:           domainEntity.OrganizationRoleAdmin,
+		})
+		service, mocks, _ := newOrganizationService(t)
+		inviteMemberForm := inputOrganization.InviteMemberForm{Email: "new@example.com", Role: domainEntity.OrganizationRoleMember}
+
+		var storedHash string
+		mocks.userRepo.On("FindUserByEmail", ctx, "new@example.com").Return((*entity.User)(nil), fmt.Errorf("条件に一致するレコードが見つかりません: record not found"))
+		mocks.organizationRepo.On("FindOrganizationById", ctx, organizationId).Return(&entity.Organization{OrganizationId: organizationId, Name: "株式会社サンプル"}, nil)
+		// トークンはハッシュ値のみを保存する
+		mocks.invitationRepo.On("CreateInvitation", ctx, mock.MatchedBy(func(invitationJson []byte) bool {
+			var invitation entity.OrganizationInvitation
+			if json.Unmarshal(invitationJson, &invitation) != nil {
+				return false
+			}
+			storedHash = invitation.TokenHash
+			return invitation.OrganizationId == organizationId &&
+				invitation.Email == "new@example.com" &&
+				invitation.Role == domainEntity.OrganizationRoleMember &&
+				invitation.InvitedBy == userId &&
+				invitation.TokenHash != "" &&
+				!strings.Contains(string(invitationJson), `"Token"`)
+		})).Return(&entity.OrganizationInvitation{}, nil)
+		// 承諾リンクのトークンが保存したハッシュ値と対応する
+		mocks.mailSender.On("SendMail", ctx, mock.MatchedBy(func(message repository.MailMessage) bool {
+			_, token, found := strings.Cut(message.Body, "/invitations/accept?token=")
+			if !found {
+				return false
+			}
+			token, _, _ = strings.Cut(token, "\n")
+			return message.To == "new@example.com" &&
+				strings.Contains(message.Subject, "株式会社サンプル") &&
+				crypto.HashToken(token) == storedHash
+		})).Return(nil)
+
+		presenter, err := service.InviteMemberService(ctx, inviteMemberForm)
+
+		assert.NoError(t, err)
+		assert.NotEmpty(t, presenter.InvitationId)
+		assert.Equal(t, domainEntity.OrganizationRoleMember, presenter.Role)
+		mocks.invitationRepo.AssertExpectations(t)
+		mocks.mailSender.AssertExpectations(t)
+	})
+
+	t.Run("メンバー招待_一般メンバーは招待できない", func(t *testing.T) {
+		t.Parallel()
+		ctx := tenant.WithTenant(auth.WithUserId(context.Background(), userId), tenant.Tenant{
+			OrganizationId: organizationId,
+			Role:           domainEntity.OrganizationRoleMember,
+		})
+		service, mocks, _ := newOrganizationService(t)
+		inviteMemberForm := inputOrganization.InviteMemberForm{Email: "new@example.com", Role: domainEntity.OrganizationRoleMember}
+
+		_, err := service.InviteMemberService(ctx, inviteMemberForm)
+
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusForbidden, responseStatusOf(err))
+		mocks.invitationRepo.AssertNotCalled(t, "CreateInvitation")
+		mocks.mailSender.AssertNotCalled(t, "SendMail")
+	})
+}
+
+func TestAcceptInvitationService(t *testing.T) {
+	t.Parallel()
+
+	const token = "invitation-token"
+	invitation := func(email string, expiresAt time.Time) *entity.OrganizationInvitation {
+		return &entity.OrganizationInvitation{
+			InvitationId:   "0192a7e4-0000-7000-8000-00000000000a",
+			OrganizationId: organizationId,
+			Email:          email,
+			Role:           domainEntity.OrganizationRoleAdmin,
+			TokenHash:      crypto.HashToken(token),
+			ExpiresAt:      expiresAt,
+		}
+	}
+	acceptInvitationForm := inputOrganization.AcceptInvitationForm{Token: token}
+
+	t.Run("招待承諾_正常系", func(t *testing.T) {
+		t.Parallel()
+		ctx := auth.WithUserId(context.Background(), userId)
+		service, mocks, _ := newOrganizationService(t)
+		found := invitation("Member@Example.com", time.Now().Add(time.Hour))
+
+		mocks.userRepo.On("FindUserById", ctx, userId).Return(&entity.User{UserId: userId, Email: "member@example.com"}, nil)
+		mocks.invitationRepo.On("FindInvitationByTokenHash", ctx, crypto.HashToken(token)).Return(found, nil)
+		mocks.membershipRepo.On("FindMembership", tenantOf(organizationId), userId).Return((*entity.OrganizationMembership)(nil), fmt.Errorf("条件に一致するレコードが見つかりません: record not found"))
+		// 招待時に指定されたロールで所属させる
+		mocks.invitationRepo.On("AcceptInvitation", tenantOf(organizationId), found, mock.MatchedBy(func(membershipJson []byte) bool {
+			var membership entity.OrganizationMembership
+			return json.Unmarshal(membershipJson, &membership) == nil &&
+				membership.OrganizationId == organizationId &&
+				membership.UserId == userId &&
+				membership.Role == domainEntity.OrganizationRoleAdmin
+		})).Return(nil)
+		mocks.organizationRepo.On("FindOrganizationById", tenantOf(organizationId), organizationId).Return(&entity.Organization{OrganizationId: organizationId, Name: "株式会社サンプル", Slug: "sample"}, nil)
+
+		presenter, err := service.AcceptInvitationService(ctx, acceptInvitationForm)
+
+		assert.NoError(t, err)
+		assert.Equal(t, organizationId, presenter.OrganizationId)
+		assert.Equal(t, domainEntity.OrganizationRoleAdmin, presenter.Role)
+		mocks.invitationRepo.AssertExpectations(t)
+	})
+
+	t.Run("招待承諾_招待先と異なるユーザー", func(t *testing.T) {
+		t.Parallel()
+		ctx := auth.WithUserId(context.Background(), userId)
+		service, mocks, _ := newOrganizationService(t)
+
+		mocks.userRepo.On("FindUserById", ctx, userId).Return(&entity.User{UserId: userId, Email: "other@example.com"}, nil)
+		mocks.invitationRepo.On("FindInvitationByTokenHash", ctx, crypto.HashToken(token)).Return(invitation("member@example.com", time.Now().Add(time.Hour)), nil)
+
+		_, err := service.AcceptInvitationService(ctx, acceptInvitationForm)
+
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusBadRequest, responseStatusOf(err))
+		mocks.invitationRepo.AssertNotCalled(t, "AcceptInvitation")
+	})
+
+	t.Run("招待承諾_有効期限切れ", func(t *testing.T) {
+		t.Parallel()
+		ctx := auth.WithUserId(context.Background(), userId)
+		service, mocks, _ := newOrganizationService(t)
+
+		mocks.userRepo.On("FindUserById", ctx, userId).Return(&entity.User{UserId: userId, Email: "member@example.com"}, nil)
+		mocks.invitationRepo.On("FindInvitationByTokenHash", ctx, crypto.HashToken(token)).Return(invitation("member@example.com", time.Now().Add(-time.Minute)), nil)
+
+		_, err := service.AcceptInvitationService(ctx, acceptInvitationForm)
+
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusBadRequest, responseStatusOf(err))
+		mocks.invitationRepo.AssertNotCalled(t, "AcceptInvitation")
+	})
+
+	t.Run("招待承諾_同時に承諾された場合", func(t *testing.T) {
+		t.Parallel()
+		ctx := auth.WithUserId(context.Background(), userId)
+		service, mocks, _ := newOrganizationService(t)
+
+		mocks.userRepo.On("FindUserById", ctx, userId).Return(&entity.User{UserId: userId, Email: "member@example.com"}, nil)
+		mocks.invitationRepo.On("FindInvitationByTokenHash", ctx, crypto.HashToken(token)).Return(invitation("member@example.com", time.Now().Add(time.Hour)), nil)
+		mocks.membershipRepo.On("FindMembership", tenantOf(organizationId), userId).Return((*entity.OrganizationMembership)(nil), fmt.Errorf("条件に一致するレコードが見つかりません: record not found"))
+		mocks.invitationRepo.On("AcceptInvitation", tenantOf(organizationId), mock.Anything, mock.Anything).Return(repository.ErrInvitationAlreadyAccepted)
+
+		_, err := service.AcceptInvitationService(ctx, acceptInvitationForm)
+
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusBadRequest, responseStatusOf(err))
+	})
+}
@@ -0,0 +1,251 @@
+package user
+
+import (
+	"context"
+	goErrors "errors"
+	"fmt"
+	"time"
+
+	"github.com/Go_CleanArch/common/auth"
+	status "github.com/Go_CleanArch/common/const"
+	"github.com/Go_CleanArch/common/crypto"
+	"github.com/Go_CleanArch/common/errors"
+	changeEmailFactory "github.com/Go_CleanArch/domain/factory/user/change_email"
+	createUserFactory "github.com/Go_CleanArch/domain/factory/user/create_user"
+	"github.com/Go_CleanArch/interface_adapter/gateway/entity"
+	inputUser "github.com/Go_CleanArch/usecase/input/user"
+	outputUser "github.com/Go_CleanArch/usecase/output/user"
+	repository "github.com/Go_CleanArch/usecase/repository_interface"
+	log "github.com/sirupsen/logrus"
+)
+
+// EmailChangeService provides email change behavior
+type EmailChangeService struct {
+	userRepository               repository.UserRepositoryInterface
+	emailChangeRequestRepository repository.EmailChangeRequestRepositoryInterface
+	mailSender                   repository.MailSenderInterface
+	userSettingRepository        repository.UserSettingRepositoryInterface
+	transactionManager           repository.TransactionManagerInterface
+	outboxRepository             repository.OutboxRepositoryInterface
+	// メール本文に記載する確認・取消リンクのベースURL
+	linkBaseUrl string
+	factory     *changeEmailFactory.ChangeEmailFactory
+}
+
+// Constructor
+func NewEmailChangeService(
+	userRepository repository.UserRepositoryInterface,
+	emailChangeRequestRepository repository.EmailChangeRequestRepositoryInterface,
+	mailSender repository.MailSenderInterface,
+	userSettingRepository repository.UserSettingRepositoryInterface,
+	transactionManager repository.TransactionManagerInterface,
+	outboxRepository repository.OutboxRepositoryInterface,
+	linkBaseUrl string,
+) *EmailChangeService {
+	return &EmailChangeService{
+		userRepository:               userRepository,
+		emailChangeRequestRepository: emailChangeRequestRepository,
+		mailSender:                   mailSender,
+		userSettingRepository:        userSettingRepository,
+		transactionManager:           transactionManager,
+		outboxRepository:             outboxRepository,
+		linkBaseUrl:                  linkBaseUrl,
+		factory:                      changeEmailFactory.NewChangeEmailFactory(),
+	}
+}
+
+// メールアドレス変更申請
+// 変更先アドレスへ確認トークンを、変更前アドレスへ取消リンク付きの通知を送信する。
+func (es *EmailChangeService) RequestEmailChangeService(ctx context.Context, changeEmailForm inputUser.ChangeEmailForm) (outputUser.EmailChangeRequestPresenter, error) {
+	var presenter outputUser.EmailChangeRequestPresenter
+
+	userId, ok := auth.UserIdFromContext(ctx)
+	if !ok {
+		apiErr := unauthorizedError()
+		return presenter, apiErr.Error()
+	}
+
+	user, err := es.userRepository.FindUserById(ctx, userId)
+	if err != nil {
+		log.WithError(err).Error("Failed to find user by id")
+		apiErr := notFoundError("userId", "ユーザーが存在しません")
+		return presenter, apiErr.Error()
+	}
+
+	// 変更先のメールアドレスが登録済みでないかチェック
+	findUser, err := es.userRepository.FindUserByEmail(ctx, changeEmailForm.Email)
+	if err != nil {
+		log.WithError(err).Info("New email is not registered")
+	}
+	existUserId := ""
+	if findUser != nil {
+		existUserId = findUser.UserId
+	}
+
+	request, apiErr := es.factory.RequestEmailChange(&changeEmailFactory.ChangeEmailInitProps{
+		UserId:       user.UserId,
+		CurrentEmail: user.Email,
+		NewEmail:     changeEmailForm.Email,
+		ExistUserId:  existUserId,
+	})
+	if apiErr != nil {
+		log.WithField("apiErr", apiErr).Error("Failed to build email change request")
+		return presenter, apiErr.Error()
+	}
+
+	requestJson, err := crypto.ConvertStructIntoJson(request)
+	if err != nil {
+		log.WithError(err).Error("Failed to convert email change request into JSON")
+		return presenter, err
+	}
+	if _, err := es.emailChangeRequestRepository.CreateEmailChangeRequest(ctx, requestJson); err != nil {
+		log.WithError(err).Error("Failed to create email change request")
+		return presenter, err
+	}
+
+	// 変更先アドレスへの確認メール、変更前アドレスへの通知メール(有効期限はユーザーのタイムゾーンで表記する)
+	settings := userSettingsOrDefault(ctx, es.userSettingRepository, user.UserId)
+	messages := []repository.MailMessage{
+		confirmEmailChangeMail(request.NewEmail, linkWithToken(es.linkBaseUrl, "/email-change/confirm", request.ConfirmToken), request.ExpiresAt.In(settings.Location())),
+		noticeEmailChangeMail(request.CurrentEmail, request.NewEmail, linkWithToken(es.linkBaseUrl, "/email-change/cancel", request.CancelToken)),
+	}
+	for _, message := range messages {
+		if err := es.mailSender.SendMail(ctx, message); err != nil {
+			log.WithError(err).Error("Failed to send email change mail")
+			return presenter, err
+		}
+	}
+
+	presenter.NewEmail = request.NewEmail
+	presenter.ExpiresAt = request.ExpiresAt
+	log.WithField("userId", userId).Info("Email change requested successfully")
+	return presenter, nil
+}
+
+// メールアドレス変更の確定
+func (es *EmailChangeService) ConfirmEmailChangeService(ctx context.Context, tokenForm inputUser.EmailChangeTokenForm) (outputUser.EmailChangeResultPresenter, error) {
+	var presenter outputUser.EmailChangeResultPresenter
+
+	request, err := es.emailChangeRequestRepository.FindEmailChangeRequestByConfirmTokenHash(ctx, crypto.HashToken(tokenForm.Token))
+	if err != nil {
+		log.WithError(err).Error("Failed to find email change request by confirm token")
+		apiErr := notFoundError("token", "無効なトークンです")
+		return presenter, apiErr.Error()
+	}
+	if apiErr := es.factory.CheckConfirmable(requestState(request)); apiErr != nil {
+		log.WithField("apiErr", apiErr).Error("Email change request is not confirmable")
+		return presenter, apiErr.Error()
+	}
+
+	// ユーザーのメールアドレス・申請の確定・メールアドレス変更のイベントを同一トランザクションで保存する
+	emailChanged := es.factory.EmailChanged(request.UserId, request.CurrentEmail, request.NewEmail)
+	err = es.transactionManager.RunInTransaction(ctx, func(ctx context.Context) error {
+		// 申請後に別の申請でメールアドレスが変更されていないかチェック
+		currentUser, err := es.userRepository.FindUserById(ctx, request.UserId)
+		if goErrors.Is(err, repository.ErrNotFound) {
+			apiErr := notFoundError("userId", "ユーザーが存在しません")
+			return apiErr.Error()
+		} else if err != nil {
+			return fmt.Errorf("ユーザーの取得に失敗しました: %w", err)
+		}
+		if apiErr := es.factory.CheckCurrentEmail(request.CurrentEmail, currentUser.Email); apiErr != nil {
+			return apiErr.Error()
+		}
+		// 申請後に同じアドレスで登録されていないか再チェック(見つからない場合のみ変更できる)
+		_, err = es.userRepository.FindUserByEmail(ctx, request.NewEmail)
+		if err == nil {
+			return repository.ErrEmailAlreadyExists
+		} else if !goErrors.Is(err, repository.ErrNotFound) {
+			return fmt.Errorf("変更先のメールアドレスの確認に失敗しました: %w", err)
+		}
+		if err := es.userRepository.UpdateUserEmail(ctx, request.UserId, request.NewEmail); err != nil {
+			return err
+		}
+		request.ConfirmedAt = &emailChanged.ChangedAt
+		if err := es.emailChangeRequestRepository.UpdateEmailChangeRequest(ctx, request); err != nil {
+			return err
+		}
+		return es.outboxRepository.AddEvents(ctx, emailChanged)
+	})
+	if err != nil {
+		if goErrors.Is(err, repository.ErrEmailAlreadyExists) {
+			// 再チェック・データベースの一意制約のいずれかで、同じアドレスでの登録を検知した場合
+			apiErr := createUserFactory.EmailAlreadyExistsError()
+			log.WithField("apiErr", apiErr).Error("New email already exists")
+			return presenter, apiErr.Error()
+		}
+		if apiErr, ok := errors.ApiErrFrom(err); ok {
+			log.WithField("apiErr", apiErr).Error("Email change request is not confirmable")
+			return presenter, err
+		}
+		log.WithError(err).Error("Failed to confirm email change")
+		return presenter, err
+	}
+
+	presenter.UserId = request.UserId
+	presenter.Email = request.NewEmail
+	log.WithField("userId", request.UserId).Info("Email change confirmed successfully")
+	return presenter, nil
+}
+
+// メールアドレス変更の取消
+func (es *EmailChangeService) CancelEmailChangeService(ctx context.Context, tokenForm inputUser.EmailChangeTokenForm) (outputUser.EmailChangeResultPresenter, error) {
+	var presenter outputUser.EmailChangeResultPresenter
+
+	request, err := es.emailChangeRequestRepository.FindEmailChangeRequestByCancelTokenHash(ctx, crypto.HashToken(tokenForm.Token))
+	if err != nil {
+		log.WithError(err).Error("Failed to find email change request by cancel token")
+		apiErr := notFoundError("token", "無効なトークンです")
+		return presenter, apiErr.Error()
+	}
+	if apiErr := es.factory.CheckCancellable(requestState(request)); apiErr != nil {
+		log.WithField("apiErr", apiErr).Error("Email change request is not cancellable")
+		return presenter, apiErr.Error()
+	}
+
+	cancelledAt := time.Now()
+	request.CancelledAt = &cancelledAt
+	if err := es.emailChangeRequestRepository.UpdateEmailChangeRequest(ctx, request); err != nil {
+		log.WithError(err).Error("Failed to update email change request")
+		return presenter, err
+	}
+
+	presenter.UserId = request.UserId
+	presenter.Email = request.CurrentEmail
+	log.WithField("userId", request.UserId).Info("Email change cancelled successfully")
+	return presenter, nil
+}
+
+func requestState(request *entity.EmailChangeRequest) changeEmailFactory.EmailChangeRequestState {
+	return changeEmailFactory.EmailChangeRequestState{
+		ExpiresAt:   request.ExpiresAt,
+		ConfirmedAt: request.ConfirmedAt,
+		CancelledAt: request.CancelledAt,
+	}
+}
+
+func unauthorizedError() *errors.ApiErr {
+	return errors.OutputApiError(
+		[]errors.ApiErrMessage{
+			{
+				Key:   "authorization",
+				Value: "ログインしてください",
+			},
+		},
+		status.ErrorStatusMap["UNAUTHORIZED"].StatusCode,
+		status.ErrorStatusMap["UNAUTHORIZED"].StatusName,
+	)
+}
+
+func notFoundError(key string, value string) *errors.ApiErr {
+	return errors.OutputApiError(
+		[]errors.ApiErrMessage{
+			{
+				Key:   key,
+				Value: value,
+			},
+		},
+		status.ErrorStatusMap["NOT_FOUND"].StatusCode,
+		status.ErrorStatusMap["NOT_FOUND"].StatusName,
+	)
+}
@@ -0,0 +1,375 @@
+package user_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Go_CleanArch/common/auth"
+	"github.com/Go_CleanArch/common/crypto"
+	"github.com/Go_CleanArch/common/errors"
+	domainEntity "github.com/Go_CleanArch/domain/entity"
+	"github.com/Go_CleanArch/domain/event"
+	createUserFactory "github.com/Go_CleanArch/domain/factory/user/create_user"
+	"github.com/Go_CleanArch/interface_adapter/gateway/entity"
+	inputUser "github.com/Go_CleanArch/usecase/input/user"
+	repository "github.com/Go_CleanArch/usecase/repository_interface"
+	user_service_impl "github.com/Go_CleanArch/usecase/service/user"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockEmailChangeRequestRepository struct {
+	mock.Mock
+}
+
+func (m *MockEmailChangeRequestRepository) CreateEmailChangeRequest(ctx context.Context, requestJson []byte) (*entity.EmailChangeRequest, error) {
+	args := m.Called(ctx, requestJson)
+	return args.Get(0).(*entity.EmailChangeRequest), args.Error(1)
+}
+
+func (m *MockEmailChangeRequestRepository) FindEmailChangeRequestByConfirmTokenHash(ctx context.Context, tokenHash string) (*entity.EmailChangeRequest, error) {
+	args := m.Called(ctx, tokenHash)
+	return args.Get(0).(*entity.EmailChangeRequest), args.Error(1)
+}
+
+func (m *MockEmailChangeRequestRepository) FindEmailChangeRequestByCancelTokenHash(ctx context.Context, tokenHash string) (*entity.EmailChangeRequest, error) {
+	args := m.Called(ctx, tokenHash)
+	return args.Get(0).(*entity.EmailChangeRequest), args.Error(1)
+}
+
+func (m *MockEmailChangeRequestRepository) UpdateEmailChangeRequest(ctx context.Context, request *entity.EmailChangeRequest) error {
+	args := m.Called(ctx, request)
+	return args.Error(0)
+}
+
+type MockMailSender struct {
+	mock.Mock
+}
+
+func (m *MockMailSender) SendMail(ctx context.Context, message repository.MailMessage) error {
+	args := m.Called(ctx, message)
+	return args.Error(0)
+}
+
+type MockTransactionManager struct {
+	mock.Mock
+}
+
+func (m *MockTransactionManager) RunInTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	args := m.Called(ctx)
+	if err := fn(ctx); err != nil {
+		return err
+	}
+	return args.Error(0)
+}
+
+type MockOutboxRepository struct {
+	mock.Mock
+}
+
+func (m *MockOutboxRepository) AddEvents(ctx context.Context, events ...event.Event) error {
+	args := m.Called(ctx, events)
+	return args.Error(0)
+}
+
+func TestRequestEmailChangeService(t *testing.T) {
+	t.Parallel()
+
+	userId := "0192a7e4-5c1b-7d2e-9f3a-4b5c6d7e8f90"
+
+	t.Run("メールアドレス変更申請_正常系", func(t *testing.T) {
+		t.Parallel()
+		ctx := auth.WithUserId(context.Background(), userId)
+		mockUserRepo := new(MockUserRepository)
+		mockRequestRepo := new(MockEmailChangeRequestRepository)
+		mockMailSender := new(MockMailSender)
+		mockSettingRepo := new(MockUserSettingRepository)
+		service := user_service_impl.NewEmailChangeService(mockUserRepo, mockRequestRepo, mockMailSender, mockSettingRepo, new(MockTransactionManager), new(MockOutboxRepository), "http://localhost:3000")
+		changeEmailForm := inputUser.ChangeEmailForm{Email: "new@example.com"}
+
+		// モックの設定
+		mockUserRepo.On("FindUserById", ctx, userId).Return(&entity.User{UserId: userId, Email: "old@example.com"}, nil)
+		mockSettingRepo.On("FindUserSettings", ctx, userId).Return([]entity.UserSetting{
+			{UserId: userId, SettingKey: domainEntity.SettingTimezone, SettingValue: `"UTC"`},
+		}, nil)
+		mockUserRepo.On("FindUserByEmail", ctx, "new@example.com").Return((*entity.User)(nil), fmt.Errorf("条件に一致するレコードが見つかりません: record not found"))
+		mockRequestRepo.On("CreateEmailChangeRequest", ctx, mock.Anything).Return(&entity.EmailChangeRequest{}, nil)
+		mockMailSender.On("SendMail", ctx, mock.MatchedBy(func(message repository.MailMessage) bool {
+			// 有効期限はユーザーが設定したタイムゾーンで表記される
+			return message.To == "new@example.com" && strings.Contains(message.Body, "http://localhost:3000/email-change/confirm?token=") && strings.Contains(message.Body, " UTC")
+		})).Return(nil).Once()
+		mockMailSender.On("SendMail", ctx, mock.MatchedBy(func(message repository.MailMessage) bool {
+			return message.To == "old@example.com" && strings.Contains(message.Body, "http://localhost:3000/email-change/cancel?token=")
+		})).Return(nil).Once()
+
+		presenter, err := service.RequestEmailChangeService(ctx, changeEmailForm)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "new@example.com", presenter.NewEmail)
+		assert.True(t, presenter.ExpiresAt.After(time.Now()))
+		mockUserRepo.AssertExpectations(t)
+		mockRequestRepo.AssertExpectations(t)
+		mockMailSender.AssertExpectations(t)
+	})
+
+	t.Run("メールアドレス変更申請_未認証", func(t *testing.T) {
+		t.Parallel()
+		ctx := context.Background()
+		mockUserRepo := new(MockUserRepository)
+		mockRequestRepo := new(MockEmailChangeRequestRepository)
+		mockMailSender := new(MockMailSender)
+		service := user_service_impl.NewEmailChangeService(mockUserRepo, mockRequestRepo, mockMailSender, new(MockUserSettingRepository), new(MockTransactionManager), new(MockOutboxRepository), "")
+		changeEmailForm := inputUser.ChangeEmailForm{Email: "new@example.com"}
+
+		_, err := service.RequestEmailChangeService(ctx, changeEmailForm)
+
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusUnauthorized, responseStatusOf(err))
+		mockUserRepo.AssertNotCalled(t, "FindUserById")
+	})
+
+	t.Run("メールアドレス変更申請_変更先アドレス登録済み", func(t *testing.T) {
+		t.Parallel()
+		ctx := auth.WithUserId(context.Background(), userId)
+		mockUserRepo := new(MockUserRepository)
+		mockRequestRepo := new(MockEmailChangeRequestRepository)
+		mockMailSender := new(MockMailSender)
+		service := user_service_impl.NewEmailChangeService(mockUserRepo, mockRequestRepo, mockMailSender, new(MockUserSettingRepository), new(MockTransactionManager), new(MockOutboxRepository), "")
+		changeEmailForm := inputUser.ChangeEmailForm{Email: "taken@example.com"}
+
+		mockUserRepo.On("FindUserById", ctx, userId).Return(&entity.User{UserId: userId, Email: "old@example.com"}, nil)
+		mockUserRepo.On("FindUserByEmail", ctx, "taken@example.com").Return(&entity.User{UserId: "other"}, nil)
+
+		_, err := service.RequestEmailChangeService(ctx, changeEmailForm)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "すでに登録されているアドレスです")
+		mockRequestRepo.AssertNotCalled(t, "CreateEmailChangeRequest")
+		mockMailSender.AssertNotCalled(t, "SendMail")
+	})
+}
+
+func TestConfirmEmailChangeService(t *testing.T) {
+	t.Parallel()
+
+	token := "confirm-token"
+	userId := "0192a7e4-5c1b-7d2e-9f3a-4b5c6d7e8f90"
+
+	t.Run("メールアドレス変更確定_正常系", func(t *testing.T) {
+		t.Parallel()
+		ctx := context.Background()
+		mockUserRepo := new(MockUserRepository)
+		mockRequestRepo := new(MockEmailChangeRequestRepository)
+		mockTransactionManager := new(MockTransactionManager)
+		mockOutboxRepo := new(MockOutboxRepository)
+		service := user_service_impl.NewEmailChangeService(mockUserRepo, mockRequestRepo, new(MockMailSender), new(MockUserSettingRepository), mockTransactionManager, mockOutboxRepo, "")
+		tokenForm := inputUser.EmailChangeTokenForm{Token: token}
+
+		mockTransactionManager.On("RunInTransaction", ctx).Return(nil)
+		mockRequestRepo.On("FindEmailChangeRequestByConfirmTokenHash", ctx, crypto.HashToken(token)).Return(&entity.EmailChangeRequest{
+			UserId:       userId,
+			CurrentEmail: "old@example.com",
+			NewEmail:     "new@example.com",
+			ExpiresAt:    time.Now().Add(time.Hour),
+		}, nil)
+		// 申請時のアドレスは大文字・小文字を区別せずに照合する
+		mockUserRepo.On("FindUserById", ctx, userId).Return(&entity.User{UserId: userId, Email: "Old@example.com"}, nil)
+		mockUserRepo.On("FindUserByEmail", ctx, "new@example.com").Return((*entity.User)(nil), repository.ErrNotFound)
+		mockUserRepo.On("UpdateUserEmail", ctx, userId, "new@example.com").Return(nil)
+		mockRequestRepo.On("UpdateEmailChangeRequest", ctx, mock.MatchedBy(func(request *entity.EmailChangeRequest) bool {
+			return request.ConfirmedAt != nil
+		})).Return(nil)
+		// メールアドレス変更のイベントを同じトランザクションで保存する
+		mockOutboxRepo.On("AddEvents", ctx, mock.MatchedBy(func(events []event.Event) bool {
+			emailChanged, ok := events[0].(event.UserEmailChanged)
+			return len(events) == 1 && ok && emailChanged.UserId == userId &&
+				emailChanged.PreviousEmail == "old@example.com" && emailChanged.NewEmail == "new@example.com"
+		})).Return(nil)
+
+		presenter, err := service.ConfirmEmailChangeService(ctx, tokenForm)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "new@example.com", presenter.Email)
+		mockUserRepo.AssertExpectations(t)
+		mockRequestRepo.AssertExpectations(t)
+		mockTransactionManager.AssertExpectations(t)
+		mockOutboxRepo.AssertExpectations(t)
+	})
+
+	t.Run("メールアドレス変更確定_申請の更新に失敗", func(t *testing.T) {
+		t.Parallel()
+		ctx := context.Background()
+		mockUserRepo := new(MockUserRepository)
+		mockRequestRepo := new(MockEmailChangeRequestRepository)
+		mockTransactionManager := new(MockTransactionManager)
+		mockOutboxRepo := new(MockOutboxRepository)
+		service := user_service_impl.NewEmailChangeService(mockUserRepo, mockRequestRepo, new(MockMailSender), new(MockUserSettingRepository), mockTransactionManager, mockOutboxRepo, "")
+		tokenForm := inputUser.EmailChangeTokenForm{Token: token}
+
+		// メールアドレスの更新と申請の確定は同一トランザクションで行い、失敗した場合はまとめてロールバックされる
+		mockTransactionManager.On("RunInTransaction", ctx).Return(nil)
+		mockRequestRepo.On("FindEmailChangeRequestByConfirmTokenHash", ctx, crypto.HashToken(token)).Return(&entity.EmailChangeRequest{
+			UserId:       userId,
+			CurrentEmail: "old@example.com",
+			NewEmail:     "new@example.com",
+			ExpiresAt:    time.Now().Add(time.Hour),
+		}, nil)
+		mockUserRepo.On("FindUserById", ctx, userId).Return(&entity.User{UserId: userId, Email: "old@example.com"}, nil)
+		mockUserRepo.On("FindUserByEmail", ctx, "new@example.com").Return((*entity.User)(nil), repository.ErrNotFound)
+		mockUserRepo.On("UpdateUserEmail", ctx, userId, "new@example.com").Return(nil)
+		mockRequestRepo.On("UpdateEmailChangeRequest", ctx, mock.Anything).Return(fmt.Errorf("connection reset"))
+
+		_, err := service.ConfirmEmailChangeService(ctx, tokenForm)
+
+		assert.ErrorContains(t, err, "connection reset")
+		assert.Equal(t, http.StatusInternalServerError, responseStatusOf(err))
+		mockTransactionManager.AssertExpectations(t)
+		mockOutboxRepo.AssertNotCalled(t, "AddEvents")
+	})
+
+	t.Run("メールアドレス変更確定_変更先アドレス登録済み", func(t *testing.T) {
+		t.Parallel()
+		for name, setup := range map[string]func(*MockUserRepository, context.Context){
+			// 申請後に同じアドレスで登録された場合
+			"再チェック": func(mockUserRepo *MockUserRepository, ctx context.Context) {
+				mockUserRepo.On("FindUserByEmail", ctx, "new@example.com").Return(&entity.User{UserId: "other"}, nil)
+			},
+			// 再チェックの後に同じアドレスで登録された場合
+			"一意制約": func(mockUserRepo *MockUserRepository, ctx context.Context) {
+				mockUserRepo.On("FindUserByEmail", ctx, "new@example.com").Return((*entity.User)(nil), repository.ErrNotFound)
+				mockUserRepo.On("UpdateUserEmail", ctx, userId, "new@example.com").Return(repository.ErrEmailAlreadyExists)
+			},
+		} {
+			t.Run(name, func(t *testing.T) {
+				t.Parallel()
+				ctx := context.Background()
+				mockUserRepo := new(MockUserRepository)
+				mockRequestRepo := new(MockEmailChangeRequestRepository)
+				mockTransactionManager := new(MockTransactionManager)
+				service := user_service_impl.NewEmailChangeService(mockUserRepo, mockRequestRepo, new(MockMailSender), new(MockUserSettingRepository), mockTransactionManager, new(MockOutboxRepository), "")
+				tokenForm := inputUser.EmailChangeTokenForm{Token: token}
+
+				mockTransactionManager.On("RunInTransaction", ctx).Return(nil)
+				mockRequestRepo.On("FindEmailChangeRequestByConfirmTokenHash", ctx, crypto.HashToken(token)).Return(&entity.EmailChangeRequest{
+					UserId:       userId,
+					CurrentEmail: "old@example.com",
+					NewEmail:     "new@example.com",
+					ExpiresAt:    time.Now().Add(time.Hour),
+				}, nil)
+				mockUserRepo.On("FindUserById", ctx, userId).Return(&entity.User{UserId: userId, Email: "old@example.com"}, nil)
+				setup(mockUserRepo, ctx)
+
+				_, err := service.ConfirmEmailChangeService(ctx, tokenForm)
+
+				apiErr, ok := errors.ApiErrFrom(err)
+				assert.True(t, ok)
+				assert.Equal(t, createUserFactory.EmailAlreadyExistsError(), apiErr)
+				mockRequestRepo.AssertNotCalled(t, "UpdateEmailChangeRequest")
+			})
+		}
+	})
+
+	t.Run("メールアドレス変更確定_申請後にメールアドレスが変更された", func(t *testing.T) {
+		t.Parallel()
+		ctx := context.Background()
+		mockUserRepo := new(MockUserRepository)
+		mockRequestRepo := new(MockEmailChangeRequestRepository)
+		mockTransactionManager := new(MockTransactionManager)
+		service := user_service_impl.NewEmailChangeService(mockUserRepo, mockRequestRepo, new(MockMailSender), new(MockUserSettingRepository), mockTransactionManager, new(MockOutboxRepository), "")
+		tokenForm := inputUser.EmailChangeTokenForm{Token: token}
+
+		mockTransactionManager.On("RunInTransaction", ctx).Return(nil)
+		mockRequestRepo.On("FindEmailChangeRequestByConfirmTokenHash", ctx, crypto.HashToken(token)).Return(&entity.EmailChangeRequest{
+			UserId:       userId,
+			CurrentEmail: "old@example.com",
+			NewEmail:     "new@example.com",
+			ExpiresAt:    time.Now().Add(time.Hour),
+		}, nil)
+		// 別の申請で変更済み
+		mockUserRepo.On("FindUserById", ctx, userId).Return(&entity.User{UserId: userId, Email: "other@example.com"}, nil)
+
+		_, err := service.ConfirmEmailChangeService(ctx, tokenForm)
+
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusConflict, responseStatusOf(err))
+		mockUserRepo.AssertNotCalled(t, "UpdateUserEmail")
+		mockRequestRepo.AssertNotCalled(t, "UpdateEmailChangeRequest")
+	})
+
+	t.Run("メールアドレス変更確定_再チェックに失敗", func(t *testing.T) {
+		t.Parallel()
+		ctx := context.Background()
+		mockUserRepo := new(MockUserRepository)
+		mockRequestRepo := new(MockEmailChangeRequestRepository)
+		mockTransactionManager := new(MockTransactionManager)
+		service := user_service_impl.NewEmailChangeService(mockUserRepo, mockRequestRepo, new(MockMailSender), new(MockUserSettingRepository), mockTransactionManager, new(MockOutboxRepository), "")
+		tokenForm := inputUser.EmailChangeTokenForm{Token: token}
+
+		mockTransactionManager.On("RunInTransaction", ctx).Return(nil)
+		mockRequestRepo.On("FindEmailChangeRequestByConfirmTokenHash", ctx, crypto.HashToken(token)).Return(&entity.EmailChangeRequest{
+			UserId:       userId,
+			CurrentEmail: "old@example.com",
+			NewEmail:     "new@example.com",
+			ExpiresAt:    time.Now().Add(time.Hour),
+		}, nil)
+		mockUserRepo.On("FindUserById", ctx, userId).Return(&entity.User{UserId: userId, Email: "old@example.com"}, nil)
+		// 見つからない場合以外のエラーは、アドレスが未登録であるものとして扱わない
+		mockUserRepo.On("FindUserByEmail", ctx, "new@example.com").Return((*entity.User)(nil), fmt.Errorf("connection reset"))
+
+		_, err := service.ConfirmEmailChangeService(ctx, tokenForm)
+
+		assert.ErrorContains(t, err, "connection reset")
+		assert.Equal(t, http.StatusInternalServerError, responseStatusOf(err))
+		mockUserRepo.AssertNotCalled(t, "UpdateUserEmail")
+	})
+
+	t.Run("メールアドレス変更確定_有効期限切れ", func(t *testing.T) {
+		t.Parallel()
+		ctx := context.Background()
+		mockUserRepo := new(MockUserRepository)
+		mockRequestRepo := new(MockEmailChangeRequestRepository)
+		service := user_service_impl.NewEmailChangeService(mockUserRepo, mockRequestRepo, new(MockMailSender), new(MockUserSettingRepository), new(MockTransactionManager), new(MockOutboxRepository), "")
+		tokenForm := inputUser.EmailChangeTokenForm{Token: token}
+
+		mockRequestRepo.On("FindEmailChangeRequestByConfirmTokenHash", ctx, crypto.HashToken(token)).Return(&entity.EmailChangeRequest{
+			UserId:    userId,
+			NewEmail:  "new@example.com",
+			ExpiresAt: time.Now().Add(-time.Minute),
+		}, nil)
+
+		_, err := service.ConfirmEmailChangeService(ctx, tokenForm)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "有効期限が切れています")
+		mockUserRepo.AssertNotCalled(t, "UpdateUserEmail")
+	})
+}
+
+func TestCancelEmailChangeService(t *testing.T) {
+	t.Parallel()
+
+	token := "cancel-token"
+
+	t.Run("メールアドレス変更取消_確定済み", func(t *testing.T) {
+		t.Parallel()
+		ctx := context.Background()
+		mockRequestRepo := new(MockEmailChangeRequestRepository)
+		service := user_service_impl.NewEmailChangeService(new(MockUserRepository), mockRequestRepo, new(MockMailSender), new(MockUserSettingRepository), new(MockTransactionManager), new(MockOutboxRepository), "")
+		tokenForm := inputUser.EmailChangeTokenForm{Token: token}
+
+		confirmedAt := time.Now()
+		mockRequestRepo.On("FindEmailChangeRequestByCancelTokenHash", ctx, crypto.HashToken(token)).Return(&entity.EmailChangeRequest{
+			ExpiresAt:   time.Now().Add(time.Hour),
+			ConfirmedAt: &confirmedAt,
+		}, nil)
+
+		_, err := service.CancelEmailChangeService(ctx, tokenForm)
+
+		assert.Error(t, err)
+		mockRequestRepo.AssertNotCalled(t, "UpdateEmailChangeRequest")
+	})
+}
@@ -0,0 +1,15 @@
+package user
+
+import (
+	"context"
+
+	"github.com/Go_CleanArch/common/errors"
+	domainEntity "github.com/Go_CleanArch/domain/entity"
+)
+
+// 組織への招待の確認・承諾(組織のサービスが実装する)
+// 招待を伴うサインアップで、ユーザーの作成前に招待を確認し、作成後に承諾するために使用する。
+type InvitationAcceptorInterface interface {
+	CheckInvitation(ctx context.Context, token string, email string) *errors.ApiErr
+	AcceptInvitation(ctx context.Context, token string, userId string, email string) (*domainEntity.OrganizationMembership, error)
+}
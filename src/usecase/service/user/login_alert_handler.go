@@ -0,0 +1,61 @@
+package user
+
+import (
+	"context"
+	goErrors "errors"
+
+	domainEntity "github.com/Go_CleanArch/domain/entity"
+	"github.com/Go_CleanArch/domain/event"
+	repository "github.com/Go_CleanArch/usecase/repository_interface"
+	log "github.com/sirupsen/logrus"
+)
+
+// LoginAlertHandler はログインのイベントを購読し、ログインの通知を有効にしているユーザーへ通知メールを送信する。
+type LoginAlertHandler struct {
+	userRepository        repository.UserRepositoryInterface
+	userSettingRepository repository.UserSettingRepositoryInterface
+	mailSender            repository.MailSenderInterface
+}
+
+// Constructor
+func NewLoginAlertHandler(
+	userRepository repository.UserRepositoryInterface,
+	userSettingRepository repository.UserSettingRepositoryInterface,
+	mailSender repository.MailSenderInterface,
+) *LoginAlertHandler {
+	return &LoginAlertHandler{
+		userRepository:        userRepository,
+		userSettingRepository: userSettingRepository,
+		mailSender:            mailSender,
+	}
+}
+
+// ログインの通知
+// エラーを返した場合はイベントが再配信されるため、設定値を取得できない場合も既定値で送信せずにエラーとする。
+func (lh *LoginAlertHandler) HandleUserLoggedIn(ctx context.Context, e event.UserLoggedIn) error {
+	settings, err := findUserSettings(ctx, lh.userSettingRepository, e.UserId)
+	if err != nil {
+		return err
+	}
+	if !settings.NotificationEnabled(domainEntity.SettingNotificationLoginAlert) {
+		return nil
+	}
+
+	user, err := lh.userRepository.FindUserById(ctx, e.UserId)
+	if goErrors.Is(err, repository.ErrNotFound) {
+		// 配信までの間に削除されたユーザーには通知しない
+		log.WithField("userId", e.UserId).Info("Skip login alert for deleted user")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// 日時はユーザーのタイムゾーンで表記する
+	if err := lh.mailSender.SendMail(ctx, loginAlertMail(user.Email, e.IpAddress, e.UserAgent, e.LoggedInAt.In(settings.Location()))); err != nil {
+		log.WithError(err).Error("Failed to send login alert mail")
+		return err
+	}
+	log.WithField("userId", e.UserId).Info("Login alert sent successfully")
+	return nil
+}
@@ -0,0 +1,103 @@
+package user_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	domainEntity "github.com/Go_CleanArch/domain/entity"
+	"github.com/Go_CleanArch/domain/event"
+	"github.com/Go_CleanArch/interface_adapter/gateway/entity"
+	repository "github.com/Go_CleanArch/usecase/repository_interface"
+	user_service_impl "github.com/Go_CleanArch/usecase/service/user"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestLoginAlertHandler(t *testing.T) {
+	t.Parallel()
+
+	userId := "0192a7e4-5c1b-7d2e-9f3a-4b5c6d7e8f90"
+	loggedIn := event.UserLoggedIn{
+		UserId:     userId,
+		IpAddress:  "203.0.113.10",
+		UserAgent:  "Mozilla/5.0",
+		LoggedInAt: time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	t.Run("ログイン通知_正常系", func(t *testing.T) {
+		t.Parallel()
+		ctx := context.Background()
+		mockUserRepo := new(MockUserRepository)
+		mockSettingRepo := new(MockUserSettingRepository)
+		mockMailSender := new(MockMailSender)
+		handler := user_service_impl.NewLoginAlertHandler(mockUserRepo, mockSettingRepo, mockMailSender)
+
+		// 未設定の場合は既定値(通知する)
+		mockSettingRepo.On("FindUserSettings", ctx, userId).Return([]entity.UserSetting{}, nil)
+		mockUserRepo.On("FindUserById", ctx, userId).Return(&entity.User{UserId: userId, Email: "test@example.com"}, nil)
+		mockMailSender.On("SendMail", ctx, mock.MatchedBy(func(message repository.MailMessage) bool {
+			// 日時はユーザーのタイムゾーン(既定値は Asia/Tokyo)で表記される
+			return message.To == "test@example.com" && strings.Contains(message.Body, "203.0.113.10") && strings.Contains(message.Body, "2024-04-01 09:00:00 JST")
+		})).Return(nil)
+
+		err := handler.HandleUserLoggedIn(ctx, loggedIn)
+
+		assert.NoError(t, err)
+		mockMailSender.AssertExpectations(t)
+	})
+
+	t.Run("ログイン通知_通知を停止している", func(t *testing.T) {
+		t.Parallel()
+		ctx := context.Background()
+		mockUserRepo := new(MockUserRepository)
+		mockSettingRepo := new(MockUserSettingRepository)
+		mockMailSender := new(MockMailSender)
+		handler := user_service_impl.NewLoginAlertHandler(mockUserRepo, mockSettingRepo, mockMailSender)
+
+		mockSettingRepo.On("FindUserSettings", ctx, userId).Return([]entity.UserSetting{
+			{UserId: userId, SettingKey: domainEntity.SettingNotificationLoginAlert, SettingValue: "false"},
+		}, nil)
+
+		err := handler.HandleUserLoggedIn(ctx, loggedIn)
+
+		assert.NoError(t, err)
+		mockMailSender.AssertNotCalled(t, "SendMail")
+	})
+
+	t.Run("ログイン通知_削除済みのユーザー", func(t *testing.T) {
+		t.Parallel()
+		ctx := context.Background()
+		mockUserRepo := new(MockUserRepository)
+		mockSettingRepo := new(MockUserSettingRepository)
+		mockMailSender := new(MockMailSender)
+		handler := user_service_impl.NewLoginAlertHandler(mockUserRepo, mockSettingRepo, mockMailSender)
+
+		mockSettingRepo.On("FindUserSettings", ctx, userId).Return([]entity.UserSetting{}, nil)
+		mockUserRepo.On("FindUserById", ctx, userId).Return((*entity.User)(nil), repository.ErrNotFound)
+
+		err := handler.HandleUserLoggedIn(ctx, loggedIn)
+
+		assert.NoError(t, err)
+		mockMailSender.AssertNotCalled(t, "SendMail")
+	})
+
+	t.Run("ログイン通知_送信に失敗した場合は再配信のためエラー", func(t *testing.T) {
+		t.Parallel()
+		ctx := context.Background()
+		mockUserRepo := new(MockUserRepository)
+		mockSettingRepo := new(MockUserSettingRepository)
+		mockMailSender := new(MockMailSender)
+		handler := user_service_impl.NewLoginAlertHandler(mockUserRepo, mockSettingRepo, mockMailSender)
+
+		mockSettingRepo.On("FindUserSettings", ctx, userId).Return([]entity.UserSetting{}, nil)
+		mockUserRepo.On("FindUserById", ctx, userId).Return(&entity.User{UserId: userId, Email: "test@example.com"}, nil)
+		mockMailSender.On("SendMail", ctx, mock.Anything).Return(fmt.Errorf("connection refused"))
+
+		err := handler.HandleUserLoggedIn(ctx, loggedIn)
+
+		assert.ErrorContains(t, err, "connection refused")
+	})
+}
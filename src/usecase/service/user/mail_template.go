@@ -0,0 +1,78 @@
+package user
+
+import (
+	"fmt"
+	"time"
+
+	repository "github.com/Go_CleanArch/usecase/repository_interface"
+)
+
+// メール本文に記載するトークン付きリンク
+func linkWithToken(baseUrl string, path string, token string) string {
+	return fmt.Sprintf("%s%s?token=%s", baseUrl, path, token)
+}
+
+// 変更先アドレスへ送る確認メール
+func confirmEmailChangeMail(to string, confirmLink string, expiresAt time.Time) repository.MailMessage {
+	return repository.MailMessage{
+		To:      to,
+		Subject: "【確認】メールアドレス変更のお手続き",
+		Body: fmt.Sprintf(`メールアドレスの変更を受け付けました。
+以下のリンクから変更を確定してください。
+
+%s
+
+有効期限: %s
+
+お心当たりのない場合は、このメールを破棄してください。
+`, confirmLink, expiresAt.Format("2006-01-02 15:04:05 MST")),
+	}
+}
+
+// 変更前アドレスへ送る通知メール
+func noticeEmailChangeMail(to string, newEmail string, cancelLink string) repository.MailMessage {
+	return repository.MailMessage{
+		To:      to,
+		Subject: "【通知】メールアドレス変更の申請がありました",
+		Body: fmt.Sprintf(`ご登録のメールアドレスを %s へ変更する申請がありました。
+
+お心当たりのない場合は、以下のリンクから変更を取り消してください。
+
+%s
+`, newEmail, cancelLink),
+	}
+}
+
+// 個人データ削除の確認メール
+func confirmErasureMail(to string, confirmLink string, expiresAt time.Time) repository.MailMessage {
+	return repository.MailMessage{
+		To:      to,
+		Subject: "【確認】アカウントと個人データの削除のお手続き",
+		Body: fmt.Sprintf(`アカウントと個人データの削除申請を受け付けました。
+以下のリンクから削除を確定してください。確定後は元に戻せません。
+
+%s
+
+有効期限: %s
+
+お心当たりのない場合は、このメールを破棄したうえでパスワードを変更してください。
+`, confirmLink, expiresAt.Format("2006-01-02 15:04:05 MST")),
+	}
+}
+
+// ログインの通知メール
+func loginAlertMail(to string, ipAddress string, userAgent string, loggedInAt time.Time) repository.MailMessage {
+	return repository.MailMessage{
+		To:      to,
+		Subject: "【通知】アカウントへのログインがありました",
+		Body: fmt.Sprintf(`ご登録のアカウントへのログインがありました。
+
+日時: %s
+IPアドレス: %s
+ブラウザ: %s
+
+お心当たりのない場合は、パスワードを変更してください。
+ログインの通知は設定から停止できます。
+`, loggedInAt.Format("2006-01-02 15:04:05 MST"), ipAddress, userAgent),
+	}
+}
@@ -0,0 +1,222 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Go_CleanArch/common/auth"
+	"github.com/Go_CleanArch/common/crypto"
+	domainEntity "github.com/Go_CleanArch/domain/entity"
+	eraseUserFactory "github.com/Go_CleanArch/domain/factory/user/erase_user"
+	loginUserDomainService "github.com/Go_CleanArch/domain/factory/user/login_user"
+	inputUser "github.com/Go_CleanArch/usecase/input/user"
+	outputUser "github.com/Go_CleanArch/usecase/output/user"
+	queryEntity "github.com/Go_CleanArch/usecase/query/entity"
+	queryInterface "github.com/Go_CleanArch/usecase/query/service/query_interface"
+	repository "github.com/Go_CleanArch/usecase/repository_interface"
+	log "github.com/sirupsen/logrus"
+)
+
+// PrivacyService provides personal data export and erasure behavior
+type PrivacyService struct {
+	userRepository            repository.UserRepositoryInterface
+	erasureRequestRepository  repository.ErasureRequestRepositoryInterface
+	privacyAuditLogRepository repository.PrivacyAuditLogRepositoryInterface
+	userDataExportQuery       queryInterface.UserDataExportQueryInterface
+	mailSender                repository.MailSenderInterface
+	objectStorage             repository.ObjectStorageInterface
+	userSettingRepository     repository.UserSettingRepositoryInterface
+	// メール本文に記載する確認リンクのベースURL
+	linkBaseUrl string
+	factory     *eraseUserFactory.EraseUserFactory
+}
+
+// Constructor
+func NewPrivacyService(
+	userRepository repository.UserRepositoryInterface,
+	erasureRequestRepository repository.ErasureRequestRepositoryInterface,
+	privacyAuditLogRepository repository.PrivacyAuditLogRepositoryInterface,
+	userDataExportQuery queryInterface.UserDataExportQueryInterface,
+	mailSender repository.MailSenderInterface,
+	objectStorage repository.ObjectStorageInterface,
+	userSettingRepository repository.UserSettingRepositoryInterface,
+	linkBaseUrl string,
+) *PrivacyService {
+	return &PrivacyService{
+		userRepository:            userRepository,
+		erasureRequestRepository:  erasureRequestRepository,
+		privacyAuditLogRepository: privacyAuditLogRepository,
+		userDataExportQuery:       userDataExportQuery,
+		mailSender:                mailSender,
+		objectStorage:             objectStorage,
+		userSettingRepository:     userSettingRepository,
+		linkBaseUrl:               linkBaseUrl,
+		factory:                   eraseUserFactory.NewEraseUserFactory(),
+	}
+}
+
+// 個人データの開示(エクスポート)
+func (ps *PrivacyService) DataExportService(ctx context.Context, dataExportForm inputUser.DataExportForm) (queryEntity.UserDataExport, error) {
+	var export queryEntity.UserDataExport
+
+	userId, ok := auth.UserIdFromContext(ctx)
+	if !ok {
+		apiErr := unauthorizedError()
+		return export, apiErr.Error()
+	}
+
+	// 開示請求の監査記録
+	if err := ps.createAuditLog(ctx, userId, domainEntity.PrivacyActionDataExport, dataExportForm.IpAddress); err != nil {
+		return export, err
+	}
+
+	result, err := ps.userDataExportQuery.FindUserDataExport(ctx, userId)
+	if errors.Is(err, repository.ErrNotFound) {
+		apiErr := notFoundError("userId", "ユーザーが存在しません")
+		return export, apiErr.Error()
+	} else if err != nil {
+		log.WithError(err).Error("Failed to find user data export")
+		return export, fmt.Errorf("個人データの取得に失敗しました: %w", err)
+	}
+
+	log.WithField("userId", userId).Info("User data exported successfully")
+	return *result, nil
+}
+
+// 個人データ削除申請
+// 本人確認のうえ、登録メールアドレスへ削除確定用のトークンを送信する。
+func (ps *PrivacyService) RequestErasureService(ctx context.Context, erasureRequestForm inputUser.ErasureRequestForm) (outputUser.ErasureRequestPresenter, error) {
+	var presenter outputUser.ErasureRequestPresenter
+
+	userId, ok := auth.UserIdFromContext(ctx)
+	if !ok {
+		apiErr := unauthorizedError()
+		return presenter, apiErr.Error()
+	}
+
+	user, err := ps.userRepository.FindUserById(ctx, userId)
+	if err != nil {
+		log.WithError(err).Error("Failed to find user by id")
+		apiErr := notFoundError("userId", "ユーザーが存在しません")
+		return presenter, apiErr.Error()
+	}
+
+	// パスワードによる本人確認
+	if _, apiErr := loginUserDomainService.NewLoginUserDomainServiceProps(
+		loginUserDomainService.WithLoginPassword(user.Password, erasureRequestForm.Password),
+	); apiErr != nil {
+		log.WithField("apiErr", apiErr).Error("Failed to verify password")
+		return presenter, apiErr.Error()
+	}
+
+	request, apiErr := ps.factory.RequestErasure(user.UserId)
+	if apiErr != nil {
+		log.WithField("apiErr", apiErr).Error("Failed to build erasure request")
+		return presenter, apiErr.Error()
+	}
+	requestJson, err := crypto.ConvertStructIntoJson(request)
+	if err != nil {
+		log.WithError(err).Error("Failed to convert erasure request into JSON")
+		return presenter, err
+	}
+	if _, err := ps.erasureRequestRepository.CreateErasureRequest(ctx, requestJson); err != nil {
+		log.WithError(err).Error("Failed to create erasure request")
+		return presenter, err
+	}
+	if err := ps.createAuditLog(ctx, userId, domainEntity.PrivacyActionErasureRequested, erasureRequestForm.IpAddress); err != nil {
+		return presenter, err
+	}
+
+	// 有効期限はユーザーのタイムゾーンで表記する
+	settings := userSettingsOrDefault(ctx, ps.userSettingRepository, userId)
+	confirmLink := linkWithToken(ps.linkBaseUrl, "/erasure/confirm", request.ConfirmToken)
+	if err := ps.mailSender.SendMail(ctx, confirmErasureMail(user.Email, confirmLink, request.ExpiresAt.In(settings.Location()))); err != nil {
+		log.WithError(err).Error("Failed to send erasure confirmation mail")
+		return presenter, err
+	}
+
+	presenter.ExpiresAt = request.ExpiresAt
+	log.WithField("userId", userId).Info("Erasure requested successfully")
+	return presenter, nil
+}
+
+// 個人データ削除の確定
+// usersと関連テーブルの個人情報を匿名化し、監査記録を残す。
+func (ps *PrivacyService) ConfirmErasureService(ctx context.Context, erasureConfirmForm inputUser.ErasureConfirmForm) (outputUser.ErasureResultPresenter, error) {
+	var presenter outputUser.ErasureResultPresenter
+
+	request, err := ps.erasureRequestRepository.FindErasureRequestByConfirmTokenHash(ctx, crypto.HashToken(erasureConfirmForm.Token))
+	if err != nil {
+		log.WithError(err).Error("Failed to find erasure request by confirm token")
+		apiErr := notFoundError("token", "無効なトークンです")
+		return presenter, apiErr.Error()
+	}
+	if apiErr := ps.factory.CheckConfirmable(eraseUserFactory.ErasureRequestState{
+		ExpiresAt:   request.ExpiresAt,
+		ConfirmedAt: request.ConfirmedAt,
+	}); apiErr != nil {
+		log.WithField("apiErr", apiErr).Error("Erasure request is not confirmable")
+		return presenter, apiErr.Error()
+	}
+
+	user, err := ps.userRepository.FindUserById(ctx, request.UserId)
+	if err != nil {
+		log.WithError(err).Error("Failed to find user by id")
+		apiErr := notFoundError("userId", "ユーザーが存在しません")
+		return presenter, apiErr.Error()
+	}
+
+	anonymizedUser, apiErr := ps.factory.AnonymizeUser(request.UserId)
+	if apiErr != nil {
+		log.WithField("apiErr", apiErr).Error("Failed to build anonymized user")
+		return presenter, apiErr.Error()
+	}
+	// 削除後に個人を特定できないよう、完了時の監査記録にはIPアドレスを残さない
+	auditLog, apiErr := ps.factory.NewPrivacyAuditLog(request.UserId, domainEntity.PrivacyActionErasureCompleted, "")
+	if apiErr != nil {
+		log.WithField("apiErr", apiErr).Error("Failed to build privacy audit log")
+		return presenter, apiErr.Error()
+	}
+
+	anonymizedUserJson, err := crypto.ConvertStructIntoJson(anonymizedUser)
+	if err != nil {
+		log.WithError(err).Error("Failed to convert anonymized user into JSON")
+		return presenter, err
+	}
+	auditLogJson, err := crypto.ConvertStructIntoJson(auditLog)
+	if err != nil {
+		log.WithError(err).Error("Failed to convert privacy audit log into JSON")
+		return presenter, err
+	}
+	if err := ps.erasureRequestRepository.EraseUserData(ctx, request, anonymizedUserJson, auditLogJson); err != nil {
+		log.WithError(err).Error("Failed to erase user data")
+		return presenter, err
+	}
+	// アバター画像の削除(DB上の参照は匿名化の際に削除済み)
+	if user.AvatarKey != nil {
+		deleteAvatarObjects(ctx, ps.objectStorage, *user.AvatarKey)
+	}
+
+	presenter.UserId = request.UserId
+	log.WithField("userId", request.UserId).Info("Erasure confirmed successfully")
+	return presenter, nil
+}
+
+func (ps *PrivacyService) createAuditLog(ctx context.Context, userId string, action string, ipAddress string) error {
+	auditLog, apiErr := ps.factory.NewPrivacyAuditLog(userId, action, ipAddress)
+	if apiErr != nil {
+		log.WithField("apiErr", apiErr).Error("Failed to build privacy audit log")
+		return apiErr.Error()
+	}
+	auditLogJson, err := crypto.ConvertStructIntoJson(auditLog)
+	if err != nil {
+		log.WithError(err).Error("Failed to convert privacy audit log into JSON")
+		return err
+	}
+	if err := ps.privacyAuditLogRepository.CreatePrivacyAuditLog(ctx, auditLogJson); err != nil {
+		log.WithError(err).Error("Failed to create privacy audit log")
+		return err
+	}
+	return nil
+}
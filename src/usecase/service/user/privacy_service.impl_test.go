@@ -0,0 +1,270 @@
+package user_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Go_CleanArch/common/auth"
+	"github.com/Go_CleanArch/common/crypto"
+	domainEntity "github.com/Go_CleanArch/domain/entity"
+	eraseUserFactory "github.com/Go_CleanArch/domain/factory/user/erase_user"
+	"github.com/Go_CleanArch/interface_adapter/gateway/entity"
+	inputUser "github.com/Go_CleanArch/usecase/input/user"
+	queryEntity "github.com/Go_CleanArch/usecase/query/entity"
+	repository "github.com/Go_CleanArch/usecase/repository_interface"
+	user_service_impl "github.com/Go_CleanArch/usecase/service/user"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockErasureRequestRepository struct {
+	mock.Mock
+}
+
+func (m *MockErasureRequestRepository) CreateErasureRequest(ctx context.Context, requestJson []byte) (*entity.ErasureRequest, error) {
+	args := m.Called(ctx, requestJson)
+	return args.Get(0).(*entity.ErasureRequest), args.Error(1)
+}
+
+func (m *MockErasureRequestRepository) FindErasureRequestByConfirmTokenHash(ctx context.Context, tokenHash string) (*entity.ErasureRequest, error) {
+	args := m.Called(ctx, tokenHash)
+	return args.Get(0).(*entity.ErasureRequest), args.Error(1)
+}
+
+func (m *MockErasureRequestRepository) EraseUserData(ctx context.Context, request *entity.ErasureRequest, anonymizedUserJson []byte, auditLogJson []byte) error {
+	args := m.Called(ctx, request, anonymizedUserJson, auditLogJson)
+	return args.Error(0)
+}
+
+type MockPrivacyAuditLogRepository struct {
+	mock.Mock
+}
+
+func (m *MockPrivacyAuditLogRepository) CreatePrivacyAuditLog(ctx context.Context, auditLogJson []byte) error {
+	args := m.Called(ctx, auditLogJson)
+	return args.Error(0)
+}
+
+type MockUserDataExportQuery struct {
+	mock.Mock
+}
+
+func (m *MockUserDataExportQuery) FindUserDataExport(ctx context.Context, userId string) (*queryEntity.UserDataExport, error) {
+	args := m.Called(ctx, userId)
+	return args.Get(0).(*queryEntity.UserDataExport), args.Error(1)
+}
+
+// 監査記録のJSONが指定した種別であるか
+func auditLogAction(action string) interface{} {
+	return mock.MatchedBy(func(auditLogJson []byte) bool {
+		var auditLog entity.PrivacyAuditLog
+		return json.Unmarshal(auditLogJson, &auditLog) == nil && auditLog.Action == action
+	})
+}
+
+type privacyServiceMocks struct {
+	userRepo           *MockUserRepository
+	erasureRequestRepo *MockErasureRequestRepository
+	auditLogRepo       *MockPrivacyAuditLogRepository
+	exportQuery        *MockUserDataExportQuery
+	mailSender         *MockMailSender
+	objectStorage      *MockObjectStorage
+	settingRepo        *MockUserSettingRepository
+}
+
+func newPrivacyService() (*user_service_impl.PrivacyService, privacyServiceMocks) {
+	mocks := privacyServiceMocks{
+		userRepo:           new(MockUserRepository),
+		erasureRequestRepo: new(MockErasureRequestRepository),
+		auditLogRepo:       new(MockPrivacyAuditLogRepository),
+		exportQuery:        new(MockUserDataExportQuery),
+		mailSender:         new(MockMailSender),
+		objectStorage:      new(MockObjectStorage),
+		settingRepo:        new(MockUserSettingRepository),
+	}
+	service := user_service_impl.NewPrivacyService(
+		mocks.userRepo,
+		mocks.erasureRequestRepo,
+		mocks.auditLogRepo,
+		mocks.exportQuery,
+		mocks.mailSender,
+		mocks.objectStorage,
+		mocks.settingRepo,
+		"http://localhost:3000",
+	)
+	return service, mocks
+}
+
+func TestDataExportService(t *testing.T) {
+	t.Parallel()
+
+	userId := "0192a7e4-5c1b-7d2e-9f3a-4b5c6d7e8f90"
+
+	t.Run("個人データ開示_正常系", func(t *testing.T) {
+		t.Parallel()
+		ctx := auth.WithUserId(context.Background(), userId)
+		service, mocks := newPrivacyService()
+
+		// 開示請求の監査記録には接続元を残す
+		mocks.auditLogRepo.On("CreatePrivacyAuditLog", ctx, mock.MatchedBy(func(auditLogJson []byte) bool {
+			var auditLog entity.PrivacyAuditLog
+			return json.Unmarshal(auditLogJson, &auditLog) == nil &&
+				auditLog.Action == domainEntity.PrivacyActionDataExport &&
+				auditLog.IpAddress == "192.0.2.1"
+		})).Return(nil)
+		mocks.exportQuery.On("FindUserDataExport", ctx, userId).Return(&queryEntity.UserDataExport{
+			Profile: queryEntity.UserProfileRecord{UserId: userId, Email: "test@example.com"},
+		}, nil)
+
+		export, err := service.DataExportService(ctx, inputUser.DataExportForm{IpAddress: "192.0.2.1"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "test@example.com", export.Profile.Email)
+		mocks.auditLogRepo.AssertExpectations(t)
+		mocks.exportQuery.AssertExpectations(t)
+	})
+
+	t.Run("個人データ開示_未認証", func(t *testing.T) {
+		t.Parallel()
+		service, mocks := newPrivacyService()
+
+		_, err := service.DataExportService(context.Background(), inputUser.DataExportForm{})
+
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusUnauthorized, responseStatusOf(err))
+		mocks.exportQuery.AssertNotCalled(t, "FindUserDataExport")
+	})
+
+	t.Run("個人データ開示_ユーザーが存在しない", func(t *testing.T) {
+		t.Parallel()
+		ctx := auth.WithUserId(context.Background(), userId)
+		service, mocks := newPrivacyService()
+
+		mocks.auditLogRepo.On("CreatePrivacyAuditLog", ctx, auditLogAction(domainEntity.PrivacyActionDataExport)).Return(nil)
+		mocks.exportQuery.On("FindUserDataExport", ctx, userId).Return((*queryEntity.UserDataExport)(nil), repository.ErrNotFound)
+
+		_, err := service.DataExportService(ctx, inputUser.DataExportForm{})
+
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusNotFound, responseStatusOf(err))
+	})
+
+	t.Run("個人データ開示_DBエラー", func(t *testing.T) {
+		t.Parallel()
+		ctx := auth.WithUserId(context.Background(), userId)
+		service, mocks := newPrivacyService()
+		dbErr := fmt.Errorf("connection refused")
+
+		mocks.auditLogRepo.On("CreatePrivacyAuditLog", ctx, auditLogAction(domainEntity.PrivacyActionDataExport)).Return(nil)
+		mocks.exportQuery.On("FindUserDataExport", ctx, userId).Return((*queryEntity.UserDataExport)(nil), dbErr)
+
+		_, err := service.DataExportService(ctx, inputUser.DataExportForm{})
+
+		// 見つからない場合以外のエラーは 404 とせず、サーバーエラーとする
+		assert.ErrorIs(t, err, dbErr)
+		assert.Equal(t, http.StatusInternalServerError, responseStatusOf(err))
+	})
+}
+
+func TestRequestErasureService(t *testing.T) {
+	t.Parallel()
+
+	userId := "0192a7e4-5c1b-7d2e-9f3a-4b5c6d7e8f90"
+	hashedPassword, _ := crypto.PasswordEncrypt("Password123")
+
+	t.Run("個人データ削除申請_正常系", func(t *testing.T) {
+		t.Parallel()
+		ctx := auth.WithUserId(context.Background(), userId)
+		service, mocks := newPrivacyService()
+		erasureRequestForm := inputUser.ErasureRequestForm{Password: "Password123", IpAddress: "192.0.2.1"}
+
+		mocks.userRepo.On("FindUserById", ctx, userId).Return(&entity.User{UserId: userId, Email: "test@example.com", Password: hashedPassword}, nil)
+		mocks.erasureRequestRepo.On("CreateErasureRequest", ctx, mock.Anything).Return(&entity.ErasureRequest{}, nil)
+		mocks.auditLogRepo.On("CreatePrivacyAuditLog", ctx, auditLogAction(domainEntity.PrivacyActionErasureRequested)).Return(nil)
+		// 設定値の取得に失敗した場合は既定のタイムゾーン(Asia/Tokyo)で表記する
+		mocks.settingRepo.On("FindUserSettings", ctx, userId).Return([]entity.UserSetting(nil), fmt.Errorf("connection refused"))
+		mocks.mailSender.On("SendMail", ctx, mock.MatchedBy(func(message repository.MailMessage) bool {
+			return message.To == "test@example.com" && strings.Contains(message.Body, " JST")
+		})).Return(nil)
+
+		presenter, err := service.RequestErasureService(ctx, erasureRequestForm)
+
+		assert.NoError(t, err)
+		assert.True(t, presenter.ExpiresAt.After(time.Now()))
+		mocks.userRepo.AssertExpectations(t)
+		mocks.erasureRequestRepo.AssertExpectations(t)
+		mocks.auditLogRepo.AssertExpectations(t)
+		mocks.mailSender.AssertExpectations(t)
+	})
+
+	t.Run("個人データ削除申請_パスワード誤り", func(t *testing.T) {
+		t.Parallel()
+		ctx := auth.WithUserId(context.Background(), userId)
+		service, mocks := newPrivacyService()
+		erasureRequestForm := inputUser.ErasureRequestForm{Password: "WrongPassword1"}
+
+		mocks.userRepo.On("FindUserById", ctx, userId).Return(&entity.User{UserId: userId, Password: hashedPassword}, nil)
+
+		_, err := service.RequestErasureService(ctx, erasureRequestForm)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "パスワードが間違っています")
+		mocks.erasureRequestRepo.AssertNotCalled(t, "CreateErasureRequest")
+	})
+}
+
+func TestConfirmErasureService(t *testing.T) {
+	t.Parallel()
+
+	token := "erasure-token"
+	userId := "0192a7e4-5c1b-7d2e-9f3a-4b5c6d7e8f90"
+
+	t.Run("個人データ削除確定_正常系", func(t *testing.T) {
+		t.Parallel()
+		ctx := context.Background()
+		service, mocks := newPrivacyService()
+		erasureConfirmForm := inputUser.ErasureConfirmForm{Token: token}
+
+		request := &entity.ErasureRequest{UserId: userId, ExpiresAt: time.Now().Add(time.Hour)}
+		avatarKey := "avatars/" + userId + "/avatar"
+		mocks.erasureRequestRepo.On("FindErasureRequestByConfirmTokenHash", ctx, crypto.HashToken(token)).Return(request, nil)
+		mocks.userRepo.On("FindUserById", ctx, userId).Return(&entity.User{UserId: userId, AvatarKey: &avatarKey}, nil)
+		mocks.objectStorage.On("DeleteObject", ctx, mock.MatchedBy(func(key string) bool {
+			return strings.HasPrefix(key, avatarKey+"/")
+		})).Return(nil).Times(len(domainEntity.AvatarVariants()))
+		mocks.erasureRequestRepo.On("EraseUserData", ctx, request, mock.MatchedBy(func(anonymizedUserJson []byte) bool {
+			var anonymizedUser entity.User
+			return json.Unmarshal(anonymizedUserJson, &anonymizedUser) == nil &&
+				anonymizedUser.UserId == userId &&
+				anonymizedUser.Email == eraseUserFactory.AnonymizedEmail(userId) &&
+				anonymizedUser.UserName == eraseUserFactory.AnonymizedUserName
+		}), auditLogAction(domainEntity.PrivacyActionErasureCompleted)).Return(nil)
+
+		presenter, err := service.ConfirmErasureService(ctx, erasureConfirmForm)
+
+		assert.NoError(t, err)
+		assert.Equal(t, userId, presenter.UserId)
+		mocks.erasureRequestRepo.AssertExpectations(t)
+		mocks.objectStorage.AssertExpectations(t)
+	})
+
+	t.Run("個人データ削除確定_無効なトークン", func(t *testing.T) {
+		t.Parallel()
+		ctx := context.Background()
+		service, mocks := newPrivacyService()
+		erasureConfirmForm := inputUser.ErasureConfirmForm{Token: token}
+
+		mocks.erasureRequestRepo.On("FindErasureRequestByConfirmTokenHash", ctx, crypto.HashToken(token)).Return((*entity.ErasureRequest)(nil), fmt.Errorf("条件に一致するレコードが見つかりません: record not found"))
+
+		_, err := service.ConfirmErasureService(ctx, erasureConfirmForm)
+
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusNotFound, responseStatusOf(err))
+		mocks.erasureRequestRepo.AssertNotCalled(t, "EraseUserData")
+	})
+}
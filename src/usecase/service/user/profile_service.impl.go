@@ -0,0 +1,136 @@
+package user
+
+import (
+	"context"
+
+	"github.com/Go_CleanArch/common/auth"
+	domainEntity "github.com/Go_CleanArch/domain/entity"
+	uploadAvatarFactory "github.com/Go_CleanArch/domain/factory/user/upload_avatar"
+	outputUser "github.com/Go_CleanArch/usecase/output/user"
+	queryInterface "github.com/Go_CleanArch/usecase/query/service/query_interface"
+	repository "github.com/Go_CleanArch/usecase/repository_interface"
+	log "github.com/sirupsen/logrus"
+)
+
+// ProfileService provides user's profile behavior
+type ProfileService struct {
+	userRepository   repository.UserRepositoryInterface
+	userProfileQuery queryInterface.UserProfileQueryInterface
+	objectStorage    repository.ObjectStorageInterface
+	factory          *uploadAvatarFactory.UploadAvatarFactory
+}
+
+// Constructor
+func NewProfileService(
+	userRepository repository.UserRepositoryInterface,
+	userProfileQuery queryInterface.UserProfileQueryInterface,
+	objectStorage repository.ObjectStorageInterface,
+) *ProfileService {
+	return &ProfileService{
+		userRepository:   userRepository,
+		userProfileQuery: userProfileQuery,
+		objectStorage:    objectStorage,
+		factory:          uploadAvatarFactory.NewUploadAvatarFactory(),
+	}
+}
+
+// プロフィールの取得
+func (ps *ProfileService) GetProfileService(ctx context.Context) (outputUser.UserProfilePresenter, error) {
+	var presenter outputUser.UserProfilePresenter
+
+	userId, ok := auth.UserIdFromContext(ctx)
+	if !ok {
+		apiErr := unauthorizedError()
+		return presenter, apiErr.Error()
+	}
+
+	profile, err := ps.userProfileQuery.FindUserProfile(ctx, userId)
+	if err != nil {
+		log.WithError(err).Error("Failed to find user profile")
+		apiErr := notFoundError("userId", "ユーザーが存在しません")
+		return presenter, apiErr.Error()
+	}
+
+	presenter.UserId = profile.UserId
+	presenter.UserName = profile.UserName
+	presenter.Email = profile.Email
+	if profile.AvatarKey != nil {
+		presenter.Avatar = ps.avatarPresenter(*profile.AvatarKey)
+	}
+	return presenter, nil
+}
+
+// アバター画像のアップロード
+// 画像を検証・再エンコードしてサムネイルとともに保存し、以前の画像を削除する。
+// data はアップロードされたファイルの内容(上限+1バイトまで読み込んだもの)。
+func (ps *ProfileService) UploadAvatarService(ctx context.Context, data []byte) (outputUser.AvatarPresenter, error) {
+	var presenter outputUser.AvatarPresenter
+
+	userId, ok := auth.UserIdFromContext(ctx)
+	if !ok {
+		apiErr := unauthorizedError()
+		return presenter, apiErr.Error()
+	}
+
+	user, err := ps.userRepository.FindUserById(ctx, userId)
+	if err != nil {
+		log.WithError(err).Error("Failed to find user by id")
+		apiErr := notFoundError("userId", "ユーザーが存在しません")
+		return presenter, apiErr.Error()
+	}
+
+	avatar, apiErr := ps.factory.BuildAvatar(userId, data)
+	if apiErr != nil {
+		log.WithField("apiErr", apiErr).Error("Failed to build avatar")
+		return presenter, apiErr.Error()
+	}
+
+	for i, object := range avatar.Objects {
+		if err := ps.objectStorage.PutObject(ctx, object.Key, object.ContentType, object.Body); err != nil {
+			log.WithError(err).Error("Failed to put avatar object")
+			// 保存済みの画像を削除して中途半端な状態を残さない
+			for _, stored := range avatar.Objects[:i] {
+				ps.deleteObject(ctx, stored.Key)
+			}
+			return presenter, err
+		}
+	}
+
+	if err := ps.userRepository.UpdateUserAvatarKey(ctx, userId, avatar.AvatarKey); err != nil {
+		log.WithError(err).Error("Failed to update user avatar key")
+		deleteAvatarObjects(ctx, ps.objectStorage, avatar.AvatarKey)
+		return presenter, err
+	}
+
+	// 以前の画像を削除(失敗してもアップロード自体は成功とする)
+	if user.AvatarKey != nil && *user.AvatarKey != avatar.AvatarKey {
+		deleteAvatarObjects(ctx, ps.objectStorage, *user.AvatarKey)
+	}
+
+	log.WithField("userId", userId).Info("Avatar uploaded successfully")
+	return *ps.avatarPresenter(avatar.AvatarKey), nil
+}
+
+func (ps *ProfileService) avatarPresenter(avatarKey string) *outputUser.AvatarPresenter {
+	return &outputUser.AvatarPresenter{
+		OriginalUrl: ps.objectStorage.ObjectUrl(domainEntity.AvatarObjectKey(avatarKey, domainEntity.AvatarVariantOriginal)),
+		LargeUrl:    ps.objectStorage.ObjectUrl(domainEntity.AvatarObjectKey(avatarKey, domainEntity.AvatarVariantLarge)),
+		SmallUrl:    ps.objectStorage.ObjectUrl(domainEntity.AvatarObjectKey(avatarKey, domainEntity.AvatarVariantSmall)),
+	}
+}
+
+func (ps *ProfileService) deleteObject(ctx context.Context, key string) {
+	if err := ps.objectStorage.DeleteObject(ctx, key); err != nil {
+		log.WithError(err).WithField("key", key).Error("Failed to delete avatar object")
+	}
+}
+
+// アバター画像一式の削除(失敗した場合はログのみ出力する)
+func deleteAvatarObjects(ctx context.Context, objectStorage repository.ObjectStorageInterface, avatarKey string) {
+	for _, variant := range domainEntity.AvatarVariants() {
+		key := domainEntity.AvatarObjectKey(avatarKey, variant)
+		if err := objectStorage.DeleteObject(ctx, key); err != nil {
+			log.WithError(err).WithField("key", key).Error("Failed to delete avatar object")
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package user_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/png"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Go_CleanArch/common/auth"
+	domainEntity "github.com/Go_CleanArch/domain/entity"
+	"github.com/Go_CleanArch/interface_adapter/gateway/entity"
+	queryEntity "github.com/Go_CleanArch/usecase/query/entity"
+	user_service_impl "github.com/Go_CleanArch/usecase/service/user"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockObjectStorage struct {
+	mock.Mock
+}
+
+func (m *MockObjectStorage) PutObject(ctx context.Context, key string, contentType string, body []byte) error {
+	args := m.Called(ctx, key, contentType, body)
+	return args.Error(0)
+}
+
+func (m *MockObjectStorage) DeleteObject(ctx context.Context, key string) error {
+	args := m.Called(ctx, key)
+	return args.Error(0)
+}
+
+func (m *MockObjectStorage) ObjectUrl(key string) string {
+	return "http://localhost:8080/storage/" + key
+}
+
+type MockUserProfileQuery struct {
+	mock.Mock
+}
+
+func (m *MockUserProfileQuery) FindUserProfile(ctx context.Context, userId string) (*queryEntity.UserProfile, error) {
+	args := m.Called(ctx, userId)
+	return args.Get(0).(*queryEntity.UserProfile), args.Error(1)
+}
+
+func newPNG(t *testing.T) []byte {
+	var buf bytes.Buffer
+	assert.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 300, 300))))
+	return buf.Bytes()
+}
+
+func TestGetProfileService(t *testing.T) {
+	t.Parallel()
+
+	userId := "0192a7e4-5c1b-7d2e-9f3a-4b5c6d7e8f90"
+
+	t.Run("プロフィール取得_アバター画像のURLを返す", func(t *testing.T) {
+		t.Parallel()
+		ctx := auth.WithUserId(context.Background(), userId)
+		mockProfileQuery := new(MockUserProfileQuery)
+		service := user_service_impl.NewProfileService(new(MockUserRepository), mockProfileQuery, new(MockObjectStorage))
+
+		avatarKey := "avatars/" + userId + "/avatar"
+		mockProfileQuery.On("FindUserProfile", ctx, userId).Return(&queryEntity.UserProfile{
+			UserId:    userId,
+			Email:     "test@example.com",
+			AvatarKey: &avatarKey,
+		}, nil)
+
+		presenter, err := service.GetProfileService(ctx)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "http://localhost:8080/storage/"+avatarKey+"/64.jpg", presenter.Avatar.SmallUrl)
+		assert.Equal(t, "http://localhost:8080/storage/"+avatarKey+"/original.jpg", presenter.Avatar.OriginalUrl)
+	})
+
+	t.Run("プロフィール取得_アバター画像未登録", func(t *testing.T) {
+		t.Parallel()
+		ctx := auth.WithUserId(context.Background(), userId)
+		mockProfileQuery := new(MockUserProfileQuery)
+		service := user_service_impl.NewProfileService(new(MockUserRepository), mockProfileQuery, new(MockObjectStorage))
+
+		mockProfileQuery.On("FindUserProfile", ctx, userId).Return(&queryEntity.UserProfile{UserId: userId}, nil)
+
+		presenter, err := service.GetProfileService(ctx)
+
+		assert.NoError(t, err)
+		assert.Nil(t, presenter.Avatar)
+	})
+}
+
+func TestUploadAvatarService(t *testing.T) {
+	t.Parallel()
+
+	userId := "0192a7e4-5c1b-7d2e-9f3a-4b5c6d7e8f90"
+
+	t.Run("アバター画像アップロード_以前の画像を削除する", func(t *testing.T) {
+		t.Parallel()
+		ctx := auth.WithUserId(context.Background(), userId)
+		mockUserRepo := new(MockUserRepository)
+		mockStorage := new(MockObjectStorage)
+		service := user_service_impl.NewProfileService(mockUserRepo, new(MockUserProfileQuery), mockStorage)
+
+		oldAvatarKey := "avatars/" + userId + "/old"
+		mockUserRepo.On("FindUserById", ctx, userId).Return(&entity.User{UserId: userId, AvatarKey: &oldAvatarKey}, nil)
+		mockStorage.On("PutObject", ctx, mock.Anything, "image/jpeg", mock.Anything).Return(nil).Times(len(domainEntity.AvatarVariants()))
+		mockUserRepo.On("UpdateUserAvatarKey", ctx, userId, mock.MatchedBy(func(avatarKey string) bool {
+			return strings.HasPrefix(avatarKey, "avatars/"+userId+"/") && avatarKey != oldAvatarKey
+		})).Return(nil)
+		mockStorage.On("DeleteObject", ctx, mock.MatchedBy(func(key string) bool {
+			return strings.HasPrefix(key, oldAvatarKey+"/")
+		})).Return(nil).Times(len(domainEntity.AvatarVariants()))
+
+		presenter, err := service.UploadAvatarService(ctx, newPNG(t))
+
+		assert.NoError(t, err)
+		assert.Contains(t, presenter.LargeUrl, "/256.jpg")
+		mockUserRepo.AssertExpectations(t)
+		mockStorage.AssertExpectations(t)
+	})
+
+	t.Run("アバター画像アップロード_保存失敗時はアップロード済みの画像を削除する", func(t *testing.T) {
+		t.Parallel()
+		ctx := auth.WithUserId(context.Background(), userId)
+		mockUserRepo := new(MockUserRepository)
+		mockStorage := new(MockObjectStorage)
+		service := user_service_impl.NewProfileService(mockUserRepo, new(MockUserProfileQuery), mockStorage)
+
+		mockUserRepo.On("FindUserById", ctx, userId).Return(&entity.User{UserId: userId}, nil)
+		mockStorage.On("PutObject", ctx, mock.MatchedBy(func(key string) bool {
+			return strings.HasSuffix(key, "/original.jpg")
+		}), "image/jpeg", mock.Anything).Return(nil)
+		mockStorage.On("PutObject", ctx, mock.Anything, "image/jpeg", mock.Anything).Return(fmt.Errorf("storage unavailable"))
+		mockStorage.On("DeleteObject", ctx, mock.MatchedBy(func(key string) bool {
+			return strings.HasSuffix(key, "/original.jpg")
+		})).Return(nil).Once()
+
+		_, err := service.UploadAvatarService(ctx, newPNG(t))
+
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusInternalServerError, responseStatusOf(err))
+		mockUserRepo.AssertNotCalled(t, "UpdateUserAvatarKey")
+		mockStorage.AssertExpectations(t)
+	})
+
+	t.Run("アバター画像アップロード_画像以外のファイル", func(t *testing.T) {
+		t.Parallel()
+		ctx := auth.WithUserId(context.Background(), userId)
+		mockUserRepo := new(MockUserRepository)
+		mockStorage := new(MockObjectStorage)
+		service := user_service_impl.NewProfileService(mockUserRepo, new(MockUserProfileQuery), mockStorage)
+
+		mockUserRepo.On("FindUserById", ctx, userId).Return(&entity.User{UserId: userId}, nil)
+
+		_, err := service.UploadAvatarService(ctx, []byte("GIF89a-not-really"))
+
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusBadRequest, responseStatusOf(err))
+		mockStorage.AssertNotCalled(t, "PutObject")
+	})
+}
@@ -0,0 +1,88 @@
+package user
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Go_CleanArch/common/auth"
+	"github.com/Go_CleanArch/common/crypto"
+	domainEntity "github.com/Go_CleanArch/domain/entity"
+	inputUser "github.com/Go_CleanArch/usecase/input/user"
+	outputUser "github.com/Go_CleanArch/usecase/output/user"
+	repository "github.com/Go_CleanArch/usecase/repository_interface"
+	log "github.com/sirupsen/logrus"
+)
+
+// SettingService provides user's settings behavior
+type SettingService struct {
+	userSettingRepository repository.UserSettingRepositoryInterface
+}
+
+// Constructor
+func NewSettingService(userSettingRepository repository.UserSettingRepositoryInterface) *SettingService {
+	return &SettingService{
+		userSettingRepository: userSettingRepository,
+	}
+}
+
+// 設定値の取得
+func (ss *SettingService) GetSettingsService(ctx context.Context) (outputUser.UserSettingsPresenter, error) {
+	var presenter outputUser.UserSettingsPresenter
+
+	userId, ok := auth.UserIdFromContext(ctx)
+	if !ok {
+		apiErr := unauthorizedError()
+		return presenter, apiErr.Error()
+	}
+
+	settings, err := findUserSettings(ctx, ss.userSettingRepository, userId)
+	if err != nil {
+		return presenter, err
+	}
+
+	presenter.Settings = settings.Values()
+	return presenter, nil
+}
+
+// 設定値の変更
+// 指定された項目のみを更新し、変更後の全項目を返す。
+func (ss *SettingService) UpdateSettingsService(ctx context.Context, updateUserSettingsForm inputUser.UpdateUserSettingsForm) (outputUser.UserSettingsPresenter, error) {
+	var presenter outputUser.UserSettingsPresenter
+
+	userId, ok := auth.UserIdFromContext(ctx)
+	if !ok {
+		apiErr := unauthorizedError()
+		return presenter, apiErr.Error()
+	}
+
+	storedSettings, err := ss.userSettingRepository.FindUserSettings(ctx, userId)
+	if err != nil {
+		log.WithError(err).Error("Failed to find user settings")
+		return presenter, fmt.Errorf("設定値の取得に失敗しました: %w", err)
+	}
+
+	// スキーマによる型・値の検証
+	settings, apiErr := domainEntity.NewUserSettings(
+		domainEntity.WithUserSettingsUserID(userId),
+		domainEntity.WithStoredSettings(storedSettingValues(storedSettings)),
+		domainEntity.WithSettingChanges(updateUserSettingsForm),
+	)
+	if apiErr != nil {
+		log.WithField("apiErr", apiErr).Error("Failed to apply user settings")
+		return presenter, apiErr.Error()
+	}
+
+	settingsJson, err := crypto.ConvertStructIntoJson(settings.Changed())
+	if err != nil {
+		log.WithError(err).Error("Failed to convert user settings to JSON")
+		return presenter, err
+	}
+	if err := ss.userSettingRepository.SaveUserSettings(ctx, userId, settingsJson); err != nil {
+		log.WithError(err).Error("Failed to save user settings")
+		return presenter, err
+	}
+
+	log.WithField("userId", userId).Info("User settings updated successfully")
+	presenter.Settings = settings.Values()
+	return presenter, nil
+}
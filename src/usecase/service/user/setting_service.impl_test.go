@@ -0,0 +1,126 @@
+package user_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/Go_CleanArch/common/auth"
+	domainEntity "github.com/Go_CleanArch/domain/entity"
+	"github.com/Go_CleanArch/interface_adapter/gateway/entity"
+	inputUser "github.com/Go_CleanArch/usecase/input/user"
+	user_service_impl "github.com/Go_CleanArch/usecase/service/user"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockUserSettingRepository struct {
+	mock.Mock
+}
+
+func (m *MockUserSettingRepository) FindUserSettings(ctx context.Context, userId string) ([]entity.UserSetting, error) {
+	args := m.Called(ctx, userId)
+	return args.Get(0).([]entity.UserSetting), args.Error(1)
+}
+
+func (m *MockUserSettingRepository) SaveUserSettings(ctx context.Context, userId string, settingsJson []byte) error {
+	args := m.Called(ctx, userId, settingsJson)
+	return args.Error(0)
+}
+
+func TestGetSettingsService(t *testing.T) {
+	t.Parallel()
+
+	userId := "0192a7e4-5c1b-7d2e-9f3a-4b5c6d7e8f90"
+
+	t.Run("設定取得_未設定の項目は既定値を返す", func(t *testing.T) {
+		t.Parallel()
+		ctx := auth.WithUserId(context.Background(), userId)
+		mockSettingRepo := new(MockUserSettingRepository)
+		service := user_service_impl.NewSettingService(mockSettingRepo)
+
+		mockSettingRepo.On("FindUserSettings", ctx, userId).Return([]entity.UserSetting{
+			{UserId: userId, SettingKey: domainEntity.SettingUiTheme, SettingValue: `"dark"`},
+			// スキーマから削除された項目は無視される
+			{UserId: userId, SettingKey: "ui.fontSize", SettingValue: `14`},
+		}, nil)
+
+		presenter, err := service.GetSettingsService(ctx)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "dark", presenter.Settings[domainEntity.SettingUiTheme])
+		assert.Equal(t, "ja", presenter.Settings[domainEntity.SettingLocale])
+		assert.Equal(t, true, presenter.Settings[domainEntity.SettingNotificationLoginAlert])
+		assert.NotContains(t, presenter.Settings, "ui.fontSize")
+	})
+
+	t.Run("設定取得_未認証", func(t *testing.T) {
+		t.Parallel()
+		service := user_service_impl.NewSettingService(new(MockUserSettingRepository))
+
+		_, err := service.GetSettingsService(context.Background())
+
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusUnauthorized, responseStatusOf(err))
+	})
+}
+
+// リクエストボディと同じJSONから設定値の変更を作成する
+func newUpdateUserSettingsForm(t *testing.T, body string) inputUser.UpdateUserSettingsForm {
+	var updateUserSettingsForm inputUser.UpdateUserSettingsForm
+	assert.NoError(t, json.Unmarshal([]byte(body), &updateUserSettingsForm))
+	return updateUserSettingsForm
+}
+
+func TestUpdateSettingsService(t *testing.T) {
+	t.Parallel()
+
+	userId := "0192a7e4-5c1b-7d2e-9f3a-4b5c6d7e8f90"
+
+	t.Run("設定変更_正常系", func(t *testing.T) {
+		t.Parallel()
+		ctx := auth.WithUserId(context.Background(), userId)
+		mockSettingRepo := new(MockUserSettingRepository)
+		service := user_service_impl.NewSettingService(mockSettingRepo)
+		updateUserSettingsForm := newUpdateUserSettingsForm(t, `{"timezone": "America/New_York", "notification.productUpdate": true}`)
+
+		mockSettingRepo.On("FindUserSettings", ctx, userId).Return([]entity.UserSetting{
+			{UserId: userId, SettingKey: domainEntity.SettingUiTheme, SettingValue: `"dark"`},
+		}, nil)
+		// 変更した項目のみが保存される
+		mockSettingRepo.On("SaveUserSettings", ctx, userId, mock.MatchedBy(func(settingsJson []byte) bool {
+			var values map[string]string
+			return json.Unmarshal(settingsJson, &values) == nil &&
+				len(values) == 2 &&
+				values[domainEntity.SettingTimezone] == `"America/New_York"` &&
+				values[domainEntity.SettingNotificationProductUpdate] == `true`
+		})).Return(nil)
+
+		presenter, err := service.UpdateSettingsService(ctx, updateUserSettingsForm)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "America/New_York", presenter.Settings[domainEntity.SettingTimezone])
+		assert.Equal(t, "dark", presenter.Settings[domainEntity.SettingUiTheme])
+		mockSettingRepo.AssertExpectations(t)
+	})
+
+	t.Run("設定変更_未定義の項目・不正な値", func(t *testing.T) {
+		t.Parallel()
+		ctx := auth.WithUserId(context.Background(), userId)
+		mockSettingRepo := new(MockUserSettingRepository)
+		service := user_service_impl.NewSettingService(mockSettingRepo)
+		updateUserSettingsForm := newUpdateUserSettingsForm(t, `{"timezone": "Mars/Olympus", "ui.theme": "blue", "notification.loginAlert": "yes", "unknown": 1}`)
+
+		mockSettingRepo.On("FindUserSettings", ctx, userId).Return([]entity.UserSetting{}, nil)
+
+		_, err := service.UpdateSettingsService(ctx, updateUserSettingsForm)
+
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusBadRequest, responseStatusOf(err))
+		for _, key := range []string{"timezone", "ui.theme", "notification.loginAlert", "unknown"} {
+			assert.Contains(t, err.Error(), "key: "+key+",")
+		}
+		mockSettingRepo.AssertNotCalled(t, "SaveUserSettings")
+	})
+}
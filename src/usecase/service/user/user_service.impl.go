@@ -2,50 +2,70 @@ package user
 
 import (
 	"context"
+	goErrors "errors"
+	"fmt"
 
+	"github.com/Go_CleanArch/common/auth"
 	status "github.com/Go_CleanArch/common/const"
 	"github.com/Go_CleanArch/common/crypto"
 	"github.com/Go_CleanArch/common/errors"
 	createUserFactory "github.com/Go_CleanArch/domain/factory/user/create_user"
 	loginUserDomainService "github.com/Go_CleanArch/domain/factory/user/login_user"
+	"github.com/Go_CleanArch/interface_adapter/gateway/entity"
 	inputUser "github.com/Go_CleanArch/usecase/input/user"
 	outputUser "github.com/Go_CleanArch/usecase/output/user"
 	repository "github.com/Go_CleanArch/usecase/repository_interface"
-	"github.com/gin-gonic/gin"
 	log "github.com/sirupsen/logrus"
 )
 
 // Service provides user's behavior
 type UserService struct {
-	userRepository repository.UserRepositoryInterface
+	userRepository         repository.UserRepositoryInterface
+	loginHistoryRepository repository.LoginHistoryRepositoryInterface
+	invitationAcceptor     InvitationAcceptorInterface
+	signupPolicy           *createUserFactory.SignupPolicy
+	tokenIssuer            *auth.TokenIssuer
+	outboxRepository       repository.OutboxRepositoryInterface
+	transactionManager     repository.TransactionManagerInterface
 }
 
 // Constructor
-func NewUserService(userRepository repository.UserRepositoryInterface) *UserService {
+func NewUserService(
+	userRepository repository.UserRepositoryInterface,
+	loginHistoryRepository repository.LoginHistoryRepositoryInterface,
+	invitationAcceptor InvitationAcceptorInterface,
+	signupPolicy *createUserFactory.SignupPolicy,
+	tokenIssuer *auth.TokenIssuer,
+	outboxRepository repository.OutboxRepositoryInterface,
+	transactionManager repository.TransactionManagerInterface,
+) *UserService {
 	return &UserService{
-		userRepository: userRepository,
+		userRepository:         userRepository,
+		loginHistoryRepository: loginHistoryRepository,
+		invitationAcceptor:     invitationAcceptor,
+		signupPolicy:           signupPolicy,
+		tokenIssuer:            tokenIssuer,
+		outboxRepository:       outboxRepository,
+		transactionManager:     transactionManager,
 	}
 }
 
 // サインアップ
-func (us *UserService) CreateUserService(ctx context.Context, c *gin.Context) (outputUser.CreateUserPresenter, error) {
-	var createUserForm inputUser.CreateUserForm
+// 失敗した場合は ApiErr を保持するエラー(errors.ApiErrFrom で取り出せる)、または想定外のエラーを返す
+func (us *UserService) CreateUserService(ctx context.Context, createUserForm inputUser.CreateUserForm) (outputUser.CreateUserPresenter, error) {
 	var createUserPresenter outputUser.CreateUserPresenter
-	if err := c.BindJSON(&createUserForm); err != nil {
-		log.WithError(err).Error("Failed to bind JSON request body")
-		return createUserPresenter, err
-	}
 
-	// 入力チェックバリデーション
-	apiErrMessages := createUserForm.CreateUserValidate()
-	if len(apiErrMessages) > 0 {
-		apiErr := errors.OutputApiError(
-			apiErrMessages,
-			status.ErrorStatusMap["BAD_REQUEST"].StatusCode,
-			status.ErrorStatusMap["BAD_REQUEST"].StatusName,
-		)
-		log.WithField("apiErr", apiErr).Error("Validation error occurred")
-		c.JSON(apiErr.Status, apiErr)
+	// サインアップの受付条件チェック
+	// 招待トークンが指定された場合は、招待先のメールアドレスでの登録であることを確認する
+	invited := createUserForm.InvitationToken != ""
+	if invited {
+		if apiErr := us.invitationAcceptor.CheckInvitation(ctx, createUserForm.InvitationToken, createUserForm.Email); apiErr != nil {
+			log.WithField("apiErr", apiErr).Info("Invalid invitation token for signup")
+			return createUserPresenter, apiErr.Error()
+		}
+	}
+	if apiErr := us.signupPolicy.CheckSignup(createUserForm.Email, invited); apiErr != nil {
+		log.WithField("apiErr", apiErr).Info("Signup is not allowed for the email")
 		return createUserPresenter, apiErr.Error()
 	}
 
@@ -71,7 +91,6 @@ func (us *UserService) CreateUserService(ctx context.Context, c *gin.Context) (o
 	)
 	if apiErr != nil {
 		log.WithField("apiErr", apiErr).Error("Failed to build user factory props")
-		c.JSON(apiErr.Status, apiErr)
 		return createUserPresenter, apiErr.Error()
 	}
 
@@ -79,85 +98,120 @@ func (us *UserService) CreateUserService(ctx context.Context, c *gin.Context) (o
 	getUserJson, err := crypto.ConvertStructIntoJson(i)
 	if err != nil {
 		log.WithError(err).Error("Failed to convert user factory props into JSON")
-		c.JSON(500, err)
-		return createUserPresenter, err
+		return createUserPresenter, fmt.Errorf("ユーザー情報の変換に失敗しました: %w", err)
+	}
+	// ユーザーの登録とユーザー登録のイベントの保存を同一トランザクションで行う
+	var createdUser *entity.User
+	err = us.transactionManager.RunInTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		if createdUser, err = us.userRepository.CreateUser(ctx, getUserJson); err != nil {
+			return err
+		}
+		return us.outboxRepository.AddEvents(ctx, i.PullEvents()...)
+	})
+	if goErrors.Is(err, repository.ErrEmailAlreadyExists) {
+		// 事前チェックの後に同じアドレスで登録された場合
+		apiErr := createUserFactory.EmailAlreadyExistsError()
+		log.WithField("apiErr", apiErr).Info("User email already exists")
+		return createUserPresenter, apiErr.Error()
 	}
-	createdUser, err := us.userRepository.CreateUser(ctx, getUserJson)
 	if err != nil {
 		log.WithError(err).Error("Failed to create user")
-		c.JSON(500, err)
-		return createUserPresenter, err
+		return createUserPresenter, fmt.Errorf("ユーザーの登録に失敗しました: %w", err)
+	}
+
+	// 招待の承諾(ユーザーは作成済みのため、失敗してもサインアップは成功とし、再度の承諾を促す)
+	if invited {
+		if _, err := us.invitationAcceptor.AcceptInvitation(ctx, createUserForm.InvitationToken, createdUser.UserId, createdUser.Email); err != nil {
+			log.WithError(err).Warn("Failed to accept invitation after signup")
+		}
 	}
+
 	if err := crypto.ConvertJsonAndCopyBean(createdUser, &createUserPresenter); err != nil {
 		log.WithError(err).Error("Failed to convert created user into presenter")
-		return createUserPresenter, err
+		return createUserPresenter, fmt.Errorf("登録したユーザーの変換に失敗しました: %w", err)
 	}
 	log.WithField("userId", createUserPresenter.UserId).Info("User created successfully")
 	return createUserPresenter, nil
 }
 
 // ログイン
-func (us *UserService) LoginService(ctx context.Context, c *gin.Context) (outputUser.LoginPresenter, error) {
-	var loginForm inputUser.LoginForm
+// 失敗した場合は ApiErr を保持するエラー(errors.ApiErrFrom で取り出せる)、または想定外のエラーを返す
+func (us *UserService) LoginService(ctx context.Context, loginForm inputUser.LoginForm) (outputUser.LoginPresenter, error) {
 	var loginPresenter outputUser.LoginPresenter
 
-	if err := c.BindJSON(&loginForm); err != nil {
-		log.WithError(err).Error("Failed to bind JSON request body")
-		return loginPresenter, err
-	}
-
-	// 入力チェックバリデーション
-	apiErrMessages := loginForm.LoginValidate()
-	if len(apiErrMessages) > 0 {
-		apiErr := errors.OutputApiError(
-			apiErrMessages,
-			status.ErrorStatusMap["BAD_REQUEST"].StatusCode,
-			status.ErrorStatusMap["BAD_REQUEST"].StatusName,
-		)
-		log.WithField("apiErr", apiErr).Error("Validation error occurred")
-		c.JSON(apiErr.Status, apiErr)
-		return loginPresenter, apiErr.Error()
-	}
-
 	getUser, err := us.userRepository.FindUserByEmail(ctx, loginForm.Email)
 	// メールアドレス確認
 	if err != nil {
 		log.WithError(err).Error("Failed to find user by email")
 		apiErr := errors.OutputApiError(
-			append(apiErrMessages,
-				errors.ApiErrMessage{
+			[]errors.ApiErrMessage{
+				{
 					Key:   "email",
 					Value: "Emailアドレスが存在しません",
 				},
-			),
+			},
 			status.ErrorStatusMap["NOT_FOUND"].StatusCode,
 			status.ErrorStatusMap["NOT_FOUND"].StatusName,
 		)
-		c.JSON(apiErr.Status, apiErr)
 		return loginPresenter, apiErr.Error()
 	}
 
 	userDomainServiceEntity, apiErr := loginUserDomainService.NewLoginUserDomainServiceProps(
-		loginUserDomainService.WithLoginUserIdAndEmail(getUser.Email),
+		loginUserDomainService.WithLoginUserId(getUser.UserId),
+		loginUserDomainService.WithLoginEmail(getUser.Email),
 		loginUserDomainService.WithLoginUserName(getUser.UserName),
 		loginUserDomainService.WithLoginPassword(getUser.Password, loginForm.Password),
 	)
 	if apiErr != nil {
 		log.WithField("apiErr", apiErr).Error("Failed to build login user domain props")
-		c.JSON(apiErr.Status, apiErr)
 		return loginPresenter, apiErr.Error()
 	}
 
 	getUserJson, err := crypto.ConvertStructIntoJson(userDomainServiceEntity)
 	if err != nil {
 		log.WithError(err).Error("Failed to convert login user domain entity into JSON")
-		return loginPresenter, err
+		return loginPresenter, fmt.Errorf("ログインユーザーの変換に失敗しました: %w", err)
 	}
 	if err := crypto.CopyBeans(getUserJson, &loginPresenter); err != nil {
 		log.WithError(err).Error("Failed to copy login user data into presenter")
-		return loginPresenter, err
+		return loginPresenter, fmt.Errorf("ログインユーザーの変換に失敗しました: %w", err)
 	}
 
+	// アクセストークンの発行
+	accessToken, err := us.tokenIssuer.Issue(loginPresenter.UserId)
+	if err != nil {
+		log.WithError(err).Error("Failed to issue access token")
+		return loginPresenter, fmt.Errorf("アクセストークンの発行に失敗しました: %w", err)
+	}
+	loginPresenter.AccessToken = accessToken
+
+	// ログイン履歴の記録(失敗してもログイン自体は成功とする)
+	us.recordLoginHistory(ctx, loginPresenter.UserId, loginForm.IpAddress, loginForm.UserAgent)
+
 	log.WithField("email", loginPresenter.Email).Info("User logged in successfully")
 	return loginPresenter, nil
 }
+
+// ログイン履歴とログインのイベントを同一トランザクションで保存する
+func (us *UserService) recordLoginHistory(ctx context.Context, userId string, ipAddress string, userAgent string) {
+	loginHistory, err := loginUserDomainService.NewLoginHistory(userId, ipAddress, userAgent)
+	if err != nil {
+		log.WithError(err).Error("Failed to build login history")
+		return
+	}
+	loginHistoryJson, err := crypto.ConvertStructIntoJson(loginHistory)
+	if err != nil {
+		log.WithError(err).Error("Failed to convert login history into JSON")
+		return
+	}
+	err = us.transactionManager.RunInTransaction(ctx, func(ctx context.Context) error {
+		if err := us.loginHistoryRepository.CreateLoginHistory(ctx, loginHistoryJson); err != nil {
+			return err
+		}
+		return us.outboxRepository.AddEvents(ctx, loginHistory.PullEvents()...)
+	})
+	if err != nil {
+		log.WithError(err).Error("Failed to create login history")
+	}
+}
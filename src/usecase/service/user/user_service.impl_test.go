@@ -1,20 +1,24 @@
 package user_test
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/Go_CleanArch/common/auth"
 	"github.com/Go_CleanArch/common/crypto"
+	"github.com/Go_CleanArch/common/errors"
+	domainEntity "github.com/Go_CleanArch/domain/entity"
+	"github.com/Go_CleanArch/domain/event"
+	createUserFactory "github.com/Go_CleanArch/domain/factory/user/create_user"
 
 	"github.com/Go_CleanArch/interface_adapter/gateway/entity"
 	inputUser "github.com/Go_CleanArch/usecase/input/user"
+	repository "github.com/Go_CleanArch/usecase/repository_interface"
 	user_service_impl "github.com/Go_CleanArch/usecase/service/user"
-	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -28,95 +32,236 @@ func (m *MockUserRepository) FindUserByEmail(ctx context.Context, email string)
 	return args.Get(0).(*entity.User), args.Error(1)
 }
 
+func (m *MockUserRepository) FindUserById(ctx context.Context, userId string) (*entity.User, error) {
+	args := m.Called(ctx, userId)
+	return args.Get(0).(*entity.User), args.Error(1)
+}
+
 func (m *MockUserRepository) CreateUser(ctx context.Context, userJson []byte) (*entity.User, error) {
 	args := m.Called(ctx, userJson)
 	return args.Get(0).(*entity.User), args.Error(1)
 }
 
+func (m *MockUserRepository) UpdateUserEmail(ctx context.Context, userId string, email string) error {
+	args := m.Called(ctx, userId, email)
+	return args.Error(0)
+}
+
+type MockLoginHistoryRepository struct {
+	mock.Mock
+}
+
+func (m *MockLoginHistoryRepository) CreateLoginHistory(ctx context.Context, loginHistoryJson []byte) error {
+	args := m.Called(ctx, loginHistoryJson)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) UpdateUserAvatarKey(ctx context.Context, userId string, avatarKey string) error {
+	args := m.Called(ctx, userId, avatarKey)
+	return args.Error(0)
+}
+
+type MockInvitationAcceptor struct {
+	mock.Mock
+}
+
+func (m *MockInvitationAcceptor) CheckInvitation(ctx context.Context, token string, email string) *errors.ApiErr {
+	args := m.Called(ctx, token, email)
+	return args.Get(0).(*errors.ApiErr)
+}
+
+func (m *MockInvitationAcceptor) AcceptInvitation(ctx context.Context, token string, userId string, email string) (*domainEntity.OrganizationMembership, error) {
+	args := m.Called(ctx, token, userId, email)
+	return args.Get(0).(*domainEntity.OrganizationMembership), args.Error(1)
+}
+
+// テスト用のサインアップ受付条件(restricted の場合は example.co.jp のみ許可する)
+func newSignupPolicy(mode string) *createUserFactory.SignupPolicy {
+	signupPolicy, _ := createUserFactory.NewSignupPolicy(mode, []string{"example.co.jp"})
+	return signupPolicy
+}
+
+// テスト用のアクセストークン発行
+func newTokenIssuer() *auth.TokenIssuer {
+	tokenIssuer, _ := auth.NewTokenIssuer("test-secret-0123456789", time.Hour)
+	return tokenIssuer
+}
+
+// テスト用のトランザクション管理(fn をそのまま実行する)
+func newTransactionManager() *MockTransactionManager {
+	transactionManager := new(MockTransactionManager)
+	transactionManager.On("RunInTransaction", mock.Anything).Return(nil).Maybe()
+	return transactionManager
+}
+
+// responseStatusOf はエラーを ErrorMiddleware でレスポンスに変換した場合のステータスを返す(ApiErr を保持しないエラーは 500)
+func responseStatusOf(err error) int {
+	if apiErr, ok := errors.ApiErrFrom(err); ok {
+		return apiErr.Status
+	}
+	return 500
+}
+
 func TestCreateUserService(t *testing.T) {
 	t.Parallel()
 	t.Run("新規ユーザー作成_正常系", func(t *testing.T) {
 		t.Parallel()
 		ctx := context.Background()
 		mockUserRepo := new(MockUserRepository)
-		userService := user_service_impl.NewUserService(mockUserRepo)
+		mockLoginHistoryRepo := new(MockLoginHistoryRepository)
+		mockOutboxRepo := new(MockOutboxRepository)
+		mockTransactionManager := newTransactionManager()
+		userService := user_service_impl.NewUserService(mockUserRepo, mockLoginHistoryRepo, new(MockInvitationAcceptor), newSignupPolicy(createUserFactory.SignupModeOpen), newTokenIssuer(), mockOutboxRepo, mockTransactionManager)
 		// テスト用のリクエストボディを作成
 		createUserForm := inputUser.CreateUserForm{
 			Email:    "test@example.com",
 			UserName: "testuser",
 			Password: "Password123",
 		}
-		requestBody, _ := json.Marshal(createUserForm)
 
 		// モックの設定
 		mockUserRepo.On("FindUserByEmail", ctx, createUserForm.Email).Return(&entity.User{}, nil)
 		mockUserRepo.On("CreateUser", ctx, mock.Anything).Return(&entity.User{UserId: "user123"}, nil)
-
-		// リクエストの作成
-		c, _ := gin.CreateTestContext(httptest.NewRecorder())
-		c.Request = httptest.NewRequest("POST", "/users", bytes.NewBuffer(requestBody))
+		// ユーザー登録のイベントを同じトランザクションで保存する
+		mockOutboxRepo.On("AddEvents", ctx, mock.MatchedBy(func(events []event.Event) bool {
+			registered, ok := events[0].(event.UserRegistered)
+			return len(events) == 1 && ok && registered.UserId != "" && registered.Email == "test@example.com"
+		})).Return(nil)
 
 		// テスト対象の関数を実行
-		presenter, err := userService.CreateUserService(ctx, c)
+		presenter, err := userService.CreateUserService(ctx, createUserForm)
 
 		// アサーション
 		assert.NoError(t, err)
 		assert.Equal(t, "user123", presenter.UserId)
 		mockUserRepo.AssertExpectations(t)
+		mockOutboxRepo.AssertExpectations(t)
+		mockTransactionManager.AssertCalled(t, "RunInTransaction", ctx)
 	})
 
-	t.Run("新規ユーザー作成_バリデーションエラー", func(t *testing.T) {
+	t.Run("新規ユーザー作成_メールアドレス重複", func(t *testing.T) {
 		t.Parallel()
 		ctx := context.Background()
 		mockUserRepo := new(MockUserRepository)
-		userService := user_service_impl.NewUserService(mockUserRepo)
+		mockLoginHistoryRepo := new(MockLoginHistoryRepository)
+		userService := user_service_impl.NewUserService(mockUserRepo, mockLoginHistoryRepo, new(MockInvitationAcceptor), newSignupPolicy(createUserFactory.SignupModeOpen), newTokenIssuer(), new(MockOutboxRepository), newTransactionManager())
 		// テスト用のリクエストボディを作成
 		createUserForm := inputUser.CreateUserForm{
-			Email:    "invalid_email",
-			UserName: "",
-			Password: "password",
+			Email:    "test@example.com",
+			UserName: "testuser",
+			Password: "Password123",
 		}
-		requestBody, _ := json.Marshal(createUserForm)
 
-		// リクエストの作成
-		c, _ := gin.CreateTestContext(httptest.NewRecorder())
-		c.Request = httptest.NewRequest("POST", "/users", bytes.NewBuffer(requestBody))
+		// モックの設定
+		mockUserRepo.On("FindUserByEmail", ctx, createUserForm.Email).Return(&entity.User{UserId: "user123"}, nil)
 
 		// テスト対象の関数を実行
-		_, err := userService.CreateUserService(ctx, c)
+		_, err := userService.CreateUserService(ctx, createUserForm)
 
 		// アサーション
 		assert.Error(t, err)
-		// assert.Contains(t, err.Error(), "Validation error occurred")
+		// assert.Contains(t, err.Error(), "Failed to build user factory props")
+		mockUserRepo.AssertExpectations(t)
 	})
 
-	t.Run("新規ユーザー作成_メールアドレス重複", func(t *testing.T) {
+	t.Run("新規ユーザー作成_同時登録による一意制約違反", func(t *testing.T) {
 		t.Parallel()
 		ctx := context.Background()
 		mockUserRepo := new(MockUserRepository)
-		userService := user_service_impl.NewUserService(mockUserRepo)
-		// テスト用のリクエストボディを作成
+		mockOutboxRepo := new(MockOutboxRepository)
+		userService := user_service_impl.NewUserService(mockUserRepo, new(MockLoginHistoryRepository), new(MockInvitationAcceptor), newSignupPolicy(createUserFactory.SignupModeOpen), newTokenIssuer(), mockOutboxRepo, newTransactionManager())
 		createUserForm := inputUser.CreateUserForm{
-			Email:    "test@example.com",
+			Email:    "Test@example.com",
 			UserName: "testuser",
 			Password: "Password123",
 		}
-		requestBody, _ := json.Marshal(createUserForm)
 
-		// モックの設定
-		mockUserRepo.On("FindUserByEmail", ctx, createUserForm.Email).Return(&entity.User{UserId: "user123"}, nil)
-
-		// リクエストの作成
-		c, _ := gin.CreateTestContext(httptest.NewRecorder())
-		c.Request = httptest.NewRequest("POST", "/users", bytes.NewBuffer(requestBody))
+		// 事前チェックの時点では未登録だが、作成時に一意制約違反となる
+		mockUserRepo.On("FindUserByEmail", ctx, "Test@example.com").Return((*entity.User)(nil), fmt.Errorf("条件に一致するレコードが見つかりません: record not found"))
+		mockUserRepo.On("CreateUser", ctx, mock.MatchedBy(func(userJson []byte) bool {
+			var user entity.User
+			return json.Unmarshal(userJson, &user) == nil && user.Email == "test@example.com"
+		})).Return((*entity.User)(nil), repository.ErrEmailAlreadyExists)
 
-		// テスト対象の関数を実行
-		_, err := userService.CreateUserService(ctx, c)
+		_, err := userService.CreateUserService(ctx, createUserForm)
 
-		// アサーション
 		assert.Error(t, err)
-		// assert.Contains(t, err.Error(), "Failed to build user factory props")
+		apiErr, ok := errors.ApiErrFrom(err)
+		assert.True(t, ok)
+		assert.Equal(t, http.StatusBadRequest, apiErr.Status)
+		assert.Equal(t, []errors.ApiErrMessage{createUserFactory.EmailAlreadyExistsMessage()}, apiErr.Messages)
 		mockUserRepo.AssertExpectations(t)
+		mockOutboxRepo.AssertNotCalled(t, "AddEvents")
+	})
+
+	t.Run("新規ユーザー作成_招待を承諾して登録", func(t *testing.T) {
+		t.Parallel()
+		ctx := context.Background()
+		mockUserRepo := new(MockUserRepository)
+		mockInvitationAcceptor := new(MockInvitationAcceptor)
+		// 招待がある場合は、許可していないドメインでも登録できる
+		mockOutboxRepo := new(MockOutboxRepository)
+		userService := user_service_impl.NewUserService(mockUserRepo, new(MockLoginHistoryRepository), mockInvitationAcceptor, newSignupPolicy(createUserFactory.SignupModeRestricted), newTokenIssuer(), mockOutboxRepo, newTransactionManager())
+		createUserForm := inputUser.CreateUserForm{
+			Email:           "test@example.com",
+			UserName:        "testuser",
+			Password:        "Password123",
+			InvitationToken: "invitation-token",
+		}
+
+		mockInvitationAcceptor.On("CheckInvitation", ctx, "invitation-token", "test@example.com").Return((*errors.ApiErr)(nil))
+		mockUserRepo.On("FindUserByEmail", ctx, createUserForm.Email).Return(&entity.User{}, nil)
+		mockUserRepo.On("CreateUser", ctx, mock.Anything).Return(&entity.User{UserId: "user123", Email: "test@example.com"}, nil)
+		mockOutboxRepo.On("AddEvents", ctx, mock.Anything).Return(nil)
+		mockInvitationAcceptor.On("AcceptInvitation", ctx, "invitation-token", "user123", "test@example.com").Return(&domainEntity.OrganizationMembership{}, nil)
+
+		presenter, err := userService.CreateUserService(ctx, createUserForm)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "user123", presenter.UserId)
+		mockInvitationAcceptor.AssertExpectations(t)
+	})
+
+	t.Run("新規ユーザー作成_無効な招待", func(t *testing.T) {
+		t.Parallel()
+		ctx := context.Background()
+		mockUserRepo := new(MockUserRepository)
+		mockInvitationAcceptor := new(MockInvitationAcceptor)
+		userService := user_service_impl.NewUserService(mockUserRepo, new(MockLoginHistoryRepository), mockInvitationAcceptor, newSignupPolicy(createUserFactory.SignupModeOpen), newTokenIssuer(), new(MockOutboxRepository), newTransactionManager())
+		createUserForm := inputUser.CreateUserForm{
+			Email:           "test@example.com",
+			UserName:        "testuser",
+			Password:        "Password123",
+			InvitationToken: "invitation-token",
+		}
+
+		mockInvitationAcceptor.On("CheckInvitation", ctx, "invitation-token", "test@example.com").Return(errors.OutputApiError(
+			[]errors.ApiErrMessage{{Key: "invitationToken", Value: "招待が見つかりません"}}, http.StatusBadRequest, "Bad Request",
+		))
+
+		_, err := userService.CreateUserService(ctx, createUserForm)
+
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusBadRequest, responseStatusOf(err))
+		mockUserRepo.AssertNotCalled(t, "CreateUser")
+	})
+
+	t.Run("新規ユーザー作成_招待制で許可されていないドメイン", func(t *testing.T) {
+		t.Parallel()
+		ctx := context.Background()
+		mockUserRepo := new(MockUserRepository)
+		userService := user_service_impl.NewUserService(mockUserRepo, new(MockLoginHistoryRepository), new(MockInvitationAcceptor), newSignupPolicy(createUserFactory.SignupModeRestricted), newTokenIssuer(), new(MockOutboxRepository), newTransactionManager())
+		createUserForm := inputUser.CreateUserForm{
+			Email:    "test@example.com",
+			UserName: "testuser",
+			Password: "Password123",
+		}
+
+		_, err := userService.CreateUserService(ctx, createUserForm)
+
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusForbidden, responseStatusOf(err))
+		mockUserRepo.AssertNotCalled(t, "CreateUser")
 	})
 }
 
@@ -124,61 +269,49 @@ func TestLoginService(t *testing.T) {
 	t.Parallel()
 	ctx := context.Background()
 	mockUserRepo := new(MockUserRepository)
-	userService := user_service_impl.NewUserService(mockUserRepo)
+	mockLoginHistoryRepo := new(MockLoginHistoryRepository)
+	mockOutboxRepo := new(MockOutboxRepository)
+	userService := user_service_impl.NewUserService(mockUserRepo, mockLoginHistoryRepo, new(MockInvitationAcceptor), newSignupPolicy(createUserFactory.SignupModeOpen), newTokenIssuer(), mockOutboxRepo, newTransactionManager())
 
 	t.Run("ログイン_正常系", func(t *testing.T) {
 		t.Parallel()
 		// テスト用のリクエストボディを作成
 		loginForm := inputUser.LoginForm{
-			Email:    "test@example.com",
-			Password: "password",
+			Email:     "test@example.com",
+			Password:  "password",
+			IpAddress: "192.0.2.1",
+			UserAgent: "test-agent",
 		}
-		requestBody, _ := json.Marshal(loginForm)
 
 		// モックの設定
 		hashedPassword, _ := crypto.PasswordEncrypt("password")
 		mockUserRepo.On("FindUserByEmail", ctx, loginForm.Email).Return(&entity.User{
+			UserId:   "0192a7e4-5c1b-7d2e-9f3a-4b5c6d7e8f90",
 			Email:    loginForm.Email,
 			UserName: "testuser",
 			Password: hashedPassword,
 		}, nil)
-
-		// リクエストの作成
-		c, _ := gin.CreateTestContext(httptest.NewRecorder())
-		c.Request = httptest.NewRequest("POST", "/login", bytes.NewBuffer(requestBody))
+		mockLoginHistoryRepo.On("CreateLoginHistory", ctx, mock.Anything).Return(nil)
+		// ログイン履歴と同じトランザクションでログインのイベントを保存する
+		mockOutboxRepo.On("AddEvents", ctx, mock.MatchedBy(func(events []event.Event) bool {
+			loggedIn, ok := events[0].(event.UserLoggedIn)
+			return len(events) == 1 && ok && loggedIn.UserId == "0192a7e4-5c1b-7d2e-9f3a-4b5c6d7e8f90" &&
+				loggedIn.IpAddress == "192.0.2.1" && loggedIn.UserAgent == "test-agent"
+		})).Return(nil)
 
 		// テスト対象の関数を実行
-		presenter, err := userService.LoginService(ctx, c)
+		presenter, err := userService.LoginService(ctx, loginForm)
 
 		// アサーション
 		assert.NoError(t, err)
 		assert.Equal(t, loginForm.Email, presenter.Email)
+		assert.Equal(t, "0192a7e4-5c1b-7d2e-9f3a-4b5c6d7e8f90", presenter.UserId)
+		claims, err := newTokenIssuer().Parse(presenter.AccessToken)
+		assert.NoError(t, err)
+		assert.Equal(t, presenter.UserId, claims.Subject)
 		mockUserRepo.AssertExpectations(t)
-	})
-
-	t.Run("ログイン_バリデーションエラー", func(t *testing.T) {
-		t.Parallel()
-		// テスト用のリクエストボディを作成
-		loginForm := inputUser.LoginForm{
-			Email:    "invalid_email",
-			Password: "password",
-		}
-		requestBody, _ := json.Marshal(loginForm)
-
-		// リクエストの作成
-		w := httptest.NewRecorder()
-		c, _ := gin.CreateTestContext(w)
-		c.Request = httptest.NewRequest("POST", "/login", bytes.NewBuffer(requestBody))
-
-		// テスト対象の関数を実行
-		result, err := userService.LoginService(ctx, c)
-
-		// アサーション
-		assert.Error(t, err)
-		assert.Empty(t, result)
-		assert.Equal(t, http.StatusBadRequest, w.Code)
-		mockUserRepo.AssertNotCalled(t, "FindUserByEmail")
-		mockUserRepo.AssertNotCalled(t, "CreateUser")
+		mockLoginHistoryRepo.AssertExpectations(t)
+		mockOutboxRepo.AssertExpectations(t)
 	})
 
 	t.Run("ログイン_メールアドレス存在しない", func(t *testing.T) {
@@ -188,18 +321,12 @@ func TestLoginService(t *testing.T) {
 			Email:    "nonexistent@example.com",
 			Password: "Password1234",
 		}
-		requestBody, _ := json.Marshal(loginForm)
 
 		// モックの設定
 		mockUserRepo.On("FindUserByEmail", ctx, loginForm.Email).Return(&entity.User{}, fmt.Errorf("条件に一致するレコードが見つかりません: record not found"))
 
-		// リクエストの作成
-		w := httptest.NewRecorder()
-		c, _ := gin.CreateTestContext(w)
-		c.Request = httptest.NewRequest("POST", "/login", bytes.NewBuffer(requestBody))
-
 		// テスト対象の関数を実行
-		_, err := userService.LoginService(ctx, c)
+		_, err := userService.LoginService(ctx, loginForm)
 		// アサーション
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "Emailアドレスが存在しません")
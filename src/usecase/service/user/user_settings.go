@@ -0,0 +1,48 @@
+package user
+
+import (
+	"context"
+	"fmt"
+
+	domainEntity "github.com/Go_CleanArch/domain/entity"
+	"github.com/Go_CleanArch/interface_adapter/gateway/entity"
+	repository "github.com/Go_CleanArch/usecase/repository_interface"
+	log "github.com/sirupsen/logrus"
+)
+
+// ユーザーの設定値の取得(未設定の項目は既定値で補完する)
+func findUserSettings(ctx context.Context, userSettingRepository repository.UserSettingRepositoryInterface, userId string) (*domainEntity.UserSettings, error) {
+	storedSettings, err := userSettingRepository.FindUserSettings(ctx, userId)
+	if err != nil {
+		log.WithError(err).Error("Failed to find user settings")
+		return nil, fmt.Errorf("設定値の取得に失敗しました: %w", err)
+	}
+
+	settings, apiErr := domainEntity.NewUserSettings(
+		domainEntity.WithUserSettingsUserID(userId),
+		domainEntity.WithStoredSettings(storedSettingValues(storedSettings)),
+	)
+	if apiErr != nil {
+		return nil, apiErr.Error()
+	}
+	return settings, nil
+}
+
+// 他のユースケースから設定値を参照する
+// 設定値の取得に失敗しても本来の処理は継続できるよう、既定値にフォールバックする。
+func userSettingsOrDefault(ctx context.Context, userSettingRepository repository.UserSettingRepositoryInterface, userId string) *domainEntity.UserSettings {
+	settings, err := findUserSettings(ctx, userSettingRepository, userId)
+	if err != nil {
+		log.WithField("userId", userId).Warn("Fall back to default user settings")
+		settings, _ = domainEntity.NewUserSettings(domainEntity.WithUserSettingsUserID(userId))
+	}
+	return settings
+}
+
+func storedSettingValues(storedSettings []entity.UserSetting) map[string]string {
+	values := make(map[string]string, len(storedSettings))
+	for _, setting := range storedSettings {
+		values[setting.SettingKey] = setting.SettingValue
+	}
+	return values
+}